@@ -8,12 +8,18 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/diogocarasco/go-pharmacy-service/internal/adjudication"
 	"github.com/diogocarasco/go-pharmacy-service/internal/api"
 	"github.com/diogocarasco/go-pharmacy-service/internal/auth"
+	"github.com/diogocarasco/go-pharmacy-service/internal/benefits"
 	"github.com/diogocarasco/go-pharmacy-service/internal/config"
 	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/eligibility"
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi"
 	"github.com/diogocarasco/go-pharmacy-service/internal/loader"
 	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/ncpdp"
+	"github.com/diogocarasco/go-pharmacy-service/internal/pricing"
 	"github.com/diogocarasco/go-pharmacy-service/internal/service"
 )
 
@@ -25,6 +31,9 @@ import (
 // @securityDefinitions.apiKey ApiKeyAuth
 // @in header
 // @name Authorization
+// @securityDefinitions.apiKey AdminKeyAuth
+// @in header
+// @name Authorization
 func main() {
 	log := logger.NewLogger()
 	log.Info("Starting pharmacy service...")
@@ -54,6 +63,13 @@ func main() {
 		}
 	}
 
+	if _, err := os.Stat(cfg.NCPDPBatchPath); os.IsNotExist(err) {
+		err = os.MkdirAll(cfg.NCPDPBatchPath, 0755)
+		if err != nil {
+			log.Fatal("Error creating NCPDP batch directory '%s': %v", cfg.NCPDPBatchPath, err)
+		}
+	}
+
 	dbRepo, err := database.InitDB(cfg.DatabasePath)
 	if err != nil {
 		log.Fatal("Error initializing database: %v", err)
@@ -83,27 +99,184 @@ func main() {
 	}
 	log.Info("CSV pharmacies loading completed.")
 
-	claimLoader := loader.NewClaimLoader(dbRepo)
+	claimCSVColumns, err := loader.ParseColumnMapping(cfg.ClaimsCSVColumns)
+	if err != nil {
+		log.Fatal("Error parsing CLAIMS_CSV_COLUMNS: %v", err)
+	}
+	revertCSVColumns, err := loader.ParseColumnMapping(cfg.RevertsCSVColumns)
+	if err != nil {
+		log.Fatal("Error parsing REVERTS_CSV_COLUMNS: %v", err)
+	}
+
+	claimCfg := service.ClaimConfig{
+		ReversalPolicy: service.ReversalPolicy{
+			MaxAge:      cfg.ReversalMaxAge,
+			ChainMaxAge: cfg.ReversalChainMaxAge,
+		},
+		RefillPolicy: service.RefillPolicy{ConsumedPercent: cfg.RefillTooSoonPercent},
+		TimelyFiling: service.TimelyFilingPolicy{
+			Limit:      cfg.TimelyFilingLimit,
+			ChainLimit: cfg.TimelyFilingChainLimit,
+		},
+	}
+	var rulesEngine *adjudication.Engine
+	if cfg.AdjudicationRules != "" {
+		rulesEngine, err = adjudication.NewEngine(cfg.AdjudicationRules, log)
+		if err != nil {
+			log.Fatal("Error loading adjudication rules: %v", err)
+		}
+		log.Info("Adjudication rules loaded from %s: %d rules.", cfg.AdjudicationRules, rulesEngine.RuleCount())
+		claimCfg.Adjudicator = rulesEngine
+	}
+	if cfg.PricingContracts != "" {
+		contracts, err := pricing.LoadContracts(cfg.PricingContracts)
+		if err != nil {
+			log.Fatal("Error loading pricing contracts: %v", err)
+		}
+		log.Info("Pricing contracts loaded from %s: %d chains, %d reference unit costs.", cfg.PricingContracts, len(contracts.Chains), len(contracts.ReferenceUnitCosts))
+		claimCfg.Pricer = contracts
+	}
+	var referenceBook *pricing.ReferenceBook
+	if _, err := os.Stat(cfg.ReferencePrices); os.IsNotExist(err) {
+		log.Warning("Reference price file %s not found, price variance checks are disabled.", cfg.ReferencePrices)
+	} else {
+		referenceBook, err = pricing.NewReferenceBook(cfg.ReferencePrices, log)
+		if err != nil {
+			log.Fatal("Error loading reference prices: %v", err)
+		}
+		log.Info("Reference prices loaded from %s: %d prices, variance threshold %g%%.", cfg.ReferencePrices, referenceBook.Len(), cfg.PriceVarianceThreshold)
+		claimCfg.PriceVariance = service.PriceVarianceConfig{
+			References:       referenceBook,
+			ThresholdPercent: cfg.PriceVarianceThreshold,
+		}
+	}
+	var rosterBook *eligibility.RosterBook
+	if _, err := os.Stat(cfg.EligibilityRoster); os.IsNotExist(err) {
+		log.Warning("Eligibility roster %s not found, eligibility checks are disabled.", cfg.EligibilityRoster)
+	} else {
+		rosterBook, err = eligibility.NewRosterBook(cfg.EligibilityRoster, log)
+		if err != nil {
+			log.Fatal("Error loading eligibility roster: %v", err)
+		}
+		log.Info("Eligibility roster loaded from %s: %d members.", cfg.EligibilityRoster, rosterBook.Len())
+		claimCfg.Eligibility = rosterBook
+	}
+	if _, err := os.Stat(cfg.BenefitPlans); os.IsNotExist(err) {
+		log.Warning("Plan benefit file %s not found, patient pay and accumulators are disabled.", cfg.BenefitPlans)
+	} else {
+		plans, err := benefits.LoadPlans(cfg.BenefitPlans)
+		if err != nil {
+			log.Fatal("Error loading plan benefits: %v", err)
+		}
+		log.Info("Plan benefits loaded from %s: %d plans.", cfg.BenefitPlans, len(plans.Plans))
+		if claimCfg.Eligibility == nil {
+			log.Warning("Patient pay needs the plan of members from the eligibility roster and is disabled without it.")
+		}
+		claimCfg.Benefits = plans
+	}
+	claimService := service.NewClaimService(log, dbRepo, claimCfg)
+	authenticator := auth.NewAuthenticator(cfg.AuthToken, log)
+	handlers := api.NewHandlers(claimService, log)
+	ncpdpProcessor := ncpdp.NewProcessor(claimService, log, cfg.NCPDPBIN)
+
+	// Files left in the drop folders are ingested and archived before the watchers start,
+	// claims before reverts so that the reverts find their claims.
+	claimLoader := loader.NewClaimLoader(dbRepo, claimService, loader.NewClaimDecoderRegistry(claimCSVColumns), cfg.QuarantinePath)
+	claimWatcher := loader.NewDirWatcher("claims", cfg.ClaimsDataPath, cfg.WatchInterval, claimLoader.LoadAndSaveClaimsFromFile)
 	log.Info("Starting claims loading from directory: %s...", cfg.ClaimsDataPath)
-	if err := claimLoader.LoadAndSaveClaimsFromDir(cfg.ClaimsDataPath); err != nil {
+	if err := claimWatcher.IngestExisting(); err != nil {
 		log.Error("Error loading and saving claims: %v", err)
 	}
 	log.Info("Claims loading completed.")
 
-	revertLoader := loader.NewRevertLoader(dbRepo)
+	revertLoader := loader.NewRevertLoader(dbRepo, claimCfg.ReversalPolicy, loader.NewRevertDecoderRegistry(revertCSVColumns), cfg.QuarantinePath)
+	revertWatcher := loader.NewDirWatcher("reverts", cfg.RevertsDataPath, cfg.WatchInterval, revertLoader.LoadAndSaveRevertsFromFile)
 	log.Info("Starting reverts loading from directory: %s...", cfg.RevertsDataPath)
-	if err := revertLoader.LoadAndSaveRevertsFromDir(cfg.RevertsDataPath); err != nil {
+	if err := revertWatcher.IngestExisting(); err != nil {
 		log.Error("Error loading and saving reverts: %v", err)
 	}
 	log.Info("Reverts loading completed.")
 
-	claimService := service.NewClaimService(log, dbRepo)
-	authenticator := auth.NewAuthenticator(cfg.AuthToken, log)
-	handlers := api.NewHandlers(claimService, log)
+	batchLoader := loader.NewNCPDPBatchLoader(ncpdpProcessor, cfg.NCPDPResponsePath)
+	log.Info("Starting NCPDP batch replay from directory: %s...", cfg.NCPDPBatchPath)
+	if err := batchLoader.LoadBatchesFromDir(cfg.NCPDPBatchPath); err != nil {
+		log.Error("Error replaying NCPDP batches: %v", err)
+	}
+	log.Info("NCPDP batch replay completed.")
+
+	watchCtx, stopWatchers := context.WithCancel(context.Background())
+	defer stopWatchers()
+
+	batchWatcher := loader.NewDirWatcher("ncpdp_batches", cfg.NCPDPBatchPath, cfg.WatchInterval, batchLoader.LoadBatchFile)
+	watchers := []*loader.DirWatcher{claimWatcher, revertWatcher, batchWatcher}
+	for _, w := range watchers {
+		go func(w *loader.DirWatcher) {
+			if err := w.Run(watchCtx); err != nil {
+				log.Error("Directory watcher stopped: %v", err)
+			}
+		}(w)
+	}
+	if rulesEngine != nil {
+		go rulesEngine.Run(watchCtx, cfg.WatchInterval)
+	}
+	if rosterBook != nil {
+		go rosterBook.Run(watchCtx, cfg.WatchInterval)
+	}
+	if referenceBook != nil {
+		go referenceBook.Run(watchCtx, cfg.WatchInterval)
+		varianceJob := service.NewPriceVarianceJob(log, dbRepo, claimCfg.PriceVariance, cfg.PriceVarianceLookback)
+		go varianceJob.Run(watchCtx, cfg.PriceVarianceJobTime)
+	}
+
+	anomalyService := service.NewAnomalyService(log, dbRepo, service.AnomalyConfig{
+		Window:              cfg.AnomalyWindow,
+		Baseline:            cfg.AnomalyBaseline,
+		SpikeFactor:         cfg.AnomalySpikeFactor,
+		ReversalRatePercent: cfg.AnomalyReversalRate,
+		PriceFactor:         cfg.AnomalyPriceFactor,
+		MinClaims:           cfg.AnomalyMinClaims,
+	})
+	go anomalyService.Run(watchCtx, cfg.AnomalyInterval)
+
+	remittanceService := service.NewRemittanceService(log, dbRepo, service.RemittanceConfig{
+		SenderID:   cfg.X12SenderID,
+		PayerName:  cfg.X12PayerName,
+		PayerID:    cfg.X12PayerID,
+		ExportDir:  cfg.RemittancePath,
+		Production: cfg.X12Production,
+		Benefits:   claimCfg.Benefits,
+	})
 
 	routerCfg := api.RouterConfig{
-		Handlers:      handlers,
-		Authenticator: authenticator,
+		Handlers:           handlers,
+		BatchHandlers:      api.NewBatchHandlers(claimService, log, cfg.ClaimBatchMaxSize),
+		NCPDPHandlers:      api.NewNCPDPHandlers(ncpdpProcessor, log),
+		RemittanceHandlers: api.NewRemittanceHandlers(remittanceService, log),
+		FHIRHandlers:       api.NewFHIRHandlers(claimService, log, cfg.X12PayerName),
+		ReportHandlers:     api.NewReportHandlers(service.NewReportService(log, dbRepo), log),
+		AnomalyHandlers:    api.NewAnomalyHandlers(anomalyService, log),
+		Authenticator:      authenticator,
+
+		AccumulatorHandlers: api.NewAccumulatorHandlers(service.NewAccumulatorService(log, dbRepo, claimCfg.Eligibility, claimCfg.Benefits), log),
+	}
+	if rosterBook != nil {
+		routerCfg.EligibilityHandlers = api.NewEligibilityHandlers(service.NewEligibilityService(log, rosterBook), log)
+	}
+	if _, err := os.Stat(cfg.NDCCatalog); os.IsNotExist(err) {
+		log.Warning("NDC catalog %s not found, the generic substitution report is disabled.", cfg.NDCCatalog)
+	} else {
+		catalog, err := pricing.LoadNDCCatalog(cfg.NDCCatalog)
+		if err != nil {
+			log.Fatal("Error loading NDC catalog: %v", err)
+		}
+		log.Info("NDC catalog loaded from %s: %d NDCs.", cfg.NDCCatalog, catalog.Len())
+		routerCfg.SubstitutionHandlers = api.NewSubstitutionHandlers(service.NewSubstitutionService(log, dbRepo, catalog), log)
+	}
+	if cfg.AdminAuthToken != "" {
+		reinstatementService := service.NewReinstatementService(log, dbRepo, cfg.ReinstateWindow)
+		resolutionService := service.NewResolutionService(log, dbRepo)
+		routerCfg.AdminHandlers = api.NewAdminHandlers(reinstatementService, resolutionService, log)
+		routerCfg.AdminAuthenticator = auth.NewAuthenticator(cfg.AdminAuthToken, log)
 	}
 	mux := api.NewRouter(routerCfg)
 
@@ -119,20 +292,66 @@ func main() {
 		}
 	}()
 
+	var ncpdpServer *ncpdp.TCPServer
+	if cfg.NCPDPTCPPort != "" && len(cfg.NCPDPTCPAllowed) == 0 {
+		log.Warning("NCPDP_TCP_ALLOWED_IPS not defined, the NCPDP TCP listener is disabled.")
+	} else if cfg.NCPDPTCPPort != "" {
+		ncpdpServer = ncpdp.NewTCPServer(":"+cfg.NCPDPTCPPort, ncpdpProcessor, log, cfg.NCPDPTCPAllowed)
+		go func() {
+			log.Info("NCPDP TCP listener starting on port %s...", cfg.NCPDPTCPPort)
+			if err := ncpdpServer.ListenAndServe(); err != nil && err != ncpdp.ErrServerClosed {
+				log.Fatal("NCPDP TCP listener failed: %v", err)
+			}
+		}()
+	}
+
+	pharmacyService := service.NewPharmacyService(log, dbRepo)
+	grpcServer := grpcapi.NewServer(":"+cfg.GRPCPort, claimService, pharmacyService, authenticator, log)
+	go func() {
+		log.Info("gRPC server starting on port %s...", cfg.GRPCPort)
+		if err := grpcServer.ListenAndServe(); err != nil && err != grpcapi.ErrServerClosed {
+			log.Fatal("gRPC server failed: %v", err)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	<-quit
 
 	log.Info("Shutdown signal received. Shutting down server...")
+	stopWatchers()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	for _, w := range watchers {
+		select {
+		case <-w.Done():
+		case <-ctx.Done():
+			log.Error("Directory watcher still ingesting at shutdown: %v", ctx.Err())
+		}
+	}
+	log.Info("Directory watchers stopped.")
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Error("Server forced to shutdown (timeout or error): %v", err)
 	} else {
 		log.Info("Server shut down gracefully.")
 	}
 
+	if err := grpcServer.Shutdown(ctx); err != nil {
+		log.Error("gRPC server forced to shutdown: %v", err)
+	} else {
+		log.Info("gRPC server shut down gracefully.")
+	}
+
+	if ncpdpServer != nil {
+		if err := ncpdpServer.Shutdown(ctx); err != nil {
+			log.Error("NCPDP TCP listener forced to shutdown: %v", err)
+		} else {
+			log.Info("NCPDP TCP listener shut down gracefully.")
+		}
+	}
+
 	log.Info("Pharmacy service terminated.")
 }
@@ -0,0 +1,158 @@
+package adjudication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/reloader"
+)
+
+// Evaluate applies the rules to a claim submitted by a pharmacy of the chain. The claim is
+// rejected when any reject rule fails, pended when only pend rules fail and paid otherwise.
+// Every failed rule is reported, rejects before pends.
+func Evaluate(rules *RuleSet, claim models.ClaimSubmissionRequest, chain string) models.Adjudication {
+	var rejects, pends []models.ClaimReject
+	if rules != nil {
+		for _, rule := range rules.Rules {
+			if !rule.appliesTo(chain) {
+				continue
+			}
+			failures := rule.check(claim)
+			if rule.Action == ActionPend {
+				pends = append(pends, failures...)
+			} else {
+				rejects = append(rejects, failures...)
+			}
+		}
+	}
+
+	switch {
+	case len(rejects) > 0:
+		return models.Adjudication{Status: models.ClaimStatusRejected, Rejects: append(rejects, pends...)}
+	case len(pends) > 0:
+		return models.Adjudication{Status: models.ClaimStatusPending, Rejects: pends}
+	default:
+		return models.Adjudication{Status: models.ClaimStatusPaid}
+	}
+}
+
+// appliesTo reports whether the rule applies to the pharmacies of the chain.
+func (r Rule) appliesTo(chain string) bool {
+	if len(r.Chains) == 0 {
+		return true
+	}
+	for _, c := range r.Chains {
+		if strings.EqualFold(c, chain) {
+			return true
+		}
+	}
+	return false
+}
+
+// coversNDC reports whether a max rule applies to the NDC; a rule without NDCs covers every NDC.
+func (r Rule) coversNDC(ndc string) bool {
+	return len(r.NDCs) == 0 || containsNDC(r.NDCs, ndc)
+}
+
+// check returns the rejects of the claim for the rule, empty when the claim passes.
+func (r Rule) check(claim models.ClaimSubmissionRequest) []models.ClaimReject {
+	switch r.Type {
+	case RuleMaxQuantity:
+		if r.coversNDC(claim.NDC) && claim.Quantity > r.Max {
+			return []models.ClaimReject{r.reject("", fmt.Sprintf("quantity %g exceeds the maximum of %g for NDC %s", claim.Quantity, r.Max, claim.NDC))}
+		}
+	case RuleMaxUnitPrice:
+		if r.coversNDC(claim.NDC) && claim.Quantity > 0 {
+			if unitPrice := claim.Price / claim.Quantity; unitPrice > r.Max {
+				return []models.ClaimReject{r.reject("", fmt.Sprintf("unit price %.4f exceeds the maximum of %.4f for NDC %s", unitPrice, r.Max, claim.NDC))}
+			}
+		}
+	case RuleBlockedNDCs:
+		if containsNDC(r.NDCs, claim.NDC) {
+			return []models.ClaimReject{r.reject("", fmt.Sprintf("NDC %s is not covered", claim.NDC))}
+		}
+	case RuleAllowedNDCs:
+		if !containsNDC(r.NDCs, claim.NDC) {
+			return []models.ClaimReject{r.reject("", fmt.Sprintf("NDC %s is not on the formulary", claim.NDC))}
+		}
+	case RuleRequiredFields:
+		var rejects []models.ClaimReject
+		for _, name := range r.Fields {
+			field := claimFields[name]
+			if !field.present(claim) {
+				rejects = append(rejects, r.reject(field.rejectCode, fmt.Sprintf("missing or invalid %s", name)))
+			}
+		}
+		return rejects
+	}
+	return nil
+}
+
+// reject builds the reject of a failed rule. The reject code and message of the rule, when set,
+// take precedence over fieldCode, the default code of the rule type and the generated message.
+func (r Rule) reject(fieldCode, message string) models.ClaimReject {
+	code := r.RejectCode
+	if code == "" {
+		code = fieldCode
+	}
+	if code == "" {
+		code = defaultRejectCodes[r.Type]
+	}
+	if r.Message != "" {
+		message = r.Message
+	}
+	return models.ClaimReject{Code: code, Rule: r.Name, Message: message}
+}
+
+// containsNDC reports whether ndc is in the list.
+func containsNDC(ndcs []string, ndc string) bool {
+	for _, n := range ndcs {
+		if n == ndc {
+			return true
+		}
+	}
+	return false
+}
+
+// Engine adjudicates claims with the rules of a file, reloaded whenever the file changes.
+// It is safe for concurrent use.
+type Engine struct {
+	file   *reloader.File[*RuleSet]
+	logger logger.Logger
+}
+
+// NewEngine loads the rules file at path. An invalid file is an error.
+func NewEngine(path string, log logger.Logger) (*Engine, error) {
+	e := &Engine{file: reloader.New(path, "adjudication rules", LoadRules, nil, log), logger: log}
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Reload reads the rules file again when it changed and reports whether new rules were loaded,
+// see reloader.File.Reload.
+func (e *Engine) Reload() (bool, error) {
+	return e.file.Reload()
+}
+
+// Run checks the rules file for changes every interval until ctx is cancelled.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	e.file.Run(ctx, interval, func() {
+		e.logger.Info("Adjudication rules reloaded from %s: %d rules.", e.file.Path(), e.RuleCount())
+	})
+}
+
+// RuleCount returns the number of rules in use.
+func (e *Engine) RuleCount() int {
+	return len(e.file.Value().Rules)
+}
+
+// Adjudicate applies the rules in use to a claim submitted by a pharmacy of the chain.
+func (e *Engine) Adjudicate(claim models.ClaimSubmissionRequest, chain string) models.Adjudication {
+	return Evaluate(e.file.Value(), claim, chain)
+}
@@ -0,0 +1,165 @@
+package adjudication_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/adjudication"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+const testRulesYAML = `
+rules:
+  - name: max-qty-oxycodone
+    type: max_quantity
+    ndcs: ["00406055262"]
+    max: 120
+  - name: max-unit-price
+    type: max_unit_price
+    max: 50
+    action: pend
+  - name: blocked
+    type: blocked_ndcs
+    ndcs: ["99999999999"]
+  - name: chain-a-formulary
+    type: allowed_ndcs
+    chains: ["chain-a"]
+    ndcs: ["00002323401", "00406055262"]
+  - name: required
+    type: required_fields
+    fields: [ndc, quantity, price]
+`
+
+func writeRules(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing rules file: %v", err)
+	}
+	return path
+}
+
+func loadTestRules(t *testing.T) *adjudication.RuleSet {
+	rules, err := adjudication.LoadRules(writeRules(t, "rules.yaml", testRulesYAML))
+	assert.Nil(t, err, "Expected no error loading the rules")
+	return rules
+}
+
+func rejectCodes(result models.Adjudication) []string {
+	codes := make([]string, len(result.Rejects))
+	for i, reject := range result.Rejects {
+		codes[i] = reject.Code
+	}
+	return codes
+}
+
+func TestEvaluate(t *testing.T) {
+	rules := loadTestRules(t)
+
+	tests := []struct {
+		name   string
+		claim  models.ClaimSubmissionRequest
+		chain  string
+		status string
+		codes  []string
+	}{
+		{"within limits", models.ClaimSubmissionRequest{NDC: "00406055262", Quantity: 30, Price: 60}, "", models.ClaimStatusPaid, []string{}},
+		{"quantity above max", models.ClaimSubmissionRequest{NDC: "00406055262", Quantity: 200, Price: 400}, "", models.ClaimStatusRejected, []string{"76"}},
+		{"unit price above max pends", models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 1, Price: 80}, "", models.ClaimStatusPending, []string{"78"}},
+		{"blocked NDC", models.ClaimSubmissionRequest{NDC: "99999999999", Quantity: 1, Price: 1}, "", models.ClaimStatusRejected, []string{"70"}},
+		{"off chain formulary", models.ClaimSubmissionRequest{NDC: "12345678901", Quantity: 1, Price: 1}, "chain-a", models.ClaimStatusRejected, []string{"MR"}},
+		{"formulary of another chain", models.ClaimSubmissionRequest{NDC: "12345678901", Quantity: 1, Price: 1}, "chain-b", models.ClaimStatusPaid, []string{}},
+		{"missing fields", models.ClaimSubmissionRequest{Quantity: 0, Price: 0}, "", models.ClaimStatusRejected, []string{"21", "E7", "DU"}},
+		{"reject and pend", models.ClaimSubmissionRequest{NDC: "99999999999", Quantity: 1, Price: 80}, "", models.ClaimStatusRejected, []string{"70", "78"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := adjudication.Evaluate(rules, tt.claim, tt.chain)
+			assert.Equal(t, tt.status, result.Status)
+			assert.Equal(t, tt.codes, rejectCodes(result))
+		})
+	}
+}
+
+func TestEvaluateRuleOverrides(t *testing.T) {
+	rules := &adjudication.RuleSet{Rules: []adjudication.Rule{
+		{Name: "custom", Type: adjudication.RuleBlockedNDCs, NDCs: []string{"111"}, RejectCode: "A1", Message: "recalled"},
+	}}
+
+	result := adjudication.Evaluate(rules, models.ClaimSubmissionRequest{NDC: "111", Quantity: 1, Price: 1}, "")
+	assert.Equal(t, []models.ClaimReject{{Code: "A1", Rule: "custom", Message: "recalled"}}, result.Rejects)
+}
+
+func TestEvaluateRequiredPrescriptionFields(t *testing.T) {
+	rules := &adjudication.RuleSet{Rules: []adjudication.Rule{
+		{Name: "prescription", Type: adjudication.RuleRequiredFields, Fields: []string{"member_id", "prescriber_npi", "days_supply"}},
+	}}
+	claim := models.ClaimSubmissionRequest{NDC: "111", Quantity: 1, Price: 1, Prescription: models.Prescription{MemberID: "M1001"}}
+
+	result := adjudication.Evaluate(rules, claim, "")
+	assert.Equal(t, models.ClaimStatusRejected, result.Status)
+	assert.Equal(t, []string{"25", "19"}, rejectCodes(result))
+}
+
+func TestLoadRulesJSON(t *testing.T) {
+	path := writeRules(t, "rules.json", `{"rules": [{"name": "blocked", "type": "blocked_ndcs", "ndcs": ["111"]}]}`)
+
+	rules, err := adjudication.LoadRules(path)
+	assert.Nil(t, err, "Expected no error loading JSON rules")
+	assert.Len(t, rules.Rules, 1)
+	assert.Equal(t, adjudication.RuleBlockedNDCs, rules.Rules[0].Type)
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown type":     "rules:\n  - name: a\n    type: max_days\n",
+		"missing max":      "rules:\n  - name: a\n    type: max_quantity\n",
+		"unknown action":   "rules:\n  - name: a\n    type: blocked_ndcs\n    ndcs: ['1']\n    action: hold\n",
+		"unknown field":    "rules:\n  - name: a\n    type: required_fields\n    fields: [dea]\n",
+		"duplicate name":   "rules:\n  - name: a\n    type: blocked_ndcs\n    ndcs: ['1']\n  - name: a\n    type: blocked_ndcs\n    ndcs: ['2']\n",
+		"unknown property": "rules:\n  - name: a\n    type: blocked_ndcs\n    ndc: ['1']\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := adjudication.LoadRules(writeRules(t, "rules.yaml", content))
+			assert.NotNil(t, err, "Expected an error for %s", name)
+		})
+	}
+}
+
+func TestEngineReload(t *testing.T) {
+	path := writeRules(t, "rules.yaml", "rules:\n  - name: blocked\n    type: blocked_ndcs\n    ndcs: ['111']\n")
+	engine, err := adjudication.NewEngine(path, logger.NewLogger())
+	assert.Nil(t, err, "Expected no error creating the engine")
+
+	claim := models.ClaimSubmissionRequest{NDC: "222", Quantity: 1, Price: 1}
+	assert.Equal(t, models.ClaimStatusPaid, engine.Adjudicate(claim, "").Status)
+
+	reloaded, err := engine.Reload()
+	assert.Nil(t, err)
+	assert.False(t, reloaded, "Expected no reload of an unchanged file")
+
+	// Invalid rules are refused and the previous ones kept
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(path, []byte("rules:\n  - name: broken\n"), 0644)
+	os.Chtimes(path, later, later)
+	_, err = engine.Reload()
+	assert.NotNil(t, err, "Expected an error reloading invalid rules")
+	assert.Equal(t, models.ClaimStatusPaid, engine.Adjudicate(claim, "").Status)
+	_, err = engine.Reload()
+	assert.Nil(t, err, "Expected an invalid file to be reported only once")
+
+	later = later.Add(time.Minute)
+	os.WriteFile(path, []byte("rules:\n  - name: blocked\n    type: blocked_ndcs\n    ndcs: ['222']\n"), 0644)
+	os.Chtimes(path, later, later)
+	reloaded, err = engine.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded, "Expected the changed file to be reloaded")
+	assert.Equal(t, models.ClaimStatusRejected, engine.Adjudicate(claim, "").Status)
+}
@@ -0,0 +1,148 @@
+package adjudication
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Rule types.
+const (
+	RuleMaxQuantity    = "max_quantity"    // Quantity above Max, for the listed NDCs or any NDC
+	RuleMaxUnitPrice   = "max_unit_price"  // Price per unit above Max, for the listed NDCs or any NDC
+	RuleBlockedNDCs    = "blocked_ndcs"    // NDC in the list
+	RuleAllowedNDCs    = "allowed_ndcs"    // NDC not in the list, e.g. the formulary of a chain
+	RuleRequiredFields = "required_fields" // Any of the listed claim fields missing
+)
+
+// Rule actions.
+const (
+	ActionReject = "reject" // The claim is rejected
+	ActionPend   = "pend"   // The claim is pended for manual review
+)
+
+// defaultRejectCodes are the NCPDP-like reject codes of each rule type.
+var defaultRejectCodes = map[string]string{
+	RuleMaxQuantity:  "76", // Plan Limitations Exceeded
+	RuleMaxUnitPrice: "78", // Cost Exceeds Maximum
+	RuleBlockedNDCs:  "70", // Product/Service Not Covered
+	RuleAllowedNDCs:  "MR", // Product Not On Formulary
+}
+
+// claimField reads one field of a claim submission for the required_fields rule.
+type claimField struct {
+	present    func(claim models.ClaimSubmissionRequest) bool
+	rejectCode string // M/I (missing/invalid) reject code of the field
+}
+
+// claimFields lists the fields a required_fields rule can name.
+var claimFields = map[string]claimField{
+	"ndc":      {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.NDC) != "" }, "21"},
+	"npi":      {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.NPI) != "" }, "05"},
+	"quantity": {func(c models.ClaimSubmissionRequest) bool { return c.Quantity > 0 }, "E7"},
+	"price":    {func(c models.ClaimSubmissionRequest) bool { return c.Price > 0 }, "DU"},
+
+	"member_id":       {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.MemberID) != "" }, "07"},
+	"prescriber_npi":  {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.PrescriberNPI) != "" }, "25"},
+	"rx_number":       {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.RxNumber) != "" }, "16"},
+	"days_supply":     {func(c models.ClaimSubmissionRequest) bool { return c.DaysSupply > 0 }, "19"},
+	"date_of_service": {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.DateOfService) != "" }, "15"},
+	"daw_code":        {func(c models.ClaimSubmissionRequest) bool { return strings.TrimSpace(c.DAWCode) != "" }, "22"},
+}
+
+// Rule is one adjudication rule. Chains, when set, limits the rule to the pharmacies of those chains.
+type Rule struct {
+	Name       string   `json:"name" yaml:"name"`                                   // Unique name, reported with the reject code
+	Type       string   `json:"type" yaml:"type"`                                   // One of the rule types
+	Action     string   `json:"action,omitempty" yaml:"action,omitempty"`           // reject (default) or pend
+	RejectCode string   `json:"reject_code,omitempty" yaml:"reject_code,omitempty"` // Overrides the default reject code of the type
+	Message    string   `json:"message,omitempty" yaml:"message,omitempty"`         // Overrides the generated explanation
+	Chains     []string `json:"chains,omitempty" yaml:"chains,omitempty"`           // Pharmacy chains the rule applies to; empty for every chain
+	NDCs       []string `json:"ndcs,omitempty" yaml:"ndcs,omitempty"`               // NDCs the rule is about
+	Max        float64  `json:"max,omitempty" yaml:"max,omitempty"`                 // Limit of the max_quantity and max_unit_price rules
+	Fields     []string `json:"fields,omitempty" yaml:"fields,omitempty"`           // Fields of the required_fields rule
+}
+
+// RuleSet is the content of a rules file.
+type RuleSet struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// LoadRules reads and validates a rules file. Files ending in .json are decoded as JSON,
+// anything else as YAML.
+func LoadRules(path string) (*RuleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules file %s: %w", path, err)
+	}
+
+	var rules RuleSet
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&rules)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&rules)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding rules file %s: %w", path, err)
+	}
+
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+	return &rules, nil
+}
+
+// Validate checks that every rule has a unique name, a known type and action, and the
+// parameters its type needs.
+func (rs *RuleSet) Validate() error {
+	names := make(map[string]bool, len(rs.Rules))
+	for i, rule := range rs.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule name '%s' is used more than once", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Action != "" && rule.Action != ActionReject && rule.Action != ActionPend {
+			return fmt.Errorf("rule '%s' has unknown action '%s', expected %s or %s", rule.Name, rule.Action, ActionReject, ActionPend)
+		}
+
+		switch rule.Type {
+		case RuleMaxQuantity, RuleMaxUnitPrice:
+			if rule.Max <= 0 {
+				return fmt.Errorf("rule '%s' of type %s needs a positive max", rule.Name, rule.Type)
+			}
+		case RuleBlockedNDCs, RuleAllowedNDCs:
+			if len(rule.NDCs) == 0 {
+				return fmt.Errorf("rule '%s' of type %s needs ndcs", rule.Name, rule.Type)
+			}
+		case RuleRequiredFields:
+			if len(rule.Fields) == 0 {
+				return fmt.Errorf("rule '%s' of type %s needs fields", rule.Name, rule.Type)
+			}
+			for _, field := range rule.Fields {
+				if _, ok := claimFields[field]; !ok {
+					return fmt.Errorf("rule '%s' names unknown claim field '%s'", rule.Name, field)
+				}
+			}
+		case "":
+			return fmt.Errorf("rule '%s' has no type", rule.Name)
+		default:
+			return fmt.Errorf("rule '%s' has unknown type '%s'", rule.Name, rule.Type)
+		}
+	}
+	return nil
+}
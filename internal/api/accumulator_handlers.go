@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type AccumulatorHandlers struct {
+	accumulatorService service.AccumulatorService
+	logger             logger.Logger
+}
+
+func NewAccumulatorHandlers(accumulatorService service.AccumulatorService, log logger.Logger) *AccumulatorHandlers {
+	return &AccumulatorHandlers{
+		accumulatorService: accumulatorService,
+		logger:             log,
+	}
+}
+
+// GetAccumulatorsHandler returns the accumulators of a member via HTTP GET.
+// @Summary Get the accumulators of a member
+// @Description Returns the deductible met and out-of-pocket spent by a member in a plan year (the calendar year of the dates of service), with what remains of the deductible and out-of-pocket maximum of the member's plan. Paid claims add their patient pay; reversals roll it back.
+// @Tags eligibility
+// @Produce json
+// @Security ApiKeyAuth
+// @Param member_id path string true "Member ID"
+// @Param plan_year query int false "Plan year, the current year by default"
+// @Success 200 {object} models.MemberAccumulators "Accumulators of the member"
+// @Failure 400 "Invalid plan year"
+// @Failure 500 "Internal server error"
+// @Router /members/{member_id}/accumulators [get]
+func (h *AccumulatorHandlers) GetAccumulatorsHandler(w http.ResponseWriter, r *http.Request) {
+	memberID := mux.Vars(r)["member_id"]
+
+	accumulators, err := h.accumulatorService.GetAccumulators(memberID, r.URL.Query().Get("plan_year"))
+	if err != nil {
+		h.logger.Error("Error fetching accumulators of member %s: %v", memberID, err)
+		if errors.Is(err, service.ErrInvalidPlanYear) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(accumulators)
+}
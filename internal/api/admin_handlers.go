@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandlers serves the privileged operations, authenticated with the admin token.
+type AdminHandlers struct {
+	reinstatementService service.ReinstatementService
+	resolutionService    service.ResolutionService
+	logger               logger.Logger
+}
+
+func NewAdminHandlers(reinstatementService service.ReinstatementService, resolutionService service.ResolutionService, log logger.Logger) *AdminHandlers {
+	return &AdminHandlers{
+		reinstatementService: reinstatementService,
+		resolutionService:    resolutionService,
+		logger:               log,
+	}
+}
+
+// ReinstateClaimHandler handles the reinstatement of a reversed claim via HTTP POST.
+// @Summary Reinstate a reversed claim
+// @Description Undoes a reversal: the revert, the requested one or else the latest one, is marked as voided with the reason and a reversed claim moves back to paid. Only allowed within the configured window after the reversal. Requires the admin token.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security AdminKeyAuth
+// @Param id path string true "ID of the claim to reinstate"
+// @Param reinstatement body models.ClaimReinstateRequest true "Revert to void and audit reason"
+// @Success 200 {object} models.Claim "Reinstated claim"
+// @Failure 400 "Invalid request or missing reason"
+// @Failure 404 "Claim or active revert not found"
+// @Failure 409 "Claim status does not allow a reinstatement or the window expired"
+// @Failure 500 "Internal server error"
+// @Router /claim/{id}/reinstate [post]
+func (h *AdminHandlers) ReinstateClaimHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ClaimReinstateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding ReinstateClaim request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	req.ClaimID = mux.Vars(r)["id"]
+	req.Actor = models.ActorAPI
+
+	claim, err := h.reinstatementService.ReinstateClaim(req)
+	if err != nil {
+		h.logger.Error("Error reinstating claim %s: %v", req.ClaimID, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidReinstatement):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrClaimNotFound), errors.Is(err, service.ErrRevertNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrReinstatementWindowExpired):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(claim)
+	h.logger.Info("Claim %s reinstated via API.", claim.ID)
+}
+
+// ResolveClaimHandler handles the resolution of a pending claim via HTTP POST.
+// @Summary Resolve a pending claim
+// @Description Moves a claim pended at adjudication to paid or rejected after its manual review, recording the reason in its status history. Requires the admin token.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security AdminKeyAuth
+// @Param id path string true "ID of the pending claim"
+// @Param resolution body models.ClaimResolveRequest true "Decision and audit reason"
+// @Success 200 {object} models.Claim "Resolved claim"
+// @Failure 400 "Invalid request, missing reason or status other than paid or rejected"
+// @Failure 404 "Claim not found"
+// @Failure 409 "Claim is not pending"
+// @Failure 500 "Internal server error"
+// @Router /claim/{id}/resolve [post]
+func (h *AdminHandlers) ResolveClaimHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ClaimResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding ResolveClaim request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	req.ClaimID = mux.Vars(r)["id"]
+	req.Actor = models.ActorAPI
+
+	claim, err := h.resolutionService.ResolveClaim(req)
+	if err != nil {
+		h.logger.Error("Error resolving claim %s: %v", req.ClaimID, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidResolution):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrClaimNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidStatusTransition):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(claim)
+	h.logger.Info("Claim %s resolved as %s via API.", claim.ID, claim.Status)
+}
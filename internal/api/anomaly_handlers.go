@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type AnomalyHandlers struct {
+	anomalyService service.AnomalyService
+	logger         logger.Logger
+}
+
+func NewAnomalyHandlers(anomalyService service.AnomalyService, log logger.Logger) *AnomalyHandlers {
+	return &AnomalyHandlers{
+		anomalyService: anomalyService,
+		logger:         log,
+	}
+}
+
+// ListAnomaliesHandler returns the anomalies found by the claim analyzer via HTTP GET.
+// @Summary List claim anomalies
+// @Description Lists the anomalies found by the claim analyzer (submission spikes, reversal rates and unit prices far above the median), the latest detected first.
+// @Tags anomalies
+// @Produce json
+// @Security ApiKeyAuth
+// @Param npi query string false "National Provider Identifier of the pharmacy"
+// @Param type query string false "submission_spike, reversal_rate or unit_price"
+// @Param severity query string false "low, medium or high"
+// @Param status query string false "open (default), resolved or all"
+// @Success 200 {array} models.Anomaly "Anomalies"
+// @Failure 400 "Invalid filter"
+// @Failure 500 "Internal server error"
+// @Router /anomalies [get]
+func (h *AnomalyHandlers) ListAnomaliesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AnomalyFilter{
+		NPI:      query.Get("npi"),
+		Type:     query.Get("type"),
+		Severity: query.Get("severity"),
+	}
+	open, closed := true, false
+	switch query.Get("status") {
+	case "", "open":
+		filter.Open = &open
+	case "resolved":
+		filter.Open = &closed
+	case "all":
+	default:
+		http.Error(w, fmt.Sprintf("%v: status '%s'", service.ErrInvalidAnomalyFilter, query.Get("status")), http.StatusBadRequest)
+		return
+	}
+
+	anomalies, err := h.anomalyService.ListAnomalies(filter)
+	if err != nil {
+		h.logger.Error("Error listing anomalies: %v", err)
+		if errors.Is(err, service.ErrInvalidAnomalyFilter) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(anomalies)
+}
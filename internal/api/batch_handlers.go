@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type BatchHandlers struct {
+	claimService service.ClaimService
+	logger       logger.Logger
+	maxBatchSize int
+}
+
+func NewBatchHandlers(claimService service.ClaimService, log logger.Logger, maxBatchSize int) *BatchHandlers {
+	return &BatchHandlers{
+		claimService: claimService,
+		logger:       log,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+// SubmitClaimBatchHandler handles batch claim submission via HTTP POST.
+// @Summary Submit a batch of claims
+// @Description Validates and processes every claim of the array and returns one result per item. In all_or_nothing mode no claim is saved unless every item is valid; in best_effort mode the valid claims are saved.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param mode query string false "all_or_nothing or best_effort (default)"
+// @Param claims body []models.ClaimSubmissionRequest true "Claims to submit"
+// @Success 200 {object} models.ClaimBatchResponse "Per-item results"
+// @Failure 400 "Invalid request or mode"
+// @Failure 413 "Batch larger than the configured maximum"
+// @Failure 422 {object} models.ClaimBatchResponse "all_or_nothing batch not committed"
+// @Failure 500 "Internal server error"
+// @Router /claims/batch [post]
+func (h *BatchHandlers) SubmitClaimBatchHandler(w http.ResponseWriter, r *http.Request) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = models.BatchModeBestEffort
+	}
+
+	var reqs []models.ClaimSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+		h.logger.Error("Error decoding claim batch request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if len(reqs) == 0 {
+		h.logger.Error("Error: empty claim batch.")
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if len(reqs) > h.maxBatchSize {
+		h.logger.Error("Claim batch of %d items exceeds the maximum of %d", len(reqs), h.maxBatchSize)
+		http.Error(w, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	response, err := h.claimService.SubmitClaims(reqs, mode)
+	if err != nil {
+		h.logger.Error("Error submitting claim batch: %v", err)
+		if errors.Is(err, service.ErrInvalidBatchMode) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	status := http.StatusOK
+	if mode == models.BatchModeAllOrNothing && !response.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+	h.logger.Info("Claim batch of %d items processed via API: %d accepted, %d rejected.", len(reqs), response.Accepted, response.Rejected)
+}
+
+// ReverseClaimBatchHandler handles batch claim reversal via HTTP POST.
+// @Summary Reverse a batch of claims
+// @Description Reverses the claims selected by ID or by pharmacy NPI and time range, atomically and with one revert record each. A reason and a reason code are required and recorded with every revert; claims refused by the reversal policy are reported as policy_violation. With dry_run the outcomes are only previewed.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param reversal body models.BatchReversalRequest true "Claims to reverse and audit reason"
+// @Success 200 {object} models.BatchReversalResponse "Per-claim outcomes"
+// @Failure 400 "Invalid request, missing reason or invalid selection"
+// @Failure 413 "More claim IDs, or claims matched by the filter, than the configured maximum"
+// @Failure 422 "Missing or unknown reason code"
+// @Failure 500 "Internal server error, no claim was reversed"
+// @Router /reversals/batch [post]
+func (h *BatchHandlers) ReverseClaimBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.BatchReversalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding reversal batch request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	if len(req.ClaimIDs) > h.maxBatchSize {
+		h.logger.Error("Reversal batch of %d claims exceeds the maximum of %d", len(req.ClaimIDs), h.maxBatchSize)
+		http.Error(w, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	req.Actor = models.ActorAPI
+	req.MaxClaims = h.maxBatchSize
+	response, err := h.claimService.ReverseClaims(req)
+	if err != nil {
+		h.logger.Error("Error reversing claim batch: %v", err)
+		if errors.Is(err, service.ErrInvalidReversalBatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, service.ErrReversalBatchTooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		} else if errors.Is(err, service.ErrReversalPolicyViolation) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+	h.logger.Info("Reversal batch processed via API (dry run: %t): %d matched, %d reversed.", response.DryRun, response.Matched, response.Reversed)
+}
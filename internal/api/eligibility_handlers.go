@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type EligibilityHandlers struct {
+	eligibilityService service.EligibilityService
+	logger             logger.Logger
+}
+
+func NewEligibilityHandlers(eligibilityService service.EligibilityService, log logger.Logger) *EligibilityHandlers {
+	return &EligibilityHandlers{
+		eligibilityService: eligibilityService,
+		logger:             log,
+	}
+}
+
+// CheckEligibilityHandler checks the eligibility of a member via HTTP POST.
+// @Summary Check the eligibility of a member
+// @Description Checks whether a member is covered on the date of service (today by default) against the eligibility roster, like an NCPDP E1 transaction. Members not covered are answered with eligible false and reject code 52 (not on the roster) or 65 (not covered on the date).
+// @Tags eligibility
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body models.EligibilityRequest true "Member and date of service"
+// @Success 200 {object} models.Eligibility "Eligibility of the member"
+// @Failure 400 "Missing member ID or invalid date of service"
+// @Failure 500 "Internal server error"
+// @Router /eligibility [post]
+func (h *EligibilityHandlers) CheckEligibilityHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.EligibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding CheckEligibility request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	eligibility, err := h.eligibilityService.CheckEligibility(req)
+	if err != nil {
+		h.logger.Error("Error checking eligibility of member %s: %v", req.MemberID, err)
+		if errors.Is(err, service.ErrInvalidEligibilityRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(eligibility)
+	h.logger.Info("Eligibility of member %s checked via API: eligible %t.", eligibility.MemberID, eligibility.Eligible)
+}
+
+// GetRosterStatusHandler returns the eligibility roster in use via HTTP GET.
+// @Summary Get the eligibility roster status
+// @Description Returns the number of members on the eligibility roster, when it was loaded and the members added, removed or changed by its latest reload.
+// @Tags eligibility
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.RosterStatus "Roster status"
+// @Router /eligibility/roster [get]
+func (h *EligibilityHandlers) GetRosterStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.eligibilityService.GetRosterStatus())
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/fhir"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+	"github.com/gorilla/mux"
+)
+
+const fhirContentType = "application/fhir+json"
+
+type FHIRHandlers struct {
+	claimService service.ClaimService
+	logger       logger.Logger
+	insurer      string
+}
+
+func NewFHIRHandlers(claimService service.ClaimService, log logger.Logger, insurer string) *FHIRHandlers {
+	return &FHIRHandlers{
+		claimService: claimService,
+		logger:       log,
+		insurer:      insurer,
+	}
+}
+
+// ReadClaimHandler returns a claim as a FHIR R4 Claim resource via HTTP GET.
+// @Summary Read a FHIR Claim
+// @Description Returns the claim as a FHIR R4 Claim; reversed claims have status cancelled
+// @Tags fhir
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Claim ID"
+// @Success 200 {object} fhir.Claim "FHIR Claim"
+// @Failure 404 {object} fhir.OperationOutcome "Claim not found"
+// @Failure 500 {object} fhir.OperationOutcome "Internal server error"
+// @Router /fhir/Claim/{id} [get]
+func (h *FHIRHandlers) ReadClaimHandler(w http.ResponseWriter, r *http.Request) {
+	claim, ok := h.fetchClaim(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+	writeFHIR(w, http.StatusOK, fhir.NewClaim(*claim))
+}
+
+// ReadClaimResponseHandler returns the adjudication of a claim as a FHIR R4 ClaimResponse via HTTP GET.
+// @Summary Read a FHIR ClaimResponse
+// @Description Returns the adjudication of the claim as a FHIR R4 ClaimResponse; the ClaimResponse shares the claim ID
+// @Tags fhir
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Claim ID"
+// @Success 200 {object} fhir.ClaimResponse "FHIR ClaimResponse"
+// @Failure 404 {object} fhir.OperationOutcome "Claim not found"
+// @Failure 500 {object} fhir.OperationOutcome "Internal server error"
+// @Router /fhir/ClaimResponse/{id} [get]
+func (h *FHIRHandlers) ReadClaimResponseHandler(w http.ResponseWriter, r *http.Request) {
+	claim, ok := h.fetchClaim(w, mux.Vars(r)["id"])
+	if !ok {
+		return
+	}
+	writeFHIR(w, http.StatusOK, fhir.NewClaimResponse(*claim, h.insurer))
+}
+
+// SearchClaimsHandler searches claims and returns a FHIR searchset Bundle via HTTP GET.
+// @Summary Search FHIR Claims
+// @Description Searches claims by provider NPI, creation date and product NDC with Bundle paging
+// @Tags fhir
+// @Produce json
+// @Security ApiKeyAuth
+// @Param provider query string false "Pharmacy NPI ([system|]code)"
+// @Param created query string false "Creation date with optional eq, ge, gt, le or lt prefix; repeatable"
+// @Param product query string false "NDC ([system|]code)"
+// @Param _count query int false "Page size (default 20, max 100)"
+// @Param _offset query int false "Number of matches skipped"
+// @Success 200 {object} fhir.Bundle "Searchset Bundle of Claims"
+// @Failure 400 {object} fhir.OperationOutcome "Invalid search parameter"
+// @Failure 500 {object} fhir.OperationOutcome "Internal server error"
+// @Router /fhir/Claim [get]
+func (h *FHIRHandlers) SearchClaimsHandler(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, "provider", "/fhir/Claim", func(claim models.Claim) interface{} {
+		return fhir.NewClaim(claim)
+	})
+}
+
+// SearchClaimResponsesHandler searches claim adjudications and returns a FHIR searchset Bundle via HTTP GET.
+// @Summary Search FHIR ClaimResponses
+// @Description Searches claim adjudications by requestor NPI, creation date and product NDC with Bundle paging
+// @Tags fhir
+// @Produce json
+// @Security ApiKeyAuth
+// @Param requestor query string false "Pharmacy NPI ([system|]code)"
+// @Param created query string false "Creation date with optional eq, ge, gt, le or lt prefix; repeatable"
+// @Param product query string false "NDC ([system|]code)"
+// @Param _count query int false "Page size (default 20, max 100)"
+// @Param _offset query int false "Number of matches skipped"
+// @Success 200 {object} fhir.Bundle "Searchset Bundle of ClaimResponses"
+// @Failure 400 {object} fhir.OperationOutcome "Invalid search parameter"
+// @Failure 500 {object} fhir.OperationOutcome "Internal server error"
+// @Router /fhir/ClaimResponse [get]
+func (h *FHIRHandlers) SearchClaimResponsesHandler(w http.ResponseWriter, r *http.Request) {
+	h.search(w, r, "requestor", "/fhir/ClaimResponse", func(claim models.Claim) interface{} {
+		return fhir.NewClaimResponse(claim, h.insurer)
+	})
+}
+
+// ValidateClaimHandler validates a FHIR Claim against the R4 structure via HTTP POST.
+// @Summary Validate a FHIR Claim
+// @Description Checks the Claim JSON against the R4 Claim structure and returns the issues found
+// @Tags fhir
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param claim body fhir.Claim true "FHIR Claim"
+// @Success 200 {object} fhir.OperationOutcome "Claim is valid"
+// @Failure 400 {object} fhir.OperationOutcome "Claim is invalid"
+// @Failure 413 {object} fhir.OperationOutcome "Claim larger than the maximum resource size"
+// @Router /fhir/Claim/$validate [post]
+func (h *FHIRHandlers) ValidateClaimHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, fhir.MaxResourceSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.logger.Error("FHIR Claim refused: larger than %d bytes", tooLarge.Limit)
+		writeFHIR(w, http.StatusRequestEntityTooLarge, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeTooLong,
+			fmt.Sprintf("request body larger than %d bytes", tooLarge.Limit)))
+		return
+	}
+	if err != nil {
+		h.logger.Error("Error reading FHIR Claim: %v", err)
+		writeFHIR(w, http.StatusBadRequest, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeStructure, "unreadable request body"))
+		return
+	}
+
+	outcome := fhir.ValidateClaim(body)
+	if outcome.HasErrors() {
+		writeFHIR(w, http.StatusBadRequest, outcome)
+		return
+	}
+	writeFHIR(w, http.StatusOK, outcome)
+}
+
+func (h *FHIRHandlers) fetchClaim(w http.ResponseWriter, id string) (*models.Claim, bool) {
+	claim, err := h.claimService.GetClaimByID(id)
+	if err != nil {
+		h.logger.Error("Error fetching claim %s: %v", id, err)
+		writeFHIR(w, http.StatusInternalServerError, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeException, "internal error fetching claim"))
+		return nil, false
+	}
+	if claim == nil {
+		h.logger.Info("Claim with ID %s not found.", id)
+		writeFHIR(w, http.StatusNotFound, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeNotFound, "Claim/"+id+" not found"))
+		return nil, false
+	}
+	return claim, true
+}
+
+func (h *FHIRHandlers) search(w http.ResponseWriter, r *http.Request, providerParam, path string, toResource func(models.Claim) interface{}) {
+	query := r.URL.Query()
+	search, err := fhir.ParseSearch(query, providerParam)
+	if err != nil {
+		writeFHIR(w, http.StatusBadRequest, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeInvalid, err.Error()))
+		return
+	}
+
+	claims, total, err := h.claimService.SearchClaims(search.Filter)
+	if err != nil {
+		h.logger.Error("Error searching claims: %v", err)
+		writeFHIR(w, http.StatusInternalServerError, fhir.NewOperationOutcome(fhir.SeverityError, fhir.IssueCodeException, "internal error searching claims"))
+		return
+	}
+
+	resources := make([]interface{}, 0, len(claims))
+	ids := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		resources = append(resources, toResource(claim))
+		ids = append(ids, claim.ID)
+	}
+
+	writeFHIR(w, http.StatusOK, fhir.NewSearchBundle(baseURL(r)+path, query, search, total, resources, ids))
+}
+
+// baseURL returns the scheme and host the request was addressed to.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+func writeFHIR(w http.ResponseWriter, status int, resource interface{}) {
+	w.Header().Set("Content-Type", fhirContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resource)
+}
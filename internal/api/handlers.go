@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -48,7 +49,7 @@ func (h *Handlers) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Security ApiKeyAuth
 // @Param claim body models.ClaimSubmissionRequest true "Claim data to submit"
-// @Success 200 {object} models.Claim "Claim submitted successfully"
+// @Success 200 {object} models.Claim "Claim submitted and adjudicated; status is paid, pending or rejected with reject codes"
 // @Failure 400 "Invalid request"
 // @Failure 500 "Internal server error"
 // @Router /claims [post]
@@ -69,9 +70,12 @@ func (h *Handlers) SubmitClaimHandler(w http.ResponseWriter, r *http.Request) {
 	claim, err := h.claimService.SubmitClaim(req)
 	if err != nil {
 		h.logger.Error("Error submitting claim: %v", err)
-		if strings.Contains(err.Error(), "pharmacy with NPI") { // Original: "farmácia com NPI"
+		switch {
+		case errors.Is(err, service.ErrInvalidPrescription):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case strings.Contains(err.Error(), "pharmacy with NPI"): // Original: "farmácia com NPI"
 			http.Error(w, "", http.StatusBadRequest)
-		} else {
+		default:
 			http.Error(w, "", http.StatusInternalServerError)
 		}
 		return
@@ -83,6 +87,44 @@ func (h *Handlers) SubmitClaimHandler(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Claim %s processed successfully via API.", claim.ID)
 }
 
+// AdjudicateClaimHandler returns the adjudication of a claim without submitting it, via HTTP POST.
+// @Summary Adjudicate a claim (dry run)
+// @Description Applies the adjudication rules in use to the claim and returns whether it would be paid, pended or rejected, with the reject codes. Nothing is saved.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param claim body models.ClaimSubmissionRequest true "Claim data to adjudicate"
+// @Success 200 {object} models.Adjudication "Adjudication the claim would get"
+// @Failure 400 "Invalid claim data or unknown NPI"
+// @Failure 500 "Internal server error"
+// @Router /claims/adjudicate [post]
+func (h *Handlers) AdjudicateClaimHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ClaimSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding AdjudicateClaim request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	adjudication, err := h.claimService.AdjudicateClaim(req)
+	if err != nil {
+		h.logger.Error("Error adjudicating claim: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidClaimData), errors.Is(err, service.ErrInvalidPrescription), errors.Is(err, service.ErrUnknownNPI):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(adjudication)
+	h.logger.Info("Claim dry-run adjudicated %s via API.", adjudication.Status)
+}
+
 // GetClaimByIDHandler fetches a claim by its ID via HTTP GET.
 // @Summary Get claim by ID
 // @Description Returns the details of a specific claim by its ID
@@ -122,16 +164,132 @@ func (h *Handlers) GetClaimByIDHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(claim)
 }
 
+// GetClaimHistoryHandler fetches the status history of a claim via HTTP GET.
+// @Summary Get the status history of a claim
+// @Description Returns every status change of a claim, oldest first, with its timestamp and actor
+// @Tags claims
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Claim ID"
+// @Success 200 {array} models.ClaimStatusChange "Status changes"
+// @Failure 404 "Claim not found"
+// @Failure 500 "Internal server error"
+// @Router /claim/{id}/history [get]
+func (h *Handlers) GetClaimHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	history, err := h.claimService.GetClaimHistory(id)
+	if err != nil {
+		if errors.Is(err, service.ErrClaimNotFound) {
+			h.logger.Info("Claim with ID %s not found.", id)
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Error fetching history of claim %s: %v", id, err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if history == nil {
+		history = []models.ClaimStatusChange{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(history)
+}
+
+// RebillClaimHandler handles the rebill of a claim via HTTP POST.
+// @Summary Rebill a claim
+// @Description Atomically reverses what is outstanding of a paid claim and creates a corrected replacement claim referencing it. Omitted fields keep the values of the original claim.
+// @Tags claims
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "ID of the claim to rebill"
+// @Param rebill body models.ClaimRebillRequest true "Corrected claim values and reason"
+// @Success 200 {object} models.Claim "Replacement claim"
+// @Failure 400 "Invalid request or corrected claim data"
+// @Failure 404 "Claim not found"
+// @Failure 409 "Claim status does not allow a rebill"
+// @Failure 422 "Reversal policy violation or corrected claim not paid at adjudication"
+// @Failure 500 "Internal server error"
+// @Router /claim/{id}/rebill [post]
+func (h *Handlers) RebillClaimHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ClaimRebillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Error("Error decoding RebillClaim request: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+	req.ClaimID = mux.Vars(r)["id"]
+	req.Actor = models.ActorAPI
+
+	claim, err := h.claimService.RebillClaim(req)
+	if err != nil {
+		h.logger.Error("Error rebilling claim %s: %v", req.ClaimID, err)
+		switch {
+		case errors.Is(err, service.ErrClaimNotFound):
+			http.Error(w, "", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidStatusTransition):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrInvalidClaimData):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrRebillNotPaid), errors.Is(err, service.ErrReversalPolicyViolation):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		default:
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(claim)
+	h.logger.Info("Claim %s rebilled as claim %s via API.", req.ClaimID, claim.ID)
+}
+
+// GetClaimVersionsHandler fetches the chain of versions of a claim via HTTP GET.
+// @Summary Get the versions of a claim
+// @Description Returns every version of the claim chain the claim belongs to, from the original claim to its latest replacement
+// @Tags claims
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Claim ID"
+// @Success 200 {array} models.Claim "Claim versions, oldest first"
+// @Failure 404 "Claim not found"
+// @Failure 500 "Internal server error"
+// @Router /claim/{id}/versions [get]
+func (h *Handlers) GetClaimVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	versions, err := h.claimService.GetClaimVersions(id)
+	if err != nil {
+		if errors.Is(err, service.ErrClaimNotFound) {
+			h.logger.Info("Claim with ID %s not found.", id)
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("Error fetching versions of claim %s: %v", id, err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(versions)
+}
+
 // ReverseClaimHandler handles claim reversal via HTTP POST.
 // @Summary Reverse an existing claim
-// @Description Reverts an already submitted claim and records the reversal
+// @Description Reverts an already submitted claim, fully or partially, and records the reversal. Without quantity and amount everything outstanding is reversed; with only one of them the other is prorated. The claim is reversed once nothing of it is outstanding. A reason code is required and the reversal policy may limit the age of the claim.
 // @Tags claims
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param reversal body models.ClaimReversalRequest true "Claim ID to be reverted"
 // @Success 200 {object} models.ClaimReversalResponse "Reversal successfully recorded"
-// @Failure 400 "Invalid request or claim already reverted/not found"
+// @Failure 400 "Invalid request, claim already reverted/not found or reversal exceeding the outstanding claim"
+// @Failure 422 "Reversal refused by the reversal policy, with the violated rule"
 // @Failure 500 "Internal server error"
 // @Router /reversal [post]
 func (h *Handlers) ReverseClaimHandler(w http.ResponseWriter, r *http.Request) {
@@ -148,10 +306,16 @@ func (h *Handlers) ReverseClaimHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.Actor = models.ActorAPI
 	revert, err := h.claimService.ReverseClaim(req)
 	if err != nil {
 		h.logger.Error("Error reverting claim: %v", err)
-		if strings.Contains(err.Error(), "claim with ID") {
+		if errors.Is(err, service.ErrInvalidReversalAmount) || errors.Is(err, service.ErrReversalExceedsClaim) ||
+			errors.Is(err, service.ErrReversalAmountMismatch) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, service.ErrReversalPolicyViolation) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		} else if strings.Contains(err.Error(), "claim with ID") {
 			http.Error(w, "", http.StatusBadRequest)
 		} else {
 			http.Error(w, "", http.StatusInternalServerError)
@@ -159,9 +323,13 @@ func (h *Handlers) ReverseClaimHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := map[string]string{
-		"status":   "claim reversed",
-		"claim_id": revert.ClaimID,
+	response := models.ClaimReversalResponse{
+		Status:     "claim reversed",
+		ClaimID:    revert.ClaimID,
+		RevertID:   revert.ID,
+		Quantity:   revert.Quantity,
+		Amount:     revert.Amount,
+		ReasonCode: revert.ReasonCode,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
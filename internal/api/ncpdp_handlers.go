@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/ncpdp"
+)
+
+type NCPDPHandlers struct {
+	processor *ncpdp.Processor
+	logger    logger.Logger
+}
+
+func NewNCPDPHandlers(processor *ncpdp.Processor, log logger.Logger) *NCPDPHandlers {
+	return &NCPDPHandlers{
+		processor: processor,
+		logger:    log,
+	}
+}
+
+// TransactionHandler processes an NCPDP Telecommunication D.0 transmission via HTTP POST.
+// @Summary Process an NCPDP D.0 transaction
+// @Description Accepts a raw D.0 B1 (billing) or B2 (reversal) transmission and returns the D.0 response
+// @Tags ncpdp
+// @Accept octet-stream
+// @Produce octet-stream
+// @Security ApiKeyAuth
+// @Param transaction body string true "Raw D.0 transmission"
+// @Success 200 {string} string "D.0 response transmission (paid, rejected or accepted)"
+// @Failure 400 "Empty or unreadable request body"
+// @Failure 413 "Transmission larger than the maximum D.0 transmission size"
+// @Router /ncpdp [post]
+func (h *NCPDPHandlers) TransactionHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, ncpdp.MaxTransmissionSize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.logger.Error("NCPDP transmission refused: larger than %d bytes", tooLarge.Limit)
+		http.Error(w, "", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil || len(data) == 0 {
+		h.logger.Error("Error reading NCPDP transmission: %v", err)
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	response := h.processor.Process(data)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type RemittanceHandlers struct {
+	remittanceService service.RemittanceService
+	logger            logger.Logger
+}
+
+func NewRemittanceHandlers(remittanceService service.RemittanceService, log logger.Logger) *RemittanceHandlers {
+	return &RemittanceHandlers{
+		remittanceService: remittanceService,
+		logger:            log,
+	}
+}
+
+// GenerateRemittanceHandler generates the X12 835 remittance advice of a pharmacy via HTTP POST.
+// Generation is not idempotent, so it is not exposed as a GET that caches or retries could repeat.
+// @Summary Generate the X12 835 remittance of a pharmacy
+// @Description Generates an 835 with the claims paid and reversed for the pharmacy in the period. Each call allocates new control numbers and exports the file.
+// @Tags remittances
+// @Produce plain
+// @Security ApiKeyAuth
+// @Param npi path string true "Pharmacy NPI"
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string true "Last day of the period (YYYY-MM-DD)"
+// @Success 200 {string} string "X12 835 interchange"
+// @Failure 400 "Invalid period"
+// @Failure 404 "Pharmacy not found"
+// @Failure 500 "Internal server error"
+// @Router /remittances/{npi} [post]
+func (h *RemittanceHandlers) GenerateRemittanceHandler(w http.ResponseWriter, r *http.Request) {
+	npi := mux.Vars(r)["npi"]
+	query := r.URL.Query()
+
+	export, err := h.remittanceService.GenerateRemittance(npi, query.Get("from"), query.Get("to"))
+	if err != nil {
+		h.logger.Error("Error generating remittance for NPI %s: %v", npi, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidPeriod):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrUnknownNPI):
+			http.Error(w, "", http.StatusNotFound)
+		default:
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/EDI-X12")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(export.Content))
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type ReportHandlers struct {
+	reportService service.ReportService
+	logger        logger.Logger
+}
+
+func NewReportHandlers(reportService service.ReportService, log logger.Logger) *ReportHandlers {
+	return &ReportHandlers{
+		reportService: reportService,
+		logger:        log,
+	}
+}
+
+// GetSavingsReportHandler returns the pricing savings by pharmacy chain via HTTP GET.
+// @Summary Get the pricing savings by chain
+// @Description Sums the submitted and allowed amounts of the paid claims of the period, by pharmacy chain, with the savings of the contract pricing.
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string true "Last day of the period (YYYY-MM-DD)"
+// @Param date query string false "Date placing claims in the period: received (default) or service"
+// @Success 200 {object} models.SavingsReport "Savings by chain"
+// @Failure 400 "Invalid period or date"
+// @Failure 500 "Internal server error"
+// @Router /reports/savings [get]
+func (h *ReportHandlers) GetSavingsReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	report, err := h.reportService.GetSavingsReport(query.Get("from"), query.Get("to"), query.Get("date"))
+	if err != nil {
+		h.logger.Error("Error generating savings report: %v", err)
+		if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrInvalidReportDate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetPriceVarianceReportHandler returns the claims flagged for their unit price variance via HTTP GET.
+// @Summary Get the price variance outliers by pharmacy
+// @Description Lists the claims of the period whose unit price is over the variance threshold above the reference price in effect on their date, by pharmacy.
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string true "Last day of the period (YYYY-MM-DD)"
+// @Param date query string false "Date placing claims in the period: received (default) or service"
+// @Param npi query string false "National Provider Identifier of the pharmacy"
+// @Success 200 {object} models.PriceVarianceReport "Flagged claims by pharmacy"
+// @Failure 400 "Invalid period or date"
+// @Failure 500 "Internal server error"
+// @Router /reports/price-variance [get]
+func (h *ReportHandlers) GetPriceVarianceReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	report, err := h.reportService.GetPriceVarianceReport(query.Get("from"), query.Get("to"), query.Get("npi"), query.Get("date"))
+	if err != nil {
+		h.logger.Error("Error generating price variance report: %v", err)
+		if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrInvalidReportDate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
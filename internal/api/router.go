@@ -11,8 +11,19 @@ import (
 )
 
 type RouterConfig struct {
-	Handlers      *Handlers
-	Authenticator *auth.Authenticator
+	Handlers             *Handlers
+	BatchHandlers        *BatchHandlers
+	NCPDPHandlers        *NCPDPHandlers
+	RemittanceHandlers   *RemittanceHandlers
+	FHIRHandlers         *FHIRHandlers
+	ReportHandlers       *ReportHandlers
+	AnomalyHandlers      *AnomalyHandlers
+	EligibilityHandlers  *EligibilityHandlers
+	AccumulatorHandlers  *AccumulatorHandlers
+	SubstitutionHandlers *SubstitutionHandlers
+	AdminHandlers        *AdminHandlers
+	Authenticator        *auth.Authenticator
+	AdminAuthenticator   *auth.Authenticator // Authenticates the privileged routes; nil leaves them unregistered
 }
 
 func NewRouter(cfg RouterConfig) *mux.Router {
@@ -30,12 +41,59 @@ func NewRouter(cfg RouterConfig) *mux.Router {
 		httpSwagger.DomID("swagger-ui"),
 	)).Methods(http.MethodGet)
 
+	if cfg.AdminHandlers != nil && cfg.AdminAuthenticator != nil {
+		reinstate := http.HandlerFunc(cfg.AdminHandlers.ReinstateClaimHandler)
+		r.Handle("/claim/{id}/reinstate", cfg.AdminAuthenticator.AuthMiddleware(reinstate)).Methods("POST")
+		resolve := http.HandlerFunc(cfg.AdminHandlers.ResolveClaimHandler)
+		r.Handle("/claim/{id}/resolve", cfg.AdminAuthenticator.AuthMiddleware(resolve)).Methods("POST")
+	}
+
 	authRouter := r.PathPrefix("/").Subrouter()
 	authRouter.Use(cfg.Authenticator.AuthMiddleware)
 
 	authRouter.HandleFunc("/claim", cfg.Handlers.SubmitClaimHandler).Methods("POST")
+	authRouter.HandleFunc("/claims/adjudicate", cfg.Handlers.AdjudicateClaimHandler).Methods("POST")
 	authRouter.HandleFunc("/claim/{id}", cfg.Handlers.GetClaimByIDHandler).Methods("GET")
+	authRouter.HandleFunc("/claim/{id}/history", cfg.Handlers.GetClaimHistoryHandler).Methods("GET")
+	authRouter.HandleFunc("/claim/{id}/rebill", cfg.Handlers.RebillClaimHandler).Methods("POST")
+	authRouter.HandleFunc("/claim/{id}/versions", cfg.Handlers.GetClaimVersionsHandler).Methods("GET")
 	authRouter.HandleFunc("/reversal", cfg.Handlers.ReverseClaimHandler).Methods("POST")
 
+	if cfg.BatchHandlers != nil {
+		authRouter.HandleFunc("/claims/batch", cfg.BatchHandlers.SubmitClaimBatchHandler).Methods("POST")
+		authRouter.HandleFunc("/reversals/batch", cfg.BatchHandlers.ReverseClaimBatchHandler).Methods("POST")
+	}
+	if cfg.NCPDPHandlers != nil {
+		authRouter.HandleFunc("/ncpdp", cfg.NCPDPHandlers.TransactionHandler).Methods("POST")
+	}
+	if cfg.RemittanceHandlers != nil {
+		authRouter.HandleFunc("/remittances/{npi}", cfg.RemittanceHandlers.GenerateRemittanceHandler).Methods("POST")
+	}
+	if cfg.FHIRHandlers != nil {
+		authRouter.HandleFunc("/fhir/Claim", cfg.FHIRHandlers.SearchClaimsHandler).Methods("GET")
+		authRouter.HandleFunc("/fhir/Claim/$validate", cfg.FHIRHandlers.ValidateClaimHandler).Methods("POST")
+		authRouter.HandleFunc("/fhir/Claim/{id}", cfg.FHIRHandlers.ReadClaimHandler).Methods("GET")
+		authRouter.HandleFunc("/fhir/ClaimResponse", cfg.FHIRHandlers.SearchClaimResponsesHandler).Methods("GET")
+		authRouter.HandleFunc("/fhir/ClaimResponse/{id}", cfg.FHIRHandlers.ReadClaimResponseHandler).Methods("GET")
+	}
+	if cfg.ReportHandlers != nil {
+		authRouter.HandleFunc("/reports/savings", cfg.ReportHandlers.GetSavingsReportHandler).Methods("GET")
+		authRouter.HandleFunc("/reports/price-variance", cfg.ReportHandlers.GetPriceVarianceReportHandler).Methods("GET")
+	}
+	if cfg.AnomalyHandlers != nil {
+		authRouter.HandleFunc("/anomalies", cfg.AnomalyHandlers.ListAnomaliesHandler).Methods("GET")
+	}
+	if cfg.EligibilityHandlers != nil {
+		authRouter.HandleFunc("/eligibility", cfg.EligibilityHandlers.CheckEligibilityHandler).Methods("POST")
+		authRouter.HandleFunc("/eligibility/roster", cfg.EligibilityHandlers.GetRosterStatusHandler).Methods("GET")
+	}
+	if cfg.AccumulatorHandlers != nil {
+		authRouter.HandleFunc("/members/{member_id}/accumulators", cfg.AccumulatorHandlers.GetAccumulatorsHandler).Methods("GET")
+	}
+	if cfg.SubstitutionHandlers != nil {
+		authRouter.HandleFunc("/reports/generic-substitution", cfg.SubstitutionHandlers.GetSubstitutionReportHandler).Methods("GET")
+		authRouter.HandleFunc("/reports/generic-substitution.csv", cfg.SubstitutionHandlers.ExportSubstitutionReportHandler).Methods("GET")
+	}
+
 	return r
 }
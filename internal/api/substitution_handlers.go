@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+type SubstitutionHandlers struct {
+	substitutionService service.SubstitutionService
+	logger              logger.Logger
+}
+
+func NewSubstitutionHandlers(substitutionService service.SubstitutionService, log logger.Logger) *SubstitutionHandlers {
+	return &SubstitutionHandlers{
+		substitutionService: substitutionService,
+		logger:              log,
+	}
+}
+
+// GetSubstitutionReportHandler returns the generic substitution savings by pharmacy and chain via HTTP GET.
+// @Summary Get the generic substitution savings
+// @Description Finds the paid claims of the period for brand NDCs with an equivalent generic NDC (same active ingredient, strength and dosage form in the NDC catalog) cheaper on average in the claims of the period, with the potential savings by pharmacy and chain.
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string true "Last day of the period (YYYY-MM-DD)"
+// @Param date query string false "Date placing claims in the period: received (default) or service"
+// @Success 200 {object} models.SubstitutionReport "Savings by pharmacy and chain"
+// @Failure 400 "Invalid period or date"
+// @Failure 500 "Internal server error"
+// @Router /reports/generic-substitution [get]
+func (h *SubstitutionHandlers) GetSubstitutionReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	report, err := h.substitutionService.GetSubstitutionReport(query.Get("from"), query.Get("to"), query.Get("date"))
+	if err != nil {
+		h.logger.Error("Error generating generic substitution report: %v", err)
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// ExportSubstitutionReportHandler exports the generic substitution savings as CSV via HTTP GET.
+// @Summary Export the generic substitution savings as CSV
+// @Description Same report as /reports/generic-substitution, one row per pharmacy and brand NDC.
+// @Tags reports
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param from query string true "First day of the period (YYYY-MM-DD)"
+// @Param to query string true "Last day of the period (YYYY-MM-DD)"
+// @Param date query string false "Date placing claims in the period: received (default) or service"
+// @Success 200 {string} string "CSV file"
+// @Failure 400 "Invalid period or date"
+// @Failure 500 "Internal server error"
+// @Router /reports/generic-substitution.csv [get]
+func (h *SubstitutionHandlers) ExportSubstitutionReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	export, err := h.substitutionService.ExportSubstitutionReport(query.Get("from"), query.Get("to"), query.Get("date"))
+	if err != nil {
+		h.logger.Error("Error exporting generic substitution report: %v", err)
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", export.FileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(export.Content)
+}
+
+// writeError answers 400 for an invalid period or date, 500 otherwise.
+func (h *SubstitutionHandlers) writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidPeriod) || errors.Is(err, service.ErrInvalidReportDate) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	} else {
+		http.Error(w, "", http.StatusInternalServerError)
+	}
+}
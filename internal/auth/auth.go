@@ -1,12 +1,20 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
 )
 
+// Errors returned by Authenticate.
+var (
+	ErrMissingAuthorization = errors.New("Unauthorized")
+	ErrInvalidTokenFormat   = errors.New("Invalid token format")
+	ErrInvalidToken         = errors.New("Invalid token")
+)
+
 type Authenticator struct {
 	authToken string
 	logger    logger.Logger
@@ -19,27 +27,33 @@ func NewAuthenticator(token string, log logger.Logger) *Authenticator {
 	}
 }
 
+// Authenticate checks an Authorization header value ("Bearer <token>") against the configured token.
+func (a *Authenticator) Authenticate(authHeader string) error {
+	if authHeader == "" {
+		a.logger.Warning("Unauthorized access attempt: Authorization header missing.") // Traduzido
+		return ErrMissingAuthorization
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		a.logger.Warning("Unauthorized access attempt: Invalid token format.") // Traduzido
+		return ErrInvalidTokenFormat
+	}
+
+	token := parts[1]
+	if token != a.authToken {
+		a.logger.Warning("Unauthorized access attempt: Invalid token.") // Traduzido
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
 // AuthMiddleware provides authentication for API requests.
 func (a *Authenticator) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			a.logger.Warning("Unauthorized access attempt: Authorization header missing.") // Traduzido
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
-			return
-		}
-
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			a.logger.Warning("Unauthorized access attempt: Invalid token format.") // Traduzido
-			http.Error(w, "Invalid token format", http.StatusUnauthorized)
-			return
-		}
-
-		token := parts[1]
-		if token != a.authToken {
-			a.logger.Warning("Unauthorized access attempt: Invalid token.") // Traduzido
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+		if err := a.Authenticate(r.Header.Get("Authorization")); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
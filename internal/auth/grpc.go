@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationMetadataKey is the gRPC metadata key carrying the bearer token, like the HTTP header.
+const authorizationMetadataKey = "authorization"
+
+// UnaryInterceptor authenticates unary gRPC calls with the same bearer token as the HTTP API.
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authenticateContext(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor authenticates streaming gRPC calls with the same bearer token as the HTTP API.
+func (a *Authenticator) StreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authenticateContext(ss.Context()); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (a *Authenticator) authenticateContext(ctx context.Context) error {
+	var authHeader string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationMetadataKey); len(values) > 0 {
+			authHeader = values[0]
+		}
+	}
+	if err := a.Authenticate(authHeader); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package benefits
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Plan holds the cost sharing terms of a benefit plan. The member pays the allowed amount of
+// their claims in full until the deductible is met, then the coinsurance share or the copay,
+// until their out-of-pocket spending reaches the maximum of the plan year.
+type Plan struct {
+	Deductible         float64 `json:"deductible" yaml:"deductible"`                                       // Paid in full by the member each plan year before cost sharing applies
+	OutOfPocketMax     float64 `json:"out_of_pocket_max" yaml:"out_of_pocket_max"`                         // Most the member pays in a plan year, deductible included; 0 for no limit
+	CoinsurancePercent float64 `json:"coinsurance_percent,omitempty" yaml:"coinsurance_percent,omitempty"` // Share of the allowed amount paid by the member after the deductible, e.g. 20 for 20%
+	Copay              float64 `json:"copay,omitempty" yaml:"copay,omitempty"`                             // Flat amount per claim after the deductible, instead of coinsurance
+}
+
+// Plans is the content of a plan benefit file.
+type Plans struct {
+	Plans map[string]Plan `json:"plans" yaml:"plans"` // Plans by plan ID, as in the eligibility roster
+}
+
+// LoadPlans reads and validates a plan benefit file. Files ending in .json are decoded as JSON,
+// anything else as YAML.
+func LoadPlans(path string) (*Plans, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan benefit file %s: %w", path, err)
+	}
+
+	var plans Plans
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&plans)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&plans)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding plan benefit file %s: %w", path, err)
+	}
+
+	if err := plans.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan benefit file %s: %w", path, err)
+	}
+	return &plans, nil
+}
+
+// Validate checks that every plan has non-negative amounts, a coinsurance in range and at most
+// one of coinsurance and copay, and that the deductible fits in the out-of-pocket maximum.
+func (p *Plans) Validate() error {
+	if len(p.Plans) == 0 {
+		return fmt.Errorf("no plans defined")
+	}
+	for id, plan := range p.Plans {
+		if err := plan.validate(); err != nil {
+			return fmt.Errorf("plan '%s': %w", id, err)
+		}
+	}
+	return nil
+}
+
+func (p Plan) validate() error {
+	if p.Deductible < 0 || p.OutOfPocketMax < 0 || p.Copay < 0 {
+		return fmt.Errorf("deductible, out_of_pocket_max and copay must not be negative")
+	}
+	if p.CoinsurancePercent < 0 || p.CoinsurancePercent > 100 {
+		return fmt.Errorf("coinsurance_percent must be in [0, 100], got %g", p.CoinsurancePercent)
+	}
+	if p.CoinsurancePercent > 0 && p.Copay > 0 {
+		return fmt.Errorf("coinsurance_percent and copay cannot both be set")
+	}
+	if p.OutOfPocketMax > 0 && p.Deductible > p.OutOfPocketMax {
+		return fmt.Errorf("deductible %.2f exceeds out_of_pocket_max %.2f", p.Deductible, p.OutOfPocketMax)
+	}
+	return nil
+}
+
+// Plan returns the terms of the plan, false when the file does not define it.
+func (p *Plans) Plan(id string) (models.BenefitPlan, bool) {
+	plan, ok := p.Plans[id]
+	if !ok {
+		return models.BenefitPlan{}, false
+	}
+	return models.BenefitPlan{
+		Plan:               id,
+		Deductible:         plan.Deductible,
+		OutOfPocketMax:     plan.OutOfPocketMax,
+		CoinsurancePercent: plan.CoinsurancePercent,
+		Copay:              plan.Copay,
+	}, true
+}
+
+// PatientPay computes the cost sharing of a claim with the allowed amount for a member of the
+// plan, given what the member accumulated in the plan year so far. It returns false when the
+// file does not define the plan.
+func (p *Plans) PatientPay(id string, allowed float64, accumulator models.Accumulator) (models.ClaimBenefit, bool) {
+	plan, ok := p.Plans[id]
+	if !ok {
+		return models.ClaimBenefit{}, false
+	}
+
+	deductible := math.Min(allowed, math.Max(0, plan.Deductible-accumulator.DeductibleMet))
+	rest := allowed - deductible
+	costShare := rest * plan.CoinsurancePercent / 100
+	if plan.Copay > 0 {
+		costShare = math.Min(plan.Copay, rest)
+	}
+	patientPay := deductible + costShare
+	if plan.OutOfPocketMax > 0 {
+		patientPay = math.Min(patientPay, math.Max(0, plan.OutOfPocketMax-accumulator.OutOfPocketSpent))
+		deductible = math.Min(deductible, patientPay)
+	}
+
+	return models.ClaimBenefit{
+		Plan:              id,
+		PlanYear:          accumulator.PlanYear,
+		PatientPay:        roundAmount(patientPay),
+		DeductibleApplied: roundAmount(deductible),
+	}, true
+}
+
+// roundAmount rounds an amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,87 @@
+package benefits_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/benefits"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+func testPlans() *benefits.Plans {
+	return &benefits.Plans{Plans: map[string]benefits.Plan{
+		"GOLD":   {Deductible: 100, OutOfPocketMax: 500, CoinsurancePercent: 20},
+		"BRONZE": {Deductible: 50, Copay: 15},
+	}}
+}
+
+func TestPatientPay(t *testing.T) {
+	plans := testPlans()
+
+	tests := []struct {
+		name       string
+		plan       string
+		allowed    float64
+		met        float64
+		spent      float64
+		patientPay float64
+		deductible float64
+	}{
+		{"claim within the deductible", "GOLD", 60, 0, 0, 60, 60},
+		{"claim meeting the deductible", "GOLD", 60, 70, 70, 36, 30},
+		{"coinsurance after the deductible", "GOLD", 60, 100, 200, 12, 0},
+		{"capped at the out-of-pocket maximum", "GOLD", 60, 100, 495, 5, 0},
+		{"out-of-pocket maximum reached", "GOLD", 60, 100, 500, 0, 0},
+		{"copay after the deductible", "BRONZE", 60, 50, 50, 15, 0},
+		{"copay above the allowed amount", "BRONZE", 10, 50, 50, 10, 0},
+		{"no out-of-pocket maximum", "BRONZE", 60, 20, 9000, 45, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			benefit, ok := plans.PatientPay(tt.plan, tt.allowed, models.Accumulator{PlanYear: 2024, DeductibleMet: tt.met, OutOfPocketSpent: tt.spent})
+			assert.True(t, ok)
+			assert.Equal(t, models.ClaimBenefit{Plan: tt.plan, PlanYear: 2024, PatientPay: tt.patientPay, DeductibleApplied: tt.deductible}, benefit)
+		})
+	}
+
+	_, ok := plans.PatientPay("PLATINUM", 60, models.Accumulator{})
+	assert.False(t, ok, "Expected no patient pay for a plan without benefit definition")
+}
+
+func TestLoadPlans(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plans.yaml")
+	os.WriteFile(path, []byte(`
+plans:
+  GOLD: {deductible: 100, out_of_pocket_max: 500, coinsurance_percent: 20}
+  BRONZE: {deductible: 50, copay: 15}
+`), 0644)
+
+	plans, err := benefits.LoadPlans(path)
+	assert.Nil(t, err, "Expected no error loading the plans")
+	assert.Equal(t, testPlans(), plans)
+	gold, ok := plans.Plan("GOLD")
+	assert.True(t, ok)
+	assert.Equal(t, models.BenefitPlan{Plan: "GOLD", Deductible: 100, OutOfPocketMax: 500, CoinsurancePercent: 20}, gold)
+
+	tests := map[string]string{
+		"no plans":                 `{"plans": {}}`,
+		"coinsurance above 100%":   `{"plans": {"GOLD": {"coinsurance_percent": 120}}}`,
+		"coinsurance and copay":    `{"plans": {"GOLD": {"coinsurance_percent": 20, "copay": 10}}}`,
+		"negative deductible":      `{"plans": {"GOLD": {"deductible": -1}}}`,
+		"deductible above the max": `{"plans": {"GOLD": {"deductible": 600, "out_of_pocket_max": 500}}}`,
+		"unknown field":            `{"plans": {"GOLD": {"coinsurance": 20}}}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			jsonPath := filepath.Join(t.TempDir(), "plans.json")
+			os.WriteFile(jsonPath, []byte(content), 0644)
+			_, err := benefits.LoadPlans(jsonPath)
+			assert.NotNil(t, err, "Expected an error for %s", name)
+		})
+	}
+}
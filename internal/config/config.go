@@ -1,19 +1,68 @@
 package config
 
 import (
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DatabasePath      string `env:"DATABASE_PATH"`
-	PharmaciesCSVPath string `env:"PHARMACIES_CSV_PATH"`
-	ClaimsDataPath    string `env:"CLAIMS_DATA_PATH"`
-	RevertsDataPath   string `env:"REVERTS_DATA_PATH"`
-	AuthToken         string `env:"AUTH_TOKEN"`
-	Port              string `env:"PORT"`
+	DatabasePath      string        `env:"DATABASE_PATH"`
+	PharmaciesCSVPath string        `env:"PHARMACIES_CSV_PATH"`
+	ClaimsDataPath    string        `env:"CLAIMS_DATA_PATH"`
+	RevertsDataPath   string        `env:"REVERTS_DATA_PATH"`
+	QuarantinePath    string        `env:"QUARANTINE_PATH"`
+	ClaimsCSVColumns  string        `env:"CLAIMS_CSV_COLUMNS"`
+	RevertsCSVColumns string        `env:"REVERTS_CSV_COLUMNS"`
+	AuthToken         string        `env:"AUTH_TOKEN"`
+	AdminAuthToken    string        `env:"ADMIN_AUTH_TOKEN"`
+	Port              string        `env:"PORT"`
+	GRPCPort          string        `env:"GRPC_PORT"`
+	WatchInterval     time.Duration `env:"WATCH_INTERVAL"`
+	ClaimBatchMaxSize int           `env:"CLAIM_BATCH_MAX_SIZE"`
+	NCPDPBIN          string        `env:"NCPDP_BIN"`
+	NCPDPTCPPort      string        `env:"NCPDP_TCP_PORT"`
+	NCPDPTCPAllowed   []*net.IPNet  `env:"NCPDP_TCP_ALLOWED_IPS"`
+	NCPDPBatchPath    string        `env:"NCPDP_BATCH_PATH"`
+	NCPDPResponsePath string        `env:"NCPDP_RESPONSE_PATH"`
+	RemittancePath    string        `env:"REMITTANCE_PATH"`
+	X12SenderID       string        `env:"X12_SENDER_ID"`
+	X12PayerName      string        `env:"X12_PAYER_NAME"`
+	X12PayerID        string        `env:"X12_PAYER_ID"`
+	X12Production     bool          `env:"X12_PRODUCTION"`
+	ReinstateWindow   time.Duration `env:"REINSTATE_WINDOW"`
+	AdjudicationRules string        `env:"ADJUDICATION_RULES_PATH"`
+	PricingContracts  string        `env:"PRICING_CONTRACTS_PATH"`
+	ReferencePrices   string        `env:"REFERENCE_PRICES_PATH"`
+	EligibilityRoster string        `env:"ELIGIBILITY_ROSTER_PATH"`
+	BenefitPlans      string        `env:"BENEFIT_PLANS_PATH"`
+	NDCCatalog        string        `env:"NDC_CATALOG_PATH"`
+
+	RefillTooSoonPercent float64 `env:"REFILL_TOO_SOON_PERCENT"`
+
+	PriceVarianceThreshold float64       `env:"PRICE_VARIANCE_THRESHOLD"`
+	PriceVarianceJobTime   time.Duration `env:"PRICE_VARIANCE_JOB_TIME"`
+	PriceVarianceLookback  time.Duration `env:"PRICE_VARIANCE_LOOKBACK"`
+
+	AnomalyInterval     time.Duration `env:"ANOMALY_INTERVAL"`
+	AnomalyWindow       time.Duration `env:"ANOMALY_WINDOW"`
+	AnomalyBaseline     time.Duration `env:"ANOMALY_BASELINE"`
+	AnomalySpikeFactor  float64       `env:"ANOMALY_SPIKE_FACTOR"`
+	AnomalyReversalRate float64       `env:"ANOMALY_REVERSAL_RATE"`
+	AnomalyPriceFactor  float64       `env:"ANOMALY_PRICE_FACTOR"`
+	AnomalyMinClaims    int           `env:"ANOMALY_MIN_CLAIMS"`
+
+	ReversalMaxAge      time.Duration            `env:"REVERSAL_MAX_AGE"`
+	ReversalChainMaxAge map[string]time.Duration `env:"REVERSAL_MAX_AGE_BY_CHAIN"`
+
+	TimelyFilingLimit      time.Duration            `env:"TIMELY_FILING_LIMIT"`
+	TimelyFilingChainLimit map[string]time.Duration `env:"TIMELY_FILING_LIMIT_BY_CHAIN"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -26,8 +75,28 @@ func LoadConfig() (*Config, error) {
 		PharmaciesCSVPath: os.Getenv("PHARMACIES_CSV_PATH"),
 		ClaimsDataPath:    os.Getenv("CLAIMS_DATA_PATH"),
 		RevertsDataPath:   os.Getenv("REVERTS_DATA_PATH"),
+		QuarantinePath:    os.Getenv("QUARANTINE_PATH"),
+		ClaimsCSVColumns:  os.Getenv("CLAIMS_CSV_COLUMNS"),
+		RevertsCSVColumns: os.Getenv("REVERTS_CSV_COLUMNS"),
 		AuthToken:         os.Getenv("AUTH_TOKEN"),
+		AdminAuthToken:    os.Getenv("ADMIN_AUTH_TOKEN"),
 		Port:              os.Getenv("PORT"),
+		GRPCPort:          os.Getenv("GRPC_PORT"),
+		NCPDPBIN:          os.Getenv("NCPDP_BIN"),
+		NCPDPTCPPort:      os.Getenv("NCPDP_TCP_PORT"),
+		NCPDPBatchPath:    os.Getenv("NCPDP_BATCH_PATH"),
+		NCPDPResponsePath: os.Getenv("NCPDP_RESPONSE_PATH"),
+		RemittancePath:    os.Getenv("REMITTANCE_PATH"),
+		X12SenderID:       os.Getenv("X12_SENDER_ID"),
+		X12PayerName:      os.Getenv("X12_PAYER_NAME"),
+		X12PayerID:        os.Getenv("X12_PAYER_ID"),
+		X12Production:     os.Getenv("X12_PRODUCTION") == "true",
+		AdjudicationRules: os.Getenv("ADJUDICATION_RULES_PATH"),
+		PricingContracts:  os.Getenv("PRICING_CONTRACTS_PATH"),
+		ReferencePrices:   os.Getenv("REFERENCE_PRICES_PATH"),
+		EligibilityRoster: os.Getenv("ELIGIBILITY_ROSTER_PATH"),
+		BenefitPlans:      os.Getenv("BENEFIT_PLANS_PATH"),
+		NDCCatalog:        os.Getenv("NDC_CATALOG_PATH"),
 	}
 
 	if cfg.DatabasePath == "" {
@@ -46,13 +115,281 @@ func LoadConfig() (*Config, error) {
 		cfg.RevertsDataPath = "./data/reverts"
 		log.Printf("REVERTS_DATA_PATH not defined, using default: %s", cfg.RevertsDataPath)
 	}
+	if cfg.QuarantinePath == "" {
+		cfg.QuarantinePath = "./data/quarantine"
+		log.Printf("QUARANTINE_PATH not defined, using default: %s", cfg.QuarantinePath)
+	}
+	if cfg.NCPDPBatchPath == "" {
+		cfg.NCPDPBatchPath = "./data/ncpdp/batches"
+		log.Printf("NCPDP_BATCH_PATH not defined, using default: %s", cfg.NCPDPBatchPath)
+	}
+	if cfg.NCPDPResponsePath == "" {
+		cfg.NCPDPResponsePath = "./data/ncpdp/responses"
+		log.Printf("NCPDP_RESPONSE_PATH not defined, using default: %s", cfg.NCPDPResponsePath)
+	}
+	tcpAllowed, err := parseNetworks(os.Getenv("NCPDP_TCP_ALLOWED_IPS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid NCPDP_TCP_ALLOWED_IPS: %w", err)
+	}
+	cfg.NCPDPTCPAllowed = tcpAllowed
+	if cfg.RemittancePath == "" {
+		cfg.RemittancePath = "./data/remittances"
+		log.Printf("REMITTANCE_PATH not defined, using default: %s", cfg.RemittancePath)
+	}
+	if cfg.X12SenderID == "" {
+		cfg.X12SenderID = "PHARMACYCLAIMS"
+		log.Printf("X12_SENDER_ID not defined, using default: %s", cfg.X12SenderID)
+	}
+	if cfg.X12PayerName == "" {
+		cfg.X12PayerName = "PHARMACY CLAIM SERVICE"
+		log.Printf("X12_PAYER_NAME not defined, using default: %s", cfg.X12PayerName)
+	}
+	if cfg.X12PayerID == "" {
+		cfg.X12PayerID = "999999999"
+		log.Printf("X12_PAYER_ID not defined, using default: %s", cfg.X12PayerID)
+	}
 	if cfg.Port == "" {
 		cfg.Port = "8080"
 		log.Printf("PORT not defined, using default: %s", cfg.Port)
 	}
+	if cfg.GRPCPort == "" {
+		cfg.GRPCPort = "50051"
+		log.Printf("GRPC_PORT not defined, using default: %s", cfg.GRPCPort)
+	}
+	cfg.WatchInterval = parseDuration("WATCH_INTERVAL", 5*time.Second)
+	cfg.ClaimBatchMaxSize = parsePositiveInt("CLAIM_BATCH_MAX_SIZE", 500)
+	cfg.ReinstateWindow = parseDuration("REINSTATE_WINDOW", 72*time.Hour)
+	maxAge, err := parseAge(os.Getenv("REVERSAL_MAX_AGE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REVERSAL_MAX_AGE: %w", err)
+	}
+	if maxAge == 0 {
+		log.Println("REVERSAL_MAX_AGE not defined, claims of any age can be reversed")
+	}
+	cfg.ReversalMaxAge = maxAge
+	cfg.ReversalChainMaxAge, err = parseChainAges(os.Getenv("REVERSAL_MAX_AGE_BY_CHAIN"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REVERSAL_MAX_AGE_BY_CHAIN: %w", err)
+	}
+	cfg.TimelyFilingLimit, err = parseAge(os.Getenv("TIMELY_FILING_LIMIT"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TIMELY_FILING_LIMIT: %w", err)
+	}
+	if cfg.TimelyFilingLimit == 0 {
+		log.Println("TIMELY_FILING_LIMIT not defined, claims of any date of service can be submitted")
+	}
+	cfg.TimelyFilingChainLimit, err = parseChainAges(os.Getenv("TIMELY_FILING_LIMIT_BY_CHAIN"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TIMELY_FILING_LIMIT_BY_CHAIN: %w", err)
+	}
+	if cfg.AdjudicationRules == "" {
+		log.Println("ADJUDICATION_RULES_PATH not defined, every valid claim is paid")
+	}
+	if cfg.PricingContracts == "" {
+		log.Println("PRICING_CONTRACTS_PATH not defined, claims are allowed their submitted price")
+	}
+	if cfg.ReferencePrices == "" {
+		cfg.ReferencePrices = "./data/pricing/reference_prices.csv"
+		log.Printf("REFERENCE_PRICES_PATH not defined, using default: %s", cfg.ReferencePrices)
+	}
+	if cfg.EligibilityRoster == "" {
+		cfg.EligibilityRoster = "./data/eligibility/roster.csv"
+		log.Printf("ELIGIBILITY_ROSTER_PATH not defined, using default: %s", cfg.EligibilityRoster)
+	}
+	if cfg.BenefitPlans == "" {
+		cfg.BenefitPlans = "./data/benefits/plans.yaml"
+		log.Printf("BENEFIT_PLANS_PATH not defined, using default: %s", cfg.BenefitPlans)
+	}
+	if cfg.NDCCatalog == "" {
+		cfg.NDCCatalog = "./data/ndc/catalog.csv"
+		log.Printf("NDC_CATALOG_PATH not defined, using default: %s", cfg.NDCCatalog)
+	}
+	cfg.RefillTooSoonPercent = parsePercent("REFILL_TOO_SOON_PERCENT", 75)
+	cfg.PriceVarianceThreshold = parsePercent("PRICE_VARIANCE_THRESHOLD", 25)
+	cfg.PriceVarianceJobTime, err = parseTimeOfDay(os.Getenv("PRICE_VARIANCE_JOB_TIME"), 2*time.Hour)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRICE_VARIANCE_JOB_TIME: %w", err)
+	}
+	cfg.PriceVarianceLookback, err = parseAge(os.Getenv("PRICE_VARIANCE_LOOKBACK"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRICE_VARIANCE_LOOKBACK: %w", err)
+	}
+	if cfg.PriceVarianceLookback == 0 {
+		cfg.PriceVarianceLookback = 30 * 24 * time.Hour
+		log.Printf("PRICE_VARIANCE_LOOKBACK not defined, using default: 30d")
+	}
+	cfg.AnomalyInterval = parseDuration("ANOMALY_INTERVAL", time.Hour)
+	cfg.AnomalyWindow = parseDuration("ANOMALY_WINDOW", 24*time.Hour)
+	cfg.AnomalyBaseline, err = parseAge(os.Getenv("ANOMALY_BASELINE"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_BASELINE: %w", err)
+	}
+	if cfg.AnomalyBaseline == 0 {
+		cfg.AnomalyBaseline = 28 * 24 * time.Hour
+		log.Printf("ANOMALY_BASELINE not defined, using default: 28d")
+	}
+	if cfg.AnomalyBaseline < cfg.AnomalyWindow {
+		return nil, fmt.Errorf("invalid ANOMALY_BASELINE: must not be shorter than ANOMALY_WINDOW (%s)", cfg.AnomalyWindow)
+	}
+	cfg.AnomalySpikeFactor = parseFactor("ANOMALY_SPIKE_FACTOR", 3)
+	cfg.AnomalyReversalRate = parsePercent("ANOMALY_REVERSAL_RATE", 20)
+	cfg.AnomalyPriceFactor = parseFactor("ANOMALY_PRICE_FACTOR", 2)
+	cfg.AnomalyMinClaims = parsePositiveInt("ANOMALY_MIN_CLAIMS", 10)
 	if cfg.AuthToken == "" {
 		log.Println("Warning: AUTH_TOKEN not defined. Authentication might not work correctly.")
 	}
+	if cfg.AdminAuthToken == "" {
+		log.Println("Warning: ADMIN_AUTH_TOKEN not defined. Privileged endpoints are disabled.")
+	}
 
 	return cfg, nil
 }
+
+// parseDuration reads a duration (e.g. "5s") from the environment, falling back to def.
+func parseDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Printf("%s not defined, using default: %s", key, def)
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: invalid %s '%s', using default: %s", key, value, def)
+		return def
+	}
+	return d
+}
+
+// parsePositiveInt reads a positive integer from the environment, falling back to def.
+func parsePositiveInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Printf("%s not defined, using default: %d", key, def)
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Warning: invalid %s '%s', using default: %d", key, value, def)
+		return def
+	}
+	return n
+}
+
+// parsePercent reads a non-negative percentage (e.g. "25" for 25%) from the environment,
+// falling back to def.
+func parsePercent(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Printf("%s not defined, using default: %g%%", key, def)
+		return def
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		log.Printf("Warning: invalid %s '%s', using default: %g%%", key, value, def)
+		return def
+	}
+	return n
+}
+
+// parseFactor reads a multiplier of at least 1 (e.g. "2.5") from the environment, falling back to def.
+func parseFactor(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Printf("%s not defined, using default: %g", key, def)
+		return def
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 1 {
+		log.Printf("Warning: invalid %s '%s', using default: %g", key, value, def)
+		return def
+	}
+	return n
+}
+
+// parseTimeOfDay parses a time of day such as "02:00" as the duration since midnight. An empty
+// value is def.
+func parseTimeOfDay(value string, def time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return def, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a time of day (HH:MM)", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// parseAge parses an age such as "90d" or "36h". Days are not supported by time.ParseDuration
+// and are handled here. An empty value is a zero age.
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("'%s' is not a positive number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("'%s' is not a positive duration", value)
+	}
+	return d, nil
+}
+
+// parseChainAges parses per chain ages such as "health=30d,saint=60d".
+func parseChainAges(spec string) (map[string]time.Duration, error) {
+	ages := make(map[string]time.Duration)
+	if strings.TrimSpace(spec) == "" {
+		return ages, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid entry '%s', expected chain=age", pair)
+		}
+		age, err := parseAge(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid age of chain '%s': %w", strings.TrimSpace(parts[0]), err)
+		}
+		if age == 0 {
+			return nil, fmt.Errorf("invalid entry '%s', expected chain=age", pair)
+		}
+		ages[strings.TrimSpace(parts[0])] = age
+	}
+	return ages, nil
+}
+
+// parseNetworks parses a comma-separated list of IP addresses and CIDR networks such as
+// "10.0.0.0/8,192.168.1.20". A single address is a network of that address only.
+func parseNetworks(spec string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("'%s' is not an IP address or CIDR network", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not an IP address or CIDR network", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -2,8 +2,12 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"strings"
 
 	"github.com/diogocarasco/go-pharmacy-service/internal/models"
 	_ "github.com/mattn/go-sqlite3"
@@ -13,13 +17,35 @@ import (
 type DBRepository interface {
 	SavePharmacy(pharmacy models.Pharmacy) error
 	GetPharmacyByNPI(npi string) (*models.Pharmacy, error)
+	ListPharmacies(chain string) ([]models.Pharmacy, error)
 	SaveClaim(claim models.Claim) error
 	GetClaimByID(id string) (*models.Claim, error)
-	UpdateClaimRevertedStatus(id string, reverted bool) error
-	SaveRevert(revert models.Revert) error
+	GetClaimByPrescription(npi, rxNumber string, fillNumber int, date string) (*models.Claim, error)
+	UpdateClaimStatus(change models.ClaimStatusChange) error
+	GetClaimStatusHistory(claimID string) ([]models.ClaimStatusChange, error)
 	Close() error
 	SaveClaims(claims []models.Claim) error
-	SaveReverts(reverts []models.Revert) error
+	ReverseClaims(reverts []models.Revert, actor string) error
+	RebillClaim(revert models.Revert, replacement models.Claim, actor string) error
+	GetClaimVersions(id string) ([]models.Claim, error)
+	GetRevertsByClaimID(claimID string) ([]models.Revert, error)
+	ReinstateClaim(revert models.Revert, change *models.ClaimStatusChange) error
+	SaveQuarantinedClaims(records []models.QuarantinedClaim) error
+	GetClaimsByNPI(npi, from, to string) ([]models.Claim, error)
+	GetLatestFill(memberID, ndc, date, excludeID string) (*models.Claim, error)
+	GetAccumulator(memberID string, planYear int) (*models.Accumulator, error)
+	GetReversedClaimsByNPI(npi, from, to string) ([]models.ReversedClaim, error)
+	NextControlNumber(name string) (int64, error)
+	SearchClaims(filter models.ClaimFilter) ([]models.Claim, int, error)
+	GetChainSavings(from, to, date string) ([]models.ChainSavings, error)
+	UpdateClaimPriceVariances(variances []models.ClaimPriceVariance) error
+	GetPriceOutliers(npi, from, to, date string) ([]models.PharmacyPriceOutliers, error)
+	GetNDCClaimTotals(from, to, date string) ([]models.NDCClaimTotals, error)
+	CountClaimsByNPI(from, to string) (map[string]int, error)
+	GetReversalCounts(from, to string) ([]models.ReversalCount, error)
+	GetPharmacyUnitPrices(from, to string) ([]models.PharmacyUnitPrice, error)
+	RecordAnomalies(anomalies []models.Anomaly, detectedAt string) error
+	ListAnomalies(filter models.AnomalyFilter) ([]models.Anomaly, error)
 }
 
 // SQLiteRepository implements DBRepository for SQLite.
@@ -77,88 +103,272 @@ func (s *SQLiteRepository) GetPharmacyByNPI(npi string) (*models.Pharmacy, error
 	return &pharmacy, nil
 }
 
-// SaveClaim inserts a new claim into the database.
-func (s *SQLiteRepository) SaveClaim(claim models.Claim) error {
-	stmt, err := s.DB.Prepare(`
-        INSERT INTO claims(id, ndc, npi, quantity, price, timestamp, reverted)
-        VALUES(?, ?, ?, ?, ?, ?, ?)
-        ON CONFLICT(id) DO UPDATE SET
-            ndc = excluded.ndc,
-            npi = excluded.npi,
-            quantity = excluded.quantity,
-            price = excluded.price,
-            timestamp = excluded.timestamp,
-            reverted = excluded.reverted;
-    `)
+// ListPharmacies fetches the pharmacies ordered by NPI, restricted to a chain when chain is not empty.
+func (s *SQLiteRepository) ListPharmacies(chain string) ([]models.Pharmacy, error) {
+	query := "SELECT chain, npi FROM pharmacies"
+	var args []interface{}
+	if chain != "" {
+		query += " WHERE chain = ?"
+		args = append(args, chain)
+	}
+	query += " ORDER BY npi"
+
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("error preparing statement to insert/update claim: %w", err)
+		return nil, fmt.Errorf("error querying pharmacies: %w", err)
 	}
-	defer stmt.Close()
+	defer rows.Close()
 
-	_, err = stmt.Exec(
-		claim.ID,
-		claim.NDC,
-		claim.NPI,
-		claim.Quantity,
-		claim.Price,
-		claim.Timestamp,
-		claim.Reverted,
-	)
+	var pharmacies []models.Pharmacy
+	for rows.Next() {
+		var pharmacy models.Pharmacy
+		if err := rows.Scan(&pharmacy.Chain, &pharmacy.NPI); err != nil {
+			return nil, fmt.Errorf("error scanning pharmacy: %w", err)
+		}
+		pharmacies = append(pharmacies, pharmacy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pharmacies: %w", err)
+	}
+	return pharmacies, nil
+}
+
+// The outstanding expressions compute the quantity and amount of a claim not reversed yet, and the
+// patient pay and deductible not rolled back yet. Voided reverts no longer count as reversed.
+const (
+	outstandingQuantitySQL   = "claims.quantity - COALESCE((SELECT SUM(r.quantity) FROM reverts r WHERE r.claim_id = claims.id AND r.voided_at = ''), 0)"
+	outstandingAmountSQL     = "claims.price - COALESCE((SELECT SUM(r.amount) FROM reverts r WHERE r.claim_id = claims.id AND r.voided_at = ''), 0)"
+	outstandingPatientPaySQL = "claims.patient_pay - COALESCE((SELECT SUM(r.patient_pay) FROM reverts r WHERE r.claim_id = claims.id AND r.voided_at = ''), 0)"
+	outstandingDeductibleSQL = "claims.deductible_applied - COALESCE((SELECT SUM(r.deductible_applied) FROM reverts r WHERE r.claim_id = claims.id AND r.voided_at = ''), 0)"
+)
+
+// outstandingColumns lists the outstanding quantity, amount, patient pay and deductible of a claim.
+const outstandingColumns = outstandingQuantitySQL + ", " + outstandingAmountSQL + ", " + outstandingPatientPaySQL + ", " + outstandingDeductibleSQL
+
+// outstandingAllowedSQL prorates the allowed amount of a claim to its outstanding amount.
+const outstandingAllowedSQL = "CASE WHEN claims.price > 0 THEN claims.allowed_amount * (" + outstandingAmountSQL + ") / claims.price ELSE claims.allowed_amount END"
+
+// claimColumns lists the claim columns in the order read by scanClaim.
+const claimColumns = "claims.id, claims.ndc, claims.npi, claims.quantity, claims.price, claims.timestamp, claims.status, " +
+	"claims.replaces_claim_id, claims.rejects, claims.allowed_amount, claims.pricing_basis, " +
+	"claims.reference_unit_price, claims.price_variance, claims.price_flagged, " +
+	"claims.member_id, claims.prescriber_npi, claims.rx_number, claims.fill_number, claims.days_supply, claims.date_of_service, claims.daw_code, claims.override_code, " +
+	"claims.benefit_plan, claims.plan_year, claims.patient_pay, claims.deductible_applied, " +
+	outstandingColumns
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanClaim reads the claimColumns of a row and derives the deprecated reverted flag from the status.
+func scanClaim(row rowScanner) (models.Claim, error) {
+	var claim models.Claim
+	var rejects string
+	err := row.Scan(&claim.ID, &claim.NDC, &claim.NPI, &claim.Quantity, &claim.Price, &claim.Timestamp, &claim.Status,
+		&claim.ReplacesClaimID, &rejects, &claim.AllowedAmount, &claim.PricingBasis,
+		&claim.ReferenceUnitPrice, &claim.PriceVariance, &claim.PriceFlagged,
+		&claim.MemberID, &claim.PrescriberNPI, &claim.RxNumber, &claim.FillNumber, &claim.DaysSupply, &claim.DateOfService, &claim.DAWCode, &claim.OverrideCode,
+		&claim.Plan, &claim.PlanYear, &claim.PatientPay, &claim.DeductibleApplied,
+		&claim.OutstandingQuantity, &claim.OutstandingAmount, &claim.OutstandingPatientPay, &claim.OutstandingDeductible)
 	if err != nil {
-		return fmt.Errorf("error executing insert/update for claim %s: %w", claim.ID, err)
+		return claim, err
 	}
-	return nil
+	claim.Reverted = claim.Status == models.ClaimStatusReversed
+	if rejects != "" {
+		if err := json.Unmarshal([]byte(rejects), &claim.Rejects); err != nil {
+			return claim, fmt.Errorf("error decoding rejects of claim %s: %w", claim.ID, err)
+		}
+	}
+	return claim, nil
 }
 
-// SaveClaims inserts multiple claims into the database within a transaction.
-func (s *SQLiteRepository) SaveClaims(claims []models.Claim) error {
-	tx, err := s.DB.Begin()
+// encodeRejects returns the JSON stored in the rejects column, empty for a claim without rejects.
+func encodeRejects(rejects []models.ClaimReject) (string, error) {
+	if len(rejects) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(rejects)
 	if err != nil {
-		return fmt.Errorf("error starting transaction for claims: %w", err)
+		return "", err
 	}
-	defer tx.Rollback()
+	return string(data), nil
+}
 
-	stmt, err := tx.Prepare(`
-        INSERT INTO claims (id, ndc, npi, quantity, price, timestamp, reverted)
-        VALUES (?, ?, ?, ?, ?, ?, ?)
+// claimStatus returns the status stored for a new claim. Claims built without a status
+// are paid, or reversed when their deprecated reverted flag is set.
+func claimStatus(claim models.Claim) string {
+	switch {
+	case claim.Status != "":
+		return claim.Status
+	case claim.Reverted:
+		return models.ClaimStatusReversed
+	default:
+		return models.ClaimStatusPaid
+	}
+}
+
+// claimPricing returns the allowed amount and pricing basis stored for a claim. Claims built
+// without pricing, e.g. loaded from files, are allowed their submitted price.
+func claimPricing(claim models.Claim) (float64, string) {
+	if claim.PricingBasis == "" {
+		return claim.Price, models.PricingBasisSubmitted
+	}
+	return claim.AllowedAmount, claim.PricingBasis
+}
+
+// upsertClaimSQL inserts a claim or updates its data. The status and cost sharing of an existing
+// claim are left untouched: the status only changes through recorded transitions, and the cost
+// sharing is part of the member accumulators.
+const upsertClaimSQL = `
+        INSERT INTO claims (id, ndc, npi, quantity, price, timestamp, status, reverted, replaces_claim_id, rejects,
+            allowed_amount, pricing_basis, reference_unit_price, price_variance, price_flagged,
+            member_id, prescriber_npi, rx_number, fill_number, days_supply, date_of_service, daw_code, override_code,
+            benefit_plan, plan_year, patient_pay, deductible_applied)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
         ON CONFLICT(id) DO UPDATE SET
             ndc = excluded.ndc,
             npi = excluded.npi,
             quantity = excluded.quantity,
             price = excluded.price,
             timestamp = excluded.timestamp,
-            reverted = excluded.reverted;
-    `)
+            allowed_amount = excluded.allowed_amount,
+            pricing_basis = excluded.pricing_basis,
+            reference_unit_price = excluded.reference_unit_price,
+            price_variance = excluded.price_variance,
+            price_flagged = excluded.price_flagged,
+            member_id = excluded.member_id,
+            prescriber_npi = excluded.prescriber_npi,
+            rx_number = excluded.rx_number,
+            fill_number = excluded.fill_number,
+            days_supply = excluded.days_supply,
+            date_of_service = excluded.date_of_service,
+            daw_code = excluded.daw_code,
+            override_code = excluded.override_code;
+    `
+
+// insertInitialStatusSQL records the first status of a claim unless it already has a history.
+const insertInitialStatusSQL = `
+        INSERT INTO claim_status_history (claim_id, from_status, to_status, actor, timestamp)
+        SELECT ?, '', ?, ?, ?
+        WHERE NOT EXISTS (SELECT 1 FROM claim_status_history WHERE claim_id = ?);
+    `
+
+// saveClaimsTx upserts the claims and records the initial status of the new ones, set by actor.
+// The cost sharing of new paid claims is added to the member accumulators.
+func saveClaimsTx(tx *sql.Tx, claims []models.Claim, actor string) error {
+	claimStmt, err := tx.Prepare(upsertClaimSQL)
 	if err != nil {
-		return fmt.Errorf("error preparing statement to save claims in batch: %w", err)
+		return fmt.Errorf("error preparing statement to insert/update claims: %w", err)
 	}
-	defer stmt.Close()
+	defer claimStmt.Close()
+
+	historyStmt, err := tx.Prepare(insertInitialStatusSQL)
+	if err != nil {
+		return fmt.Errorf("error preparing statement to insert claim status history: %w", err)
+	}
+	defer historyStmt.Close()
 
 	for _, claim := range claims {
-		_, err := stmt.Exec(claim.ID, claim.NDC, claim.NPI, claim.Quantity, claim.Price, claim.Timestamp, claim.Reverted)
+		status := claimStatus(claim)
+		rejects, err := encodeRejects(claim.Rejects)
+		if err != nil {
+			return fmt.Errorf("error encoding rejects of claim %s: %w", claim.ID, err)
+		}
+		allowed, basis := claimPricing(claim)
+		_, err = claimStmt.Exec(claim.ID, claim.NDC, claim.NPI, claim.Quantity, claim.Price, claim.Timestamp,
+			status, status == models.ClaimStatusReversed, claim.ReplacesClaimID, rejects, allowed, basis,
+			claim.ReferenceUnitPrice, claim.PriceVariance, claim.PriceFlagged,
+			claim.MemberID, claim.PrescriberNPI, claim.RxNumber, claim.FillNumber, claim.DaysSupply, claim.DateOfService, claim.DAWCode, claim.OverrideCode,
+			claim.Plan, claim.PlanYear, claim.PatientPay, claim.DeductibleApplied)
 		if err != nil {
 			return fmt.Errorf("error executing insert/update for claim %s: %w", claim.ID, err)
 		}
+		res, err := historyStmt.Exec(claim.ID, status, actor, claim.Timestamp, claim.ID)
+		if err != nil {
+			return fmt.Errorf("error recording initial status of claim %s: %w", claim.ID, err)
+		}
+		if created, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("error checking rows affected: %w", err)
+		} else if created == 0 || status != models.ClaimStatusPaid {
+			continue
+		}
+		if err := addToAccumulatorTx(tx, claim.MemberID, claim.PlanYear, claim.DeductibleApplied, claim.PatientPay, claim.Timestamp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addToAccumulatorTx adds the deductible and patient pay of a claim to the accumulator of the
+// member in the plan year; negative amounts roll them back. Claims without a member or plan year
+// do not accumulate.
+func addToAccumulatorTx(tx *sql.Tx, memberID string, planYear int, deductible, patientPay float64, timestamp string) error {
+	if memberID == "" || planYear == 0 || (deductible == 0 && patientPay == 0) {
+		return nil
+	}
+	_, err := tx.Exec(`
+        INSERT INTO member_accumulators (member_id, plan_year, deductible_met, out_of_pocket_spent, updated_at)
+        VALUES (?, ?, ROUND(?, 2), ROUND(?, 2), ?)
+        ON CONFLICT(member_id, plan_year) DO UPDATE SET
+            deductible_met = ROUND(deductible_met + excluded.deductible_met, 2),
+            out_of_pocket_spent = ROUND(out_of_pocket_spent + excluded.out_of_pocket_spent, 2),
+            updated_at = excluded.updated_at;
+    `, memberID, planYear, deductible, patientPay, timestamp)
+	if err != nil {
+		return fmt.Errorf("error updating accumulator of member %s for plan year %d: %w", memberID, planYear, err)
+	}
+	return nil
+}
+
+// GetAccumulator fetches the accumulator of the member in the plan year. A member without paid
+// claims in the year has an empty accumulator.
+func (s *SQLiteRepository) GetAccumulator(memberID string, planYear int) (*models.Accumulator, error) {
+	accumulator := models.Accumulator{MemberID: memberID, PlanYear: planYear}
+	err := s.DB.QueryRow(`
+        SELECT deductible_met, out_of_pocket_spent, updated_at
+        FROM member_accumulators
+        WHERE member_id = ? AND plan_year = ?;
+    `, memberID, planYear).Scan(&accumulator.DeductibleMet, &accumulator.OutOfPocketSpent, &accumulator.UpdatedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error fetching accumulator of member %s for plan year %d: %w", memberID, planYear, err)
+	}
+	return &accumulator, nil
+}
+
+// SaveClaim inserts a new claim into the database along with its initial status.
+func (s *SQLiteRepository) SaveClaim(claim models.Claim) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for claim %s: %w", claim.ID, err)
 	}
+	defer tx.Rollback()
 
+	if err := saveClaimsTx(tx, []models.Claim{claim}, models.ActorSystem); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveClaims inserts multiple claims into the database within a transaction.
+func (s *SQLiteRepository) SaveClaims(claims []models.Claim) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for claims: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveClaimsTx(tx, claims, models.ActorSystem); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
 // GetClaimByID fetches a claim by its ID.
 func (s *SQLiteRepository) GetClaimByID(id string) (*models.Claim, error) {
-	row := s.DB.QueryRow("SELECT id, ndc, npi, quantity, price, timestamp, reverted FROM claims WHERE id = ?", id)
-
-	var claim models.Claim
+	row := s.DB.QueryRow("SELECT "+claimColumns+" FROM claims WHERE id = ?", id)
 
-	err := row.Scan(
-		&claim.ID,
-		&claim.NDC,
-		&claim.NPI,
-		&claim.Quantity,
-		&claim.Price,
-		&claim.Timestamp,
-		&claim.Reverted,
-	)
+	claim, err := scanClaim(row)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -168,80 +378,868 @@ func (s *SQLiteRepository) GetClaimByID(id string) (*models.Claim, error) {
 	return &claim, nil
 }
 
-// UpdateClaimRevertedStatus updates the 'reverted' status of a claim.
-func (s *SQLiteRepository) UpdateClaimRevertedStatus(id string, reverted bool) error {
-	stmt, err := s.DB.Prepare("UPDATE claims SET reverted = ? WHERE id = ?")
+// GetClaimByPrescription fetches the latest claim of a pharmacy for the fill of a prescription
+// filled on the date (YYYY-MM-DD). Rejected and rebilled claims are left out.
+func (s *SQLiteRepository) GetClaimByPrescription(npi, rxNumber string, fillNumber int, date string) (*models.Claim, error) {
+	row := s.DB.QueryRow(`
+        SELECT `+claimColumns+`
+        FROM claims
+        WHERE npi = ? AND rx_number = ? AND fill_number = ? AND `+fillDateSQL+` = ? AND status NOT IN (?, ?)
+        ORDER BY timestamp DESC
+        LIMIT 1;
+    `, npi, rxNumber, fillNumber, date, models.ClaimStatusRejected, models.ClaimStatusRebilled)
+
+	claim, err := scanClaim(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching claim of Rx %s fill %d for NPI %s: %w", rxNumber, fillNumber, npi, err)
+	}
+	return &claim, nil
+}
+
+// updateClaimStatusTx moves a claim from change.FromStatus to change.ToStatus and records the change.
+// It fails when the claim does not exist or is no longer in change.FromStatus. The outstanding cost
+// sharing of a claim entering or leaving the paid status is added to or rolled back from the
+// member accumulators.
+func updateClaimStatusTx(tx *sql.Tx, change models.ClaimStatusChange) error {
+	res, err := tx.Exec(
+		"UPDATE claims SET status = ?, reverted = ? WHERE id = ? AND status = ?",
+		change.ToStatus, change.ToStatus == models.ClaimStatusReversed, change.ClaimID, change.FromStatus,
+	)
 	if err != nil {
-		return fmt.Errorf("error preparing statement to update claim status: %w", err)
+		return fmt.Errorf("error executing status update for claim %s: %w", change.ClaimID, err)
 	}
-	defer stmt.Close()
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error checking rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("claim with ID '%s' not found in status '%s' for status update", change.ClaimID, change.FromStatus)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO claim_status_history (claim_id, from_status, to_status, actor, reason, timestamp)
+        VALUES (?, ?, ?, ?, ?, ?);
+    `, change.ClaimID, change.FromStatus, change.ToStatus, change.Actor, change.Reason, change.Timestamp)
+	if err != nil {
+		return fmt.Errorf("error recording status change of claim %s: %w", change.ClaimID, err)
+	}
+
+	var sign float64
+	switch {
+	case change.ToStatus == models.ClaimStatusPaid:
+		sign = 1
+	case change.FromStatus == models.ClaimStatusPaid:
+		sign = -1
+	default:
+		return nil
+	}
+	claim, err := scanClaim(tx.QueryRow("SELECT "+claimColumns+" FROM claims WHERE id = ?", change.ClaimID))
+	if err != nil {
+		return fmt.Errorf("error reading claim %s for its accumulators: %w", change.ClaimID, err)
+	}
+	return addToAccumulatorTx(tx, claim.MemberID, claim.PlanYear,
+		sign*claim.OutstandingDeductible, sign*claim.OutstandingPatientPay, change.Timestamp)
+}
+
+// UpdateClaimStatus applies a status change to a claim and records it in the status history
+// within a transaction.
+func (s *SQLiteRepository) UpdateClaimStatus(change models.ClaimStatusChange) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for claim status update: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := updateClaimStatusTx(tx, change); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetClaimStatusHistory fetches the status changes of a claim in the order they happened.
+func (s *SQLiteRepository) GetClaimStatusHistory(claimID string) ([]models.ClaimStatusChange, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, claim_id, from_status, to_status, actor, reason, timestamp
+        FROM claim_status_history
+        WHERE claim_id = ?
+        ORDER BY id;
+    `, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying status history of claim %s: %w", claimID, err)
+	}
+	defer rows.Close()
+
+	var history []models.ClaimStatusChange
+	for rows.Next() {
+		var change models.ClaimStatusChange
+		if err := rows.Scan(&change.ID, &change.ClaimID, &change.FromStatus, &change.ToStatus, &change.Actor, &change.Reason, &change.Timestamp); err != nil {
+			return nil, fmt.Errorf("error scanning status history of claim %s: %w", claimID, err)
+		}
+		history = append(history, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status history of claim %s: %w", claimID, err)
+	}
+	return history, nil
+}
+
+// ErrReversalRefused is wrapped by the errors of reversals refused because their claim does not
+// exist, is not paid or has less outstanding than the reversal.
+var ErrReversalRefused = errors.New("reversal refused")
+
+// ErrRevertExists is wrapped by the error of a reversal whose revert ID is already recorded.
+var ErrRevertExists = errors.New("revert already recorded")
+
+// reverseClaimTx inserts the revert of a paid claim. Once nothing of the claim is outstanding it
+// moves to status, and the change is recorded for actor. It fails with ErrRevertExists when the
+// revert is already recorded, and with ErrReversalRefused when the claim does not exist, is not
+// paid or would be reversed beyond its outstanding quantity or amount.
+//
+// The revert rolls back the share of the outstanding patient pay and deductible of the claim it
+// reverses of its outstanding amount from the member accumulators, all of it once nothing is
+// outstanding. As each claim only rolls back what it added, reversals can come in any order.
+func reverseClaimTx(tx *sql.Tx, revert models.Revert, status, actor string) error {
+	var recorded int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM reverts WHERE id = ?", revert.ID).Scan(&recorded); err != nil {
+		return fmt.Errorf("error checking revert %s: %w", revert.ID, err)
+	}
+	if recorded > 0 {
+		return fmt.Errorf("%w: %s", ErrRevertExists, revert.ID)
+	}
+	claim, err := scanClaim(tx.QueryRow("SELECT "+claimColumns+" FROM claims WHERE id = ?", revert.ClaimID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: claim %s not found", ErrReversalRefused, revert.ClaimID)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading claim %s for reversal: %w", revert.ClaimID, err)
+	}
+	if claim.Status != models.ClaimStatusPaid {
+		return fmt.Errorf("%w: claim %s is %s and cannot be reversed", ErrReversalRefused, revert.ClaimID, claim.Status)
+	}
+	if revert.Quantity > claim.OutstandingQuantity+models.ReversalTolerance || revert.Amount > claim.OutstandingAmount+models.ReversalTolerance {
+		return fmt.Errorf("%w: revert %s exceeds the outstanding quantity or amount of claim %s", ErrReversalRefused, revert.ID, revert.ClaimID)
+	}
+
+	fullyReversed := claim.OutstandingQuantity-revert.Quantity <= models.ReversalTolerance && claim.OutstandingAmount-revert.Amount <= models.ReversalTolerance
+	patientPay, deductible := claim.OutstandingPatientPay, claim.OutstandingDeductible
+	if !fullyReversed && claim.OutstandingAmount > 0 {
+		share := revert.Amount / claim.OutstandingAmount
+		patientPay = math.Round(patientPay*share*100) / 100
+		deductible = math.Min(math.Round(deductible*share*100)/100, patientPay)
+	}
+
+	_, err = tx.Exec("INSERT INTO reverts (id, claim_id, timestamp, reason, quantity, amount, reason_code, patient_pay, deductible_applied) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		revert.ID, revert.ClaimID, revert.Timestamp, revert.Reason, revert.Quantity, revert.Amount, revert.ReasonCode, patientPay, deductible)
+	if err != nil {
+		return fmt.Errorf("error inserting revert %s: %w", revert.ID, err)
+	}
+	if err := addToAccumulatorTx(tx, claim.MemberID, claim.PlanYear, -deductible, -patientPay, revert.Timestamp); err != nil {
+		return err
+	}
+
+	if !fullyReversed {
+		return nil
+	}
+	return updateClaimStatusTx(tx, models.ClaimStatusChange{
+		ClaimID:    revert.ClaimID,
+		FromStatus: models.ClaimStatusPaid,
+		ToStatus:   status,
+		Actor:      actor,
+		Reason:     revert.Reason,
+		Timestamp:  revert.Timestamp,
+	})
+}
+
+// ReverseClaims inserts the reverts of paid claims and records the status changes in a single
+// transaction. A claim moves to reversed once nothing of it is outstanding. Nothing is written when
+// any of the claims does not exist, is not paid or would be reversed beyond its outstanding
+// quantity or amount.
+func (s *SQLiteRepository) ReverseClaims(reverts []models.Revert, actor string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for claim reversals: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, revert := range reverts {
+		if err := reverseClaimTx(tx, revert, models.ClaimStatusReversed, actor); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RebillClaim reverses what is outstanding of a paid claim, moves it to rebilled and saves its
+// replacement in a single transaction.
+func (s *SQLiteRepository) RebillClaim(revert models.Revert, replacement models.Claim, actor string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for rebill of claim %s: %w", revert.ClaimID, err)
+	}
+	defer tx.Rollback()
+
+	if err := reverseClaimTx(tx, revert, models.ClaimStatusRebilled, actor); err != nil {
+		return err
+	}
+	if err := saveClaimsTx(tx, []models.Claim{replacement}, actor); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
 
-	res, err := stmt.Exec(reverted, id)
+// GetClaimVersions fetches every version of the claim chain the claim belongs to, from the
+// original claim to its latest replacement.
+func (s *SQLiteRepository) GetClaimVersions(id string) ([]models.Claim, error) {
+	rows, err := s.DB.Query(`
+        WITH RECURSIVE
+            ancestors(id, replaces_claim_id) AS (
+                SELECT id, replaces_claim_id FROM claims WHERE id = ?
+                UNION
+                SELECT c.id, c.replaces_claim_id FROM claims c JOIN ancestors a ON c.id = a.replaces_claim_id
+            ),
+            versions(id, version) AS (
+                SELECT id, 1 FROM ancestors WHERE replaces_claim_id = ''
+                UNION ALL
+                SELECT c.id, v.version + 1 FROM claims c JOIN versions v ON c.replaces_claim_id = v.id
+            )
+        SELECT `+claimColumns+`
+        FROM claims
+        JOIN versions ON versions.id = claims.id
+        ORDER BY versions.version;
+    `, id)
 	if err != nil {
-		return fmt.Errorf("error executing status update for claim %s: %w", id, err)
+		return nil, fmt.Errorf("error querying versions of claim %s: %w", id, err)
 	}
+	defer rows.Close()
 
+	var versions []models.Claim
+	for rows.Next() {
+		claim, err := scanClaim(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning version of claim %s: %w", id, err)
+		}
+		versions = append(versions, claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating versions of claim %s: %w", id, err)
+	}
+	return versions, nil
+}
+
+// GetRevertsByClaimID fetches the reverts of a claim, voided ones included, oldest first.
+func (s *SQLiteRepository) GetRevertsByClaimID(claimID string) ([]models.Revert, error) {
+	rows, err := s.DB.Query(`
+        SELECT id, claim_id, timestamp, reason, reason_code, quantity, amount, voided_at, void_reason
+        FROM reverts
+        WHERE claim_id = ?
+        ORDER BY timestamp, rowid;
+    `, claimID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reverts of claim %s: %w", claimID, err)
+	}
+	defer rows.Close()
+
+	var reverts []models.Revert
+	for rows.Next() {
+		var revert models.Revert
+		if err := rows.Scan(&revert.ID, &revert.ClaimID, &revert.Timestamp, &revert.Reason, &revert.ReasonCode, &revert.Quantity,
+			&revert.Amount, &revert.VoidedAt, &revert.VoidReason); err != nil {
+			return nil, fmt.Errorf("error scanning revert of claim %s: %w", claimID, err)
+		}
+		reverts = append(reverts, revert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reverts of claim %s: %w", claimID, err)
+	}
+	return reverts, nil
+}
+
+// ReinstateClaim voids a revert and, when change is not nil, applies the status change of its
+// claim in a single transaction. The revert record is kept for the audit trail.
+func (s *SQLiteRepository) ReinstateClaim(revert models.Revert, change *models.ClaimStatusChange) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for reinstatement of claim %s: %w", revert.ClaimID, err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("UPDATE reverts SET voided_at = ?, void_reason = ? WHERE id = ? AND claim_id = ? AND voided_at = ''",
+		revert.VoidedAt, revert.VoidReason, revert.ID, revert.ClaimID)
+	if err != nil {
+		return fmt.Errorf("error voiding revert %s: %w", revert.ID, err)
+	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("error checking rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("claim with ID '%s' not found for status update", id)
+		return fmt.Errorf("revert with ID '%s' of claim '%s' not found or already voided", revert.ID, revert.ClaimID)
 	}
-	return nil
+
+	// The cost sharing rolled back by the revert counts again while the claim is paid; a reversed
+	// claim adds it back with its move to paid.
+	if change == nil {
+		var memberID, status string
+		var planYear int
+		var patientPay, deductible float64
+		err := tx.QueryRow(`
+            SELECT c.member_id, c.plan_year, c.status, r.patient_pay, r.deductible_applied
+            FROM reverts r JOIN claims c ON c.id = r.claim_id
+            WHERE r.id = ?;
+        `, revert.ID).Scan(&memberID, &planYear, &status, &patientPay, &deductible)
+		if err != nil {
+			return fmt.Errorf("error reading revert %s for its accumulators: %w", revert.ID, err)
+		}
+		if status == models.ClaimStatusPaid {
+			if err := addToAccumulatorTx(tx, memberID, planYear, deductible, patientPay, revert.VoidedAt); err != nil {
+				return err
+			}
+		}
+	}
+
+	if change != nil {
+		if err := updateClaimStatusTx(tx, *change); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
-// SaveRevert inserts a new revert record into the database.
-func (s *SQLiteRepository) SaveRevert(revert models.Revert) error {
-	stmt, err := s.DB.Prepare(`
-        INSERT INTO reverts(id, claim_id, timestamp)
-        VALUES(?, ?, ?)
-        ON CONFLICT(id) DO UPDATE SET
-            claim_id = excluded.claim_id,
-            timestamp = excluded.timestamp;
+// SaveQuarantinedClaims inserts rejected claim records into the quarantine table within a transaction.
+// Records are keyed by source file and position, so reloading the same file replaces them.
+func (s *SQLiteRepository) SaveQuarantinedClaims(records []models.QuarantinedClaim) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for quarantined claims: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+        INSERT OR REPLACE INTO quarantined_claims (source_file, record_index, claim_id, reason, payload, timestamp)
+        VALUES (?, ?, ?, ?, ?, ?);
     `)
 	if err != nil {
-		return fmt.Errorf("error preparing statement to insert/update revert: %w", err)
+		return fmt.Errorf("error preparing statement to save quarantined claims: %w", err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(
-		revert.ID,
-		revert.ClaimID,
-		revert.Timestamp,
-	)
+	for _, record := range records {
+		_, err := stmt.Exec(record.SourceFile, record.RecordIndex, record.ClaimID, record.Reason, record.Payload, record.Timestamp)
+		if err != nil {
+			return fmt.Errorf("error saving quarantined record %d of %s: %w", record.RecordIndex, record.SourceFile, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetClaimsByNPI fetches the claims of a pharmacy submitted in [from, to) that were paid at
+// adjudication; pending and rejected claims are left out.
+// Bounds are compared against the claim timestamp as strings, e.g. "2024-02-01".
+func (s *SQLiteRepository) GetClaimsByNPI(npi, from, to string) ([]models.Claim, error) {
+	rows, err := s.DB.Query(`
+        SELECT `+claimColumns+`
+        FROM claims
+        WHERE npi = ? AND timestamp >= ? AND timestamp < ? AND status NOT IN (?, ?)
+        ORDER BY timestamp, id;
+    `, npi, from, to, models.ClaimStatusPending, models.ClaimStatusRejected)
 	if err != nil {
-		return fmt.Errorf("error executing insert/update for revert %s: %w", revert.ID, err)
+		return nil, fmt.Errorf("error querying claims for NPI %s: %w", npi, err)
 	}
-	return nil
+	defer rows.Close()
+
+	var claims []models.Claim
+	for rows.Next() {
+		claim, err := scanClaim(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning claim for NPI %s: %w", npi, err)
+		}
+		claims = append(claims, claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claims for NPI %s: %w", npi, err)
+	}
+	return claims, nil
+}
+
+// fillDateSQL is the date a claim was filled: its date of service, or the date of its timestamp
+// for claims submitted without one.
+const fillDateSQL = "COALESCE(NULLIF(date_of_service, ''), substr(timestamp, 1, 10))"
+
+// GetLatestFill fetches the latest fill of the NDC for the member filled on or before the date
+// (YYYY-MM-DD). Only paid and pending claims with a days supply count as fills; reversed, rejected
+// and rebilled claims are left out, as is the claim excludeID, e.g. the claim being rebilled. The
+// lookup uses the (member_id, ndc, date_of_service) index. It returns nil when the member has no
+// such fill.
+func (s *SQLiteRepository) GetLatestFill(memberID, ndc, date, excludeID string) (*models.Claim, error) {
+	row := s.DB.QueryRow(`
+        SELECT `+claimColumns+`
+        FROM claims
+        WHERE member_id = ? AND ndc = ? AND days_supply > 0 AND status IN (?, ?)
+            AND `+fillDateSQL+` <= ? AND id != ?
+        ORDER BY `+fillDateSQL+` DESC, timestamp DESC
+        LIMIT 1;
+    `, memberID, ndc, models.ClaimStatusPaid, models.ClaimStatusPending, date, excludeID)
+
+	claim, err := scanClaim(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error fetching latest fill of NDC %s for member %s: %w", ndc, memberID, err)
+	}
+	return &claim, nil
 }
 
-// SaveReverts inserts multiple reverts into the database within a transaction.
-func (s *SQLiteRepository) SaveReverts(reverts []models.Revert) error {
+// GetReversedClaimsByNPI fetches the reversals recorded in [from, to) for claims of a pharmacy.
+// Voided reverts are left out.
+func (s *SQLiteRepository) GetReversedClaimsByNPI(npi, from, to string) ([]models.ReversedClaim, error) {
+	rows, err := s.DB.Query(`
+        SELECT r.id, r.claim_id, r.timestamp, r.quantity, r.amount, r.reason, r.reason_code, r.patient_pay, r.deductible_applied,
+               c.id, c.ndc, c.npi, c.quantity, c.price, c.timestamp, c.status, c.allowed_amount, c.pricing_basis,
+               c.benefit_plan, c.patient_pay, c.deductible_applied
+        FROM reverts r
+        JOIN claims c ON c.id = r.claim_id
+        WHERE c.npi = ? AND r.timestamp >= ? AND r.timestamp < ? AND r.voided_at = ''
+        ORDER BY r.timestamp, r.id;
+    `, npi, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reversed claims for NPI %s: %w", npi, err)
+	}
+	defer rows.Close()
+
+	var reversed []models.ReversedClaim
+	for rows.Next() {
+		var rc models.ReversedClaim
+		if err := rows.Scan(
+			&rc.Revert.ID, &rc.Revert.ClaimID, &rc.Revert.Timestamp, &rc.Revert.Quantity, &rc.Revert.Amount, &rc.Revert.Reason, &rc.Revert.ReasonCode,
+			&rc.Revert.PatientPay, &rc.Revert.DeductibleApplied,
+			&rc.Claim.ID, &rc.Claim.NDC, &rc.Claim.NPI, &rc.Claim.Quantity, &rc.Claim.Price, &rc.Claim.Timestamp, &rc.Claim.Status,
+			&rc.Claim.AllowedAmount, &rc.Claim.PricingBasis,
+			&rc.Claim.Plan, &rc.Claim.PatientPay, &rc.Claim.DeductibleApplied,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning reversed claim for NPI %s: %w", npi, err)
+		}
+		rc.Claim.Reverted = rc.Claim.Status == models.ClaimStatusReversed
+		reversed = append(reversed, rc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reversed claims for NPI %s: %w", npi, err)
+	}
+	return reversed, nil
+}
+
+// NextControlNumber increments and returns the persisted control number with the given name.
+func (s *SQLiteRepository) NextControlNumber(name string) (int64, error) {
 	tx, err := s.DB.Begin()
 	if err != nil {
-		return fmt.Errorf("error starting transaction for reverts: %w", err)
+		return 0, fmt.Errorf("error starting transaction for control number %s: %w", name, err)
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
-        INSERT INTO reverts (id, claim_id, timestamp)
-        VALUES (?, ?, ?)
-        ON CONFLICT(id) DO UPDATE SET
-            claim_id = excluded.claim_id,
-            timestamp = excluded.timestamp;
-    `)
+	if _, err := tx.Exec("INSERT OR IGNORE INTO x12_control_numbers(name, value) VALUES(?, 0)", name); err != nil {
+		return 0, fmt.Errorf("error initializing control number %s: %w", name, err)
+	}
+	if _, err := tx.Exec("UPDATE x12_control_numbers SET value = value + 1 WHERE name = ?", name); err != nil {
+		return 0, fmt.Errorf("error incrementing control number %s: %w", name, err)
+	}
+
+	var value int64
+	if err := tx.QueryRow("SELECT value FROM x12_control_numbers WHERE name = ?", name).Scan(&value); err != nil {
+		return 0, fmt.Errorf("error reading control number %s: %w", name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error committing control number %s: %w", name, err)
+	}
+	return value, nil
+}
+
+// SearchClaims fetches the claims matching the filter ordered by timestamp, along with the
+// total number of matches ignoring Limit and Offset.
+func (s *SQLiteRepository) SearchClaims(filter models.ClaimFilter) ([]models.Claim, int, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.NPI != "" {
+		conditions = append(conditions, "npi = ?")
+		args = append(args, filter.NPI)
+	}
+	if filter.NDC != "" {
+		conditions = append(conditions, "ndc = ?")
+		args = append(args, filter.NDC)
+	}
+	if filter.From != "" {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.To != "" {
+		conditions = append(conditions, "timestamp < ?")
+		args = append(args, filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := s.DB.QueryRow("SELECT COUNT(*) FROM claims"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting claims: %w", err)
+	}
+
+	query := "SELECT " + claimColumns + " FROM claims" + where + " ORDER BY timestamp, id"
+	if filter.Limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, filter.Limit, filter.Offset)
+	}
+
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
-		return fmt.Errorf("error preparing statement to save reverts in batch: %w", err)
+		return nil, 0, fmt.Errorf("error searching claims: %w", err)
+	}
+	defer rows.Close()
+
+	var claims []models.Claim
+	for rows.Next() {
+		claim, err := scanClaim(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning claim: %w", err)
+		}
+		claims = append(claims, claim)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating claims: %w", err)
+	}
+	return claims, total, nil
+}
+
+// reportDateSQL returns the expression placing claims in the period of a report by the date, one
+// of models.ReportDateReceived and models.ReportDateService.
+func reportDateSQL(date string) string {
+	if date == models.ReportDateService {
+		return fillDateSQL
+	}
+	return "timestamp"
+}
+
+// GetChainSavings sums the submitted and allowed amounts of the paid claims in [from, to) by the
+// date, grouped by the chain of their pharmacy and sorted by chain. Partially reversed claims count
+// with their outstanding amount and the share of their allowed amount it stands for.
+func (s *SQLiteRepository) GetChainSavings(from, to, date string) ([]models.ChainSavings, error) {
+	rows, err := s.DB.Query(`
+        SELECT p.chain, COUNT(*), SUM(`+outstandingAmountSQL+`), SUM(`+outstandingAllowedSQL+`)
+        FROM claims
+        JOIN pharmacies p ON p.npi = claims.npi
+        WHERE claims.status = ? AND `+reportDateSQL(date)+` >= ? AND `+reportDateSQL(date)+` < ?
+        GROUP BY p.chain
+        ORDER BY p.chain;
+    `, models.ClaimStatusPaid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying savings by chain: %w", err)
+	}
+	defer rows.Close()
+
+	var savings []models.ChainSavings
+	for rows.Next() {
+		var chain models.ChainSavings
+		if err := rows.Scan(&chain.Chain, &chain.ClaimCount, &chain.SubmittedAmount, &chain.AllowedAmount); err != nil {
+			return nil, fmt.Errorf("error scanning savings by chain: %w", err)
+		}
+		savings = append(savings, chain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating savings by chain: %w", err)
+	}
+	return savings, nil
+}
+
+// UpdateClaimPriceVariances stores the price variance of the claims within a transaction.
+func (s *SQLiteRepository) UpdateClaimPriceVariances(variances []models.ClaimPriceVariance) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for price variances: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare("UPDATE claims SET reference_unit_price = ?, price_variance = ?, price_flagged = ? WHERE id = ?")
+	if err != nil {
+		return fmt.Errorf("error preparing statement to update price variances: %w", err)
 	}
 	defer stmt.Close()
 
-	for _, revert := range reverts {
-		_, err := stmt.Exec(revert.ID, revert.ClaimID, revert.Timestamp)
+	for _, variance := range variances {
+		if _, err := stmt.Exec(variance.ReferenceUnitPrice, variance.VariancePercent, variance.Flagged, variance.ClaimID); err != nil {
+			return fmt.Errorf("error updating price variance of claim %s: %w", variance.ClaimID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPriceOutliers fetches the claims in [from, to) by the date flagged for their unit price
+// variance, grouped by pharmacy and sorted by NPI then date. An empty npi matches every pharmacy.
+func (s *SQLiteRepository) GetPriceOutliers(npi, from, to, date string) ([]models.PharmacyPriceOutliers, error) {
+	rows, err := s.DB.Query(`
+        SELECT c.npi, COALESCE(p.chain, ''), c.id, c.ndc, c.quantity, c.price, c.reference_unit_price, c.price_variance,
+               c.status, c.timestamp, c.date_of_service
+        FROM claims c
+        LEFT JOIN pharmacies p ON p.npi = c.npi
+        WHERE c.price_flagged = TRUE AND `+reportDateSQL(date)+` >= ? AND `+reportDateSQL(date)+` < ? AND (? = '' OR c.npi = ?)
+        ORDER BY c.npi, `+reportDateSQL(date)+`, c.timestamp, c.id;
+    `, from, to, npi, npi)
+	if err != nil {
+		return nil, fmt.Errorf("error querying price outliers: %w", err)
+	}
+	defer rows.Close()
+
+	var pharmacies []models.PharmacyPriceOutliers
+	for rows.Next() {
+		var pharmacyNPI, chain string
+		var outlier models.PriceOutlier
+		if err := rows.Scan(&pharmacyNPI, &chain, &outlier.ClaimID, &outlier.NDC, &outlier.Quantity, &outlier.Price,
+			&outlier.ReferenceUnitPrice, &outlier.VariancePercent, &outlier.Status, &outlier.Timestamp, &outlier.DateOfService); err != nil {
+			return nil, fmt.Errorf("error scanning price outlier: %w", err)
+		}
+		if len(pharmacies) == 0 || pharmacies[len(pharmacies)-1].NPI != pharmacyNPI {
+			pharmacies = append(pharmacies, models.PharmacyPriceOutliers{NPI: pharmacyNPI, Chain: chain})
+		}
+		last := &pharmacies[len(pharmacies)-1]
+		last.Claims = append(last.Claims, outlier)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating price outliers: %w", err)
+	}
+	return pharmacies, nil
+}
+
+// GetNDCClaimTotals sums the outstanding quantity and amount of the paid claims in [from, to) by
+// the date, grouped by pharmacy and NDC and sorted by NPI then NDC. Claims without an outstanding
+// quantity are left out.
+func (s *SQLiteRepository) GetNDCClaimTotals(from, to, date string) ([]models.NDCClaimTotals, error) {
+	rows, err := s.DB.Query(`
+        SELECT claims.npi, COALESCE(p.chain, ''), claims.ndc, COUNT(*), SUM(`+outstandingQuantitySQL+`), SUM(`+outstandingAmountSQL+`)
+        FROM claims
+        LEFT JOIN pharmacies p ON p.npi = claims.npi
+        WHERE claims.status = ? AND `+outstandingQuantitySQL+` > 0 AND `+reportDateSQL(date)+` >= ? AND `+reportDateSQL(date)+` < ?
+        GROUP BY claims.npi, claims.ndc
+        ORDER BY claims.npi, claims.ndc;
+    `, models.ClaimStatusPaid, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error querying claim totals by NDC: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []models.NDCClaimTotals
+	for rows.Next() {
+		var total models.NDCClaimTotals
+		if err := rows.Scan(&total.NPI, &total.Chain, &total.NDC, &total.ClaimCount, &total.Quantity, &total.Amount); err != nil {
+			return nil, fmt.Errorf("error scanning claim totals by NDC: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claim totals by NDC: %w", err)
+	}
+	return totals, nil
+}
+
+// CountClaimsByNPI counts the claims submitted in [from, to) by pharmacy, whatever their status.
+func (s *SQLiteRepository) CountClaimsByNPI(from, to string) (map[string]int, error) {
+	rows, err := s.DB.Query(`
+        SELECT npi, COUNT(*)
+        FROM claims
+        WHERE timestamp >= ? AND timestamp < ?
+        GROUP BY npi;
+    `, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("error counting claims by NPI: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var npi string
+		var count int
+		if err := rows.Scan(&npi, &count); err != nil {
+			return nil, fmt.Errorf("error scanning claim count by NPI: %w", err)
+		}
+		counts[npi] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating claim counts by NPI: %w", err)
+	}
+	return counts, nil
+}
+
+// GetReversalCounts counts, by pharmacy, the claims submitted in [from, to) that were paid at
+// adjudication and how many of them have a reversal that was not voided. Sorted by NPI.
+func (s *SQLiteRepository) GetReversalCounts(from, to string) ([]models.ReversalCount, error) {
+	rows, err := s.DB.Query(`
+        SELECT c.npi, COUNT(*),
+               SUM(CASE WHEN EXISTS (SELECT 1 FROM reverts r WHERE r.claim_id = c.id AND r.voided_at = '') THEN 1 ELSE 0 END)
+        FROM claims c
+        WHERE c.timestamp >= ? AND c.timestamp < ? AND c.status NOT IN (?, ?)
+        GROUP BY c.npi
+        ORDER BY c.npi;
+    `, from, to, models.ClaimStatusPending, models.ClaimStatusRejected)
+	if err != nil {
+		return nil, fmt.Errorf("error counting reversals by NPI: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []models.ReversalCount
+	for rows.Next() {
+		var count models.ReversalCount
+		if err := rows.Scan(&count.NPI, &count.ClaimCount, &count.ReversedCount); err != nil {
+			return nil, fmt.Errorf("error scanning reversal count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reversal counts: %w", err)
+	}
+	return counts, nil
+}
+
+// GetPharmacyUnitPrices computes the average unit price billed by every pharmacy for every NDC
+// over the claims submitted in [from, to), rejected claims excluded. Sorted by NDC then NPI.
+func (s *SQLiteRepository) GetPharmacyUnitPrices(from, to string) ([]models.PharmacyUnitPrice, error) {
+	rows, err := s.DB.Query(`
+        SELECT ndc, npi, COUNT(*), SUM(price) / SUM(quantity)
+        FROM claims
+        WHERE timestamp >= ? AND timestamp < ? AND status != ? AND quantity > 0
+        GROUP BY ndc, npi
+        ORDER BY ndc, npi;
+    `, from, to, models.ClaimStatusRejected)
+	if err != nil {
+		return nil, fmt.Errorf("error querying unit prices by pharmacy: %w", err)
+	}
+	defer rows.Close()
+
+	var prices []models.PharmacyUnitPrice
+	for rows.Next() {
+		var price models.PharmacyUnitPrice
+		if err := rows.Scan(&price.NDC, &price.NPI, &price.ClaimCount, &price.UnitPrice); err != nil {
+			return nil, fmt.Errorf("error scanning unit price by pharmacy: %w", err)
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unit prices by pharmacy: %w", err)
+	}
+	return prices, nil
+}
+
+// RecordAnomalies stores the anomalies found by an analysis at detectedAt within a transaction.
+// An anomaly already open for the same type, pharmacy and NDC is updated; a new one is opened
+// otherwise. Open anomalies not found anymore are resolved.
+func (s *SQLiteRepository) RecordAnomalies(anomalies []models.Anomaly, detectedAt string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for anomalies: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, type, npi, ndc FROM anomalies WHERE resolved_at = ''")
+	if err != nil {
+		return fmt.Errorf("error querying open anomalies: %w", err)
+	}
+	open := make(map[[3]string]int64)
+	for rows.Next() {
+		var id int64
+		var key [3]string
+		if err := rows.Scan(&id, &key[0], &key[1], &key[2]); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning open anomaly: %w", err)
+		}
+		open[key] = id
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating open anomalies: %w", err)
+	}
+
+	for _, anomaly := range anomalies {
+		key := [3]string{anomaly.Type, anomaly.NPI, anomaly.NDC}
+		if id, ok := open[key]; ok {
+			_, err = tx.Exec(`
+                UPDATE anomalies SET severity = ?, value = ?, expected = ?, message = ?, last_detected_at = ?
+                WHERE id = ?;
+            `, anomaly.Severity, anomaly.Value, anomaly.Expected, anomaly.Message, detectedAt, id)
+			delete(open, key)
+		} else {
+			_, err = tx.Exec(`
+                INSERT INTO anomalies (type, npi, ndc, severity, value, expected, message, first_detected_at, last_detected_at)
+                VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+            `, anomaly.Type, anomaly.NPI, anomaly.NDC, anomaly.Severity, anomaly.Value, anomaly.Expected, anomaly.Message, detectedAt, detectedAt)
+		}
 		if err != nil {
-			return fmt.Errorf("error executing insert/update for revert %s: %w", revert.ID, err)
+			return fmt.Errorf("error saving %s anomaly of NPI %s: %w", anomaly.Type, anomaly.NPI, err)
 		}
 	}
 
+	for _, id := range open {
+		if _, err := tx.Exec("UPDATE anomalies SET resolved_at = ? WHERE id = ?", detectedAt, id); err != nil {
+			return fmt.Errorf("error resolving anomaly %d: %w", id, err)
+		}
+	}
 	return tx.Commit()
 }
+
+// ListAnomalies fetches the anomalies matching the filter, the latest detected first.
+func (s *SQLiteRepository) ListAnomalies(filter models.AnomalyFilter) ([]models.Anomaly, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.NPI != "" {
+		conditions = append(conditions, "npi = ?")
+		args = append(args, filter.NPI)
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, filter.Type)
+	}
+	if filter.Severity != "" {
+		conditions = append(conditions, "severity = ?")
+		args = append(args, filter.Severity)
+	}
+	if filter.Open != nil {
+		if *filter.Open {
+			conditions = append(conditions, "resolved_at = ''")
+		} else {
+			conditions = append(conditions, "resolved_at != ''")
+		}
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := s.DB.Query(`
+        SELECT id, type, npi, ndc, severity, value, expected, message, first_detected_at, last_detected_at, resolved_at
+        FROM anomalies`+where+`
+        ORDER BY last_detected_at DESC, id DESC;
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []models.Anomaly
+	for rows.Next() {
+		var anomaly models.Anomaly
+		if err := rows.Scan(&anomaly.ID, &anomaly.Type, &anomaly.NPI, &anomaly.NDC, &anomaly.Severity, &anomaly.Value,
+			&anomaly.Expected, &anomaly.Message, &anomaly.FirstDetectedAt, &anomaly.LastDetectedAt, &anomaly.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("error scanning anomaly: %w", err)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating anomalies: %w", err)
+	}
+	return anomalies, nil
+}
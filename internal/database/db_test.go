@@ -0,0 +1,163 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// newTestRepository opens a migrated in-memory SQLite database with one pharmacy of chain health.
+func newTestRepository(t *testing.T) *database.SQLiteRepository {
+	dbRepo, err := database.InitDB(":memory:")
+	if err != nil {
+		t.Fatalf("error opening test database: %v", err)
+	}
+	t.Cleanup(func() { dbRepo.Close() })
+
+	repo := dbRepo.(*database.SQLiteRepository)
+	// Every connection to :memory: opens its own database.
+	repo.DB.SetMaxOpenConns(1)
+	if err := database.ApplyMigrations(repo.DB); err != nil {
+		t.Fatalf("error migrating test database: %v", err)
+	}
+	if err := repo.SavePharmacy(models.Pharmacy{NPI: "1234567890", Chain: "health"}); err != nil {
+		t.Fatalf("error saving test pharmacy: %v", err)
+	}
+	return repo
+}
+
+// testClaim returns a paid claim of the test pharmacy submitted on 2024-02-01.
+func testClaim(id string, quantity, price, allowed float64) models.Claim {
+	return models.Claim{
+		ID: id, NDC: "00002323401", NPI: "1234567890", Quantity: quantity, Price: price, Timestamp: "2024-02-01T10:00:00",
+		Status: models.ClaimStatusPaid, AllowedAmount: allowed, PricingBasis: models.PricingBasisContract,
+	}
+}
+
+func TestGetChainSavingsNetsReversals(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{
+		testClaim("paid", 10, 100, 80),
+		testClaim("partial", 10, 100, 80),
+		testClaim("voided", 10, 100, 80),
+		testClaim("reversed", 10, 100, 80),
+	}))
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{
+		{ID: "r1", ClaimID: "partial", Timestamp: "2024-02-02T10:00:00", Quantity: 4, Amount: 40},
+		{ID: "r2", ClaimID: "voided", Timestamp: "2024-02-02T10:00:00", Quantity: 5, Amount: 50},
+		{ID: "r3", ClaimID: "reversed", Timestamp: "2024-02-02T10:00:00", Quantity: 10, Amount: 100},
+	}, models.ActorAPI))
+	assert.Nil(t, repo.ReinstateClaim(models.Revert{ID: "r2", ClaimID: "voided", VoidedAt: "2024-02-03T10:00:00", VoidReason: "wrong claim"}, nil))
+
+	savings, err := repo.GetChainSavings("2024-02-01", "2024-03-01", models.ReportDateReceived)
+	assert.Nil(t, err)
+	if assert.Len(t, savings, 1) {
+		assert.Equal(t, 3, savings[0].ClaimCount, "Expected the fully reversed claim left out")
+		assert.InDelta(t, 260, savings[0].SubmittedAmount, 0.001, "Expected the partial reversal netted and the voided one ignored")
+		assert.InDelta(t, 208, savings[0].AllowedAmount, 0.001, "Expected the allowed amount prorated to the outstanding amount")
+	}
+}
+
+func TestGetNDCClaimTotalsNetsReversals(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{
+		testClaim("paid", 10, 100, 100),
+		testClaim("partial", 10, 100, 100),
+		testClaim("reversed", 10, 100, 100),
+	}))
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{
+		{ID: "r1", ClaimID: "partial", Timestamp: "2024-02-02T10:00:00", Quantity: 6, Amount: 60},
+		{ID: "r2", ClaimID: "reversed", Timestamp: "2024-02-02T10:00:00", Quantity: 10, Amount: 100},
+	}, models.ActorAPI))
+
+	totals, err := repo.GetNDCClaimTotals("2024-02-01", "2024-03-01", models.ReportDateService)
+	assert.Nil(t, err)
+	if assert.Len(t, totals, 1) {
+		assert.Equal(t, models.NDCClaimTotals{NPI: "1234567890", Chain: "health", NDC: "00002323401", ClaimCount: 2, Quantity: 14, Amount: 140}, totals[0],
+			"Expected the outstanding quantity and amount of the paid claims")
+	}
+}
+
+// memberClaim returns a paid claim of member M1 with its cost sharing in plan year 2024.
+func memberClaim(id string, patientPay, deductible float64) models.Claim {
+	claim := testClaim(id, 10, 100, 100)
+	claim.MemberID = "M1"
+	claim.ClaimBenefit = models.ClaimBenefit{Plan: "GOLD", PlanYear: 2024, PatientPay: patientPay, DeductibleApplied: deductible}
+	return claim
+}
+
+// assertAccumulator checks the deductible met and out-of-pocket spent of member M1 in 2024.
+func assertAccumulator(t *testing.T, repo *database.SQLiteRepository, deductible, outOfPocket float64, msg string) {
+	t.Helper()
+	accumulator, err := repo.GetAccumulator("M1", 2024)
+	assert.Nil(t, err)
+	assert.InDelta(t, deductible, accumulator.DeductibleMet, 0.001, msg)
+	assert.InDelta(t, outOfPocket, accumulator.OutOfPocketSpent, 0.001, msg)
+}
+
+func TestReverseClaimsPartialThenFull(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{memberClaim("c1", 30, 20)}))
+	assertAccumulator(t, repo, 20, 30, "Expected the cost sharing of the paid claim")
+
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r1", ClaimID: "c1", Timestamp: "2024-02-02T10:00:00", Quantity: 4, Amount: 40}}, models.ActorAPI))
+	assertAccumulator(t, repo, 12, 18, "Expected the share of the partial reversal rolled back")
+	claim, err := repo.GetClaimByID("c1")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status)
+	assert.InDelta(t, 18, claim.OutstandingPatientPay, 0.001)
+	assert.InDelta(t, 12, claim.OutstandingDeductible, 0.001)
+
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r2", ClaimID: "c1", Timestamp: "2024-02-03T10:00:00", Quantity: 6, Amount: 60}}, models.ActorAPI))
+	assertAccumulator(t, repo, 0, 0, "Expected everything outstanding rolled back by the last reversal")
+	claim, err = repo.GetClaimByID("c1")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusReversed, claim.Status)
+
+	err = repo.ReverseClaims([]models.Revert{{ID: "r3", ClaimID: "c1", Timestamp: "2024-02-04T10:00:00", Quantity: 1, Amount: 10}}, models.ActorAPI)
+	assert.ErrorIs(t, err, database.ErrReversalRefused, "Expected a reversed claim not to be reversed again")
+	err = repo.ReverseClaims([]models.Revert{{ID: "r2", ClaimID: "c1", Timestamp: "2024-02-04T10:00:00"}}, models.ActorAPI)
+	assert.ErrorIs(t, err, database.ErrRevertExists)
+	assertAccumulator(t, repo, 0, 0, "Expected refused reversals not to change the accumulator")
+}
+
+func TestReverseClaimsInReverseSubmissionOrder(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{memberClaim("c1", 30, 20), memberClaim("c2", 25, 0)}))
+	assertAccumulator(t, repo, 20, 55, "Expected the cost sharing of both claims")
+
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r2", ClaimID: "c2", Timestamp: "2024-02-02T10:00:00", Quantity: 10, Amount: 100}}, models.ActorAPI))
+	assertAccumulator(t, repo, 20, 30, "Expected the later claim to roll back only its own cost sharing")
+
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r1", ClaimID: "c1", Timestamp: "2024-02-03T10:00:00", Quantity: 10, Amount: 100}}, models.ActorAPI))
+	assertAccumulator(t, repo, 0, 0, "Expected nothing left once both claims are reversed")
+}
+
+func TestReinstateClaimThenReverse(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{memberClaim("c1", 30, 20)}))
+
+	// A voided partial reversal of a paid claim counts its cost sharing again.
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r1", ClaimID: "c1", Timestamp: "2024-02-02T10:00:00", Quantity: 5, Amount: 50}}, models.ActorAPI))
+	assertAccumulator(t, repo, 10, 15, "Expected half the cost sharing rolled back")
+	assert.Nil(t, repo.ReinstateClaim(models.Revert{ID: "r1", ClaimID: "c1", VoidedAt: "2024-02-03T10:00:00", VoidReason: "wrong quantity"}, nil))
+	assertAccumulator(t, repo, 20, 30, "Expected the voided partial reversal added back")
+
+	// A reinstated reversed claim counts all of it again.
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r2", ClaimID: "c1", Timestamp: "2024-02-04T10:00:00", Quantity: 10, Amount: 100}}, models.ActorAPI))
+	assertAccumulator(t, repo, 0, 0, "Expected the whole cost sharing rolled back")
+	assert.Nil(t, repo.ReinstateClaim(models.Revert{ID: "r2", ClaimID: "c1", VoidedAt: "2024-02-05T10:00:00", VoidReason: "reversed the wrong claim"},
+		&models.ClaimStatusChange{ClaimID: "c1", FromStatus: models.ClaimStatusReversed, ToStatus: models.ClaimStatusPaid, Actor: models.ActorAPI,
+			Reason: "reversed the wrong claim", Timestamp: "2024-02-05T10:00:00"}))
+	assertAccumulator(t, repo, 20, 30, "Expected the reinstated claim to count again")
+	claim, err := repo.GetClaimByID("c1")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status)
+	assert.InDelta(t, 10, claim.OutstandingQuantity, 0.001, "Expected voided reverts not to count")
+
+	assert.Nil(t, repo.ReverseClaims([]models.Revert{{ID: "r3", ClaimID: "c1", Timestamp: "2024-02-06T10:00:00", Quantity: 10, Amount: 100}}, models.ActorAPI))
+	assertAccumulator(t, repo, 0, 0, "Expected the reversal after the reinstatement to roll back everything again")
+}
@@ -31,11 +31,243 @@ func ApplyMigrations(db *sql.DB) error {
 		timestamp TEXT NOT NULL,
 		FOREIGN KEY (claim_id) REFERENCES claims(id)
 	);
+	CREATE TABLE IF NOT EXISTS quarantined_claims (
+		source_file TEXT NOT NULL,
+		record_index INTEGER NOT NULL,
+		claim_id TEXT,
+		reason TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		timestamp TEXT NOT NULL,
+		PRIMARY KEY (source_file, record_index)
+	);
+	CREATE TABLE IF NOT EXISTS claim_status_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		claim_id TEXT NOT NULL,
+		from_status TEXT NOT NULL,
+		to_status TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		timestamp TEXT NOT NULL,
+		FOREIGN KEY (claim_id) REFERENCES claims(id)
+	);
+	CREATE TABLE IF NOT EXISTS x12_control_numbers (
+		name TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS anomalies (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		type TEXT NOT NULL,
+		npi TEXT NOT NULL,
+		ndc TEXT NOT NULL DEFAULT '',
+		severity TEXT NOT NULL,
+		value REAL NOT NULL,
+		expected REAL NOT NULL,
+		message TEXT NOT NULL,
+		first_detected_at TEXT NOT NULL,
+		last_detected_at TEXT NOT NULL,
+		resolved_at TEXT NOT NULL DEFAULT ''
+	);
+	CREATE TABLE IF NOT EXISTS member_accumulators (
+		member_id TEXT NOT NULL,
+		plan_year INTEGER NOT NULL,
+		deductible_met REAL NOT NULL DEFAULT 0,
+		out_of_pocket_spent REAL NOT NULL DEFAULT 0,
+		updated_at TEXT NOT NULL,
+		PRIMARY KEY (member_id, plan_year)
+	);
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_anomalies_open ON anomalies(type, npi, ndc) WHERE resolved_at = '';
+	CREATE INDEX IF NOT EXISTS idx_anomalies_npi ON anomalies(npi);
+	CREATE INDEX IF NOT EXISTS idx_claims_npi_timestamp ON claims(npi, timestamp);
+	CREATE INDEX IF NOT EXISTS idx_reverts_claim_id ON reverts(claim_id);
+	CREATE INDEX IF NOT EXISTS idx_claims_ndc ON claims(ndc);
+	CREATE INDEX IF NOT EXISTS idx_claims_timestamp ON claims(timestamp);
+	CREATE INDEX IF NOT EXISTS idx_claim_status_history_claim_id ON claim_status_history(claim_id);
 	`
 	_, err := db.Exec(schema)
 	if err != nil {
 		return fmt.Errorf("error applying migrations: %w", err)
 	}
+
+	if _, err := addColumnIfMissing(db, "reverts", "reason", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	added, err := addColumnIfMissing(db, "claims", "status", "TEXT NOT NULL DEFAULT 'paid'")
+	if err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if added {
+		if err := backfillClaimStatus(db); err != nil {
+			return fmt.Errorf("error applying migrations: %w", err)
+		}
+	}
+
+	if _, err := addColumnIfMissing(db, "claims", "replaces_claim_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_claims_replaces_claim_id ON claims(replaces_claim_id)"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := addColumnIfMissing(db, "claims", "rejects", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	added, err = addColumnIfMissing(db, "claims", "allowed_amount", "REAL NOT NULL DEFAULT 0")
+	if err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := addColumnIfMissing(db, "claims", "pricing_basis", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if added {
+		// Claims submitted before contract pricing existed were paid their submitted price.
+		if _, err := db.Exec("UPDATE claims SET allowed_amount = price, pricing_basis = 'submitted'"); err != nil {
+			return fmt.Errorf("error applying migrations: error backfilling allowed amounts: %w", err)
+		}
+	}
+
+	for _, column := range []struct{ name, definition string }{
+		{"reference_unit_price", "REAL NOT NULL DEFAULT 0"},
+		{"price_variance", "REAL NOT NULL DEFAULT 0"},
+		{"price_flagged", "BOOLEAN NOT NULL DEFAULT FALSE"},
+	} {
+		if _, err := addColumnIfMissing(db, "claims", column.name, column.definition); err != nil {
+			return fmt.Errorf("error applying migrations: %w", err)
+		}
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_claims_price_flagged_timestamp ON claims(price_flagged, timestamp)"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	for _, column := range []struct{ name, definition string }{
+		{"member_id", "TEXT NOT NULL DEFAULT ''"},
+		{"prescriber_npi", "TEXT NOT NULL DEFAULT ''"},
+		{"rx_number", "TEXT NOT NULL DEFAULT ''"},
+		{"fill_number", "INTEGER NOT NULL DEFAULT 0"},
+		{"days_supply", "INTEGER NOT NULL DEFAULT 0"},
+		{"date_of_service", "TEXT NOT NULL DEFAULT ''"},
+		{"daw_code", "TEXT NOT NULL DEFAULT ''"},
+		{"override_code", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if _, err := addColumnIfMissing(db, "claims", column.name, column.definition); err != nil {
+			return fmt.Errorf("error applying migrations: %w", err)
+		}
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_claims_member_ndc_date_of_service ON claims(member_id, ndc, date_of_service)"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	added, err = addColumnIfMissing(db, "reverts", "quantity", "REAL NOT NULL DEFAULT 0")
+	if err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := addColumnIfMissing(db, "reverts", "amount", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if added {
+		// Reverts recorded before partial reversals existed reversed the whole claim.
+		if _, err := db.Exec(`
+			UPDATE reverts SET
+				quantity = COALESCE((SELECT c.quantity FROM claims c WHERE c.id = reverts.claim_id), 0),
+				amount = COALESCE((SELECT c.price FROM claims c WHERE c.id = reverts.claim_id), 0)
+		`); err != nil {
+			return fmt.Errorf("error applying migrations: error backfilling revert quantities: %w", err)
+		}
+	}
+
+	if _, err := addColumnIfMissing(db, "reverts", "voided_at", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := addColumnIfMissing(db, "reverts", "void_reason", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+	if _, err := addColumnIfMissing(db, "reverts", "reason_code", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("error applying migrations: %w", err)
+	}
+
+	// Member cost sharing: the patient pay of claims and what reversals rolled back of it.
+	for _, column := range []struct{ table, name, definition string }{
+		{"claims", "benefit_plan", "TEXT NOT NULL DEFAULT ''"},
+		{"claims", "plan_year", "INTEGER NOT NULL DEFAULT 0"},
+		{"claims", "patient_pay", "REAL NOT NULL DEFAULT 0"},
+		{"claims", "deductible_applied", "REAL NOT NULL DEFAULT 0"},
+		{"reverts", "patient_pay", "REAL NOT NULL DEFAULT 0"},
+		{"reverts", "deductible_applied", "REAL NOT NULL DEFAULT 0"},
+	} {
+		if _, err := addColumnIfMissing(db, column.table, column.name, column.definition); err != nil {
+			return fmt.Errorf("error applying migrations: %w", err)
+		}
+	}
+
 	log.Println("Migrations applied successfully.")
 	return nil
 }
+
+// backfillClaimStatus maps the reverted flag of the claims stored before the status column
+// existed and records their history: every claim was paid at submission, and the reverted
+// ones were reversed at the time of their first revert.
+func backfillClaimStatus(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction for claim status backfill: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`UPDATE claims SET status = 'reversed' WHERE reverted = TRUE`,
+		`INSERT INTO claim_status_history (claim_id, from_status, to_status, actor, timestamp)
+		SELECT id, '', 'paid', 'migration', timestamp FROM claims ORDER BY timestamp, id`,
+		`INSERT INTO claim_status_history (claim_id, from_status, to_status, actor, timestamp)
+		SELECT c.id, 'paid', 'reversed', 'migration',
+			COALESCE((SELECT MIN(r.timestamp) FROM reverts r WHERE r.claim_id = c.id), c.timestamp)
+		FROM claims c WHERE c.reverted = TRUE ORDER BY c.timestamp, c.id`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("error backfilling claim status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing claim status backfill: %w", err)
+	}
+	log.Println("Claim status backfilled from the reverted flag.")
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table and reports whether it was added.
+// SQLite has no ADD COLUMN IF NOT EXISTS, so the current columns are read from the table info first.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("error reading columns of table %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid          int
+			name         string
+			columnType   string
+			notNull      bool
+			defaultValue sql.NullString
+			primaryKey   int
+		)
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &primaryKey); err != nil {
+			return false, fmt.Errorf("error scanning columns of table %s: %w", table, err)
+		}
+		if name == column {
+			return false, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error reading columns of table %s: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return false, fmt.Errorf("error adding column %s to table %s: %w", column, table, err)
+	}
+	log.Printf("Column %s added to table %s.", column, table)
+	return true, nil
+}
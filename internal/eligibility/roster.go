@@ -0,0 +1,317 @@
+package eligibility
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/metrics"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/reloader"
+)
+
+// dateLayout is the layout of coverage dates and dates of service.
+const dateLayout = "2006-01-02"
+
+// rosterDateLayouts are the accepted coverage date formats: ISO and MM/DD/YYYY.
+var rosterDateLayouts = []string{dateLayout, "01/02/2006"}
+
+// rosterColumns maps the accepted header names of the roster columns, compared in lower case
+// with spaces as underscores, to their column.
+var rosterColumns = map[string]string{
+	"member_id":      "member_id",
+	"cardholder_id":  "member_id",
+	"plan":           "plan",
+	"plan_id":        "plan",
+	"coverage_start": "coverage_start",
+	"start_date":     "coverage_start",
+	"coverage_end":   "coverage_end",
+	"end_date":       "coverage_end",
+}
+
+// period is a coverage period of a member with its parsed dates. A zero end is open-ended.
+type period struct {
+	coverage   models.Coverage
+	start, end time.Time
+}
+
+// covers reports whether the period covers the day.
+func (p period) covers(day time.Time) bool {
+	return !day.Before(p.start) && (p.end.IsZero() || !day.After(p.end))
+}
+
+// Roster holds the coverage periods of a roster file by member ID.
+type Roster struct {
+	byMember map[string][]period // Sorted by start date
+}
+
+// LoadRoster reads an eligibility roster CSV file. The header row must name the member_id, plan,
+// coverage_start and coverage_end columns; other columns are ignored. A member may have several
+// rows, e.g. after a plan change, but their periods cannot overlap. An invalid row is an error.
+func LoadRoster(path string) (*Roster, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening eligibility roster %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of eligibility roster %s: %w", path, err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))), " ", "_")
+		if column, ok := rosterColumns[key]; ok {
+			index[column] = i
+		}
+	}
+	for _, column := range []string{"member_id", "plan", "coverage_start", "coverage_end"} {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("eligibility roster %s has no %s column", path, column)
+		}
+	}
+
+	roster := &Roster{byMember: make(map[string][]period)}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading line %d of eligibility roster %s: %w", line, path, err)
+		}
+		p, err := parsePeriod(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d of eligibility roster %s: %w", line, path, err)
+		}
+		roster.byMember[p.coverage.MemberID] = append(roster.byMember[p.coverage.MemberID], p)
+	}
+
+	for memberID, periods := range roster.byMember {
+		sort.SliceStable(periods, func(i, j int) bool {
+			return periods[i].start.Before(periods[j].start)
+		})
+		for i := 1; i < len(periods); i++ {
+			if previous := periods[i-1]; previous.end.IsZero() || !periods[i].start.After(previous.end) {
+				return nil, fmt.Errorf("eligibility roster %s has overlapping coverage periods for member %s", path, memberID)
+			}
+		}
+	}
+	return roster, nil
+}
+
+// parsePeriod reads the coverage period of a CSV record.
+func parsePeriod(record []string, index map[string]int) (period, error) {
+	field := func(column string) string {
+		if i := index[column]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	p := period{coverage: models.Coverage{MemberID: field("member_id"), Plan: field("plan")}}
+	if p.coverage.MemberID == "" {
+		return p, errors.New("missing member_id")
+	}
+	if p.coverage.Plan == "" {
+		return p, fmt.Errorf("missing plan of member %s", p.coverage.MemberID)
+	}
+	start, err := parseDate(field("coverage_start"))
+	if err != nil {
+		return p, fmt.Errorf("coverage start '%s' of member %s must be YYYY-MM-DD or MM/DD/YYYY", field("coverage_start"), p.coverage.MemberID)
+	}
+	p.start = start
+	p.coverage.Start = start.Format(dateLayout)
+	if field("coverage_end") != "" {
+		end, err := parseDate(field("coverage_end"))
+		if err != nil {
+			return p, fmt.Errorf("coverage end '%s' of member %s must be YYYY-MM-DD or MM/DD/YYYY", field("coverage_end"), p.coverage.MemberID)
+		}
+		if end.Before(start) {
+			return p, fmt.Errorf("coverage of member %s ends before it starts", p.coverage.MemberID)
+		}
+		p.end = end
+		p.coverage.End = end.Format(dateLayout)
+	}
+	return p, nil
+}
+
+// parseDate parses a coverage date in one of the accepted layouts.
+func parseDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range rosterDateLayouts {
+		var date time.Time
+		if date, err = time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// Check returns the eligibility of the member on the date: eligible with the coverage in effect,
+// or not eligible with reject code 52 for a member not on the roster and 65 for a member without
+// coverage on the date.
+func (r *Roster) Check(memberID string, date time.Time) models.Eligibility {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	eligibility := models.Eligibility{MemberID: memberID, DateOfService: day.Format(dateLayout)}
+
+	periods, ok := r.byMember[memberID]
+	if !ok {
+		eligibility.RejectCode = models.RejectNonMatchedCardholderID
+		eligibility.Message = fmt.Sprintf("member %s is not on the eligibility roster", memberID)
+		return eligibility
+	}
+	for _, p := range periods {
+		if p.covers(day) {
+			coverage := p.coverage
+			eligibility.Eligible = true
+			eligibility.Coverage = &coverage
+			return eligibility
+		}
+	}
+	eligibility.RejectCode = models.RejectPatientNotCovered
+	eligibility.Message = fmt.Sprintf("member %s is not covered on %s", memberID, eligibility.DateOfService)
+	return eligibility
+}
+
+// Len returns the number of members on the roster.
+func (r *Roster) Len() int {
+	return len(r.byMember)
+}
+
+// Diff returns the members added to current, removed from previous, and those whose coverage
+// periods differ between both rosters, each sorted by member ID.
+func Diff(previous, current *Roster) models.RosterChanges {
+	changes := models.RosterChanges{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	for memberID, periods := range current.byMember {
+		before, ok := previous.byMember[memberID]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, memberID)
+		case !slices.Equal(coverages(before), coverages(periods)):
+			changes.Changed = append(changes.Changed, memberID)
+		}
+	}
+	for memberID := range previous.byMember {
+		if _, ok := current.byMember[memberID]; !ok {
+			changes.Removed = append(changes.Removed, memberID)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	sort.Strings(changes.Changed)
+	return changes
+}
+
+// coverages returns the coverages of the periods, for comparisons.
+func coverages(periods []period) []models.Coverage {
+	result := make([]models.Coverage, len(periods))
+	for i, p := range periods {
+		result[i] = p.coverage
+	}
+	return result
+}
+
+// RosterBook serves the eligibility roster of a file, reloaded whenever the file changes.
+// It is safe for concurrent use.
+type RosterBook struct {
+	file   *reloader.File[*Roster]
+	logger logger.Logger
+
+	mu       sync.RWMutex
+	loadedAt time.Time
+	changes  *models.RosterChanges
+}
+
+// NewRosterBook loads the eligibility roster at path. An invalid file is an error.
+func NewRosterBook(path string, log logger.Logger) (*RosterBook, error) {
+	b := &RosterBook{logger: log}
+	b.file = reloader.New(path, "eligibility roster", LoadRoster, b.loaded, log)
+	if _, err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reload reads the roster again when it changed, see reloader.File.Reload, and returns the
+// changes to the members, or nil when the file did not change or on the first load.
+func (b *RosterBook) Reload() (*models.RosterChanges, error) {
+	reloaded, err := b.file.Reload()
+	if err != nil || !reloaded {
+		return nil, err
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.changes, nil
+}
+
+// loaded records the changes from the previous roster, nil on the first load, and updates the
+// roster metrics.
+func (b *RosterBook) loaded(previous, roster *Roster) {
+	var changes *models.RosterChanges
+	if previous != nil {
+		diff := Diff(previous, roster)
+		changes = &diff
+		metrics.EligibilityRosterChangesTotal.WithLabelValues("added").Add(float64(len(diff.Added)))
+		metrics.EligibilityRosterChangesTotal.WithLabelValues("removed").Add(float64(len(diff.Removed)))
+		metrics.EligibilityRosterChangesTotal.WithLabelValues("changed").Add(float64(len(diff.Changed)))
+	}
+	metrics.EligibilityRosterMembers.Set(float64(roster.Len()))
+
+	b.mu.Lock()
+	b.loadedAt = time.Now()
+	b.changes = changes
+	b.mu.Unlock()
+}
+
+// Run checks the roster for changes every interval until ctx is cancelled and logs the members
+// added, removed or changed by each reload.
+func (b *RosterBook) Run(ctx context.Context, interval time.Duration) {
+	b.file.Run(ctx, interval, func() {
+		b.mu.RLock()
+		changes := b.changes
+		b.mu.RUnlock()
+		if changes == nil {
+			return
+		}
+		b.logger.Info("Eligibility roster reloaded from %s: %d members, %d added, %d removed, %d changed.",
+			b.file.Path(), b.Len(), len(changes.Added), len(changes.Removed), len(changes.Changed))
+		if len(changes.Removed) > 0 {
+			b.logger.Warning("Members removed from the eligibility roster: %s", strings.Join(changes.Removed, ", "))
+		}
+	})
+}
+
+// Check returns the eligibility of the member on the date, see Roster.Check.
+func (b *RosterBook) Check(memberID string, date time.Time) models.Eligibility {
+	return b.file.Value().Check(memberID, date)
+}
+
+// Len returns the number of members on the roster in use.
+func (b *RosterBook) Len() int {
+	return b.file.Value().Len()
+}
+
+// Status returns the size of the roster in use, when it was loaded and the changes of its
+// latest reload.
+func (b *RosterBook) Status() models.RosterStatus {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return models.RosterStatus{
+		MemberCount: b.file.Value().Len(),
+		LoadedAt:    b.loadedAt.Format("2006-01-02T15:04:05"),
+		Changes:     b.changes,
+	}
+}
@@ -0,0 +1,110 @@
+package eligibility_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/eligibility"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+const testRosterCSV = `Member ID,Plan,Coverage Start,Coverage End,Group
+M1001,GOLD,2024-01-01,,G1
+M1002,SILVER,01/01/2024,06/30/2024,G1
+M1002,GOLD,2024-07-01,,G1
+`
+
+func writeRoster(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "roster.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing eligibility roster: %v", err)
+	}
+	return path
+}
+
+func day(value string) time.Time {
+	date, _ := time.Parse("2006-01-02", value)
+	return date
+}
+
+func TestRosterCheck(t *testing.T) {
+	roster, err := eligibility.LoadRoster(writeRoster(t, testRosterCSV))
+	assert.Nil(t, err, "Expected no error loading the roster")
+	assert.Equal(t, 2, roster.Len())
+
+	tests := []struct {
+		name       string
+		memberID   string
+		date       string
+		plan       string
+		rejectCode string
+	}{
+		{"open-ended coverage", "M1001", "2030-01-01", "GOLD", ""},
+		{"before coverage starts", "M1001", "2023-12-31", "", models.RejectPatientNotCovered},
+		{"last day of a period", "M1002", "2024-06-30", "SILVER", ""},
+		{"after a plan change", "M1002", "2024-07-01", "GOLD", ""},
+		{"unknown member", "M9999", "2024-07-01", "", models.RejectNonMatchedCardholderID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := roster.Check(tt.memberID, day(tt.date))
+			assert.Equal(t, tt.rejectCode == "", result.Eligible)
+			assert.Equal(t, tt.rejectCode, result.RejectCode)
+			assert.Equal(t, tt.date, result.DateOfService)
+			if tt.plan != "" {
+				assert.Equal(t, tt.plan, result.Coverage.Plan)
+			}
+		})
+	}
+}
+
+func TestLoadRosterInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing column":      "member_id,plan,coverage_start\nM1,GOLD,2024-01-01\n",
+		"missing member":      "member_id,plan,coverage_start,coverage_end\n,GOLD,2024-01-01,\n",
+		"missing plan":        "member_id,plan,coverage_start,coverage_end\nM1,,2024-01-01,\n",
+		"invalid start":       "member_id,plan,coverage_start,coverage_end\nM1,GOLD,2024/01/01,\n",
+		"end before start":    "member_id,plan,coverage_start,coverage_end\nM1,GOLD,2024-02-01,2024-01-31\n",
+		"overlapping periods": "member_id,plan,coverage_start,coverage_end\nM1,GOLD,2024-01-01,2024-06-30\nM1,SILVER,2024-06-30,\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := eligibility.LoadRoster(writeRoster(t, content))
+			assert.NotNil(t, err, "Expected an error for %s", name)
+		})
+	}
+}
+
+func TestRosterBookReloadReportsChanges(t *testing.T) {
+	path := writeRoster(t, testRosterCSV)
+	book, err := eligibility.NewRosterBook(path, logger.NewLogger())
+	assert.Nil(t, err, "Expected no error creating the roster book")
+	assert.Nil(t, book.Status().Changes, "The first load should report no changes")
+
+	changes, err := book.Reload()
+	assert.Nil(t, err)
+	assert.Nil(t, changes, "An unchanged file should not be reloaded")
+
+	// Invalid rosters are refused and the previous one kept
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(path, []byte("member_id,plan\nM1001,GOLD\n"), 0644)
+	os.Chtimes(path, later, later)
+	_, err = book.Reload()
+	assert.NotNil(t, err, "Expected an error reloading an invalid roster")
+	assert.True(t, book.Check("M1002", day("2024-07-01")).Eligible)
+
+	later = later.Add(time.Minute)
+	os.WriteFile(path, []byte("member_id,plan,coverage_start,coverage_end\nM1001,GOLD,2024-01-01,2024-12-31\nM1003,BRONZE,2024-01-01,\n"), 0644)
+	os.Chtimes(path, later, later)
+	changes, err = book.Reload()
+	assert.Nil(t, err)
+	assert.Equal(t, &models.RosterChanges{Added: []string{"M1003"}, Removed: []string{"M1002"}, Changed: []string{"M1001"}}, changes)
+	assert.Equal(t, changes, book.Status().Changes)
+	assert.Equal(t, 2, book.Status().MemberCount)
+	assert.Equal(t, models.RejectNonMatchedCardholderID, book.Check("M1002", day("2024-07-01")).RejectCode)
+}
@@ -0,0 +1,219 @@
+package fhir
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Paging limits of search results.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ErrInvalidSearchParameter is returned when a search parameter cannot be interpreted.
+var ErrInvalidSearchParameter = errors.New("invalid search parameter")
+
+// timestampLayout is the layout of the stored claim timestamps.
+const timestampLayout = "2006-01-02T15:04:05"
+
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+type BundleSearch struct {
+	Mode string `json:"mode"`
+}
+
+type BundleEntry struct {
+	FullURL  string        `json:"fullUrl,omitempty"`
+	Resource interface{}   `json:"resource"`
+	Search   *BundleSearch `json:"search,omitempty"`
+}
+
+// Bundle is a FHIR R4 searchset Bundle.
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Total        int           `json:"total"`
+	Link         []BundleLink  `json:"link,omitempty"`
+	Entry        []BundleEntry `json:"entry,omitempty"`
+}
+
+// Search is a parsed claim search. Offset and Count page the results.
+type Search struct {
+	Filter models.ClaimFilter
+	Count  int
+	Offset int
+}
+
+// ParseSearch parses the search parameters of a Claim or ClaimResponse search.
+// The provider parameter (requestor for ClaimResponse) matches the pharmacy NPI, product the NDC
+// and created the claim timestamp; created accepts the eq, ge, gt, le and lt prefixes and can be
+// repeated to build a range.
+func ParseSearch(query url.Values, providerParam string) (*Search, error) {
+	search := &Search{Count: DefaultPageSize}
+
+	if value := query.Get(providerParam); value != "" {
+		npi, err := tokenValue(providerParam, value, SystemNPI)
+		if err != nil {
+			return nil, err
+		}
+		search.Filter.NPI = npi
+	}
+	if value := query.Get("product"); value != "" {
+		ndc, err := tokenValue("product", value, SystemNDC)
+		if err != nil {
+			return nil, err
+		}
+		search.Filter.NDC = ndc
+	}
+
+	var from, to time.Time
+	for _, value := range query["created"] {
+		lower, upper, err := dateRange(value)
+		if err != nil {
+			return nil, err
+		}
+		if lower.After(from) {
+			from = lower
+		}
+		if !upper.IsZero() && (to.IsZero() || upper.Before(to)) {
+			to = upper
+		}
+	}
+	if !from.IsZero() {
+		search.Filter.From = from.Format(timestampLayout)
+	}
+	if !to.IsZero() {
+		search.Filter.To = to.Format(timestampLayout)
+	}
+
+	if value := query.Get("_count"); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("%w: _count '%s'", ErrInvalidSearchParameter, value)
+		}
+		search.Count = min(count, MaxPageSize)
+	}
+	if value := query.Get("_offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("%w: _offset '%s'", ErrInvalidSearchParameter, value)
+		}
+		search.Offset = offset
+	}
+
+	search.Filter.Limit = search.Count
+	search.Filter.Offset = search.Offset
+	return search, nil
+}
+
+// tokenValue returns the code of a token parameter ("code" or "system|code"), rejecting
+// systems other than the expected one.
+func tokenValue(name, value, system string) (string, error) {
+	if i := strings.Index(value, "|"); i >= 0 {
+		if value[:i] != "" && value[:i] != system {
+			return "", fmt.Errorf("%w: %s system must be %s", ErrInvalidSearchParameter, name, system)
+		}
+		value = value[i+1:]
+	}
+	if value == "" {
+		return "", fmt.Errorf("%w: %s code is required", ErrInvalidSearchParameter, name)
+	}
+	return value, nil
+}
+
+// dateRange returns the [lower, upper) timestamp range matched by a created parameter.
+// Without a zone, dates are interpreted in UTC like the stored timestamps.
+func dateRange(value string) (time.Time, time.Time, error) {
+	prefix := "eq"
+	if len(value) > 2 && value[0] >= 'a' && value[0] <= 'z' {
+		prefix, value = value[:2], value[2:]
+	}
+
+	start, end, err := datePeriod(value)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: created '%s'", ErrInvalidSearchParameter, value)
+	}
+
+	switch prefix {
+	case "eq":
+		return start, end, nil
+	case "ge":
+		return start, time.Time{}, nil
+	case "gt":
+		return end, time.Time{}, nil
+	case "le":
+		return time.Time{}, end, nil
+	case "lt":
+		return time.Time{}, start, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: unsupported created prefix '%s'", ErrInvalidSearchParameter, prefix)
+	}
+}
+
+// datePeriod returns the period covered by a date at its precision, e.g. a whole day for "2024-02-01".
+func datePeriod(value string) (time.Time, time.Time, error) {
+	precisions := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{time.RFC3339, func(t time.Time) time.Time { return t.Add(time.Second) }},
+		{timestampLayout, func(t time.Time) time.Time { return t.Add(time.Second) }},
+	}
+	for _, precision := range precisions {
+		if t, err := time.Parse(precision.layout, value); err == nil {
+			t = t.UTC()
+			return t, precision.next(t), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unparseable date '%s'", value)
+}
+
+// NewSearchBundle builds a searchset Bundle for a page of results. baseURL is the absolute URL of
+// the searched resource type; query holds the search parameters used to build the paging links.
+func NewSearchBundle(baseURL string, query url.Values, search *Search, total int, resources []interface{}, ids []string) *Bundle {
+	bundle := &Bundle{
+		ResourceType: "Bundle",
+		Type:         "searchset",
+		Total:        total,
+	}
+
+	bundle.Link = append(bundle.Link, BundleLink{Relation: "self", URL: pageURL(baseURL, query, search.Count, search.Offset)})
+	if search.Offset+search.Count < total {
+		bundle.Link = append(bundle.Link, BundleLink{Relation: "next", URL: pageURL(baseURL, query, search.Count, search.Offset+search.Count)})
+	}
+	if search.Offset > 0 {
+		bundle.Link = append(bundle.Link, BundleLink{Relation: "previous", URL: pageURL(baseURL, query, search.Count, max(search.Offset-search.Count, 0))})
+	}
+
+	for i, resource := range resources {
+		bundle.Entry = append(bundle.Entry, BundleEntry{
+			FullURL:  baseURL + "/" + ids[i],
+			Resource: resource,
+			Search:   &BundleSearch{Mode: "match"},
+		})
+	}
+	return bundle
+}
+
+func pageURL(baseURL string, query url.Values, count, offset int) string {
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	params.Set("_count", strconv.Itoa(count))
+	params.Set("_offset", strconv.Itoa(offset))
+	return baseURL + "?" + params.Encode()
+}
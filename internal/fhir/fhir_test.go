@@ -0,0 +1,196 @@
+package fhir_test
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/fhir"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+func testClaim(reverted bool) models.Claim {
+	status := models.ClaimStatusPaid
+	if reverted {
+		status = models.ClaimStatusReversed
+	}
+	return models.Claim{
+		ID:        "claim-1",
+		NDC:       "00002323401",
+		Quantity:  30,
+		NPI:       "1234567890",
+		Price:     100.5,
+		Timestamp: "2024-02-01T10:00:00",
+		Status:    status,
+		Reverted:  reverted,
+
+		AllowedAmount: 100.5,
+		PricingBasis:  models.PricingBasisSubmitted,
+
+		OutstandingQuantity: 30,
+		OutstandingAmount:   100.5,
+	}
+}
+
+func TestNewClaim(t *testing.T) {
+	claim := fhir.NewClaim(testClaim(false))
+
+	assert.Equal(t, "Claim", claim.ResourceType)
+	assert.Equal(t, fhir.StatusActive, claim.Status)
+	assert.Equal(t, "2024-02-01T10:00:00Z", claim.Created)
+	assert.Equal(t, fhir.SystemNPI, claim.Provider.Identifier.System)
+	assert.Equal(t, "1234567890", claim.Provider.Identifier.Value)
+	require.Len(t, claim.Item, 1)
+	assert.Equal(t, fhir.Coding{System: fhir.SystemNDC, Code: "00002323401"}, claim.Item[0].ProductOrService.Coding[0])
+	assert.Equal(t, 30.0, claim.Item[0].Quantity.Value)
+	assert.Equal(t, 100.5, claim.Total.Value)
+
+	assert.Equal(t, fhir.StatusCancelled, fhir.NewClaim(testClaim(true)).Status)
+}
+
+func TestNewClaimWithPrescription(t *testing.T) {
+	source := testClaim(false)
+	source.MemberID = "M1001"
+	source.DateOfService = "2024-01-31"
+	claim := fhir.NewClaim(source)
+
+	assert.Equal(t, &fhir.Identifier{System: fhir.SystemMemberID, Value: "M1001"}, claim.Patient.Identifier)
+	assert.Equal(t, "2024-01-31", claim.Item[0].ServicedDate)
+
+	data, err := json.Marshal(claim)
+	require.NoError(t, err)
+	assert.False(t, fhir.ValidateClaim(data).HasErrors(), "Expected a claim with a member ID to be valid")
+}
+
+func TestNewClaimIsValid(t *testing.T) {
+	data, err := json.Marshal(fhir.NewClaim(testClaim(true)))
+	require.NoError(t, err)
+
+	outcome := fhir.ValidateClaim(data)
+	assert.False(t, outcome.HasErrors(), "%+v", outcome.Issue)
+}
+
+func TestNewClaimResponse(t *testing.T) {
+	paid := fhir.NewClaimResponse(testClaim(false), "PHARMACY CLAIM SERVICE")
+	assert.Equal(t, fhir.StatusActive, paid.Status)
+	assert.Equal(t, "Claim/claim-1", paid.Request.Reference)
+	assert.Equal(t, "complete", paid.Outcome)
+	assert.Equal(t, 100.5, paid.Total[0].Amount.Value)
+
+	reversed := fhir.NewClaimResponse(testClaim(true), "PHARMACY CLAIM SERVICE")
+	assert.Equal(t, fhir.StatusCancelled, reversed.Status)
+	assert.Equal(t, "Claim reversed", reversed.Disposition)
+	assert.Equal(t, 0.0, reversed.Total[0].Amount.Value)
+
+	claim := testClaim(false)
+	claim.OutstandingQuantity, claim.OutstandingAmount = 20, 67
+	partial := fhir.NewClaimResponse(claim, "PHARMACY CLAIM SERVICE")
+	assert.Equal(t, fhir.StatusActive, partial.Status)
+	assert.Equal(t, "Claim partially reversed", partial.Disposition)
+	assert.Equal(t, 67.0, partial.Total[0].Amount.Value)
+
+	claim = testClaim(false)
+	claim.AllowedAmount, claim.PricingBasis = 80.4, models.PricingBasisContract
+	contract := fhir.NewClaimResponse(claim, "PHARMACY CLAIM SERVICE")
+	assert.Equal(t, 80.4, contract.Total[0].Amount.Value, "Expected the allowed amount as benefit")
+	assert.Equal(t, 100.5, contract.Total[1].Amount.Value, "Expected the submitted price")
+}
+
+func TestValidateClaimInvalid(t *testing.T) {
+	outcome := fhir.ValidateClaim([]byte(`{
+		"resourceType": "Claim",
+		"status": "paid",
+		"use": "claim",
+		"created": "2024-13-01",
+		"type": {}, "patient": {}, "provider": {}, "priority": {},
+		"insurance": [{"sequence": 0, "coverage": {}}],
+		"item": [{"sequence": 1}],
+		"drug": "x"
+	}`))
+
+	require.True(t, outcome.HasErrors())
+	var expressions []string
+	for _, issue := range outcome.Issue {
+		expressions = append(expressions, issue.Expression...)
+	}
+	assert.ElementsMatch(t, []string{
+		"Claim.drug",
+		"Claim.status",
+		"Claim.created",
+		"Claim.insurance[0].sequence",
+		"Claim.insurance[0].focal",
+		"Claim.item[0].productOrService",
+	}, expressions)
+}
+
+func TestValidateClaimNotJSON(t *testing.T) {
+	outcome := fhir.ValidateClaim([]byte(`[1, 2]`))
+	assert.True(t, outcome.HasErrors())
+	assert.Equal(t, fhir.IssueCodeStructure, outcome.Issue[0].Code)
+}
+
+func TestParseSearch(t *testing.T) {
+	query := url.Values{
+		"provider": {fhir.SystemNPI + "|1234567890"},
+		"product":  {"00002323401"},
+		"created":  {"ge2024-02-01", "lt2024-03"},
+		"_count":   {"500"},
+		"_offset":  {"40"},
+	}
+
+	search, err := fhir.ParseSearch(query, "provider")
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimFilter{
+		NPI:    "1234567890",
+		NDC:    "00002323401",
+		From:   "2024-02-01T00:00:00",
+		To:     "2024-03-01T00:00:00",
+		Limit:  fhir.MaxPageSize,
+		Offset: 40,
+	}, search.Filter)
+}
+
+func TestParseSearchCreatedEquals(t *testing.T) {
+	search, err := fhir.ParseSearch(url.Values{"created": {"2024-02-01"}}, "provider")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-01T00:00:00", search.Filter.From)
+	assert.Equal(t, "2024-02-02T00:00:00", search.Filter.To)
+	assert.Equal(t, fhir.DefaultPageSize, search.Count)
+}
+
+func TestParseSearchInvalid(t *testing.T) {
+	for _, query := range []url.Values{
+		{"provider": {"urn:other|1234567890"}},
+		{"created": {"yesterday"}},
+		{"created": {"sa2024-02-01"}},
+		{"_count": {"0"}},
+		{"_offset": {"-1"}},
+	} {
+		_, err := fhir.ParseSearch(query, "provider")
+		assert.ErrorIs(t, err, fhir.ErrInvalidSearchParameter, "%v", query)
+	}
+}
+
+func TestNewSearchBundleLinks(t *testing.T) {
+	query := url.Values{"provider": {"1234567890"}}
+	search, err := fhir.ParseSearch(url.Values{"_count": {"10"}, "_offset": {"10"}}, "provider")
+	require.NoError(t, err)
+
+	claim := fhir.NewClaim(testClaim(false))
+	bundle := fhir.NewSearchBundle("http://localhost/fhir/Claim", query, search, 25, []interface{}{claim}, []string{claim.ID})
+
+	assert.Equal(t, "searchset", bundle.Type)
+	assert.Equal(t, 25, bundle.Total)
+	links := map[string]string{}
+	for _, link := range bundle.Link {
+		links[link.Relation] = link.URL
+	}
+	assert.Equal(t, "http://localhost/fhir/Claim?_count=10&_offset=10&provider=1234567890", links["self"])
+	assert.Equal(t, "http://localhost/fhir/Claim?_count=10&_offset=20&provider=1234567890", links["next"])
+	assert.Equal(t, "http://localhost/fhir/Claim?_count=10&_offset=0&provider=1234567890", links["previous"])
+	require.Len(t, bundle.Entry, 1)
+	assert.Equal(t, "http://localhost/fhir/Claim/claim-1", bundle.Entry[0].FullURL)
+}
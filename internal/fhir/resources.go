@@ -0,0 +1,253 @@
+package fhir
+
+import (
+	"math"
+	"strings"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Code systems used by the mapped resources.
+const (
+	SystemNPI             = "http://hl7.org/fhir/sid/us-npi"
+	SystemNDC             = "http://hl7.org/fhir/sid/ndc"
+	SystemClaimType       = "http://terminology.hl7.org/CodeSystem/claim-type"
+	SystemProcessPriority = "http://terminology.hl7.org/CodeSystem/processpriority"
+	SystemAdjudication    = "http://terminology.hl7.org/CodeSystem/adjudication"
+	SystemRelatedClaim    = "http://terminology.hl7.org/CodeSystem/ex-relatedclaimrelationship"
+	SystemClaimID         = "urn:pharmacy-claims:claim-id"
+	SystemMemberID        = "urn:pharmacy-claims:member-id"
+	SystemRejectCode      = "urn:pharmacy-claims:reject-code"
+)
+
+// Financial resource statuses.
+const (
+	StatusActive    = "active"
+	StatusCancelled = "cancelled"
+)
+
+const currencyUSD = "USD"
+
+type Coding struct {
+	System  string `json:"system,omitempty"`
+	Code    string `json:"code,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+type CodeableConcept struct {
+	Coding []Coding `json:"coding,omitempty"`
+	Text   string   `json:"text,omitempty"`
+}
+
+type Identifier struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type Reference struct {
+	Reference  string      `json:"reference,omitempty"`
+	Identifier *Identifier `json:"identifier,omitempty"`
+	Display    string      `json:"display,omitempty"`
+}
+
+type Money struct {
+	Value    float64 `json:"value"`
+	Currency string  `json:"currency,omitempty"`
+}
+
+type Quantity struct {
+	Value float64 `json:"value"`
+}
+
+type ClaimInsurance struct {
+	Sequence int       `json:"sequence"`
+	Focal    bool      `json:"focal"`
+	Coverage Reference `json:"coverage"`
+}
+
+type ClaimRelated struct {
+	Claim        *Reference       `json:"claim,omitempty"`
+	Relationship *CodeableConcept `json:"relationship,omitempty"`
+}
+
+type ClaimItem struct {
+	Sequence         int             `json:"sequence"`
+	ProductOrService CodeableConcept `json:"productOrService"`
+	ServicedDate     string          `json:"servicedDate,omitempty"`
+	Quantity         *Quantity       `json:"quantity,omitempty"`
+	Net              *Money          `json:"net,omitempty"`
+}
+
+// Claim is the subset of the FHIR R4 Claim resource produced by this service.
+type Claim struct {
+	ResourceType string           `json:"resourceType"`
+	ID           string           `json:"id"`
+	Identifier   []Identifier     `json:"identifier,omitempty"`
+	Status       string           `json:"status"`
+	Type         CodeableConcept  `json:"type"`
+	Use          string           `json:"use"`
+	Patient      Reference        `json:"patient"`
+	Created      string           `json:"created"`
+	Provider     Reference        `json:"provider"`
+	Priority     CodeableConcept  `json:"priority"`
+	Related      []ClaimRelated   `json:"related,omitempty"`
+	Insurance    []ClaimInsurance `json:"insurance"`
+	Item         []ClaimItem      `json:"item,omitempty"`
+	Total        *Money           `json:"total,omitempty"`
+}
+
+type ClaimResponseTotal struct {
+	Category CodeableConcept `json:"category"`
+	Amount   Money           `json:"amount"`
+}
+
+// ClaimResponseError is a reject code given at adjudication.
+type ClaimResponseError struct {
+	Code CodeableConcept `json:"code"`
+}
+
+// ClaimResponse is the subset of the FHIR R4 ClaimResponse resource produced by this service.
+type ClaimResponse struct {
+	ResourceType string               `json:"resourceType"`
+	ID           string               `json:"id"`
+	Status       string               `json:"status"`
+	Type         CodeableConcept      `json:"type"`
+	Use          string               `json:"use"`
+	Patient      Reference            `json:"patient"`
+	Created      string               `json:"created"`
+	Insurer      Reference            `json:"insurer"`
+	Requestor    *Reference           `json:"requestor,omitempty"`
+	Request      *Reference           `json:"request,omitempty"`
+	Outcome      string               `json:"outcome"`
+	Disposition  string               `json:"disposition,omitempty"`
+	Total        []ClaimResponseTotal `json:"total,omitempty"`
+	Error        []ClaimResponseError `json:"error,omitempty"`
+}
+
+// claimStatus maps the claim lifecycle status to the financial resource status.
+// Reversed and rebilled claims are no longer in force.
+func claimStatus(claim models.Claim) string {
+	switch claim.Status {
+	case models.ClaimStatusReversed, models.ClaimStatusRebilled:
+		return StatusCancelled
+	default:
+		return StatusActive
+	}
+}
+
+// dateTime converts a claim timestamp to a FHIR dateTime. Stored timestamps have no zone
+// and are written in UTC, which FHIR requires to be explicit when a time is present.
+func dateTime(timestamp string) string {
+	if len(timestamp) > len("2006-01-02") && !strings.HasSuffix(timestamp, "Z") && !strings.ContainsAny(timestamp[10:], "+-") {
+		return timestamp + "Z"
+	}
+	return timestamp
+}
+
+func pharmacyClaimType() CodeableConcept {
+	return CodeableConcept{Coding: []Coding{{System: SystemClaimType, Code: "pharmacy"}}}
+}
+
+func providerReference(npi string) Reference {
+	return Reference{Identifier: &Identifier{System: SystemNPI, Value: npi}}
+}
+
+// patientReference identifies the patient by the member ID of the claim, when submitted.
+func patientReference(memberID string) Reference {
+	if memberID == "" {
+		return Reference{Display: "Unknown patient"}
+	}
+	return Reference{Identifier: &Identifier{System: SystemMemberID, Value: memberID}}
+}
+
+// NewClaim maps a claim to a FHIR R4 Claim with the pharmacy as provider and the NDC as
+// productOrService coding. A replacement claim is related to the claim it rebills as prior.
+func NewClaim(claim models.Claim) *Claim {
+	var related []ClaimRelated
+	if claim.ReplacesClaimID != "" {
+		related = []ClaimRelated{{
+			Claim:        &Reference{Reference: "Claim/" + claim.ReplacesClaimID},
+			Relationship: &CodeableConcept{Coding: []Coding{{System: SystemRelatedClaim, Code: "prior"}}},
+		}}
+	}
+
+	return &Claim{
+		ResourceType: "Claim",
+		ID:           claim.ID,
+		Identifier:   []Identifier{{System: SystemClaimID, Value: claim.ID}},
+		Status:       claimStatus(claim),
+		Type:         pharmacyClaimType(),
+		Use:          "claim",
+		Patient:      patientReference(claim.MemberID),
+		Created:      dateTime(claim.Timestamp),
+		Provider:     providerReference(claim.NPI),
+		Priority:     CodeableConcept{Coding: []Coding{{System: SystemProcessPriority, Code: "normal"}}},
+		Related:      related,
+		Insurance:    []ClaimInsurance{{Sequence: 1, Focal: true, Coverage: Reference{Display: "Pharmacy benefit"}}},
+		Item: []ClaimItem{{
+			Sequence:         1,
+			ProductOrService: CodeableConcept{Coding: []Coding{{System: SystemNDC, Code: claim.NDC}}},
+			ServicedDate:     claim.DateOfService,
+			Quantity:         &Quantity{Value: claim.Quantity},
+			Net:              &Money{Value: claim.Price, Currency: currencyUSD},
+		}},
+		Total: &Money{Value: claim.Price, Currency: currencyUSD},
+	}
+}
+
+// NewClaimResponse maps the adjudication of a claim to a FHIR R4 ClaimResponse.
+// Only paid claims report a benefit, the allowed amount net of partial reversals, next to the
+// submitted price; pending claims are queued and
+// rejected ones in error. The reject codes of pending and rejected claims are listed as errors.
+func NewClaimResponse(claim models.Claim, insurer string) *ClaimResponse {
+	outcome, disposition, benefit := "complete", "Claim paid", claim.AllowedAmount
+	if claim.Price > 0 && claim.OutstandingAmount < claim.Price {
+		benefit = math.Round(claim.AllowedAmount*claim.OutstandingAmount/claim.Price*100) / 100
+	}
+	switch claim.Status {
+	case models.ClaimStatusPaid:
+		if claim.OutstandingAmount < claim.Price-models.ReversalTolerance {
+			disposition = "Claim partially reversed"
+		}
+	case models.ClaimStatusPending:
+		outcome, disposition, benefit = "queued", "Claim pending adjudication", 0
+	case models.ClaimStatusRejected:
+		outcome, disposition, benefit = "error", "Claim rejected", 0
+	case models.ClaimStatusReversed:
+		disposition, benefit = "Claim reversed", 0
+	case models.ClaimStatusRebilled:
+		disposition, benefit = "Claim rebilled", 0
+	}
+
+	var rejects []ClaimResponseError
+	for _, reject := range claim.Rejects {
+		rejects = append(rejects, ClaimResponseError{Code: CodeableConcept{
+			Coding: []Coding{{System: SystemRejectCode, Code: reject.Code, Display: reject.Message}},
+			Text:   reject.Rule,
+		}})
+	}
+
+	requestor := providerReference(claim.NPI)
+	return &ClaimResponse{
+		ResourceType: "ClaimResponse",
+		ID:           claim.ID,
+		Status:       claimStatus(claim),
+		Type:         pharmacyClaimType(),
+		Use:          "claim",
+		Patient:      patientReference(claim.MemberID),
+		Created:      dateTime(claim.Timestamp),
+		Insurer:      Reference{Display: insurer},
+		Requestor:    &requestor,
+		Request:      &Reference{Reference: "Claim/" + claim.ID},
+		Outcome:      outcome,
+		Disposition:  disposition,
+		Total: []ClaimResponseTotal{{
+			Category: CodeableConcept{Coding: []Coding{{System: SystemAdjudication, Code: "benefit"}}},
+			Amount:   Money{Value: benefit, Currency: currencyUSD},
+		}, {
+			Category: CodeableConcept{Coding: []Coding{{System: SystemAdjudication, Code: "submitted"}}},
+			Amount:   Money{Value: claim.Price, Currency: currencyUSD},
+		}},
+		Error: rejects,
+	}
+}
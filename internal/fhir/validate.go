@@ -0,0 +1,240 @@
+package fhir
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Issue severities and codes of an OperationOutcome.
+const (
+	SeverityError       = "error"
+	SeverityInformation = "information"
+
+	IssueCodeStructure     = "structure"
+	IssueCodeRequired      = "required"
+	IssueCodeValue         = "value"
+	IssueCodeInvalid       = "invalid"
+	IssueCodeNotFound      = "not-found"
+	IssueCodeException     = "exception"
+	IssueCodeInformational = "informational"
+	IssueCodeTooLong       = "too-long"
+)
+
+// MaxResourceSize bounds the size of a resource accepted for validation.
+const MaxResourceSize = 1 << 20
+
+type OperationOutcomeIssue struct {
+	Severity    string   `json:"severity"`
+	Code        string   `json:"code"`
+	Diagnostics string   `json:"diagnostics,omitempty"`
+	Expression  []string `json:"expression,omitempty"`
+}
+
+// OperationOutcome reports errors and validation results.
+type OperationOutcome struct {
+	ResourceType string                  `json:"resourceType"`
+	Issue        []OperationOutcomeIssue `json:"issue"`
+}
+
+// NewOperationOutcome returns an OperationOutcome with a single issue.
+func NewOperationOutcome(severity, code, diagnostics string) *OperationOutcome {
+	return &OperationOutcome{
+		ResourceType: "OperationOutcome",
+		Issue:        []OperationOutcomeIssue{{Severity: severity, Code: code, Diagnostics: diagnostics}},
+	}
+}
+
+// HasErrors reports whether any issue has error severity.
+func (o *OperationOutcome) HasErrors() bool {
+	for _, issue := range o.Issue {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// claimElements lists the elements of the R4 Claim resource.
+var claimElements = map[string]bool{
+	"resourceType": true, "id": true, "meta": true, "implicitRules": true, "language": true,
+	"text": true, "contained": true, "extension": true, "modifierExtension": true,
+	"identifier": true, "status": true, "type": true, "subType": true, "use": true,
+	"patient": true, "billablePeriod": true, "created": true, "enterer": true, "insurer": true,
+	"provider": true, "priority": true, "fundsReserve": true, "related": true,
+	"prescription": true, "originalPrescription": true, "payee": true, "referral": true,
+	"facility": true, "careTeam": true, "supportingInfo": true, "diagnosis": true,
+	"procedure": true, "insurance": true, "accident": true, "item": true, "total": true,
+}
+
+var (
+	claimStatuses = map[string]bool{"active": true, "cancelled": true, "draft": true, "entered-in-error": true}
+	claimUses     = map[string]bool{"claim": true, "preauthorization": true, "predetermination": true}
+
+	fhirIDPattern       = regexp.MustCompile(`^[A-Za-z0-9\-.]{1,64}$`)
+	fhirDateTimePattern = regexp.MustCompile(`^[0-9]{4}(-(0[1-9]|1[0-2])(-(0[1-9]|[12][0-9]|3[01])(T([01][0-9]|2[0-3]):[0-5][0-9]:([0-5][0-9]|60)(\.[0-9]+)?(Z|(\+|-)((0[0-9]|1[0-3]):[0-5][0-9]|14:00)))?)?)?$`)
+)
+
+// ValidateClaim checks a JSON document against the structure of the R4 Claim resource:
+// unknown elements, required elements and their cardinality, primitive types and the
+// required status and use value sets. The result always contains at least one issue.
+func ValidateClaim(data []byte) *OperationOutcome {
+	outcome := &OperationOutcome{ResourceType: "OperationOutcome"}
+	addIssue := func(code, expression, format string, args ...interface{}) {
+		outcome.Issue = append(outcome.Issue, OperationOutcomeIssue{
+			Severity:    SeverityError,
+			Code:        code,
+			Diagnostics: fmt.Sprintf(format, args...),
+			Expression:  []string{expression},
+		})
+	}
+
+	var resource map[string]interface{}
+	if err := json.Unmarshal(data, &resource); err != nil {
+		outcome.Issue = append(outcome.Issue, OperationOutcomeIssue{
+			Severity:    SeverityError,
+			Code:        IssueCodeStructure,
+			Diagnostics: fmt.Sprintf("invalid JSON object: %v", err),
+		})
+		return outcome
+	}
+
+	if resourceType, _ := resource["resourceType"].(string); resourceType != "Claim" {
+		addIssue(IssueCodeInvalid, "resourceType", "resourceType must be 'Claim'")
+	}
+
+	unknown := make([]string, 0)
+	for name := range resource {
+		if !claimElements[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		addIssue(IssueCodeStructure, "Claim."+name, "unknown element '%s'", name)
+	}
+
+	if id, ok := resource["id"]; ok {
+		if value, isString := id.(string); !isString || !fhirIDPattern.MatchString(value) {
+			addIssue(IssueCodeValue, "Claim.id", "id must be 1-64 letters, digits, '-' or '.'")
+		}
+	}
+
+	checkCode := func(name string, valueSet map[string]bool) {
+		value, ok := resource[name]
+		if !ok {
+			addIssue(IssueCodeRequired, "Claim."+name, "%s is required", name)
+			return
+		}
+		if code, isString := value.(string); !isString || !valueSet[code] {
+			addIssue(IssueCodeValue, "Claim."+name, "%s has an invalid code", name)
+		}
+	}
+	checkCode("status", claimStatuses)
+	checkCode("use", claimUses)
+
+	if created, ok := resource["created"]; !ok {
+		addIssue(IssueCodeRequired, "Claim.created", "created is required")
+	} else if value, isString := created.(string); !isString || !fhirDateTimePattern.MatchString(value) {
+		addIssue(IssueCodeValue, "Claim.created", "created must be a FHIR dateTime")
+	}
+
+	for _, name := range []string{"type", "patient", "provider", "priority"} {
+		checkObject(resource, name, "Claim."+name, true, addIssue)
+	}
+	for _, name := range []string{"subType", "enterer", "insurer", "fundsReserve", "prescription",
+		"originalPrescription", "payee", "referral", "facility", "accident", "total", "billablePeriod"} {
+		checkObject(resource, name, "Claim."+name, false, addIssue)
+	}
+
+	insurance, ok := checkArray(resource, "insurance", "Claim.insurance", true, addIssue)
+	if ok && len(insurance) == 0 {
+		addIssue(IssueCodeRequired, "Claim.insurance", "insurance requires at least one entry")
+	}
+	for i, entry := range insurance {
+		path := fmt.Sprintf("Claim.insurance[%d]", i)
+		element, isObject := entry.(map[string]interface{})
+		if !isObject {
+			addIssue(IssueCodeStructure, path, "insurance entries must be objects")
+			continue
+		}
+		checkPositiveInt(element, "sequence", path+".sequence", addIssue)
+		if focal, ok := element["focal"]; !ok {
+			addIssue(IssueCodeRequired, path+".focal", "focal is required")
+		} else if _, isBool := focal.(bool); !isBool {
+			addIssue(IssueCodeValue, path+".focal", "focal must be a boolean")
+		}
+		checkObject(element, "coverage", path+".coverage", true, addIssue)
+	}
+
+	items, _ := checkArray(resource, "item", "Claim.item", false, addIssue)
+	for i, entry := range items {
+		path := fmt.Sprintf("Claim.item[%d]", i)
+		element, isObject := entry.(map[string]interface{})
+		if !isObject {
+			addIssue(IssueCodeStructure, path, "item entries must be objects")
+			continue
+		}
+		checkPositiveInt(element, "sequence", path+".sequence", addIssue)
+		checkObject(element, "productOrService", path+".productOrService", true, addIssue)
+		for _, name := range []string{"quantity", "unitPrice", "net"} {
+			checkObject(element, name, path+"."+name, false, addIssue)
+		}
+	}
+
+	for _, name := range []string{"identifier", "related", "careTeam", "supportingInfo", "diagnosis", "procedure"} {
+		checkArray(resource, name, "Claim."+name, false, addIssue)
+	}
+
+	if len(outcome.Issue) == 0 {
+		outcome.Issue = append(outcome.Issue, OperationOutcomeIssue{
+			Severity:    SeverityInformation,
+			Code:        IssueCodeInformational,
+			Diagnostics: "Claim is valid",
+		})
+	}
+	return outcome
+}
+
+type issueFunc func(code, expression, format string, args ...interface{})
+
+func checkObject(resource map[string]interface{}, name, path string, required bool, addIssue issueFunc) {
+	value, ok := resource[name]
+	if !ok {
+		if required {
+			addIssue(IssueCodeRequired, path, "%s is required", name)
+		}
+		return
+	}
+	if _, isObject := value.(map[string]interface{}); !isObject {
+		addIssue(IssueCodeStructure, path, "%s must be an object", name)
+	}
+}
+
+func checkArray(resource map[string]interface{}, name, path string, required bool, addIssue issueFunc) ([]interface{}, bool) {
+	value, ok := resource[name]
+	if !ok {
+		if required {
+			addIssue(IssueCodeRequired, path, "%s is required", name)
+		}
+		return nil, false
+	}
+	array, isArray := value.([]interface{})
+	if !isArray {
+		addIssue(IssueCodeStructure, path, "%s must be an array", name)
+		return nil, false
+	}
+	return array, true
+}
+
+func checkPositiveInt(resource map[string]interface{}, name, path string, addIssue issueFunc) {
+	value, ok := resource[name]
+	if !ok {
+		addIssue(IssueCodeRequired, path, "%s is required", name)
+		return
+	}
+	if number, isNumber := value.(float64); !isNumber || number < 1 || number != float64(int64(number)) {
+		addIssue(IssueCodeValue, path, "%s must be a positive integer", name)
+	}
+}
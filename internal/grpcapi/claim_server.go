@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi/pharmacyv1"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// Page sizes of ListClaims.
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// ClaimServer implements pharmacyv1.ClaimServiceServer on top of service.ClaimService.
+type ClaimServer struct {
+	pharmacyv1.UnimplementedClaimServiceServer
+
+	claimService service.ClaimService
+	logger       logger.Logger
+
+	closeOnce sync.Once
+	closing   chan struct{}
+}
+
+func NewClaimServer(claimService service.ClaimService, log logger.Logger) *ClaimServer {
+	return &ClaimServer{
+		claimService: claimService,
+		logger:       log,
+		closing:      make(chan struct{}),
+	}
+}
+
+func (s *ClaimServer) SubmitClaim(ctx context.Context, req *pharmacyv1.SubmitClaimRequest) (*pharmacyv1.Claim, error) {
+	claim, err := s.claimService.SubmitClaim(models.ClaimSubmissionRequest{
+		NDC:      req.GetNdc(),
+		NPI:      req.GetNpi(),
+		Quantity: req.GetQuantity(),
+		Price:    req.GetPrice(),
+
+		Prescription: models.Prescription{
+			MemberID:      req.GetMemberId(),
+			PrescriberNPI: req.GetPrescriberNpi(),
+			RxNumber:      req.GetRxNumber(),
+			FillNumber:    int(req.GetFillNumber()),
+			DaysSupply:    int(req.GetDaysSupply()),
+			DateOfService: req.GetDateOfService(),
+			DAWCode:       req.GetDawCode(),
+			OverrideCode:  req.GetOverrideCode(),
+		},
+	})
+	if err != nil {
+		s.logger.Error("Error submitting claim via gRPC: %v", err)
+		if errors.Is(err, service.ErrInvalidClaimData) || errors.Is(err, service.ErrInvalidPrescription) || errors.Is(err, service.ErrUnknownNPI) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	s.logger.Info("Claim %s processed successfully via gRPC.", claim.ID)
+	return toProtoClaim(*claim), nil
+}
+
+func (s *ClaimServer) ReverseClaim(ctx context.Context, req *pharmacyv1.ReverseClaimRequest) (*pharmacyv1.Reversal, error) {
+	revert, err := s.claimService.ReverseClaim(models.ClaimReversalRequest{
+		ClaimID:    req.GetClaimId(),
+		Quantity:   req.GetQuantity(),
+		Amount:     req.GetAmount(),
+		ReasonCode: req.GetReasonCode(),
+		Reason:     req.GetReason(),
+		Actor:      models.ActorGRPC,
+	})
+	if err != nil {
+		s.logger.Error("Error reverting claim via gRPC: %v", err)
+		switch {
+		case errors.Is(err, service.ErrInvalidStatusTransition), errors.Is(err, service.ErrReversalExceedsClaim),
+			errors.Is(err, service.ErrReversalPolicyViolation):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, service.ErrInvalidReversalAmount), errors.Is(err, service.ErrReversalAmountMismatch):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case strings.Contains(err.Error(), "not found"):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case strings.Contains(err.Error(), "already reverted"):
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		case strings.Contains(err.Error(), "invalid reversal"):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	s.logger.Info("Claim %s reverted successfully via gRPC.", revert.ClaimID)
+	return &pharmacyv1.Reversal{
+		Id:         revert.ID,
+		ClaimId:    revert.ClaimID,
+		Timestamp:  revert.Timestamp,
+		Quantity:   revert.Quantity,
+		Amount:     revert.Amount,
+		ReasonCode: revert.ReasonCode,
+		Reason:     revert.Reason,
+	}, nil
+}
+
+func (s *ClaimServer) GetClaim(ctx context.Context, req *pharmacyv1.GetClaimRequest) (*pharmacyv1.Claim, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "claim ID is required")
+	}
+
+	claim, err := s.claimService.GetClaimByID(req.GetId())
+	if err != nil {
+		s.logger.Error("Error fetching claim %s via gRPC: %v", req.GetId(), err)
+		return nil, status.Error(codes.Internal, "internal error fetching claim")
+	}
+	if claim == nil {
+		return nil, status.Errorf(codes.NotFound, "claim with ID '%s' not found", req.GetId())
+	}
+	return toProtoClaim(*claim), nil
+}
+
+func (s *ClaimServer) ListClaims(ctx context.Context, req *pharmacyv1.ListClaimsRequest) (*pharmacyv1.ListClaimsResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize < 0 {
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	pageSize = min(pageSize, maxPageSize)
+
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		var err error
+		offset, err = strconv.Atoi(token)
+		if err != nil || offset < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page_token '%s'", token)
+		}
+	}
+
+	claims, total, err := s.claimService.SearchClaims(models.ClaimFilter{
+		NPI:    req.GetNpi(),
+		NDC:    req.GetNdc(),
+		From:   req.GetFrom(),
+		To:     req.GetTo(),
+		Limit:  pageSize,
+		Offset: offset,
+	})
+	if err != nil {
+		s.logger.Error("Error listing claims via gRPC: %v", err)
+		return nil, status.Error(codes.Internal, "internal error listing claims")
+	}
+
+	resp := &pharmacyv1.ListClaimsResponse{TotalSize: int32(total)}
+	for _, claim := range claims {
+		resp.Claims = append(resp.Claims, toProtoClaim(claim))
+	}
+	if next := offset + len(claims); len(claims) > 0 && next < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+	return resp, nil
+}
+
+// WatchClaims streams the claims submitted through the claim service until the client
+// cancels the call or the server shuts down. Response headers are sent as soon as the
+// subscription exists, so clients can wait for them before relying on the stream.
+func (s *ClaimServer) WatchClaims(req *pharmacyv1.WatchClaimsRequest, stream pharmacyv1.ClaimService_WatchClaimsServer) error {
+	claims, unsubscribe := s.claimService.WatchClaims()
+	defer unsubscribe()
+
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case claim, ok := <-claims:
+			if !ok {
+				return nil
+			}
+			if (req.GetNpi() != "" && claim.NPI != req.GetNpi()) || (req.GetNdc() != "" && claim.NDC != req.GetNdc()) {
+				continue
+			}
+			if err := stream.Send(toProtoClaim(claim)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// closeWatchers ends every open WatchClaims stream, so a graceful stop does not wait for them.
+func (s *ClaimServer) closeWatchers() {
+	s.closeOnce.Do(func() { close(s.closing) })
+}
+
+func toProtoClaim(claim models.Claim) *pharmacyv1.Claim {
+	rejects := make([]*pharmacyv1.ClaimReject, len(claim.Rejects))
+	for i, reject := range claim.Rejects {
+		rejects[i] = &pharmacyv1.ClaimReject{Code: reject.Code, Rule: reject.Rule, Message: reject.Message}
+	}
+	return &pharmacyv1.Claim{
+		Id:        claim.ID,
+		Ndc:       claim.NDC,
+		Npi:       claim.NPI,
+		Quantity:  claim.Quantity,
+		Price:     claim.Price,
+		Timestamp: claim.Timestamp,
+		Reverted:  claim.Reverted,
+		Status:    claim.Status,
+
+		OutstandingQuantity: claim.OutstandingQuantity,
+		OutstandingAmount:   claim.OutstandingAmount,
+		ReplacesClaimId:     claim.ReplacesClaimID,
+		Rejects:             rejects,
+		AllowedAmount:       claim.AllowedAmount,
+		PricingBasis:        claim.PricingBasis,
+		ReferenceUnitPrice:  claim.ReferenceUnitPrice,
+		PriceVariance:       claim.PriceVariance,
+		PriceFlagged:        claim.PriceFlagged,
+		MemberId:            claim.MemberID,
+		PrescriberNpi:       claim.PrescriberNPI,
+		RxNumber:            claim.RxNumber,
+		FillNumber:          int32(claim.FillNumber),
+		DaysSupply:          int32(claim.DaysSupply),
+		DateOfService:       claim.DateOfService,
+		DawCode:             claim.DAWCode,
+		OverrideCode:        claim.OverrideCode,
+		Plan:                claim.Plan,
+		PlanYear:            int32(claim.PlanYear),
+		PatientPay:          claim.PatientPay,
+		DeductibleApplied:   claim.DeductibleApplied,
+	}
+}
@@ -0,0 +1,52 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi/pharmacyv1"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// PharmacyServer implements pharmacyv1.PharmacyServiceServer on top of service.PharmacyService.
+type PharmacyServer struct {
+	pharmacyv1.UnimplementedPharmacyServiceServer
+
+	pharmacyService service.PharmacyService
+	logger          logger.Logger
+}
+
+func NewPharmacyServer(pharmacyService service.PharmacyService, log logger.Logger) *PharmacyServer {
+	return &PharmacyServer{
+		pharmacyService: pharmacyService,
+		logger:          log,
+	}
+}
+
+func (s *PharmacyServer) GetPharmacy(ctx context.Context, req *pharmacyv1.GetPharmacyRequest) (*pharmacyv1.Pharmacy, error) {
+	pharmacy, err := s.pharmacyService.GetPharmacy(req.GetNpi())
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownNPI) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pharmacyv1.Pharmacy{Chain: pharmacy.Chain, Npi: pharmacy.NPI}, nil
+}
+
+func (s *PharmacyServer) ListPharmacies(ctx context.Context, req *pharmacyv1.ListPharmaciesRequest) (*pharmacyv1.ListPharmaciesResponse, error) {
+	pharmacies, err := s.pharmacyService.ListPharmacies(req.GetChain())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	resp := &pharmacyv1.ListPharmaciesResponse{}
+	for _, pharmacy := range pharmacies {
+		resp.Pharmacies = append(resp.Pharmacies, &pharmacyv1.Pharmacy{Chain: pharmacy.Chain, Npi: pharmacy.NPI})
+	}
+	return resp, nil
+}
@@ -0,0 +1,1383 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        v5.29.3
+// source: pharmacy/v1/pharmacy.proto
+
+package pharmacyv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Claim struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ndc      string                 `protobuf:"bytes,2,opt,name=ndc,proto3" json:"ndc,omitempty"`
+	Npi      string                 `protobuf:"bytes,3,opt,name=npi,proto3" json:"npi,omitempty"`
+	Quantity float64                `protobuf:"fixed64,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price    float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	// Submission time (YYYY-MM-DDTHH:MM:SS).
+	Timestamp string `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Deprecated: true when status is "reversed".
+	Reverted bool `protobuf:"varint,7,opt,name=reverted,proto3" json:"reverted,omitempty"`
+	// Lifecycle status: pending, paid, rejected, reversed or rebilled.
+	Status string `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"`
+	// Quantity and amount not reversed yet.
+	OutstandingQuantity float64 `protobuf:"fixed64,9,opt,name=outstanding_quantity,json=outstandingQuantity,proto3" json:"outstanding_quantity,omitempty"`
+	OutstandingAmount   float64 `protobuf:"fixed64,10,opt,name=outstanding_amount,json=outstandingAmount,proto3" json:"outstanding_amount,omitempty"`
+	// ID of the rebilled claim this claim replaces, if any.
+	ReplacesClaimId string `protobuf:"bytes,11,opt,name=replaces_claim_id,json=replacesClaimId,proto3" json:"replaces_claim_id,omitempty"`
+	// Reject codes given at adjudication to a pending or rejected claim.
+	Rejects []*ClaimReject `protobuf:"bytes,12,rep,name=rejects,proto3" json:"rejects,omitempty"`
+	// Amount reimbursed under the contract of the chain; price is the submitted amount.
+	AllowedAmount float64 `protobuf:"fixed64,13,opt,name=allowed_amount,json=allowedAmount,proto3" json:"allowed_amount,omitempty"`
+	// How the allowed amount was computed: submitted, contract or lesser_of.
+	PricingBasis string `protobuf:"bytes,14,opt,name=pricing_basis,json=pricingBasis,proto3" json:"pricing_basis,omitempty"`
+	// Reference unit price in effect on the claim date, 0 when none.
+	ReferenceUnitPrice float64 `protobuf:"fixed64,15,opt,name=reference_unit_price,json=referenceUnitPrice,proto3" json:"reference_unit_price,omitempty"`
+	// Unit price above (or below) the reference price, in percent.
+	PriceVariance float64 `protobuf:"fixed64,16,opt,name=price_variance,json=priceVariance,proto3" json:"price_variance,omitempty"`
+	// True when the price variance is over the configured threshold.
+	PriceFlagged bool `protobuf:"varint,17,opt,name=price_flagged,json=priceFlagged,proto3" json:"price_flagged,omitempty"`
+	// Member and prescription details, empty or 0 when not submitted.
+	MemberId      string `protobuf:"bytes,18,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	PrescriberNpi string `protobuf:"bytes,19,opt,name=prescriber_npi,json=prescriberNpi,proto3" json:"prescriber_npi,omitempty"`
+	RxNumber      string `protobuf:"bytes,20,opt,name=rx_number,json=rxNumber,proto3" json:"rx_number,omitempty"`
+	FillNumber    int32  `protobuf:"varint,21,opt,name=fill_number,json=fillNumber,proto3" json:"fill_number,omitempty"`
+	DaysSupply    int32  `protobuf:"varint,22,opt,name=days_supply,json=daysSupply,proto3" json:"days_supply,omitempty"`
+	// Date the prescription was filled (YYYY-MM-DD).
+	DateOfService string `protobuf:"bytes,23,opt,name=date_of_service,json=dateOfService,proto3" json:"date_of_service,omitempty"`
+	// Dispense as written code, "0" to "9".
+	DawCode string `protobuf:"bytes,24,opt,name=daw_code,json=dawCode,proto3" json:"daw_code,omitempty"`
+	// Submission clarification code bypassing the refill-too-soon check, e.g. "03" for a vacation supply.
+	OverrideCode string `protobuf:"bytes,25,opt,name=override_code,json=overrideCode,proto3" json:"override_code,omitempty"`
+	// Cost sharing under the benefit plan of the member, empty or 0 without one.
+	Plan     string `protobuf:"bytes,26,opt,name=plan,proto3" json:"plan,omitempty"`
+	PlanYear int32  `protobuf:"varint,27,opt,name=plan_year,json=planYear,proto3" json:"plan_year,omitempty"`
+	// Amount paid by the member, deductible included.
+	PatientPay float64 `protobuf:"fixed64,28,opt,name=patient_pay,json=patientPay,proto3" json:"patient_pay,omitempty"`
+	// Part of the patient pay applied to the deductible.
+	DeductibleApplied float64 `protobuf:"fixed64,29,opt,name=deductible_applied,json=deductibleApplied,proto3" json:"deductible_applied,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Claim) Reset() {
+	*x = Claim{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Claim) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Claim) ProtoMessage() {}
+
+func (x *Claim) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Claim.ProtoReflect.Descriptor instead.
+func (*Claim) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Claim) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Claim) GetNdc() string {
+	if x != nil {
+		return x.Ndc
+	}
+	return ""
+}
+
+func (x *Claim) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+func (x *Claim) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Claim) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Claim) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *Claim) GetReverted() bool {
+	if x != nil {
+		return x.Reverted
+	}
+	return false
+}
+
+func (x *Claim) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Claim) GetOutstandingQuantity() float64 {
+	if x != nil {
+		return x.OutstandingQuantity
+	}
+	return 0
+}
+
+func (x *Claim) GetOutstandingAmount() float64 {
+	if x != nil {
+		return x.OutstandingAmount
+	}
+	return 0
+}
+
+func (x *Claim) GetReplacesClaimId() string {
+	if x != nil {
+		return x.ReplacesClaimId
+	}
+	return ""
+}
+
+func (x *Claim) GetRejects() []*ClaimReject {
+	if x != nil {
+		return x.Rejects
+	}
+	return nil
+}
+
+func (x *Claim) GetAllowedAmount() float64 {
+	if x != nil {
+		return x.AllowedAmount
+	}
+	return 0
+}
+
+func (x *Claim) GetPricingBasis() string {
+	if x != nil {
+		return x.PricingBasis
+	}
+	return ""
+}
+
+func (x *Claim) GetReferenceUnitPrice() float64 {
+	if x != nil {
+		return x.ReferenceUnitPrice
+	}
+	return 0
+}
+
+func (x *Claim) GetPriceVariance() float64 {
+	if x != nil {
+		return x.PriceVariance
+	}
+	return 0
+}
+
+func (x *Claim) GetPriceFlagged() bool {
+	if x != nil {
+		return x.PriceFlagged
+	}
+	return false
+}
+
+func (x *Claim) GetMemberId() string {
+	if x != nil {
+		return x.MemberId
+	}
+	return ""
+}
+
+func (x *Claim) GetPrescriberNpi() string {
+	if x != nil {
+		return x.PrescriberNpi
+	}
+	return ""
+}
+
+func (x *Claim) GetRxNumber() string {
+	if x != nil {
+		return x.RxNumber
+	}
+	return ""
+}
+
+func (x *Claim) GetFillNumber() int32 {
+	if x != nil {
+		return x.FillNumber
+	}
+	return 0
+}
+
+func (x *Claim) GetDaysSupply() int32 {
+	if x != nil {
+		return x.DaysSupply
+	}
+	return 0
+}
+
+func (x *Claim) GetDateOfService() string {
+	if x != nil {
+		return x.DateOfService
+	}
+	return ""
+}
+
+func (x *Claim) GetDawCode() string {
+	if x != nil {
+		return x.DawCode
+	}
+	return ""
+}
+
+func (x *Claim) GetOverrideCode() string {
+	if x != nil {
+		return x.OverrideCode
+	}
+	return ""
+}
+
+func (x *Claim) GetPlan() string {
+	if x != nil {
+		return x.Plan
+	}
+	return ""
+}
+
+func (x *Claim) GetPlanYear() int32 {
+	if x != nil {
+		return x.PlanYear
+	}
+	return 0
+}
+
+func (x *Claim) GetPatientPay() float64 {
+	if x != nil {
+		return x.PatientPay
+	}
+	return 0
+}
+
+func (x *Claim) GetDeductibleApplied() float64 {
+	if x != nil {
+		return x.DeductibleApplied
+	}
+	return 0
+}
+
+// ClaimReject is a reject code given to a claim by an adjudication rule.
+type ClaimReject struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// NCPDP-like reject code, e.g. "76" (Plan Limitations Exceeded).
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Name of the rule that gave the code.
+	Rule          string `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`
+	Message       string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimReject) Reset() {
+	*x = ClaimReject{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimReject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimReject) ProtoMessage() {}
+
+func (x *ClaimReject) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimReject.ProtoReflect.Descriptor instead.
+func (*ClaimReject) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClaimReject) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *ClaimReject) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *ClaimReject) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type Reversal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClaimId       string                 `protobuf:"bytes,2,opt,name=claim_id,json=claimId,proto3" json:"claim_id,omitempty"`
+	Timestamp     string                 `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Amount        float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"`
+	ReasonCode    string                 `protobuf:"bytes,6,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	Reason        string                 `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reversal) Reset() {
+	*x = Reversal{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reversal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reversal) ProtoMessage() {}
+
+func (x *Reversal) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reversal.ProtoReflect.Descriptor instead.
+func (*Reversal) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Reversal) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Reversal) GetClaimId() string {
+	if x != nil {
+		return x.ClaimId
+	}
+	return ""
+}
+
+func (x *Reversal) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *Reversal) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Reversal) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *Reversal) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *Reversal) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type Pharmacy struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chain         string                 `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	Npi           string                 `protobuf:"bytes,2,opt,name=npi,proto3" json:"npi,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pharmacy) Reset() {
+	*x = Pharmacy{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pharmacy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pharmacy) ProtoMessage() {}
+
+func (x *Pharmacy) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pharmacy.ProtoReflect.Descriptor instead.
+func (*Pharmacy) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Pharmacy) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+func (x *Pharmacy) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+type SubmitClaimRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Ndc      string                 `protobuf:"bytes,1,opt,name=ndc,proto3" json:"ndc,omitempty"`
+	Npi      string                 `protobuf:"bytes,2,opt,name=npi,proto3" json:"npi,omitempty"`
+	Quantity float64                `protobuf:"fixed64,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price    float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	// Optional member and prescription details, see Claim.
+	MemberId      string `protobuf:"bytes,5,opt,name=member_id,json=memberId,proto3" json:"member_id,omitempty"`
+	PrescriberNpi string `protobuf:"bytes,6,opt,name=prescriber_npi,json=prescriberNpi,proto3" json:"prescriber_npi,omitempty"`
+	RxNumber      string `protobuf:"bytes,7,opt,name=rx_number,json=rxNumber,proto3" json:"rx_number,omitempty"`
+	FillNumber    int32  `protobuf:"varint,8,opt,name=fill_number,json=fillNumber,proto3" json:"fill_number,omitempty"`
+	DaysSupply    int32  `protobuf:"varint,9,opt,name=days_supply,json=daysSupply,proto3" json:"days_supply,omitempty"`
+	DateOfService string `protobuf:"bytes,10,opt,name=date_of_service,json=dateOfService,proto3" json:"date_of_service,omitempty"`
+	DawCode       string `protobuf:"bytes,11,opt,name=daw_code,json=dawCode,proto3" json:"daw_code,omitempty"`
+	OverrideCode  string `protobuf:"bytes,12,opt,name=override_code,json=overrideCode,proto3" json:"override_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitClaimRequest) Reset() {
+	*x = SubmitClaimRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitClaimRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitClaimRequest) ProtoMessage() {}
+
+func (x *SubmitClaimRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitClaimRequest.ProtoReflect.Descriptor instead.
+func (*SubmitClaimRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SubmitClaimRequest) GetNdc() string {
+	if x != nil {
+		return x.Ndc
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *SubmitClaimRequest) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SubmitClaimRequest) GetMemberId() string {
+	if x != nil {
+		return x.MemberId
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetPrescriberNpi() string {
+	if x != nil {
+		return x.PrescriberNpi
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetRxNumber() string {
+	if x != nil {
+		return x.RxNumber
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetFillNumber() int32 {
+	if x != nil {
+		return x.FillNumber
+	}
+	return 0
+}
+
+func (x *SubmitClaimRequest) GetDaysSupply() int32 {
+	if x != nil {
+		return x.DaysSupply
+	}
+	return 0
+}
+
+func (x *SubmitClaimRequest) GetDateOfService() string {
+	if x != nil {
+		return x.DateOfService
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetDawCode() string {
+	if x != nil {
+		return x.DawCode
+	}
+	return ""
+}
+
+func (x *SubmitClaimRequest) GetOverrideCode() string {
+	if x != nil {
+		return x.OverrideCode
+	}
+	return ""
+}
+
+// Without quantity and amount everything outstanding is reversed; with only one
+// of them the other is prorated at the unit price of the claim.
+type ReverseClaimRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	ClaimId  string                 `protobuf:"bytes,1,opt,name=claim_id,json=claimId,proto3" json:"claim_id,omitempty"`
+	Quantity float64                `protobuf:"fixed64,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Amount   float64                `protobuf:"fixed64,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	// NCPDP-style reason code of the reversal, required.
+	ReasonCode string `protobuf:"bytes,4,opt,name=reason_code,json=reasonCode,proto3" json:"reason_code,omitempty"`
+	// Reason text, required with reason code 99.
+	Reason        string `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseClaimRequest) Reset() {
+	*x = ReverseClaimRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseClaimRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseClaimRequest) ProtoMessage() {}
+
+func (x *ReverseClaimRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseClaimRequest.ProtoReflect.Descriptor instead.
+func (*ReverseClaimRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ReverseClaimRequest) GetClaimId() string {
+	if x != nil {
+		return x.ClaimId
+	}
+	return ""
+}
+
+func (x *ReverseClaimRequest) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *ReverseClaimRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ReverseClaimRequest) GetReasonCode() string {
+	if x != nil {
+		return x.ReasonCode
+	}
+	return ""
+}
+
+func (x *ReverseClaimRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type GetClaimRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetClaimRequest) Reset() {
+	*x = GetClaimRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetClaimRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetClaimRequest) ProtoMessage() {}
+
+func (x *GetClaimRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetClaimRequest.ProtoReflect.Descriptor instead.
+func (*GetClaimRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetClaimRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListClaimsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Npi   string                 `protobuf:"bytes,1,opt,name=npi,proto3" json:"npi,omitempty"`
+	Ndc   string                 `protobuf:"bytes,2,opt,name=ndc,proto3" json:"ndc,omitempty"`
+	// Inclusive lower bound of the claim timestamp (e.g. "2024-02-01").
+	From string `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	// Exclusive upper bound of the claim timestamp.
+	To string `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	// Maximum number of claims returned; defaults to 50, capped at 500.
+	PageSize int32 `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// Token returned as next_page_token by the previous call.
+	PageToken     string `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListClaimsRequest) Reset() {
+	*x = ListClaimsRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListClaimsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListClaimsRequest) ProtoMessage() {}
+
+func (x *ListClaimsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListClaimsRequest.ProtoReflect.Descriptor instead.
+func (*ListClaimsRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListClaimsRequest) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+func (x *ListClaimsRequest) GetNdc() string {
+	if x != nil {
+		return x.Ndc
+	}
+	return ""
+}
+
+func (x *ListClaimsRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ListClaimsRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ListClaimsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListClaimsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListClaimsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Claims    []*Claim               `protobuf:"bytes,1,rep,name=claims,proto3" json:"claims,omitempty"`
+	TotalSize int32                  `protobuf:"varint,2,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`
+	// Empty on the last page.
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListClaimsResponse) Reset() {
+	*x = ListClaimsResponse{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListClaimsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListClaimsResponse) ProtoMessage() {}
+
+func (x *ListClaimsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListClaimsResponse.ProtoReflect.Descriptor instead.
+func (*ListClaimsResponse) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListClaimsResponse) GetClaims() []*Claim {
+	if x != nil {
+		return x.Claims
+	}
+	return nil
+}
+
+func (x *ListClaimsResponse) GetTotalSize() int32 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *ListClaimsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type WatchClaimsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only stream claims of this pharmacy when set.
+	Npi string `protobuf:"bytes,1,opt,name=npi,proto3" json:"npi,omitempty"`
+	// Only stream claims of this NDC when set.
+	Ndc           string `protobuf:"bytes,2,opt,name=ndc,proto3" json:"ndc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchClaimsRequest) Reset() {
+	*x = WatchClaimsRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchClaimsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchClaimsRequest) ProtoMessage() {}
+
+func (x *WatchClaimsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchClaimsRequest.ProtoReflect.Descriptor instead.
+func (*WatchClaimsRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchClaimsRequest) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+func (x *WatchClaimsRequest) GetNdc() string {
+	if x != nil {
+		return x.Ndc
+	}
+	return ""
+}
+
+type GetPharmacyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Npi           string                 `protobuf:"bytes,1,opt,name=npi,proto3" json:"npi,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPharmacyRequest) Reset() {
+	*x = GetPharmacyRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPharmacyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPharmacyRequest) ProtoMessage() {}
+
+func (x *GetPharmacyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPharmacyRequest.ProtoReflect.Descriptor instead.
+func (*GetPharmacyRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPharmacyRequest) GetNpi() string {
+	if x != nil {
+		return x.Npi
+	}
+	return ""
+}
+
+type ListPharmaciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chain         string                 `protobuf:"bytes,1,opt,name=chain,proto3" json:"chain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPharmaciesRequest) Reset() {
+	*x = ListPharmaciesRequest{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPharmaciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPharmaciesRequest) ProtoMessage() {}
+
+func (x *ListPharmaciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPharmaciesRequest.ProtoReflect.Descriptor instead.
+func (*ListPharmaciesRequest) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListPharmaciesRequest) GetChain() string {
+	if x != nil {
+		return x.Chain
+	}
+	return ""
+}
+
+type ListPharmaciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pharmacies    []*Pharmacy            `protobuf:"bytes,1,rep,name=pharmacies,proto3" json:"pharmacies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPharmaciesResponse) Reset() {
+	*x = ListPharmaciesResponse{}
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPharmaciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPharmaciesResponse) ProtoMessage() {}
+
+func (x *ListPharmaciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pharmacy_v1_pharmacy_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPharmaciesResponse.ProtoReflect.Descriptor instead.
+func (*ListPharmaciesResponse) Descriptor() ([]byte, []int) {
+	return file_pharmacy_v1_pharmacy_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListPharmaciesResponse) GetPharmacies() []*Pharmacy {
+	if x != nil {
+		return x.Pharmacies
+	}
+	return nil
+}
+
+var File_pharmacy_v1_pharmacy_proto protoreflect.FileDescriptor
+
+var file_pharmacy_v1_pharmacy_proto_rawDesc = string([]byte{
+	0x0a, 0x1a, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x68,
+	0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0b, 0x70, 0x68,
+	0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x22, 0xd7, 0x07, 0x0a, 0x05, 0x43, 0x6c,
+	0x61, 0x69, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x64, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6e, 0x64, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x70, 0x69, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6e, 0x70, 0x69, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x76, 0x65, 0x72,
+	0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x76, 0x65, 0x72,
+	0x74, 0x65, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x6f,
+	0x75, 0x74, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x71, 0x75, 0x61, 0x6e, 0x74,
+	0x69, 0x74, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x13, 0x6f, 0x75, 0x74, 0x73, 0x74,
+	0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x51, 0x75, 0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x2d,
+	0x0a, 0x12, 0x6f, 0x75, 0x74, 0x73, 0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x6f, 0x75, 0x74, 0x73,
+	0x74, 0x61, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x2a, 0x0a,
+	0x11, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x73, 0x5f, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f,
+	0x69, 0x64, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63,
+	0x65, 0x73, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x49, 0x64, 0x12, 0x32, 0x0a, 0x07, 0x72, 0x65, 0x6a,
+	0x65, 0x63, 0x74, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x68, 0x61,
+	0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x52, 0x07, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x12, 0x25, 0x0a,
+	0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18,
+	0x0d, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x41, 0x6d,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x69, 0x63, 0x69, 0x6e, 0x67, 0x5f,
+	0x62, 0x61, 0x73, 0x69, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x72, 0x69,
+	0x63, 0x69, 0x6e, 0x67, 0x42, 0x61, 0x73, 0x69, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x72, 0x65, 0x66,
+	0x65, 0x72, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x5f, 0x70, 0x72, 0x69, 0x63,
+	0x65, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x01, 0x52, 0x12, 0x72, 0x65, 0x66, 0x65, 0x72, 0x65, 0x6e,
+	0x63, 0x65, 0x55, 0x6e, 0x69, 0x74, 0x50, 0x72, 0x69, 0x63, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x70,
+	0x72, 0x69, 0x63, 0x65, 0x5f, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x63, 0x65, 0x18, 0x10, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x72, 0x69, 0x63, 0x65, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e,
+	0x63, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x69, 0x63, 0x65, 0x5f, 0x66, 0x6c, 0x61, 0x67,
+	0x67, 0x65, 0x64, 0x18, 0x11, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x70, 0x72, 0x69, 0x63, 0x65,
+	0x46, 0x6c, 0x61, 0x67, 0x67, 0x65, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x65, 0x6d, 0x62, 0x65,
+	0x72, 0x5f, 0x69, 0x64, 0x18, 0x12, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x6d, 0x62,
+	0x65, 0x72, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x72, 0x5f, 0x6e, 0x70, 0x69, 0x18, 0x13, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70, 0x72,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x4e, 0x70, 0x69, 0x12, 0x1b, 0x0a, 0x09, 0x72,
+	0x78, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x72, 0x78, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c, 0x6c,
+	0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x15, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x66,
+	0x69, 0x6c, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x79,
+	0x73, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x18, 0x16, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x64, 0x61, 0x79, 0x73, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x61,
+	0x74, 0x65, 0x5f, 0x6f, 0x66, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x17, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x61, 0x74, 0x65, 0x4f, 0x66, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x61, 0x77, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x18,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x61, 0x77, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x19,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x18, 0x1a, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x6c, 0x61, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x6e, 0x5f, 0x79,
+	0x65, 0x61, 0x72, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x6c, 0x61, 0x6e, 0x59,
+	0x65, 0x61, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x74, 0x69, 0x65, 0x6e, 0x74, 0x5f, 0x70,
+	0x61, 0x79, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x70, 0x61, 0x74, 0x69, 0x65, 0x6e,
+	0x74, 0x50, 0x61, 0x79, 0x12, 0x2d, 0x0a, 0x12, 0x64, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x62,
+	0x6c, 0x65, 0x5f, 0x61, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x11, 0x64, 0x65, 0x64, 0x75, 0x63, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x41, 0x70, 0x70, 0x6c,
+	0x69, 0x65, 0x64, 0x22, 0x4f, 0x0a, 0x0b, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x75, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0xc0, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61,
+	0x6c, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75,
+	0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75,
+	0x61, 0x6e, 0x74, 0x69, 0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f,
+	0x0a, 0x0b, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x32, 0x0a, 0x08, 0x50, 0x68, 0x61, 0x72, 0x6d,
+	0x61, 0x63, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x70, 0x69,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x70, 0x69, 0x22, 0xf5, 0x02, 0x0a, 0x12,
+	0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x64, 0x63, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6e, 0x64, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x70, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6e, 0x70, 0x69, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x05, 0x70, 0x72, 0x69, 0x63, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x65, 0x6d, 0x62,
+	0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6d, 0x65, 0x6d,
+	0x62, 0x65, 0x72, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x65, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x72, 0x5f, 0x6e, 0x70, 0x69, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x70,
+	0x72, 0x65, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x4e, 0x70, 0x69, 0x12, 0x1b, 0x0a, 0x09,
+	0x72, 0x78, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x08, 0x72, 0x78, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x69, 0x6c,
+	0x6c, 0x5f, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a,
+	0x66, 0x69, 0x6c, 0x6c, 0x4e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61,
+	0x79, 0x73, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x64, 0x61, 0x79, 0x73, 0x53, 0x75, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x26, 0x0a, 0x0f, 0x64,
+	0x61, 0x74, 0x65, 0x5f, 0x6f, 0x66, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x18, 0x0a,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x64, 0x61, 0x74, 0x65, 0x4f, 0x66, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x61, 0x77, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x64, 0x61, 0x77, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x23,
+	0x0a, 0x0d, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x43,
+	0x6f, 0x64, 0x65, 0x22, 0x9d, 0x01, 0x0a, 0x13, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x43,
+	0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x71, 0x75, 0x61, 0x6e, 0x74, 0x69,
+	0x74, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x22, 0x21, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x97, 0x01, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x43,
+	0x6c, 0x61, 0x69, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03,
+	0x6e, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x70, 0x69, 0x12, 0x10,
+	0x0a, 0x03, 0x6e, 0x64, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x64, 0x63,
+	0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x74, 0x6f, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e,
+	0x22, 0x87, 0x01, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x06, 0x63, 0x6c, 0x61, 0x69, 0x6d,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61,
+	0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x06, 0x63, 0x6c, 0x61,
+	0x69, 0x6d, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f,
+	0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78,
+	0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x38, 0x0a, 0x12, 0x57, 0x61,
+	0x74, 0x63, 0x68, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x6e, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e,
+	0x70, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x64, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6e, 0x64, 0x63, 0x22, 0x26, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x72, 0x6d,
+	0x61, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x70,
+	0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6e, 0x70, 0x69, 0x22, 0x2d, 0x0a, 0x15,
+	0x4c, 0x69, 0x73, 0x74, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x22, 0x4f, 0x0a, 0x16, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0a, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63,
+	0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x68, 0x61, 0x72,
+	0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79,
+	0x52, 0x0a, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x32, 0xee, 0x02, 0x0a,
+	0x0c, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x42, 0x0a,
+	0x0b, 0x53, 0x75, 0x62, 0x6d, 0x69, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x12, 0x1f, 0x2e, 0x70,
+	0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x6d, 0x69,
+	0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e,
+	0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x12, 0x47, 0x0a, 0x0c, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x43, 0x6c, 0x61, 0x69,
+	0x6d, 0x12, 0x20, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x65, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76,
+	0x31, 0x2e, 0x52, 0x65, 0x76, 0x65, 0x72, 0x73, 0x61, 0x6c, 0x12, 0x3c, 0x0a, 0x08, 0x47, 0x65,
+	0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x12, 0x1c, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x12, 0x4d, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74,
+	0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x12, 0x1e, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68,
+	0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x12, 0x1f, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61,
+	0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x30, 0x01, 0x32, 0xb3, 0x01,
+	0x0a, 0x0f, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
+	0x65, 0x12, 0x45, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79,
+	0x12, 0x1f, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x15, 0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e,
+	0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x12, 0x59, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74,
+	0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x12, 0x22, 0x2e, 0x70, 0x68, 0x61,
+	0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x68, 0x61,
+	0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x23,
+	0x2e, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x50, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x42, 0x54, 0x5a, 0x52, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x64, 0x69, 0x6f, 0x67, 0x6f, 0x63, 0x61, 0x72, 0x61, 0x73, 0x63, 0x6f, 0x2f, 0x67,
+	0x6f, 0x2d, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x2d, 0x73, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63,
+	0x61, 0x70, 0x69, 0x2f, 0x70, 0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x76, 0x31, 0x3b, 0x70,
+	0x68, 0x61, 0x72, 0x6d, 0x61, 0x63, 0x79, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+})
+
+var (
+	file_pharmacy_v1_pharmacy_proto_rawDescOnce sync.Once
+	file_pharmacy_v1_pharmacy_proto_rawDescData []byte
+)
+
+func file_pharmacy_v1_pharmacy_proto_rawDescGZIP() []byte {
+	file_pharmacy_v1_pharmacy_proto_rawDescOnce.Do(func() {
+		file_pharmacy_v1_pharmacy_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pharmacy_v1_pharmacy_proto_rawDesc), len(file_pharmacy_v1_pharmacy_proto_rawDesc)))
+	})
+	return file_pharmacy_v1_pharmacy_proto_rawDescData
+}
+
+var file_pharmacy_v1_pharmacy_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_pharmacy_v1_pharmacy_proto_goTypes = []any{
+	(*Claim)(nil),                  // 0: pharmacy.v1.Claim
+	(*ClaimReject)(nil),            // 1: pharmacy.v1.ClaimReject
+	(*Reversal)(nil),               // 2: pharmacy.v1.Reversal
+	(*Pharmacy)(nil),               // 3: pharmacy.v1.Pharmacy
+	(*SubmitClaimRequest)(nil),     // 4: pharmacy.v1.SubmitClaimRequest
+	(*ReverseClaimRequest)(nil),    // 5: pharmacy.v1.ReverseClaimRequest
+	(*GetClaimRequest)(nil),        // 6: pharmacy.v1.GetClaimRequest
+	(*ListClaimsRequest)(nil),      // 7: pharmacy.v1.ListClaimsRequest
+	(*ListClaimsResponse)(nil),     // 8: pharmacy.v1.ListClaimsResponse
+	(*WatchClaimsRequest)(nil),     // 9: pharmacy.v1.WatchClaimsRequest
+	(*GetPharmacyRequest)(nil),     // 10: pharmacy.v1.GetPharmacyRequest
+	(*ListPharmaciesRequest)(nil),  // 11: pharmacy.v1.ListPharmaciesRequest
+	(*ListPharmaciesResponse)(nil), // 12: pharmacy.v1.ListPharmaciesResponse
+}
+var file_pharmacy_v1_pharmacy_proto_depIdxs = []int32{
+	1,  // 0: pharmacy.v1.Claim.rejects:type_name -> pharmacy.v1.ClaimReject
+	0,  // 1: pharmacy.v1.ListClaimsResponse.claims:type_name -> pharmacy.v1.Claim
+	3,  // 2: pharmacy.v1.ListPharmaciesResponse.pharmacies:type_name -> pharmacy.v1.Pharmacy
+	4,  // 3: pharmacy.v1.ClaimService.SubmitClaim:input_type -> pharmacy.v1.SubmitClaimRequest
+	5,  // 4: pharmacy.v1.ClaimService.ReverseClaim:input_type -> pharmacy.v1.ReverseClaimRequest
+	6,  // 5: pharmacy.v1.ClaimService.GetClaim:input_type -> pharmacy.v1.GetClaimRequest
+	7,  // 6: pharmacy.v1.ClaimService.ListClaims:input_type -> pharmacy.v1.ListClaimsRequest
+	9,  // 7: pharmacy.v1.ClaimService.WatchClaims:input_type -> pharmacy.v1.WatchClaimsRequest
+	10, // 8: pharmacy.v1.PharmacyService.GetPharmacy:input_type -> pharmacy.v1.GetPharmacyRequest
+	11, // 9: pharmacy.v1.PharmacyService.ListPharmacies:input_type -> pharmacy.v1.ListPharmaciesRequest
+	0,  // 10: pharmacy.v1.ClaimService.SubmitClaim:output_type -> pharmacy.v1.Claim
+	2,  // 11: pharmacy.v1.ClaimService.ReverseClaim:output_type -> pharmacy.v1.Reversal
+	0,  // 12: pharmacy.v1.ClaimService.GetClaim:output_type -> pharmacy.v1.Claim
+	8,  // 13: pharmacy.v1.ClaimService.ListClaims:output_type -> pharmacy.v1.ListClaimsResponse
+	0,  // 14: pharmacy.v1.ClaimService.WatchClaims:output_type -> pharmacy.v1.Claim
+	3,  // 15: pharmacy.v1.PharmacyService.GetPharmacy:output_type -> pharmacy.v1.Pharmacy
+	12, // 16: pharmacy.v1.PharmacyService.ListPharmacies:output_type -> pharmacy.v1.ListPharmaciesResponse
+	10, // [10:17] is the sub-list for method output_type
+	3,  // [3:10] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_pharmacy_v1_pharmacy_proto_init() }
+func file_pharmacy_v1_pharmacy_proto_init() {
+	if File_pharmacy_v1_pharmacy_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pharmacy_v1_pharmacy_proto_rawDesc), len(file_pharmacy_v1_pharmacy_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_pharmacy_v1_pharmacy_proto_goTypes,
+		DependencyIndexes: file_pharmacy_v1_pharmacy_proto_depIdxs,
+		MessageInfos:      file_pharmacy_v1_pharmacy_proto_msgTypes,
+	}.Build()
+	File_pharmacy_v1_pharmacy_proto = out.File
+	file_pharmacy_v1_pharmacy_proto_goTypes = nil
+	file_pharmacy_v1_pharmacy_proto_depIdxs = nil
+}
@@ -0,0 +1,439 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: pharmacy/v1/pharmacy.proto
+
+package pharmacyv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClaimService_SubmitClaim_FullMethodName  = "/pharmacy.v1.ClaimService/SubmitClaim"
+	ClaimService_ReverseClaim_FullMethodName = "/pharmacy.v1.ClaimService/ReverseClaim"
+	ClaimService_GetClaim_FullMethodName     = "/pharmacy.v1.ClaimService/GetClaim"
+	ClaimService_ListClaims_FullMethodName   = "/pharmacy.v1.ClaimService/ListClaims"
+	ClaimService_WatchClaims_FullMethodName  = "/pharmacy.v1.ClaimService/WatchClaims"
+)
+
+// ClaimServiceClient is the client API for ClaimService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClaimService submits, reverses and queries pharmacy claims.
+type ClaimServiceClient interface {
+	// SubmitClaim records a new claim for a known pharmacy.
+	SubmitClaim(ctx context.Context, in *SubmitClaimRequest, opts ...grpc.CallOption) (*Claim, error)
+	// ReverseClaim reverts a previously submitted claim.
+	ReverseClaim(ctx context.Context, in *ReverseClaimRequest, opts ...grpc.CallOption) (*Reversal, error)
+	// GetClaim fetches a claim by its ID.
+	GetClaim(ctx context.Context, in *GetClaimRequest, opts ...grpc.CallOption) (*Claim, error)
+	// ListClaims searches claims ordered by timestamp, one page at a time.
+	ListClaims(ctx context.Context, in *ListClaimsRequest, opts ...grpc.CallOption) (*ListClaimsResponse, error)
+	// WatchClaims streams the claims submitted after the call starts until the client cancels.
+	WatchClaims(ctx context.Context, in *WatchClaimsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Claim], error)
+}
+
+type claimServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClaimServiceClient(cc grpc.ClientConnInterface) ClaimServiceClient {
+	return &claimServiceClient{cc}
+}
+
+func (c *claimServiceClient) SubmitClaim(ctx context.Context, in *SubmitClaimRequest, opts ...grpc.CallOption) (*Claim, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Claim)
+	err := c.cc.Invoke(ctx, ClaimService_SubmitClaim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *claimServiceClient) ReverseClaim(ctx context.Context, in *ReverseClaimRequest, opts ...grpc.CallOption) (*Reversal, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Reversal)
+	err := c.cc.Invoke(ctx, ClaimService_ReverseClaim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *claimServiceClient) GetClaim(ctx context.Context, in *GetClaimRequest, opts ...grpc.CallOption) (*Claim, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Claim)
+	err := c.cc.Invoke(ctx, ClaimService_GetClaim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *claimServiceClient) ListClaims(ctx context.Context, in *ListClaimsRequest, opts ...grpc.CallOption) (*ListClaimsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListClaimsResponse)
+	err := c.cc.Invoke(ctx, ClaimService_ListClaims_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *claimServiceClient) WatchClaims(ctx context.Context, in *WatchClaimsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Claim], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClaimService_ServiceDesc.Streams[0], ClaimService_WatchClaims_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchClaimsRequest, Claim]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaimService_WatchClaimsClient = grpc.ServerStreamingClient[Claim]
+
+// ClaimServiceServer is the server API for ClaimService service.
+// All implementations must embed UnimplementedClaimServiceServer
+// for forward compatibility.
+//
+// ClaimService submits, reverses and queries pharmacy claims.
+type ClaimServiceServer interface {
+	// SubmitClaim records a new claim for a known pharmacy.
+	SubmitClaim(context.Context, *SubmitClaimRequest) (*Claim, error)
+	// ReverseClaim reverts a previously submitted claim.
+	ReverseClaim(context.Context, *ReverseClaimRequest) (*Reversal, error)
+	// GetClaim fetches a claim by its ID.
+	GetClaim(context.Context, *GetClaimRequest) (*Claim, error)
+	// ListClaims searches claims ordered by timestamp, one page at a time.
+	ListClaims(context.Context, *ListClaimsRequest) (*ListClaimsResponse, error)
+	// WatchClaims streams the claims submitted after the call starts until the client cancels.
+	WatchClaims(*WatchClaimsRequest, grpc.ServerStreamingServer[Claim]) error
+	mustEmbedUnimplementedClaimServiceServer()
+}
+
+// UnimplementedClaimServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClaimServiceServer struct{}
+
+func (UnimplementedClaimServiceServer) SubmitClaim(context.Context, *SubmitClaimRequest) (*Claim, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitClaim not implemented")
+}
+func (UnimplementedClaimServiceServer) ReverseClaim(context.Context, *ReverseClaimRequest) (*Reversal, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseClaim not implemented")
+}
+func (UnimplementedClaimServiceServer) GetClaim(context.Context, *GetClaimRequest) (*Claim, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaim not implemented")
+}
+func (UnimplementedClaimServiceServer) ListClaims(context.Context, *ListClaimsRequest) (*ListClaimsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListClaims not implemented")
+}
+func (UnimplementedClaimServiceServer) WatchClaims(*WatchClaimsRequest, grpc.ServerStreamingServer[Claim]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchClaims not implemented")
+}
+func (UnimplementedClaimServiceServer) mustEmbedUnimplementedClaimServiceServer() {}
+func (UnimplementedClaimServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeClaimServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClaimServiceServer will
+// result in compilation errors.
+type UnsafeClaimServiceServer interface {
+	mustEmbedUnimplementedClaimServiceServer()
+}
+
+func RegisterClaimServiceServer(s grpc.ServiceRegistrar, srv ClaimServiceServer) {
+	// If the following call pancis, it indicates UnimplementedClaimServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClaimService_ServiceDesc, srv)
+}
+
+func _ClaimService_SubmitClaim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitClaimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).SubmitClaim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_SubmitClaim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).SubmitClaim(ctx, req.(*SubmitClaimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClaimService_ReverseClaim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseClaimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).ReverseClaim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_ReverseClaim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).ReverseClaim(ctx, req.(*ReverseClaimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClaimService_GetClaim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).GetClaim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_GetClaim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).GetClaim(ctx, req.(*GetClaimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClaimService_ListClaims_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListClaimsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClaimServiceServer).ListClaims(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClaimService_ListClaims_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClaimServiceServer).ListClaims(ctx, req.(*ListClaimsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClaimService_WatchClaims_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchClaimsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ClaimServiceServer).WatchClaims(m, &grpc.GenericServerStream[WatchClaimsRequest, Claim]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClaimService_WatchClaimsServer = grpc.ServerStreamingServer[Claim]
+
+// ClaimService_ServiceDesc is the grpc.ServiceDesc for ClaimService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClaimService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pharmacy.v1.ClaimService",
+	HandlerType: (*ClaimServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitClaim",
+			Handler:    _ClaimService_SubmitClaim_Handler,
+		},
+		{
+			MethodName: "ReverseClaim",
+			Handler:    _ClaimService_ReverseClaim_Handler,
+		},
+		{
+			MethodName: "GetClaim",
+			Handler:    _ClaimService_GetClaim_Handler,
+		},
+		{
+			MethodName: "ListClaims",
+			Handler:    _ClaimService_ListClaims_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchClaims",
+			Handler:       _ClaimService_WatchClaims_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pharmacy/v1/pharmacy.proto",
+}
+
+const (
+	PharmacyService_GetPharmacy_FullMethodName    = "/pharmacy.v1.PharmacyService/GetPharmacy"
+	PharmacyService_ListPharmacies_FullMethodName = "/pharmacy.v1.PharmacyService/ListPharmacies"
+)
+
+// PharmacyServiceClient is the client API for PharmacyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PharmacyService queries the registered pharmacies.
+type PharmacyServiceClient interface {
+	// GetPharmacy fetches a pharmacy by its NPI.
+	GetPharmacy(ctx context.Context, in *GetPharmacyRequest, opts ...grpc.CallOption) (*Pharmacy, error)
+	// ListPharmacies lists the registered pharmacies, optionally restricted to a chain.
+	ListPharmacies(ctx context.Context, in *ListPharmaciesRequest, opts ...grpc.CallOption) (*ListPharmaciesResponse, error)
+}
+
+type pharmacyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPharmacyServiceClient(cc grpc.ClientConnInterface) PharmacyServiceClient {
+	return &pharmacyServiceClient{cc}
+}
+
+func (c *pharmacyServiceClient) GetPharmacy(ctx context.Context, in *GetPharmacyRequest, opts ...grpc.CallOption) (*Pharmacy, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Pharmacy)
+	err := c.cc.Invoke(ctx, PharmacyService_GetPharmacy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pharmacyServiceClient) ListPharmacies(ctx context.Context, in *ListPharmaciesRequest, opts ...grpc.CallOption) (*ListPharmaciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPharmaciesResponse)
+	err := c.cc.Invoke(ctx, PharmacyService_ListPharmacies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PharmacyServiceServer is the server API for PharmacyService service.
+// All implementations must embed UnimplementedPharmacyServiceServer
+// for forward compatibility.
+//
+// PharmacyService queries the registered pharmacies.
+type PharmacyServiceServer interface {
+	// GetPharmacy fetches a pharmacy by its NPI.
+	GetPharmacy(context.Context, *GetPharmacyRequest) (*Pharmacy, error)
+	// ListPharmacies lists the registered pharmacies, optionally restricted to a chain.
+	ListPharmacies(context.Context, *ListPharmaciesRequest) (*ListPharmaciesResponse, error)
+	mustEmbedUnimplementedPharmacyServiceServer()
+}
+
+// UnimplementedPharmacyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPharmacyServiceServer struct{}
+
+func (UnimplementedPharmacyServiceServer) GetPharmacy(context.Context, *GetPharmacyRequest) (*Pharmacy, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPharmacy not implemented")
+}
+func (UnimplementedPharmacyServiceServer) ListPharmacies(context.Context, *ListPharmaciesRequest) (*ListPharmaciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPharmacies not implemented")
+}
+func (UnimplementedPharmacyServiceServer) mustEmbedUnimplementedPharmacyServiceServer() {}
+func (UnimplementedPharmacyServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafePharmacyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PharmacyServiceServer will
+// result in compilation errors.
+type UnsafePharmacyServiceServer interface {
+	mustEmbedUnimplementedPharmacyServiceServer()
+}
+
+func RegisterPharmacyServiceServer(s grpc.ServiceRegistrar, srv PharmacyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPharmacyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PharmacyService_ServiceDesc, srv)
+}
+
+func _PharmacyService_GetPharmacy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPharmacyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PharmacyServiceServer).GetPharmacy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PharmacyService_GetPharmacy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PharmacyServiceServer).GetPharmacy(ctx, req.(*GetPharmacyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PharmacyService_ListPharmacies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPharmaciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PharmacyServiceServer).ListPharmacies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PharmacyService_ListPharmacies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PharmacyServiceServer).ListPharmacies(ctx, req.(*ListPharmaciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PharmacyService_ServiceDesc is the grpc.ServiceDesc for PharmacyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PharmacyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pharmacy.v1.PharmacyService",
+	HandlerType: (*PharmacyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPharmacy",
+			Handler:    _PharmacyService_GetPharmacy_Handler,
+		},
+		{
+			MethodName: "ListPharmacies",
+			Handler:    _PharmacyService_ListPharmacies_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pharmacy/v1/pharmacy.proto",
+}
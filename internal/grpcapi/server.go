@@ -0,0 +1,101 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/auth"
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi/pharmacyv1"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// ErrServerClosed is returned by ListenAndServe after Shutdown is called.
+var ErrServerClosed = errors.New("grpcapi: server closed")
+
+// Server serves the ClaimService and PharmacyService gRPC APIs. Every call is authenticated
+// with the bearer token of the HTTP API, sent in the "authorization" metadata.
+type Server struct {
+	addr   string
+	server *grpc.Server
+	claims *ClaimServer
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewServer creates a gRPC server listening on addr (e.g. ":9090").
+func NewServer(addr string, claimService service.ClaimService, pharmacyService service.PharmacyService, authenticator *auth.Authenticator, log logger.Logger) *Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(authenticator.UnaryInterceptor),
+		grpc.StreamInterceptor(authenticator.StreamInterceptor),
+	)
+
+	claims := NewClaimServer(claimService, log)
+	pharmacyv1.RegisterClaimServiceServer(server, claims)
+	pharmacyv1.RegisterPharmacyServiceServer(server, NewPharmacyServer(pharmacyService, log))
+	reflection.Register(server)
+
+	return &Server{addr: addr, server: server, claims: claims}
+}
+
+// ListenAndServe listens on the configured address and serves calls until Shutdown.
+func (s *Server) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve serves calls accepted on listener until Shutdown.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.mu.Unlock()
+
+	err := s.server.Serve(listener)
+	if errors.Is(err, grpc.ErrServerStopped) || s.isClosed() {
+		return ErrServerClosed
+	}
+	return err
+}
+
+// Shutdown ends the open WatchClaims streams, stops accepting calls and waits for the
+// in-flight calls to finish. Calls still running when ctx is done are cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.claims.closeWatchers()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
@@ -0,0 +1,224 @@
+package grpcapi_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/auth"
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi"
+	"github.com/diogocarasco/go-pharmacy-service/internal/grpcapi/pharmacyv1"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+const testToken = "secret"
+
+type testClients struct {
+	claims     pharmacyv1.ClaimServiceClient
+	pharmacies pharmacyv1.PharmacyServiceClient
+	repo       database.DBRepository
+}
+
+// startServer serves the gRPC API over an in-memory listener, backed by a SQLite database
+// with two registered pharmacies.
+func startServer(t *testing.T) testClients {
+	t.Helper()
+
+	dbRepo, err := database.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { dbRepo.Close() })
+	require.NoError(t, database.ApplyMigrations(dbRepo.(*database.SQLiteRepository).DB))
+	require.NoError(t, dbRepo.SavePharmacy(models.Pharmacy{Chain: "health", NPI: "1234567890"}))
+	require.NoError(t, dbRepo.SavePharmacy(models.Pharmacy{Chain: "saint", NPI: "2222222222"}))
+
+	log := logger.NewLogger()
+	server := grpcapi.NewServer("", service.NewClaimService(log, dbRepo, service.ClaimConfig{}), service.NewPharmacyService(log, dbRepo),
+		auth.NewAuthenticator(testToken, log), log)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return testClients{
+		claims:     pharmacyv1.NewClaimServiceClient(conn),
+		pharmacies: pharmacyv1.NewPharmacyServiceClient(conn),
+		repo:       dbRepo,
+	}
+}
+
+func authorized(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+testToken)
+}
+
+func TestUnauthenticatedCallsAreRejected(t *testing.T) {
+	clients := startServer(t)
+
+	_, err := clients.claims.GetClaim(context.Background(), &pharmacyv1.GetClaimRequest{Id: "claim-1"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	stream, err := clients.claims.WatchClaims(ctx, &pharmacyv1.WatchClaimsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestSubmitReverseAndGetClaim(t *testing.T) {
+	clients := startServer(t)
+	ctx := authorized(context.Background())
+
+	claim, err := clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "1234567890", Quantity: 30, Price: 100})
+	require.NoError(t, err)
+	assert.NotEmpty(t, claim.Id)
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status)
+
+	_, err = clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "9999999999", Quantity: 30, Price: 100})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	reversal, err := clients.claims.ReverseClaim(ctx, &pharmacyv1.ReverseClaimRequest{ClaimId: claim.Id, ReasonCode: models.ReversalReasonBilledInError})
+	require.NoError(t, err)
+	assert.Equal(t, claim.Id, reversal.ClaimId)
+
+	_, err = clients.claims.ReverseClaim(ctx, &pharmacyv1.ReverseClaimRequest{ClaimId: claim.Id, ReasonCode: models.ReversalReasonBilledInError})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	fetched, err := clients.claims.GetClaim(ctx, &pharmacyv1.GetClaimRequest{Id: claim.Id})
+	require.NoError(t, err)
+	assert.True(t, fetched.Reverted)
+	assert.Equal(t, models.ClaimStatusReversed, fetched.Status)
+
+	history, err := clients.repo.GetClaimStatusHistory(claim.Id)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "", history[0].FromStatus)
+	assert.Equal(t, models.ClaimStatusPaid, history[0].ToStatus)
+	assert.Equal(t, models.ClaimStatusPaid, history[1].FromStatus)
+	assert.Equal(t, models.ClaimStatusReversed, history[1].ToStatus)
+	assert.Equal(t, models.ActorGRPC, history[1].Actor)
+
+	_, err = clients.claims.GetClaim(ctx, &pharmacyv1.GetClaimRequest{Id: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestPartialReversals(t *testing.T) {
+	clients := startServer(t)
+	ctx := authorized(context.Background())
+
+	claim, err := clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "1234567890", Quantity: 30, Price: 90})
+	require.NoError(t, err)
+
+	reversal, err := clients.claims.ReverseClaim(ctx, &pharmacyv1.ReverseClaimRequest{ClaimId: claim.Id, ReasonCode: models.ReversalReasonBilledInError, Quantity: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, reversal.Quantity)
+	assert.Equal(t, 30.0, reversal.Amount)
+
+	fetched, err := clients.claims.GetClaim(ctx, &pharmacyv1.GetClaimRequest{Id: claim.Id})
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, fetched.Status)
+	assert.Equal(t, 20.0, fetched.OutstandingQuantity)
+	assert.Equal(t, 60.0, fetched.OutstandingAmount)
+
+	_, err = clients.claims.ReverseClaim(ctx, &pharmacyv1.ReverseClaimRequest{ClaimId: claim.Id, ReasonCode: models.ReversalReasonBilledInError, Amount: 61})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = clients.claims.ReverseClaim(ctx, &pharmacyv1.ReverseClaimRequest{ClaimId: claim.Id, ReasonCode: models.ReversalReasonBilledInError, Amount: 60})
+	require.NoError(t, err)
+
+	fetched, err = clients.claims.GetClaim(ctx, &pharmacyv1.GetClaimRequest{Id: claim.Id})
+	require.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusReversed, fetched.Status)
+	assert.Equal(t, 0.0, fetched.OutstandingQuantity)
+	assert.Equal(t, 0.0, fetched.OutstandingAmount)
+}
+
+func TestListClaimsPages(t *testing.T) {
+	clients := startServer(t)
+	ctx := authorized(context.Background())
+
+	for i := 0; i < 3; i++ {
+		_, err := clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "1234567890", Quantity: 1, Price: 10})
+		require.NoError(t, err)
+	}
+	_, err := clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "2222222222", Quantity: 1, Price: 10})
+	require.NoError(t, err)
+
+	first, err := clients.claims.ListClaims(ctx, &pharmacyv1.ListClaimsRequest{Npi: "1234567890", PageSize: 2})
+	require.NoError(t, err)
+	assert.Len(t, first.Claims, 2)
+	assert.EqualValues(t, 3, first.TotalSize)
+	require.NotEmpty(t, first.NextPageToken)
+
+	second, err := clients.claims.ListClaims(ctx, &pharmacyv1.ListClaimsRequest{Npi: "1234567890", PageSize: 2, PageToken: first.NextPageToken})
+	require.NoError(t, err)
+	assert.Len(t, second.Claims, 1)
+	assert.Empty(t, second.NextPageToken)
+
+	_, err = clients.claims.ListClaims(ctx, &pharmacyv1.ListClaimsRequest{PageToken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestWatchClaimsStreamsNewClaims(t *testing.T) {
+	clients := startServer(t)
+	ctx, cancel := context.WithTimeout(authorized(context.Background()), 5*time.Second)
+	defer cancel()
+
+	stream, err := clients.claims.WatchClaims(ctx, &pharmacyv1.WatchClaimsRequest{Npi: "1234567890"})
+	require.NoError(t, err)
+	// Wait for the response headers so the subscription exists before submitting.
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	_, err = clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "11111111111", Npi: "2222222222", Quantity: 1, Price: 10})
+	require.NoError(t, err)
+	submitted, err := clients.claims.SubmitClaim(ctx, &pharmacyv1.SubmitClaimRequest{Ndc: "00002323401", Npi: "1234567890", Quantity: 1, Price: 10})
+	require.NoError(t, err)
+
+	received, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, submitted.Id, received.Id, "claims of other pharmacies must be filtered out")
+}
+
+func TestPharmacies(t *testing.T) {
+	clients := startServer(t)
+	ctx := authorized(context.Background())
+
+	pharmacy, err := clients.pharmacies.GetPharmacy(ctx, &pharmacyv1.GetPharmacyRequest{Npi: "2222222222"})
+	require.NoError(t, err)
+	assert.Equal(t, "saint", pharmacy.Chain)
+
+	_, err = clients.pharmacies.GetPharmacy(ctx, &pharmacyv1.GetPharmacyRequest{Npi: "9999999999"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	all, err := clients.pharmacies.ListPharmacies(ctx, &pharmacyv1.ListPharmaciesRequest{})
+	require.NoError(t, err)
+	assert.Len(t, all.Pharmacies, 2)
+
+	health, err := clients.pharmacies.ListPharmacies(ctx, &pharmacyv1.ListPharmaciesRequest{Chain: "health"})
+	require.NoError(t, err)
+	require.Len(t, health.Pharmacies, 1)
+	assert.Equal(t, "1234567890", health.Pharmacies[0].Npi)
+}
@@ -2,26 +2,48 @@ package loader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
 
 	"github.com/diogocarasco/go-pharmacy-service/internal/database"
 	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
 )
 
 type ClaimLoader struct {
-	DBRepo database.DBRepository
+	DBRepo        database.DBRepository
+	Claims        service.ClaimService
+	Decoders      *DecoderRegistry
+	QuarantineDir string
 }
 
-func NewClaimLoader(dbRepo database.DBRepository) *ClaimLoader {
-	return &ClaimLoader{DBRepo: dbRepo}
+func NewClaimLoader(dbRepo database.DBRepository, claims service.ClaimService, decoders *DecoderRegistry, quarantineDir string) *ClaimLoader {
+	return &ClaimLoader{DBRepo: dbRepo, Claims: claims, Decoders: decoders, QuarantineDir: quarantineDir}
 }
 
-// LoadAndSaveClaimsFromDir reads all JSON files from a directory and saves them to the database.
+// NewClaimDecoderRegistry creates a decoder registry for claim files.
+func NewClaimDecoderRegistry(csvColumns map[string]string) *DecoderRegistry {
+	return NewDecoderRegistry(csvColumns, "quantity", "price", "fill_number", "days_supply")
+}
+
+// claimFileResult holds the decoded records of a single claims file and the records rejected.
+type claimFileResult struct {
+	name     string
+	total    int
+	claims   []models.Claim
+	indexes  []int             // Position of each decoded claim inside the file
+	records  []json.RawMessage // Original record of each decoded claim
+	rejected []models.QuarantinedClaim
+	loaded   int
+}
+
+// LoadAndSaveClaimsFromDir reads all supported files from a directory and loads their claims
+// through the claim service, which adjudicates them like submitted claims. Records refused by the
+// business rules are quarantined instead of saved.
 func (cl *ClaimLoader) LoadAndSaveClaimsFromDir(dirPath string) error {
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
@@ -34,45 +56,124 @@ func (cl *ClaimLoader) LoadAndSaveClaimsFromDir(dirPath string) error {
 	}
 
 	var allClaims []models.Claim
+	var results []*claimFileResult
 
 	for _, file := range files {
 		if file.IsDir() {
 			continue
 		}
 
-		if !strings.HasSuffix(file.Name(), ".json") {
-			log.Printf("INFO: Ignoring non-JSON file: %s/%s", absPath, file.Name())
+		if !cl.Decoders.Supports(file.Name()) {
+			log.Printf("INFO: Ignoring unsupported file: %s/%s", absPath, file.Name())
 			continue
 		}
 
 		filePath := filepath.Join(absPath, file.Name())
-		data, err := ioutil.ReadFile(filePath)
+		result, err := cl.readClaimsFile(filePath)
 		if err != nil {
-			log.Printf("ERROR: Error reading claims file %s: %v", filePath, err)
+			log.Printf("ERROR: %v", err)
 			continue
 		}
 
-		var claimsFromFile []models.Claim
-		if err := json.Unmarshal(data, &claimsFromFile); err != nil {
-			log.Printf("ERROR: Error decoding JSON from claims file %s: %v", filePath, err)
-			continue
-		}
-
-		log.Printf("INFO: Loaded %d claims from file: %s", len(claimsFromFile), file.Name())
-		allClaims = append(allClaims, claimsFromFile...)
+		log.Printf("INFO: Read %d claims from file: %s (%d malformed)", len(result.claims), file.Name(), len(result.rejected))
+		allClaims = append(allClaims, result.claims...)
+		results = append(results, result)
 	}
 
-	log.Printf("INFO: Finished loading claims from all JSON files. Total of %d records found.", len(allClaims))
+	log.Printf("INFO: Finished reading claims from all files. Total of %d records found.", len(allClaims))
 
+	var outcomes []error
+	var loadErr error
 	if len(allClaims) > 0 {
-		log.Println("INFO: Starting to save all claims to the database...")
-		if err := cl.DBRepo.SaveClaims(allClaims); err != nil {
-			return fmt.Errorf("error saving claims to the database: %w", err)
+		log.Println("INFO: Starting to load all claims to the database...")
+		if outcomes, loadErr = cl.Claims.LoadClaims(allClaims); loadErr == nil {
+			log.Println("INFO: All claims loaded to the database.")
 		}
-		log.Println("INFO: All claims saved successfully to the database.")
 	} else {
 		log.Println("INFO: No claims to save to the database.")
 	}
 
+	// Refused records are quarantined even when the paid ones could not be saved.
+	offset := 0
+	for _, result := range results {
+		if outcomes != nil {
+			result.applyOutcomes(outcomes[offset:offset+len(result.claims)], loadErr == nil)
+		}
+		offset += len(result.claims)
+		if _, err := quarantine(cl.DBRepo, cl.QuarantineDir, result.name, result.total, result.loaded, result.rejected); err != nil {
+			log.Printf("ERROR: %v", err)
+		}
+	}
+
+	if loadErr != nil {
+		return fmt.Errorf("error loading claims to the database: %w", loadErr)
+	}
 	return nil
 }
+
+// LoadAndSaveClaimsFromFile reads a single file and loads its claims through the claim service.
+// It returns the number of claims saved.
+func (cl *ClaimLoader) LoadAndSaveClaimsFromFile(filePath string) (int, error) {
+	result, err := cl.readClaimsFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	var loadErr error
+	if len(result.claims) > 0 {
+		var outcomes []error
+		outcomes, loadErr = cl.Claims.LoadClaims(result.claims)
+		if outcomes != nil {
+			result.applyOutcomes(outcomes, loadErr == nil)
+		}
+	}
+
+	// Refused records are quarantined even when the paid ones could not be saved.
+	_, quarantineErr := quarantine(cl.DBRepo, cl.QuarantineDir, result.name, result.total, result.loaded, result.rejected)
+	if loadErr != nil {
+		return 0, fmt.Errorf("error loading claims from file %s to the database: %w", filePath, loadErr)
+	}
+	if quarantineErr != nil {
+		return result.loaded, quarantineErr
+	}
+	return result.loaded, nil
+}
+
+// readClaimsFile decodes the records stored in filePath. Malformed records are rejected.
+func (cl *ClaimLoader) readClaimsFile(filePath string) (*claimFileResult, error) {
+	records, err := cl.Decoders.DecodeFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error loading claims file: %w", err)
+	}
+
+	result := &claimFileResult{name: filepath.Base(filePath), total: len(records)}
+	for i, record := range records {
+		var claim models.Claim
+		if err := json.Unmarshal(record, &claim); err != nil {
+			result.rejected = append(result.rejected, newQuarantinedClaim(result.name, i, "", "malformed record: "+err.Error(), record))
+			continue
+		}
+		result.claims = append(result.claims, claim)
+		result.indexes = append(result.indexes, i)
+		result.records = append(result.records, record)
+	}
+	return result, nil
+}
+
+// applyOutcomes counts the claims of the file saved by the claim service and rejects the ones it
+// refused, in file order. Claims already recorded are neither loaded nor rejected.
+func (r *claimFileResult) applyOutcomes(outcomes []error, saved bool) {
+	for j, outcome := range outcomes {
+		switch {
+		case outcome == nil:
+			if saved {
+				r.loaded++
+			}
+		case errors.Is(outcome, service.ErrClaimExists):
+			log.Printf("INFO: Skipping claim %s of %s: already recorded", r.claims[j].ID, r.name)
+		default:
+			r.rejected = append(r.rejected, newQuarantinedClaim(r.name, r.indexes[j], r.claims[j].ID, outcome.Error(), r.records[j]))
+		}
+	}
+	sort.Slice(r.rejected, func(a, b int) bool { return r.rejected[a].RecordIndex < r.rejected[b].RecordIndex })
+}
@@ -0,0 +1,153 @@
+package loader_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/loader"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// newTestRepository opens a migrated SQLite database in a temporary directory with one pharmacy.
+func newTestRepository(t *testing.T) *database.SQLiteRepository {
+	dbRepo, err := database.InitDB(filepath.Join(t.TempDir(), "pharmacy.db"))
+	if err != nil {
+		t.Fatalf("error opening test database: %v", err)
+	}
+	t.Cleanup(func() { dbRepo.Close() })
+
+	repo := dbRepo.(*database.SQLiteRepository)
+	if err := database.ApplyMigrations(repo.DB); err != nil {
+		t.Fatalf("error migrating test database: %v", err)
+	}
+	if err := repo.SavePharmacy(models.Pharmacy{NPI: "1234567890", Chain: "health"}); err != nil {
+		t.Fatalf("error saving test pharmacy: %v", err)
+	}
+	return repo
+}
+
+// failingClaimsRepository fails every claim save.
+type failingClaimsRepository struct {
+	database.DBRepository
+}
+
+func (r failingClaimsRepository) SaveClaims(claims []models.Claim) error {
+	return errors.New("disk full")
+}
+
+const quarantineTestClaims = `[
+  {"id": "c1", "ndc": "00002323401", "npi": "1234567890", "quantity": 2, "price": 10.5},
+  {"id": "c2", "ndc": "00002323401", "npi": "9999999999", "quantity": 1, "price": 4},
+  {"id": "c3", "ndc": "", "npi": "1234567890", "quantity": 1, "price": 4},
+  {"id": "c4", "ndc": "00002323401", "npi": "1234567890", "quantity": 1, "price": 4},
+  {"id": "c5", "ndc": "00002323401", "npi": "1234567890", "quantity": 1, "price": 4, "status": "bogus",
+   "pricing_basis": "contract", "allowed_amount": 99999, "patient_pay": 3},
+  {"id": "c6", "ndc": "00002323401", "npi": "1234567890", "quantity": 1, "price": 4, "date_of_service": "2020-01-01"}
+]`
+
+// newTestClaimLoader returns a claim loader adjudicating through a claim service that rejects
+// claims filed more than a year after their date of service.
+func newTestClaimLoader(dbRepo database.DBRepository, quarantineDir string) *loader.ClaimLoader {
+	claimService := service.NewClaimService(logger.NewLogger(), dbRepo, service.ClaimConfig{
+		TimelyFiling: service.TimelyFilingPolicy{Limit: 365 * 24 * time.Hour},
+	})
+	return loader.NewClaimLoader(dbRepo, claimService, loader.NewClaimDecoderRegistry(nil), quarantineDir)
+}
+
+func TestLoadClaimsQuarantine(t *testing.T) {
+	tests := []struct {
+		name       string
+		failSave   bool
+		wantLoaded int
+	}{
+		{name: "paid records saved", wantLoaded: 3},
+		{name: "save failure still quarantines", failSave: true, wantLoaded: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newTestRepository(t)
+			var dbRepo database.DBRepository = repo
+			if tt.failSave {
+				dbRepo = failingClaimsRepository{repo}
+			}
+			quarantineDir := filepath.Join(t.TempDir(), "quarantine")
+			claimLoader := newTestClaimLoader(dbRepo, quarantineDir)
+
+			loaded, err := claimLoader.LoadAndSaveClaimsFromFile(writeFile(t, "claims.json", []byte(quarantineTestClaims)))
+			if tt.failSave {
+				assert.NotNil(t, err, "Expected the save error to be returned")
+			} else {
+				assert.Nil(t, err, "Expected no error loading the claims file")
+			}
+			assert.Equal(t, tt.wantLoaded, loaded)
+
+			var rows int
+			assert.Nil(t, repo.DB.QueryRow("SELECT COUNT(*) FROM quarantined_claims WHERE source_file = 'claims.json'").Scan(&rows))
+			assert.Equal(t, 3, rows, "Expected the rejected records in the quarantine table")
+
+			data, err := os.ReadFile(filepath.Join(quarantineDir, "claims.rejected.ndjson"))
+			assert.Nil(t, err, "Expected a rejected records file")
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			if assert.Len(t, lines, 3) {
+				var first struct {
+					RecordIndex int             `json:"record_index"`
+					ClaimID     string          `json:"claim_id"`
+					Reason      string          `json:"reason"`
+					Record      json.RawMessage `json:"record"`
+				}
+				assert.Nil(t, json.Unmarshal([]byte(lines[0]), &first))
+				assert.Equal(t, 1, first.RecordIndex)
+				assert.Equal(t, "c2", first.ClaimID)
+				assert.NotEmpty(t, first.Reason)
+				assert.Contains(t, string(first.Record), "9999999999")
+			}
+
+			data, err = os.ReadFile(filepath.Join(quarantineDir, "claims.summary.json"))
+			assert.Nil(t, err, "Expected a load summary file")
+			var summary models.LoadSummary
+			assert.Nil(t, json.Unmarshal(data, &summary))
+			assert.Equal(t, "claims.json", summary.SourceFile)
+			assert.Equal(t, 6, summary.Total)
+			assert.Equal(t, tt.wantLoaded, summary.Loaded)
+			assert.Equal(t, 3, summary.Rejected, "Expected the unknown NPI, the missing NDC and the late claim quarantined")
+			assert.Len(t, summary.Reasons, 3)
+		})
+	}
+}
+
+func TestLoadClaimsIgnoresRecordedOutcome(t *testing.T) {
+	repo := newTestRepository(t)
+	claimLoader := newTestClaimLoader(repo, filepath.Join(t.TempDir(), "quarantine"))
+	path := writeFile(t, "claims.json", []byte(quarantineTestClaims))
+
+	loaded, err := claimLoader.LoadAndSaveClaimsFromFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, loaded)
+
+	claim, err := repo.GetClaimByID("c5")
+	assert.Nil(t, err)
+	if assert.NotNil(t, claim) {
+		assert.Equal(t, models.ClaimStatusPaid, claim.Status, "Expected the status of the record ignored")
+		assert.InDelta(t, 4, claim.AllowedAmount, 0.001, "Expected the allowed amount of the record ignored")
+		assert.Equal(t, models.PricingBasisSubmitted, claim.PricingBasis)
+		assert.Zero(t, claim.PatientPay, "Expected the patient pay of the record ignored")
+	}
+	claim, err = repo.GetClaimByID("c6")
+	assert.Nil(t, err)
+	assert.Nil(t, claim, "Expected a claim rejected at adjudication not to be saved")
+
+	loaded, err = claimLoader.LoadAndSaveClaimsFromFile(path)
+	assert.Nil(t, err, "Expected no error loading the claims file again")
+	assert.Equal(t, 0, loaded, "Expected recorded claims to be skipped")
+}
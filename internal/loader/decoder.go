@@ -0,0 +1,263 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const gzipExt = ".gz"
+
+// RecordDecoder decodes the content of an input file into raw JSON objects, one per record.
+// Decoding to raw records lets the claim and revert loaders share every input format.
+type RecordDecoder interface {
+	Decode(r io.Reader) ([]json.RawMessage, error)
+}
+
+// DecoderRegistry selects a RecordDecoder by file extension, falling back to content sniffing.
+// Files ending in ".gz" (or starting with the gzip magic bytes) are decompressed first.
+type DecoderRegistry struct {
+	decoders map[string]RecordDecoder
+}
+
+// NewDecoderRegistry creates a registry with the JSON, NDJSON and CSV decoders registered.
+// csvColumns maps CSV header names to record keys; numericKeys lists the record keys whose
+// CSV values must be decoded as numbers.
+func NewDecoderRegistry(csvColumns map[string]string, numericKeys ...string) *DecoderRegistry {
+	r := &DecoderRegistry{decoders: make(map[string]RecordDecoder)}
+	r.Register(".json", JSONDecoder{})
+	r.Register(".ndjson", NDJSONDecoder{})
+	r.Register(".csv", NewCSVDecoder(csvColumns, numericKeys...))
+	return r
+}
+
+// Register adds or replaces the decoder used for files with the given extension (e.g. ".json").
+func (r *DecoderRegistry) Register(ext string, decoder RecordDecoder) {
+	r.decoders[strings.ToLower(ext)] = decoder
+}
+
+// Supports reports whether a file name has an extension handled by the registry.
+func (r *DecoderRegistry) Supports(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasSuffix(lower, gzipExt) {
+		lower = strings.TrimSuffix(lower, gzipExt)
+		if filepath.Ext(lower) == "" {
+			return true // Sniffed after decompression
+		}
+	}
+	_, ok := r.decoders[filepath.Ext(lower)]
+	return ok
+}
+
+// DecodeFile opens filePath, decompresses it if needed and decodes its records.
+func (r *DecoderRegistry) DecodeFile(filePath string) ([]json.RawMessage, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	name := strings.ToLower(filepath.Base(filePath))
+
+	var content io.Reader = reader
+	magic, _ := reader.Peek(2)
+	if strings.HasSuffix(name, gzipExt) || bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, fmt.Errorf("error opening gzip file %s: %w", filePath, err)
+		}
+		defer gz.Close()
+		content = gz
+		name = strings.TrimSuffix(name, gzipExt)
+	}
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+
+	decoder, ok := r.decoders[filepath.Ext(name)]
+	if !ok {
+		decoder = r.sniff(data)
+	}
+
+	records, err := decoder.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding file %s: %w", filePath, err)
+	}
+	return records, nil
+}
+
+// sniff picks a decoder from the first non-blank byte of the content.
+func (r *DecoderRegistry) sniff(data []byte) RecordDecoder {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		return r.decoders[".json"]
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		return r.decoders[".ndjson"]
+	default:
+		return r.decoders[".csv"]
+	}
+}
+
+// JSONDecoder decodes a single top-level JSON array of records.
+// Content starting with an object is treated as newline-delimited JSON.
+type JSONDecoder struct{}
+
+func (JSONDecoder) Decode(r io.Reader) ([]json.RawMessage, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return NDJSONDecoder{}.Decode(bytes.NewReader(trimmed))
+	}
+
+	var records []json.RawMessage
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("error decoding JSON array: %w", err)
+	}
+	return records, nil
+}
+
+// NDJSONDecoder decodes newline-delimited JSON, one record per non-blank line.
+type NDJSONDecoder struct{}
+
+func (NDJSONDecoder) Decode(r io.Reader) ([]json.RawMessage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	var records []json.RawMessage
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			return nil, fmt.Errorf("invalid JSON on line %d", lineNumber)
+		}
+		records = append(records, json.RawMessage(append([]byte(nil), line...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading NDJSON: %w", err)
+	}
+	return records, nil
+}
+
+// CSVDecoder decodes CSV files with a header row into JSON objects.
+type CSVDecoder struct {
+	columns     map[string]string
+	numericKeys map[string]bool
+}
+
+// NewCSVDecoder creates a CSV decoder. Header names missing from columns are used as keys as-is.
+func NewCSVDecoder(columns map[string]string, numericKeys ...string) *CSVDecoder {
+	d := &CSVDecoder{
+		columns:     make(map[string]string),
+		numericKeys: make(map[string]bool),
+	}
+	for header, key := range columns {
+		d.columns[strings.ToLower(strings.TrimSpace(header))] = key
+	}
+	for _, key := range numericKeys {
+		d.numericKeys[key] = true
+	}
+	return d
+}
+
+func (d *CSVDecoder) Decode(r io.Reader) ([]json.RawMessage, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %w", err)
+	}
+
+	keys := make([]string, len(header))
+	for i, name := range header {
+		normalized := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if key, ok := d.columns[normalized]; ok {
+			keys[i] = key
+		} else {
+			keys[i] = normalized
+		}
+	}
+
+	var records []json.RawMessage
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV row: %w", err)
+		}
+
+		record := make(map[string]interface{}, len(row))
+		for i, value := range row {
+			if i >= len(keys) || keys[i] == "" {
+				continue
+			}
+			record[keys[i]] = d.convert(keys[i], value)
+		}
+
+		encoded, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding CSV row: %w", err)
+		}
+		records = append(records, encoded)
+	}
+	return records, nil
+}
+
+// convert turns a CSV cell into a JSON value. Numeric keys that fail to parse are kept
+// as strings so the record is rejected by validation instead of failing the whole file.
+func (d *CSVDecoder) convert(key, value string) interface{} {
+	value = strings.TrimSpace(value)
+	if !d.numericKeys[key] {
+		return value
+	}
+	if value == "" {
+		return nil
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+	return number
+}
+
+// ParseColumnMapping parses a mapping such as "qty=quantity,amount=price" into a map
+// from CSV header names to record keys.
+func ParseColumnMapping(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid column mapping entry '%s', expected header=key", pair)
+		}
+		mapping[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return mapping, nil
+}
@@ -0,0 +1,136 @@
+package loader_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/loader"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("error writing test file: %v", err)
+	}
+	return path
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatalf("error compressing test data: %v", err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+func decodeClaims(t *testing.T, registry *loader.DecoderRegistry, path string) []models.Claim {
+	records, err := registry.DecodeFile(path)
+	assert.Nil(t, err, "Expected no error decoding %s", path)
+
+	claims := make([]models.Claim, len(records))
+	for i, record := range records {
+		assert.Nil(t, json.Unmarshal(record, &claims[i]), "Expected record %d to decode into a claim", i)
+	}
+	return claims
+}
+
+func TestDecodeClaimFormats(t *testing.T) {
+	registry := loader.NewClaimDecoderRegistry(nil)
+
+	jsonData := []byte(`[{"id":"a","ndc":"00002323401","npi":"1234567890","quantity":2,"price":10.5}]`)
+	ndjsonData := []byte("{\"id\":\"a\",\"ndc\":\"00002323401\",\"npi\":\"1234567890\",\"quantity\":2,\"price\":10.5}\n\n")
+	csvData := []byte("id,ndc,npi,quantity,price\na,00002323401,1234567890,2,10.5\n")
+
+	paths := []string{
+		writeFile(t, "claims.json", jsonData),
+		writeFile(t, "claims.ndjson", ndjsonData),
+		writeFile(t, "claims.csv", csvData),
+		writeFile(t, "claims.json.gz", gzipBytes(t, jsonData)),
+		writeFile(t, "claims.ndjson.gz", gzipBytes(t, ndjsonData)),
+		writeFile(t, "claims.csv.gz", gzipBytes(t, csvData)),
+		writeFile(t, "claims.gz", gzipBytes(t, ndjsonData)),
+	}
+
+	for _, path := range paths {
+		claims := decodeClaims(t, registry, path)
+		assert.Len(t, claims, 1, "Expected one claim from %s", path)
+		if len(claims) == 1 {
+			assert.Equal(t, "00002323401", claims[0].NDC, "NDC should be preserved for %s", path)
+			assert.Equal(t, 2.0, claims[0].Quantity, "Quantity should be numeric for %s", path)
+			assert.Equal(t, 10.5, claims[0].Price, "Price should be numeric for %s", path)
+		}
+	}
+}
+
+func TestDecodeClaimCSVColumnMapping(t *testing.T) {
+	columns, err := loader.ParseColumnMapping("claim_id=id, drug = ndc,pharmacy=npi,qty=quantity,amount=price")
+	assert.Nil(t, err, "Expected a valid column mapping")
+
+	registry := loader.NewClaimDecoderRegistry(columns)
+	path := writeFile(t, "partner.csv", []byte("Claim_ID,Drug,Pharmacy,Qty,Amount\nb,00054027225,0987654321,30,99.9\n"))
+
+	claims := decodeClaims(t, registry, path)
+	assert.Len(t, claims, 1, "Expected one claim from the mapped CSV")
+	if len(claims) == 1 {
+		assert.Equal(t, models.Claim{ID: "b", NDC: "00054027225", NPI: "0987654321", Quantity: 30, Price: 99.9}, claims[0])
+	}
+}
+
+func TestDecodeClaimCSVPrescription(t *testing.T) {
+	registry := loader.NewClaimDecoderRegistry(nil)
+	path := writeFile(t, "claims.csv", []byte("id,ndc,npi,quantity,price,member_id,rx_number,fill_number,days_supply,date_of_service,daw_code\n"+
+		"c,00002323401,1234567890,30,45,M1001,000123,0,30,2024-02-01,0\n"+
+		"d,00002323401,1234567890,30,45,,,,,,\n"))
+
+	claims := decodeClaims(t, registry, path)
+	assert.Len(t, claims, 2, "Expected two claims from the CSV")
+	if len(claims) == 2 {
+		assert.Equal(t, models.Prescription{MemberID: "M1001", RxNumber: "000123", DaysSupply: 30, DateOfService: "2024-02-01", DAWCode: "0"}, claims[0].Prescription)
+		assert.Equal(t, models.Prescription{}, claims[1].Prescription, "Empty prescription columns should be left unset")
+	}
+}
+
+func TestDecodeRevertCSV(t *testing.T) {
+	columns, err := loader.ParseColumnMapping("revert_id=id,qty=quantity")
+	assert.Nil(t, err, "Expected a valid column mapping")
+
+	registry := loader.NewRevertDecoderRegistry(columns)
+	path := writeFile(t, "reverts.csv", []byte("revert_id,claim_id,timestamp,qty,amount\n"+
+		"r1,c1,2024-03-01T10:00:00,1.5,7.25\n"+
+		"r2,c2,2024-03-01T10:00:00,,\n"))
+
+	records, err := registry.DecodeFile(path)
+	assert.Nil(t, err, "Expected no error decoding %s", path)
+	reverts := make([]models.Revert, len(records))
+	for i, record := range records {
+		assert.Nil(t, json.Unmarshal(record, &reverts[i]), "Expected record %d to decode into a revert", i)
+	}
+	assert.Equal(t, []models.Revert{
+		{ID: "r1", ClaimID: "c1", Timestamp: "2024-03-01T10:00:00", Quantity: 1.5, Amount: 7.25},
+		{ID: "r2", ClaimID: "c2", Timestamp: "2024-03-01T10:00:00"},
+	}, reverts, "Expected numeric quantity and amount, left unset when empty")
+}
+
+func TestParseColumnMappingInvalid(t *testing.T) {
+	_, err := loader.ParseColumnMapping("qty")
+	assert.NotNil(t, err, "Expected an error for a mapping entry without '='")
+}
+
+func TestDecoderRegistrySupports(t *testing.T) {
+	registry := loader.NewClaimDecoderRegistry(nil)
+
+	assert.True(t, registry.Supports("claims.CSV"))
+	assert.True(t, registry.Supports("claims.ndjson.gz"))
+	assert.True(t, registry.Supports("claims.gz"))
+	assert.False(t, registry.Supports("claims.txt"))
+	assert.False(t, registry.Supports("claims.txt.gz"))
+}
@@ -0,0 +1,213 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/metrics"
+)
+
+const (
+	processedDirName = "processed"
+	failedDirName    = "failed"
+	errorSidecarExt  = ".error"
+)
+
+// FileIngestFunc ingests a single file and returns the number of records saved.
+type FileIngestFunc func(filePath string) (int, error)
+
+// fileState is the last observed size and modification time of a pending file.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// DirWatcher polls a drop folder and ingests files dropped while the service runs.
+// A file is only ingested once its size and modification time are unchanged between
+// two consecutive polls, so partially written files are left alone.
+// Ingested files are moved to "processed/", failed ones to "failed/" next to an ".error" sidecar.
+type DirWatcher struct {
+	kind     string
+	dirPath  string
+	interval time.Duration
+	ingest   FileIngestFunc
+	known    map[string]bool
+	pending  map[string]fileState
+	done     chan struct{}
+}
+
+// NewDirWatcher creates a watcher for dirPath. kind labels logs and metrics (e.g. "claims").
+func NewDirWatcher(kind, dirPath string, interval time.Duration, ingest FileIngestFunc) *DirWatcher {
+	return &DirWatcher{
+		kind:     kind,
+		dirPath:  dirPath,
+		interval: interval,
+		ingest:   ingest,
+		known:    make(map[string]bool),
+		pending:  make(map[string]fileState),
+		done:     make(chan struct{}),
+	}
+}
+
+// IngestExisting ingests and archives every file already in the directory, in name order, without
+// waiting for them to be stable. It is meant to be called once at startup, before Run, so that the
+// files left from a previous run are not loaded again on the next one and the files of one watcher
+// can be ingested before those of another.
+func (w *DirWatcher) IngestExisting() error {
+	entries, err := os.ReadDir(w.dirPath)
+	if err != nil {
+		return fmt.Errorf("error reading %s directory %s: %w", w.kind, w.dirPath, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		w.ingestFile(entry.Name())
+	}
+	return nil
+}
+
+// Run watches the directory until ctx is cancelled. Files already present when Run starts are
+// ingested like dropped files once stable, unless IngestExisting handled them first.
+// A file being ingested when ctx is cancelled is finished before Run returns.
+func (w *DirWatcher) Run(ctx context.Context) error {
+	defer close(w.done)
+
+	for _, dir := range []string{processedDirName, failedDirName} {
+		if err := os.MkdirAll(filepath.Join(w.dirPath, dir), 0755); err != nil {
+			return fmt.Errorf("error creating %s directory for %s watcher: %w", dir, w.kind, err)
+		}
+	}
+
+	log.Printf("INFO: Watching %s directory %s every %s", w.kind, w.dirPath, w.interval)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("INFO: Stopping %s directory watcher.", w.kind)
+			return nil
+		case <-ticker.C:
+			w.poll()
+		}
+	}
+}
+
+// Done returns a channel closed once Run has returned.
+func (w *DirWatcher) Done() <-chan struct{} {
+	return w.done
+}
+
+// poll scans the directory once and ingests every file that has stopped changing.
+func (w *DirWatcher) poll() {
+	entries, err := os.ReadDir(w.dirPath)
+	if err != nil {
+		log.Printf("ERROR: Error reading %s directory %s: %v", w.kind, w.dirPath, err)
+		return
+	}
+
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		present[name] = true
+		if w.known[name] {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		current := fileState{size: info.Size(), modTime: info.ModTime()}
+
+		previous, seen := w.pending[name]
+		if !seen || previous != current {
+			w.pending[name] = current
+			continue
+		}
+
+		delete(w.pending, name)
+		w.ingestFile(name)
+	}
+
+	for name := range w.known {
+		if !present[name] {
+			delete(w.known, name)
+		}
+	}
+	for name := range w.pending {
+		if !present[name] {
+			delete(w.pending, name)
+		}
+	}
+}
+
+// ingestFile runs the ingest function and moves the file according to the outcome.
+func (w *DirWatcher) ingestFile(name string) {
+	rows, err := w.ingest(filepath.Join(w.dirPath, name))
+	if err != nil {
+		log.Printf("ERROR: Error ingesting %s file %s: %v", w.kind, name, err)
+		metrics.IngestedFilesTotal.WithLabelValues(w.kind, "failed").Inc()
+	} else {
+		metrics.IngestedFilesTotal.WithLabelValues(w.kind, "processed").Inc()
+		metrics.IngestedRowsTotal.WithLabelValues(w.kind).Add(float64(rows))
+		log.Printf("INFO: Ingested %d %s from file %s", rows, w.kind, name)
+	}
+
+	if archiveErr := archiveFile(w.dirPath, name, err); archiveErr != nil {
+		log.Printf("ERROR: %v", archiveErr)
+		w.known[name] = true
+	}
+}
+
+// archiveFile moves an ingested file to the "processed/" directory, or to "failed/" next to an
+// ".error" sidecar when ingestErr is not nil. A file archived earlier under the same name is kept:
+// the new one gets the first free ".<n>" suffix.
+func archiveFile(dirPath, name string, ingestErr error) error {
+	target := processedDirName
+	if ingestErr != nil {
+		target = failedDirName
+	}
+	if err := os.MkdirAll(filepath.Join(dirPath, target), 0755); err != nil {
+		return fmt.Errorf("error creating %s directory in %s: %w", target, dirPath, err)
+	}
+
+	archivedPath := filepath.Join(dirPath, target, name)
+	for n := 1; archived(archivedPath); n++ {
+		archivedPath = filepath.Join(dirPath, target, fmt.Sprintf("%s.%d", name, n))
+	}
+	if err := os.Rename(filepath.Join(dirPath, name), archivedPath); err != nil {
+		return fmt.Errorf("error moving file %s to %s: %w", name, target, err)
+	}
+	if base := filepath.Base(archivedPath); base != name {
+		log.Printf("INFO: File %s archived in %s as %s, a file of the same name was archived before", name, target, base)
+	}
+
+	if ingestErr != nil {
+		sidecar := fmt.Sprintf("%s\n%s\n", time.Now().Format("2006-01-02T15:04:05"), ingestErr.Error())
+		if err := os.WriteFile(archivedPath+errorSidecarExt, []byte(sidecar), 0644); err != nil {
+			return fmt.Errorf("error writing error sidecar for file %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// archived reports whether an archived file, or its ".error" sidecar, exists at path.
+func archived(path string) bool {
+	for _, p := range []string{path, path + errorSidecarExt} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			return true
+		}
+	}
+	return false
+}
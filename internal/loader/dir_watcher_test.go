@@ -0,0 +1,183 @@
+package loader_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/loader"
+)
+
+const testWatchInterval = 20 * time.Millisecond
+
+// recordingIngest records the size of every file it ingests and fails with err when not nil.
+type recordingIngest struct {
+	mu    sync.Mutex
+	sizes map[string]int64
+	err   error
+}
+
+func (r *recordingIngest) ingest(filePath string) (int, error) {
+	info, statErr := os.Stat(filePath)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if statErr == nil {
+		r.sizes[filepath.Base(filePath)] = info.Size()
+	}
+	return 1, r.err
+}
+
+func (r *recordingIngest) size(name string) (int64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size, ok := r.sizes[name]
+	return size, ok
+}
+
+func TestDirWatcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingestErr   error
+		preexisting bool
+		appends     int
+		wantDir     string
+	}{
+		{name: "ingested file moves to processed", wantDir: "processed"},
+		{name: "failed file moves to failed with sidecar", ingestErr: errors.New("bad record 3"), wantDir: "failed"},
+		{name: "file still being written waits until stable", appends: 10, wantDir: "processed"},
+		{name: "file present at startup is ingested once stable", preexisting: true, wantDir: "processed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "claims.json")
+			if tt.preexisting {
+				assert.Nil(t, os.WriteFile(path, []byte("[]"), 0644))
+			}
+
+			recorder := &recordingIngest{sizes: make(map[string]int64), err: tt.ingestErr}
+			watcher := loader.NewDirWatcher("claims", dir, testWatchInterval, recorder.ingest)
+			ctx, cancel := context.WithCancel(context.Background())
+			go watcher.Run(ctx)
+			defer func() {
+				cancel()
+				<-watcher.Done()
+			}()
+
+			wantSize := int64(2)
+			if !tt.preexisting {
+				time.Sleep(testWatchInterval / 2)
+				assert.Nil(t, os.WriteFile(path, []byte("[]"), 0644))
+				for i := 0; i < tt.appends; i++ {
+					time.Sleep(testWatchInterval / 4)
+					file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+					assert.Nil(t, err)
+					file.WriteString(" ")
+					file.Close()
+				}
+				wantSize += int64(tt.appends)
+			}
+
+			archived := filepath.Join(dir, tt.wantDir, "claims.json")
+			assert.Eventually(t, func() bool {
+				_, err := os.Stat(archived)
+				return err == nil
+			}, time.Second, testWatchInterval, "Expected the file to be moved to %s/", tt.wantDir)
+
+			size, _ := recorder.size("claims.json")
+			assert.Equal(t, wantSize, size, "Expected the file to be ingested once fully written")
+			_, err := os.Stat(path)
+			assert.True(t, os.IsNotExist(err), "Expected the file to leave the drop folder")
+
+			sidecar, err := os.ReadFile(archived + ".error")
+			if tt.ingestErr != nil {
+				assert.Nil(t, err, "Expected an .error sidecar")
+				assert.True(t, strings.Contains(string(sidecar), tt.ingestErr.Error()))
+			} else {
+				assert.True(t, os.IsNotExist(err), "Expected no .error sidecar")
+			}
+		})
+	}
+}
+
+func TestDirWatcherDoneWaitsForIngest(t *testing.T) {
+	dir := t.TempDir()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	watcher := loader.NewDirWatcher("claims", dir, testWatchInterval, func(filePath string) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go watcher.Run(ctx)
+
+	time.Sleep(testWatchInterval / 2)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "claims.json"), []byte("[]"), 0644))
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the file to be ingested")
+	}
+
+	cancel()
+	select {
+	case <-watcher.Done():
+		t.Fatal("Expected Done to wait for the file being ingested")
+	case <-time.After(3 * testWatchInterval):
+	}
+
+	close(release)
+	select {
+	case <-watcher.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected Done to be closed once the ingest finished")
+	}
+	_, err := os.Stat(filepath.Join(dir, "processed", "claims.json"))
+	assert.Nil(t, err, "Expected the in-flight file to be archived before Done")
+}
+
+func TestDirWatcherIngestExisting(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "claims.json"), []byte("[]"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("[]"), 0644))
+
+	recorder := &recordingIngest{sizes: make(map[string]int64)}
+	watcher := loader.NewDirWatcher("claims", dir, testWatchInterval, recorder.ingest)
+	assert.Nil(t, watcher.IngestExisting())
+
+	_, ingested := recorder.size("claims.json")
+	assert.True(t, ingested, "Expected a file present at startup to be ingested right away")
+	_, err := os.Stat(filepath.Join(dir, "processed", "claims.json"))
+	assert.Nil(t, err, "Expected the file to be archived so a restart does not load it again")
+	_, ingested = recorder.size(".hidden")
+	assert.False(t, ingested, "Expected hidden files to be ignored")
+}
+
+func TestDirWatcherKeepsEarlierArchives(t *testing.T) {
+	dir := t.TempDir()
+	recorder := &recordingIngest{sizes: make(map[string]int64), err: errors.New("bad record")}
+	watcher := loader.NewDirWatcher("claims", dir, testWatchInterval, recorder.ingest)
+
+	for _, content := range []string{"[1]", "[2]", "[3]"} {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "claims.json"), []byte(content), 0644))
+		assert.Nil(t, watcher.IngestExisting())
+	}
+
+	for i, name := range []string{"claims.json", "claims.json.1", "claims.json.2"} {
+		data, err := os.ReadFile(filepath.Join(dir, "failed", name))
+		assert.Nil(t, err, "Expected %s archived", name)
+		assert.Equal(t, fmt.Sprintf("[%d]", i+1), string(data), "Expected an earlier file of the same name not to be overwritten")
+		_, err = os.Stat(filepath.Join(dir, "failed", name+".error"))
+		assert.Nil(t, err, "Expected the sidecar of %s kept", name)
+	}
+}
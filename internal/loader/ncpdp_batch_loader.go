@@ -0,0 +1,90 @@
+package loader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/ncpdp"
+)
+
+const batchResponseExt = ".rsp"
+
+// NCPDPBatchLoader replays NCPDP Batch Standard files through the D.0 processor.
+// Unlike claim files, batch transactions are not idempotent, so every file is moved to
+// "processed/" or "failed/" once handled and is never replayed twice.
+type NCPDPBatchLoader struct {
+	Processor   *ncpdp.Processor
+	ResponseDir string
+}
+
+func NewNCPDPBatchLoader(processor *ncpdp.Processor, responseDir string) *NCPDPBatchLoader {
+	return &NCPDPBatchLoader{Processor: processor, ResponseDir: responseDir}
+}
+
+// LoadBatchesFromDir replays every batch file waiting in a directory.
+func (bl *NCPDPBatchLoader) LoadBatchesFromDir(dirPath string) error {
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return fmt.Errorf("error obtaining absolute path of NCPDP batch directory %s: %w", dirPath, err)
+	}
+
+	files, err := os.ReadDir(absPath)
+	if err != nil {
+		return fmt.Errorf("error reading NCPDP batch directory %s: %w", absPath, err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		count, err := bl.LoadBatchFile(filepath.Join(absPath, file.Name()))
+		if err != nil {
+			log.Printf("ERROR: %v", err)
+		} else {
+			log.Printf("INFO: Replayed %d NCPDP transactions from batch file: %s", count, file.Name())
+		}
+
+		if archiveErr := archiveFile(absPath, file.Name(), err); archiveErr != nil {
+			log.Printf("ERROR: %v", archiveErr)
+		}
+	}
+
+	return nil
+}
+
+// LoadBatchFile validates a batch file, replays its transactions and writes the response batch
+// to the response directory. It returns the number of transactions replayed.
+// No transaction is replayed when the header or trailer is invalid.
+func (bl *NCPDPBatchLoader) LoadBatchFile(filePath string) (int, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("error reading NCPDP batch file %s: %w", filePath, err)
+	}
+
+	batch, err := ncpdp.ParseBatch(data)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing NCPDP batch file %s: %w", filePath, err)
+	}
+	if batch.Header.TransmissionType != ncpdp.BatchTransaction {
+		return 0, fmt.Errorf("NCPDP batch file %s has transmission type '%s', expected '%s'", filePath, batch.Header.TransmissionType, ncpdp.BatchTransaction)
+	}
+
+	response := bl.Processor.ProcessBatch(batch)
+
+	if err := os.MkdirAll(bl.ResponseDir, 0755); err != nil {
+		return len(batch.Details), fmt.Errorf("error creating NCPDP response directory %s: %w", bl.ResponseDir, err)
+	}
+	name := filepath.Base(filePath)
+	responsePath := filepath.Join(bl.ResponseDir, strings.TrimSuffix(name, filepath.Ext(name))+batchResponseExt)
+	if err := os.WriteFile(responsePath, response.Encode(), 0644); err != nil {
+		return len(batch.Details), fmt.Errorf("error writing NCPDP batch response %s: %w", responsePath, err)
+	}
+
+	log.Printf("INFO: NCPDP batch %s from %s processed, response written to %s", batch.Header.BatchNumber, batch.Header.SenderID, responsePath)
+	return len(batch.Details), nil
+}
@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// quarantineLine is the NDJSON representation of a rejected record.
+type quarantineLine struct {
+	RecordIndex int             `json:"record_index"`
+	ClaimID     string          `json:"claim_id,omitempty"`
+	Reason      string          `json:"reason"`
+	Record      json.RawMessage `json:"record"`
+}
+
+func newQuarantinedClaim(sourceFile string, index int, claimID, reason string, record json.RawMessage) models.QuarantinedClaim {
+	return models.QuarantinedClaim{
+		SourceFile:  sourceFile,
+		RecordIndex: index,
+		ClaimID:     claimID,
+		Reason:      reason,
+		Payload:     string(record),
+		Timestamp:   time.Now().Format("2006-01-02T15:04:05"),
+	}
+}
+
+// quarantine stores the rejected records of a file in the quarantine table and writes
+// "<file>.rejected.ndjson" and "<file>.summary.json" to the quarantine directory. total is the
+// number of records of the file and loaded the number of them actually saved.
+func quarantine(dbRepo database.DBRepository, quarantineDir, name string, total, loaded int, rejected []models.QuarantinedClaim) (*models.LoadSummary, error) {
+	summary := &models.LoadSummary{
+		SourceFile: name,
+		Total:      total,
+		Loaded:     loaded,
+		Rejected:   len(rejected),
+		Reasons:    make(map[string]int),
+	}
+	for _, record := range rejected {
+		summary.Reasons[record.Reason]++
+	}
+
+	log.Printf("INFO: Load summary for %s: %d total, %d loaded, %d rejected", summary.SourceFile, summary.Total, summary.Loaded, summary.Rejected)
+
+	if len(rejected) > 0 {
+		if err := dbRepo.SaveQuarantinedClaims(rejected); err != nil {
+			return summary, fmt.Errorf("error saving quarantined claims from %s: %w", name, err)
+		}
+	}
+
+	if quarantineDir == "" {
+		return summary, nil
+	}
+	if err := os.MkdirAll(quarantineDir, 0755); err != nil {
+		return summary, fmt.Errorf("error creating quarantine directory %s: %w", quarantineDir, err)
+	}
+
+	base := strings.TrimSuffix(name, gzipExt)
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+	if len(rejected) > 0 {
+		var sb strings.Builder
+		for _, record := range rejected {
+			line, err := json.Marshal(quarantineLine{
+				RecordIndex: record.RecordIndex,
+				ClaimID:     record.ClaimID,
+				Reason:      record.Reason,
+				Record:      json.RawMessage(record.Payload),
+			})
+			if err != nil {
+				return summary, fmt.Errorf("error encoding quarantined record %d of %s: %w", record.RecordIndex, name, err)
+			}
+			sb.Write(line)
+			sb.WriteByte('\n')
+		}
+		ndjsonPath := filepath.Join(quarantineDir, base+".rejected.ndjson")
+		if err := os.WriteFile(ndjsonPath, []byte(sb.String()), 0644); err != nil {
+			return summary, fmt.Errorf("error writing quarantine file %s: %w", ndjsonPath, err)
+		}
+	}
+
+	summaryData, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return summary, fmt.Errorf("error encoding load summary for %s: %w", name, err)
+	}
+	summaryPath := filepath.Join(quarantineDir, base+".summary.json")
+	if err := os.WriteFile(summaryPath, summaryData, 0644); err != nil {
+		return summary, fmt.Errorf("error writing load summary %s: %w", summaryPath, err)
+	}
+	return summary, nil
+}
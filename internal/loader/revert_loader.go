@@ -2,26 +2,37 @@ package loader
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/diogocarasco/go-pharmacy-service/internal/database"
 	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
 )
 
 type RevertLoader struct {
-	DBRepo database.DBRepository
+	DBRepo        database.DBRepository
+	Policy        service.ReversalPolicy
+	Decoders      *DecoderRegistry
+	QuarantineDir string
 }
 
-func NewRevertLoader(dbRepo database.DBRepository) *RevertLoader {
-	return &RevertLoader{DBRepo: dbRepo}
+func NewRevertLoader(dbRepo database.DBRepository, policy service.ReversalPolicy, decoders *DecoderRegistry, quarantineDir string) *RevertLoader {
+	return &RevertLoader{DBRepo: dbRepo, Policy: policy, Decoders: decoders, QuarantineDir: quarantineDir}
 }
 
-// LoadAndSaveRevertsFromDir reads all JSON files from a directory and saves them to the database.
+// NewRevertDecoderRegistry creates a decoder registry for revert files.
+func NewRevertDecoderRegistry(csvColumns map[string]string) *DecoderRegistry {
+	return NewDecoderRegistry(csvColumns, "quantity", "amount")
+}
+
+// LoadAndSaveRevertsFromDir reads all supported files from a directory and reverses their claims.
+// Reverts refused by the reversal policy or for the state of their claim are quarantined instead
+// of saved.
 func (rl *RevertLoader) LoadAndSaveRevertsFromDir(dirPath string) error {
 	absPath, err := filepath.Abs(dirPath)
 	if err != nil {
@@ -33,46 +44,132 @@ func (rl *RevertLoader) LoadAndSaveRevertsFromDir(dirPath string) error {
 		return fmt.Errorf("error reading reverts directory %s: %w", absPath, err)
 	}
 
-	var allReverts []models.Revert
-
+	total := 0
 	for _, file := range files {
 		if file.IsDir() {
 			continue
 		}
 
-		if !strings.HasSuffix(file.Name(), ".json") {
-			log.Printf("INFO: Ignoring non-JSON file: %s/%s", absPath, file.Name())
+		if !rl.Decoders.Supports(file.Name()) {
+			log.Printf("INFO: Ignoring unsupported file: %s/%s", absPath, file.Name())
 			continue
 		}
 
-		filePath := filepath.Join(absPath, file.Name())
-		data, err := ioutil.ReadFile(filePath)
+		saved, err := rl.LoadAndSaveRevertsFromFile(filepath.Join(absPath, file.Name()))
 		if err != nil {
-			log.Printf("ERROR: Error reading reverts file %s: %v", filePath, err)
+			log.Printf("ERROR: %v", err)
+		}
+		total += saved
+	}
+
+	log.Printf("INFO: Finished loading reverts from all files. Total of %d reverts saved.", total)
+	return nil
+}
+
+// LoadAndSaveRevertsFromFile reads a single file and reverses the claims of its reverts, each
+// with the same checks and status changes as a reversal through the API: a known reason code is
+// required and the claim must be within the reversal window at the time of the revert. Reverts
+// already recorded are skipped, so a file can be loaded again. It returns the number of reverts
+// saved.
+func (rl *RevertLoader) LoadAndSaveRevertsFromFile(filePath string) (int, error) {
+	records, err := rl.Decoders.DecodeFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("error loading reverts file: %w", err)
+	}
+
+	name := filepath.Base(filePath)
+	var rejected []models.QuarantinedClaim
+	saved, skipped := 0, 0
+	var saveErr error
+	chains := make(map[string]string)
+	for i, record := range records {
+		var revert models.Revert
+		if err := json.Unmarshal(record, &revert); err != nil {
+			rejected = append(rejected, newQuarantinedClaim(name, i, "", "malformed record: "+err.Error(), record))
 			continue
 		}
 
-		var revertsFromFile []models.Revert
-		if err := json.Unmarshal(data, &revertsFromFile); err != nil {
-			log.Printf("ERROR: Error decoding JSON from reverts file %s: %v", filePath, err)
+		reason, err := rl.reverse(revert, chains)
+		if errors.Is(err, database.ErrRevertExists) {
+			skipped++
 			continue
 		}
+		if err != nil {
+			saveErr = fmt.Errorf("error saving revert %d of file %s to the database: %w", i, filePath, err)
+			break
+		}
+		if reason != "" {
+			rejected = append(rejected, newQuarantinedClaim(name, i, revert.ClaimID, reason, record))
+			continue
+		}
+		saved++
+	}
 
-		log.Printf("INFO: Loaded %d reverts from file: %s", len(revertsFromFile), file.Name())
-		allReverts = append(allReverts, revertsFromFile...)
+	log.Printf("INFO: Saved %d reverts from file: %s (%d already recorded, %d rejected)", saved, name, skipped, len(rejected))
+	// Refused reverts are quarantined even when a later one could not be saved.
+	_, quarantineErr := quarantine(rl.DBRepo, rl.QuarantineDir, name, len(records), saved, rejected)
+	if saveErr != nil {
+		return saved, saveErr
 	}
+	return saved, quarantineErr
+}
 
-	log.Printf("INFO: Finished loading reverts from all JSON files. Total of %d records found.", len(allReverts))
+// reverse reverses the claim of a revert. It returns the reason the revert must be quarantined,
+// or an error wrapping database.ErrRevertExists when it is already recorded. Without quantity and
+// amount the revert reverses everything outstanding of its claim. chains caches the pharmacy
+// chains looked up by the reversal policy.
+func (rl *RevertLoader) reverse(revert models.Revert, chains map[string]string) (string, error) {
+	if revert.ID == "" {
+		return "missing revert ID", nil
+	}
+	if revert.ClaimID == "" {
+		return "missing claim ID", nil
+	}
+	if revert.Quantity < 0 || revert.Amount < 0 {
+		return service.ErrInvalidReversalAmount.Error(), nil
+	}
 
-	if len(allReverts) > 0 {
-		log.Println("INFO: Starting to save all reverts to the database...")
-		if err := rl.DBRepo.SaveReverts(allReverts); err != nil {
-			return fmt.Errorf("error saving reverts to the database: %w", err)
+	claim, err := rl.DBRepo.GetClaimByID(revert.ClaimID)
+	if err != nil {
+		return "", err
+	}
+	if claim == nil {
+		return fmt.Sprintf("claim %s not found", revert.ClaimID), nil
+	}
+	recorded, err := rl.DBRepo.GetRevertsByClaimID(revert.ClaimID)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range recorded {
+		if r.ID == revert.ID {
+			return "", fmt.Errorf("%w: %s", database.ErrRevertExists, revert.ID)
 		}
-		log.Println("INFO: All reverts saved successfully to the database.")
-	} else {
-		log.Println("INFO: No reverts to save to the database.")
 	}
 
-	return nil
+	if err := rl.Policy.CheckReasonCode(revert.ReasonCode, revert.Reason); err != nil {
+		return err.Error(), nil
+	}
+	reversed := time.Now()
+	if revert.Timestamp == "" {
+		revert.Timestamp = reversed.Format("2006-01-02T15:04:05")
+	} else if reversed, err = time.ParseInLocation("2006-01-02T15:04:05", revert.Timestamp, time.Local); err != nil {
+		return fmt.Sprintf("invalid timestamp '%s': must be YYYY-MM-DDTHH:MM:SS", revert.Timestamp), nil
+	}
+	if err := service.CheckReversalAge(rl.DBRepo, rl.Policy, *claim, reversed, chains); err != nil {
+		if errors.Is(err, service.ErrReversalPolicyViolation) {
+			return err.Error(), nil
+		}
+		return "", err
+	}
+	if claim.Status == models.ClaimStatusPaid {
+		if revert.Quantity, revert.Amount, err = service.ReversalAmounts(claim, revert.Quantity, revert.Amount); err != nil {
+			return err.Error(), nil
+		}
+	}
+
+	err = rl.DBRepo.ReverseClaims([]models.Revert{revert}, models.ActorSystem)
+	if errors.Is(err, database.ErrReversalRefused) {
+		return err.Error(), nil
+	}
+	return "", err
 }
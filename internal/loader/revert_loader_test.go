@@ -0,0 +1,104 @@
+package loader_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/loader"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+const revertTestReverts = `[
+  {"id": "r1", "claim_id": "c1", "timestamp": "2024-03-01T10:00:00", "quantity": 1, "amount": 5, "reason_code": "04"},
+  {"id": "r2", "claim_id": "c2", "timestamp": "2024-03-01T10:00:00", "reason_code": "01"},
+  {"id": "r3", "claim_id": "c3", "timestamp": "2024-03-01T10:00:00", "quantity": 5, "amount": 20, "reason_code": "04"},
+  {"id": "r4", "claim_id": "missing", "timestamp": "2024-03-01T10:00:00", "reason_code": "01"},
+  {"id": "r5", "claim_id": "c4", "timestamp": "2024-03-01T10:00:00"},
+  {"id": "r6", "claim_id": "c4", "timestamp": "2024-03-01T10:00:00", "reason_code": "01"}
+]`
+
+func TestLoadRevertsReversesClaims(t *testing.T) {
+	repo := newTestRepository(t)
+	assert.Nil(t, repo.SaveClaims([]models.Claim{
+		{ID: "c1", NDC: "00002323401", NPI: "1234567890", Quantity: 2, Price: 10, Timestamp: "2024-02-01T10:00:00",
+			Prescription: models.Prescription{MemberID: "M1"},
+			ClaimBenefit: models.ClaimBenefit{Plan: "gold", PlanYear: 2024, PatientPay: 4, DeductibleApplied: 2}},
+		{ID: "c2", NDC: "00002323401", NPI: "1234567890", Quantity: 3, Price: 12, Timestamp: "2024-02-01T10:00:00"},
+		{ID: "c3", NDC: "00002323401", NPI: "1234567890", Quantity: 2, Price: 8, Timestamp: "2024-02-01T10:00:00"},
+		{ID: "c4", NDC: "00002323401", NPI: "1234567890", Quantity: 2, Price: 8, Timestamp: "2023-06-01T10:00:00"},
+	}))
+	quarantineDir := filepath.Join(t.TempDir(), "quarantine")
+	policy := service.ReversalPolicy{MaxAge: 90 * 24 * time.Hour}
+	revertLoader := loader.NewRevertLoader(repo, policy, loader.NewRevertDecoderRegistry(nil), quarantineDir)
+	path := writeFile(t, "reverts.json", []byte(revertTestReverts))
+
+	saved, err := revertLoader.LoadAndSaveRevertsFromFile(path)
+	assert.Nil(t, err, "Expected no error loading the reverts file")
+	assert.Equal(t, 2, saved)
+
+	partial, err := repo.GetClaimByID("c1")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, partial.Status, "Expected a partial revert to leave the claim paid")
+	assert.InDelta(t, 1, partial.OutstandingQuantity, 0.001)
+	assert.InDelta(t, 5, partial.OutstandingAmount, 0.001)
+	accumulator, err := repo.GetAccumulator("M1", 2024)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2, accumulator.OutOfPocketSpent, 0.001, "Expected half the patient pay rolled back")
+	assert.InDelta(t, 1, accumulator.DeductibleMet, 0.001, "Expected half the deductible rolled back")
+
+	whole, err := repo.GetClaimByID("c2")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusReversed, whole.Status, "Expected a revert without amounts to reverse the whole claim")
+	reverts, err := repo.GetRevertsByClaimID("c2")
+	assert.Nil(t, err)
+	if assert.Len(t, reverts, 1) {
+		assert.InDelta(t, 3, reverts[0].Quantity, 0.001)
+		assert.InDelta(t, 12, reverts[0].Amount, 0.001)
+	}
+	history, err := repo.GetClaimStatusHistory("c2")
+	assert.Nil(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, models.ClaimStatusReversed, history[1].ToStatus)
+		assert.Equal(t, models.ActorSystem, history[1].Actor)
+	}
+
+	over, err := repo.GetClaimByID("c3")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, over.Status, "Expected a revert beyond the claim to be refused")
+	reverts, err = repo.GetRevertsByClaimID("c3")
+	assert.Nil(t, err)
+	assert.Empty(t, reverts)
+
+	old, err := repo.GetClaimByID("c4")
+	assert.Nil(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, old.Status, "Expected reverts without reason code or past the reversal window to be refused")
+	reverts, err = repo.GetRevertsByClaimID("c1")
+	assert.Nil(t, err)
+	if assert.Len(t, reverts, 1) {
+		assert.Equal(t, models.ReversalReasonWrongQuantity, reverts[0].ReasonCode)
+	}
+
+	data, err := os.ReadFile(filepath.Join(quarantineDir, "reverts.summary.json"))
+	assert.Nil(t, err, "Expected a load summary file")
+	var summary models.LoadSummary
+	assert.Nil(t, json.Unmarshal(data, &summary))
+	assert.Equal(t, 6, summary.Total)
+	assert.Equal(t, 2, summary.Loaded)
+	assert.Equal(t, 4, summary.Rejected, "Expected the over-reversal, the unknown claim and the policy violations quarantined")
+
+	saved, err = revertLoader.LoadAndSaveRevertsFromFile(path)
+	assert.Nil(t, err, "Expected no error loading the reverts file again")
+	assert.Equal(t, 0, saved, "Expected recorded reverts to be skipped")
+	reverts, err = repo.GetRevertsByClaimID("c1")
+	assert.Nil(t, err)
+	assert.Len(t, reverts, 1, "Expected no duplicate revert on reload")
+	accumulator, err = repo.GetAccumulator("M1", 2024)
+	assert.Nil(t, err)
+	assert.InDelta(t, 2, accumulator.OutOfPocketSpent, 0.001, "Expected the accumulator untouched on reload")
+}
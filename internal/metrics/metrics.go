@@ -25,3 +25,33 @@ var ClaimReversalsTotal = promauto.NewCounter(prometheus.CounterOpts{
 	Name: "claim_reversals_total",
 	Help: "Total number of claim reversals.",
 })
+
+var IngestedFilesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ingested_files_total",
+	Help: "Total number of files ingested by the directory watchers.",
+}, []string{"kind", "status"})
+
+var IngestedRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "ingested_rows_total",
+	Help: "Total number of records ingested by the directory watchers.",
+}, []string{"kind"})
+
+var AnomaliesOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "claim_anomalies_open",
+	Help: "Number of open claim anomalies found by the analyzer.",
+}, []string{"type", "severity"})
+
+var PharmacyAnomalyScore = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "pharmacy_anomaly_score",
+	Help: "Observed value of the open anomalies of a pharmacy divided by its expected value.",
+}, []string{"type", "npi", "ndc"})
+
+var EligibilityRosterMembers = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "eligibility_roster_members",
+	Help: "Number of members on the eligibility roster in use.",
+})
+
+var EligibilityRosterChangesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "eligibility_roster_changes_total",
+	Help: "Total number of members added, removed or changed by eligibility roster reloads.",
+}, []string{"change"})
@@ -0,0 +1,38 @@
+package models
+
+// ClaimBenefit holds the cost sharing of a claim under the benefit plan of its member. Claims
+// without a member, or whose plan has no benefit definition, have no plan and no patient pay.
+type ClaimBenefit struct {
+	Plan              string  `json:"plan,omitempty" db:"benefit_plan"`                     // Benefit plan of the member on the date of service
+	PlanYear          int     `json:"plan_year,omitempty" db:"plan_year"`                   // Plan year (calendar year of the date of service) the claim accumulates to
+	PatientPay        float64 `json:"patient_pay" db:"patient_pay"`                         // Amount paid by the member, deductible included
+	DeductibleApplied float64 `json:"deductible_applied,omitempty" db:"deductible_applied"` // Part of the patient pay applied to the deductible
+}
+
+// BenefitPlan represents the cost sharing terms of a plan from the plan benefit file.
+type BenefitPlan struct {
+	Plan               string  `json:"plan"`                          // Plan ID, as in the eligibility roster
+	Deductible         float64 `json:"deductible"`                    // Amount the member pays in full each plan year before cost sharing applies
+	OutOfPocketMax     float64 `json:"out_of_pocket_max"`             // Most the member pays in a plan year, deductible included; 0 for no limit
+	CoinsurancePercent float64 `json:"coinsurance_percent,omitempty"` // Share of the allowed amount paid by the member after the deductible
+	Copay              float64 `json:"copay,omitempty"`               // Flat amount paid by the member per claim after the deductible, instead of coinsurance
+}
+
+// Accumulator represents the deductible met and out-of-pocket spent by a member in a plan year.
+// It is the sum of the outstanding cost sharing of the member's paid claims of the year.
+type Accumulator struct {
+	MemberID         string  `json:"member_id" db:"member_id"`                     // ID of the plan member
+	PlanYear         int     `json:"plan_year" db:"plan_year"`                     // Calendar year of the dates of service
+	DeductibleMet    float64 `json:"deductible_met" db:"deductible_met"`           // Amount applied to the deductible
+	OutOfPocketSpent float64 `json:"out_of_pocket_spent" db:"out_of_pocket_spent"` // Patient pay, deductible included
+	UpdatedAt        string  `json:"updated_at,omitempty" db:"updated_at"`         // Date and time of the latest change
+}
+
+// MemberAccumulators represents the accumulators of a member in a plan year against the limits of
+// the member's plan.
+type MemberAccumulators struct {
+	Accumulator
+	Plan                 *BenefitPlan `json:"plan,omitempty"`                    // Plan of the member at the end of the plan year, or today for the current year
+	DeductibleRemaining  float64      `json:"deductible_remaining"`              // Deductible still to meet
+	OutOfPocketRemaining *float64     `json:"out_of_pocket_remaining,omitempty"` // Out-of-pocket left before the maximum, absent without a maximum
+}
@@ -0,0 +1,40 @@
+package models
+
+// ClaimReject is a reject code given to a claim by an adjudication rule.
+type ClaimReject struct {
+	Code    string `json:"code"`    // NCPDP-like reject code, e.g. "76" (Plan Limitations Exceeded)
+	Rule    string `json:"rule"`    // Name of the rule that gave the code
+	Message string `json:"message"` // Explanation of the violated rule
+}
+
+// Adjudication represents the outcome of the adjudication of a claim.
+type Adjudication struct {
+	Status  string        `json:"status"`            // paid, pending or rejected
+	Rejects []ClaimReject `json:"rejects,omitempty"` // Codes of the rules that pended or rejected the claim
+}
+
+// RejectRefillTooSoon is the reject code of a fill submitted before the previous fill of the same
+// drug for the same member is consumed enough.
+const RejectRefillTooSoon = "79"
+
+// RejectClaimTooOld is the reject code of a claim submitted after the timely filing limit of its
+// date of service.
+const RejectClaimTooOld = "81"
+
+// Override codes (NCPDP submission clarification codes) that bypass the refill-too-soon check.
+const (
+	OverrideVacationSupply     = "03" // Vacation supply
+	OverrideLostPrescription   = "04" // Lost, stolen or damaged prescription
+	OverrideTherapyChange      = "05" // Therapy change, e.g. a dose increase
+	OverrideMedicallyNecessary = "07" // Medically necessary
+	OverrideEmergency          = "13" // Payer-recognized emergency or disaster
+)
+
+// RefillOverrideCodes describes the override codes accepted on a claim.
+var RefillOverrideCodes = map[string]string{
+	OverrideVacationSupply:     "Vacation supply",
+	OverrideLostPrescription:   "Lost, stolen or damaged prescription",
+	OverrideTherapyChange:      "Therapy change",
+	OverrideMedicallyNecessary: "Medically necessary",
+	OverrideEmergency:          "Payer-recognized emergency or disaster",
+}
@@ -0,0 +1,54 @@
+package models
+
+// Anomaly types found by the claim analyzer.
+const (
+	AnomalySubmissionSpike = "submission_spike" // Submissions of a pharmacy far above its rolling baseline
+	AnomalyReversalRate    = "reversal_rate"    // Share of reversed claims of a pharmacy above the threshold
+	AnomalyUnitPrice       = "unit_price"       // Unit price of a pharmacy for an NDC far above the cross-pharmacy median
+)
+
+// Anomaly severities, from how far the observed value is past its limit.
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// Anomaly represents a finding of the claim analyzer. A finding stays open while the analyzer
+// keeps detecting it and is resolved once it no longer does.
+type Anomaly struct {
+	ID              int64   `json:"id"`                    // Unique ID of the anomaly
+	Type            string  `json:"type"`                  // submission_spike, reversal_rate or unit_price
+	NPI             string  `json:"npi"`                   // National Provider Identifier of the pharmacy
+	NDC             string  `json:"ndc,omitempty"`         // National Drug Code, for unit_price anomalies
+	Severity        string  `json:"severity"`              // low, medium or high
+	Value           float64 `json:"value"`                 // Observed value: claims in the window, reversal rate in percent or unit price
+	Expected        float64 `json:"expected"`              // Baseline claims per window, reversal rate threshold or median unit price
+	Message         string  `json:"message"`               // Description of the finding
+	FirstDetectedAt string  `json:"first_detected_at"`     // Date and time of the first detection
+	LastDetectedAt  string  `json:"last_detected_at"`      // Date and time of the latest detection
+	ResolvedAt      string  `json:"resolved_at,omitempty"` // Date and time the finding was no longer detected
+}
+
+// AnomalyFilter represents the criteria used to list anomalies. Empty fields are ignored.
+type AnomalyFilter struct {
+	NPI      string // National Provider Identifier of the pharmacy
+	Type     string // Anomaly type
+	Severity string // Anomaly severity
+	Open     *bool  // true for open anomalies only, false for resolved ones only
+}
+
+// ReversalCount represents the number of claims of a pharmacy and how many were reversed.
+type ReversalCount struct {
+	NPI           string
+	ClaimCount    int
+	ReversedCount int
+}
+
+// PharmacyUnitPrice represents the average unit price of an NDC billed by a pharmacy.
+type PharmacyUnitPrice struct {
+	NDC        string
+	NPI        string
+	ClaimCount int
+	UnitPrice  float64 // Total price / total quantity
+}
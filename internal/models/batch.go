@@ -0,0 +1,81 @@
+package models
+
+// Claim batch submission modes.
+const (
+	BatchModeAllOrNothing = "all_or_nothing" // Nothing is saved unless every item is valid
+	BatchModeBestEffort   = "best_effort"    // Valid items are saved, invalid items are rejected
+)
+
+// Statuses of a batch item.
+const (
+	BatchItemAccepted = "accepted" // The claim was saved
+	BatchItemRejected = "rejected" // The item is invalid or could not be saved
+	BatchItemSkipped  = "skipped"  // The item is valid but the all-or-nothing batch was not committed
+)
+
+// ClaimBatchItemResult represents the outcome of one item of a claim batch.
+type ClaimBatchItemResult struct {
+	Index   int    `json:"index"`              // Position of the item in the submitted array
+	Status  string `json:"status"`             // accepted, rejected or skipped
+	ClaimID string `json:"claim_id,omitempty"` // ID of the created claim when accepted
+	Error   string `json:"error,omitempty"`    // Reason of the rejection
+
+	ClaimStatus string        `json:"claim_status,omitempty"` // Adjudicated status of the created claim (paid, pending or rejected)
+	Rejects     []ClaimReject `json:"rejects,omitempty"`      // Reject codes of a pending or rejected claim
+}
+
+// ClaimBatchResponse represents the response payload after a batch claim submission.
+type ClaimBatchResponse struct {
+	Mode      string                 `json:"mode"`      // all_or_nothing or best_effort
+	Committed bool                   `json:"committed"` // Whether the accepted claims were saved
+	Accepted  int                    `json:"accepted"`  // Number of claims saved
+	Rejected  int                    `json:"rejected"`  // Number of items rejected
+	Results   []ClaimBatchItemResult `json:"results"`   // One result per submitted item, in order
+}
+
+// Outcomes of a claim in a batch reversal.
+const (
+	ReversalOutcomeReversed        = "reversed"         // The claim was reversed
+	ReversalOutcomeWouldReverse    = "would_reverse"    // Dry run: the claim would be reversed
+	ReversalOutcomeAlreadyReversed = "already_reversed" // The claim was reversed before
+	ReversalOutcomeNotFound        = "not_found"        // No claim has the requested ID
+	ReversalOutcomeNotReversible   = "not_reversible"   // The claim status does not allow a reversal
+	ReversalOutcomePolicyViolation = "policy_violation" // The reversal policy refuses the reversal of the claim
+)
+
+// ReversalFilter selects the claims of a pharmacy submitted in a time range.
+type ReversalFilter struct {
+	NPI  string `json:"npi"`  // National Provider Identifier of the pharmacy
+	From string `json:"from"` // Inclusive lower bound of the claim timestamp (e.g. "2024-02-01T08:00:00")
+	To   string `json:"to"`   // Exclusive upper bound of the claim timestamp
+}
+
+// BatchReversalRequest represents the input payload for reversing several claims at once.
+// Exactly one of ClaimIDs and Filter must be set.
+type BatchReversalRequest struct {
+	ClaimIDs   []string        `json:"claim_ids,omitempty"` // IDs of the claims to reverse
+	Filter     *ReversalFilter `json:"filter,omitempty"`    // Claims to reverse, selected by pharmacy and time range
+	Reason     string          `json:"reason"`              // Audit reason recorded with every revert
+	ReasonCode string          `json:"reason_code"`         // Required reason code recorded with every revert, see ReversalReasonCodes
+	DryRun     bool            `json:"dry_run"`             // Preview the outcomes without reversing
+	Actor      string          `json:"-"`                   // Channel of the request, recorded in the status history
+	MaxClaims  int             `json:"-"`                   // Most claims the filter may match, 0 for no limit
+}
+
+// BatchReversalResult represents the outcome of one claim of a batch reversal.
+type BatchReversalResult struct {
+	ClaimID  string `json:"claim_id"`            // ID of the claim
+	Outcome  string `json:"outcome"`             // reversed, would_reverse, already_reversed, not_reversible, policy_violation or not_found
+	RevertID string `json:"revert_id,omitempty"` // ID of the revert record when reversed
+	Error    string `json:"error,omitempty"`     // Rule of the reversal policy violated by the claim
+}
+
+// BatchReversalResponse represents the response payload after a batch reversal.
+type BatchReversalResponse struct {
+	DryRun     bool                  `json:"dry_run"`     // Whether this was a preview
+	Reason     string                `json:"reason"`      // Audit reason of the reversal
+	ReasonCode string                `json:"reason_code"` // Reason code of the reversal
+	Matched    int                   `json:"matched"`     // Number of claims found
+	Reversed   int                   `json:"reversed"`    // Number of claims reversed (or that would be in a dry run)
+	Results    []BatchReversalResult `json:"results"`     // One result per claim
+}
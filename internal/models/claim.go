@@ -6,9 +6,27 @@ type Claim struct {
 	NDC       string  `json:"ndc" db:"ndc"`             // National Drug Code of the medication
 	NPI       string  `json:"npi" db:"npi"`             // National Provider Identifier of the pharmacy
 	Quantity  float64 `json:"quantity" db:"quantity"`   // Quantity of the medication
-	Price     float64 `json:"price" db:"price"`         // Price of the medication
+	Price     float64 `json:"price" db:"price"`         // Price submitted (billed) by the pharmacy
 	Timestamp string  `json:"timestamp" db:"timestamp"` // Date and time of claim submission
-	Reverted  bool    `json:"reverted" db:"reverted"`   // Indicates if the claim has been reverted
+	Status    string  `json:"status" db:"status"`       // Lifecycle status (pending, paid, rejected, reversed or rebilled)
+	Reverted  bool    `json:"reverted" db:"reverted"`   // Deprecated: true when Status is reversed, kept for compatibility
+
+	ReplacesClaimID string        `json:"replaces_claim_id,omitempty" db:"replaces_claim_id"` // ID of the rebilled claim this claim replaces
+	Rejects         []ClaimReject `json:"rejects,omitempty" db:"rejects"`                     // Reject codes given at adjudication to a pending or rejected claim
+	AllowedAmount   float64       `json:"allowed_amount" db:"allowed_amount"`                 // Amount reimbursed under the contract of the chain
+	PricingBasis    string        `json:"pricing_basis" db:"pricing_basis"`                   // How the allowed amount was computed (submitted, contract or lesser_of)
+
+	ReferenceUnitPrice float64 `json:"reference_unit_price,omitempty" db:"reference_unit_price"` // Reference unit price in effect on the claim date, 0 when none
+	PriceVariance      float64 `json:"price_variance,omitempty" db:"price_variance"`             // Unit price above (or below) the reference, in percent
+	PriceFlagged       bool    `json:"price_flagged" db:"price_flagged"`                         // Price variance over the configured threshold
+
+	Prescription
+	ClaimBenefit
+
+	OutstandingQuantity   float64 `json:"outstanding_quantity" db:"-"`              // Quantity not reversed yet
+	OutstandingAmount     float64 `json:"outstanding_amount" db:"-"`                // Amount not reversed yet
+	OutstandingPatientPay float64 `json:"outstanding_patient_pay,omitempty" db:"-"` // Patient pay not rolled back by reversals yet
+	OutstandingDeductible float64 `json:"outstanding_deductible,omitempty" db:"-"`  // Deductible applied not rolled back by reversals yet
 }
 
 // ClaimSubmissionRequest represents the input payload for creating a new claim.
@@ -17,6 +35,20 @@ type ClaimSubmissionRequest struct {
 	Quantity float64 `json:"quantity"` // Quantity of the medication
 	NPI      string  `json:"npi"`      // National Provider Identifier of the pharmacy
 	Price    float64 `json:"price"`    // Price of the medication
+
+	Prescription
+}
+
+// Prescription holds the member and prescription details of a claim. Every field is optional.
+type Prescription struct {
+	MemberID      string `json:"member_id,omitempty" db:"member_id"`             // ID of the plan member the prescription was filled for
+	PrescriberNPI string `json:"prescriber_npi,omitempty" db:"prescriber_npi"`   // National Provider Identifier of the prescriber
+	RxNumber      string `json:"rx_number,omitempty" db:"rx_number"`             // Prescription number assigned by the pharmacy
+	FillNumber    int    `json:"fill_number,omitempty" db:"fill_number"`         // 0 for the original fill, 1 to 99 for refills
+	DaysSupply    int    `json:"days_supply,omitempty" db:"days_supply"`         // Days the dispensed quantity lasts
+	DateOfService string `json:"date_of_service,omitempty" db:"date_of_service"` // Date the prescription was filled (YYYY-MM-DD)
+	DAWCode       string `json:"daw_code,omitempty" db:"daw_code"`               // Dispense as written (product selection) code, "0" to "9"
+	OverrideCode  string `json:"override_code,omitempty" db:"override_code"`     // Bypasses the refill-too-soon check, see RefillOverrideCodes
 }
 
 // ClaimResponse represents the response payload after a claim submission.
@@ -26,12 +58,62 @@ type ClaimResponse struct {
 }
 
 // ClaimReversalRequest represents the input payload for reverting a claim.
+// Without quantity and amount the whole outstanding claim is reversed; when only one of them is
+// set, the other is prorated at the unit price of the claim.
 type ClaimReversalRequest struct {
-	ClaimID string `json:"claim_id"` // ID of the claim to be reverted
+	ClaimID    string  `json:"claim_id"`           // ID of the claim to be reverted
+	Quantity   float64 `json:"quantity,omitempty"` // Quantity to reverse, e.g. the units returned by the patient
+	Amount     float64 `json:"amount,omitempty"`   // Amount to reverse
+	ReasonCode string  `json:"reason_code"`        // Required reason code, see ReversalReasonCodes
+	Reason     string  `json:"reason,omitempty"`   // Reason text, required with reason code 99
+	Actor      string  `json:"-"`                  // Channel of the request, recorded in the status history
 }
 
 // ClaimReversalResponse represents the response payload after a claim reversal.
 type ClaimReversalResponse struct {
-	Status  string `json:"status"`   // Operation status (e.g., "claim reversed")
-	ClaimID string `json:"claim_id"` // ID of the reverted claim
+	Status     string  `json:"status"`      // Operation status (e.g., "claim reversed")
+	ClaimID    string  `json:"claim_id"`    // ID of the reverted claim
+	RevertID   string  `json:"revert_id"`   // ID of the revert record
+	Quantity   float64 `json:"quantity"`    // Quantity reversed
+	Amount     float64 `json:"amount"`      // Amount reversed
+	ReasonCode string  `json:"reason_code"` // Reason code of the reversal
+}
+
+// ClaimRebillRequest represents the input payload for rebilling a claim. Zero fields keep the
+// value of the original claim; the pharmacy of the claim cannot change.
+type ClaimRebillRequest struct {
+	ClaimID  string  `json:"-"`                  // ID of the claim to rebill, taken from the path
+	NDC      string  `json:"ndc,omitempty"`      // Corrected National Drug Code
+	Quantity float64 `json:"quantity,omitempty"` // Corrected quantity
+	Price    float64 `json:"price,omitempty"`    // Corrected price
+
+	ReasonCode string `json:"reason_code"`      // Required reason code of the reversal of the original claim, see ReversalReasonCodes
+	Reason     string `json:"reason,omitempty"` // Reason of the rebill, e.g. "pricing error", required with reason code 99
+	Actor      string `json:"-"`                // Channel of the request, recorded in the status history
+}
+
+// ClaimReinstateRequest represents the input payload for undoing the reversal of a claim.
+type ClaimReinstateRequest struct {
+	ClaimID  string `json:"-"`                   // ID of the claim to reinstate, taken from the path
+	RevertID string `json:"revert_id,omitempty"` // Revert to void; defaults to the latest revert of the claim
+	Reason   string `json:"reason"`              // Required audit reason, e.g. "reversed the wrong claim"
+	Actor    string `json:"-"`                   // Channel of the request, recorded in the status history
+}
+
+// ClaimResolveRequest represents the input payload for resolving a claim pended at adjudication.
+type ClaimResolveRequest struct {
+	ClaimID string `json:"-"`      // ID of the pending claim, taken from the path
+	Status  string `json:"status"` // Decision of the review, paid or rejected
+	Reason  string `json:"reason"` // Required audit reason, e.g. "prior authorization approved"
+	Actor   string `json:"-"`      // Channel of the request, recorded in the status history
+}
+
+// ClaimFilter represents the criteria used to search claims. Empty fields are ignored.
+type ClaimFilter struct {
+	NPI    string // National Provider Identifier of the pharmacy
+	NDC    string // National Drug Code of the medication
+	From   string // Inclusive lower bound of the claim timestamp (e.g. "2024-02-01")
+	To     string // Exclusive upper bound of the claim timestamp
+	Limit  int    // Maximum number of claims returned; 0 returns every match
+	Offset int    // Number of matching claims skipped
 }
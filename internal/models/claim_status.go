@@ -0,0 +1,31 @@
+package models
+
+// Lifecycle statuses of a claim.
+const (
+	ClaimStatusPending  = "pending"  // Submitted, waiting for adjudication
+	ClaimStatusPaid     = "paid"     // Adjudicated and payable
+	ClaimStatusRejected = "rejected" // Refused at adjudication
+	ClaimStatusReversed = "reversed" // Paid and then reversed
+	ClaimStatusRebilled = "rebilled" // Paid and then replaced by a corrected claim
+)
+
+// Actors recorded in the claim status history.
+const (
+	ActorAPI       = "api"       // REST API
+	ActorGRPC      = "grpc"      // gRPC API
+	ActorNCPDP     = "ncpdp"     // NCPDP Telecommunication channel
+	ActorSystem    = "system"    // Internal processing such as file loads
+	ActorMigration = "migration" // Backfill of claims stored before the status history existed
+)
+
+// ClaimStatusChange represents one transition of the status of a claim.
+// The first change of a claim has an empty FromStatus.
+type ClaimStatusChange struct {
+	ID         int64  `json:"id" db:"id"`                   // Sequential ID of the change
+	ClaimID    string `json:"claim_id" db:"claim_id"`       // ID of the claim
+	FromStatus string `json:"from_status" db:"from_status"` // Status before the change
+	ToStatus   string `json:"to_status" db:"to_status"`     // Status after the change
+	Actor      string `json:"actor" db:"actor"`             // Channel or process that made the change
+	Reason     string `json:"reason,omitempty" db:"reason"` // Optional reason of the change
+	Timestamp  string `json:"timestamp" db:"timestamp"`     // Date and time of the change
+}
@@ -0,0 +1,45 @@
+package models
+
+// Eligibility reject codes, as returned by NCPDP E1 eligibility verifications.
+const (
+	RejectNonMatchedCardholderID = "52" // Non-Matched Cardholder ID: the member is not on the roster
+	RejectPatientNotCovered      = "65" // Patient Is Not Covered: no coverage on the date of service
+)
+
+// Coverage represents a period a member is covered by a plan. An empty end date is open-ended.
+type Coverage struct {
+	MemberID string `json:"member_id"`              // ID of the plan member
+	Plan     string `json:"plan"`                   // Plan covering the member
+	Start    string `json:"coverage_start"`         // First covered date (YYYY-MM-DD)
+	End      string `json:"coverage_end,omitempty"` // Last covered date (YYYY-MM-DD), empty when open-ended
+}
+
+// EligibilityRequest represents the input payload of an eligibility check.
+type EligibilityRequest struct {
+	MemberID      string `json:"member_id"`                 // ID of the plan member
+	DateOfService string `json:"date_of_service,omitempty"` // Date to check (YYYY-MM-DD); defaults to today
+}
+
+// Eligibility represents the outcome of an eligibility check.
+type Eligibility struct {
+	MemberID      string    `json:"member_id"`             // ID of the plan member
+	DateOfService string    `json:"date_of_service"`       // Date checked (YYYY-MM-DD)
+	Eligible      bool      `json:"eligible"`              // true when the member is covered on the date
+	Coverage      *Coverage `json:"coverage,omitempty"`    // Coverage in effect on the date, when eligible
+	RejectCode    string    `json:"reject_code,omitempty"` // 52 or 65 when not eligible
+	Message       string    `json:"message,omitempty"`     // Reason the member is not eligible
+}
+
+// RosterChanges represents the members added, removed or with a changed coverage by a roster reload.
+type RosterChanges struct {
+	Added   []string `json:"added"`   // IDs of the members new to the roster
+	Removed []string `json:"removed"` // IDs of the members no longer on the roster
+	Changed []string `json:"changed"` // IDs of the members whose coverage periods changed
+}
+
+// RosterStatus represents the eligibility roster in use and the changes of its latest reload.
+type RosterStatus struct {
+	MemberCount int            `json:"member_count"`           // Members on the roster
+	LoadedAt    string         `json:"loaded_at"`              // Date and time the roster was (re)loaded
+	Changes     *RosterChanges `json:"last_changes,omitempty"` // Changes of the latest reload, none for the first load
+}
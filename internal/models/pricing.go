@@ -0,0 +1,85 @@
+package models
+
+// Pricing bases of the allowed amount of a claim.
+const (
+	PricingBasisSubmitted = "submitted" // No contract applies: the submitted price is allowed
+	PricingBasisContract  = "contract"  // Contract amount from the reference unit cost of the NDC
+	PricingBasisLesserOf  = "lesser_of" // Submitted price, lower than the contract amount
+)
+
+// ClaimPricing represents the allowed amount computed for a claim.
+type ClaimPricing struct {
+	AllowedAmount float64 `json:"allowed_amount"` // Amount reimbursed for the claim
+	Basis         string  `json:"basis"`          // submitted, contract or lesser_of
+}
+
+// ChainSavings represents the difference between the submitted and allowed amounts of the
+// paid claims of a pharmacy chain.
+type ChainSavings struct {
+	Chain           string  `json:"chain"`            // Pharmacy chain
+	ClaimCount      int     `json:"claim_count"`      // Number of paid claims
+	SubmittedAmount float64 `json:"submitted_amount"` // Total price submitted by the pharmacies
+	AllowedAmount   float64 `json:"allowed_amount"`   // Total amount allowed
+	Savings         float64 `json:"savings"`          // SubmittedAmount - AllowedAmount
+	SavingsPercent  float64 `json:"savings_percent"`  // Savings as a percentage of SubmittedAmount
+}
+
+// Dates by which reports place claims in their period.
+const (
+	ReportDateReceived = "received" // Date the claim was submitted, from its timestamp
+	ReportDateService  = "service"  // Date of service of the claim, or its submission date without one
+)
+
+// SavingsReport represents the pricing savings of every chain over a period.
+type SavingsReport struct {
+	From   string         `json:"from"`   // First day of the period (YYYY-MM-DD)
+	To     string         `json:"to"`     // Last day of the period (YYYY-MM-DD)
+	Date   string         `json:"date"`   // Date placing the claims in the period: received or service
+	Chains []ChainSavings `json:"chains"` // Savings by chain, sorted by chain
+	Total  ChainSavings   `json:"total"`  // Savings of every chain, with an empty chain
+}
+
+// PriceVariance represents the comparison of the unit price (price / quantity) of a claim with
+// the reference unit price in effect on its date.
+type PriceVariance struct {
+	ReferenceUnitPrice float64 `json:"reference_unit_price"` // Reference unit price, 0 when none is in effect
+	VariancePercent    float64 `json:"variance_percent"`     // Unit price above (or below) the reference, in percent
+	Flagged            bool    `json:"flagged"`              // VariancePercent is over the variance threshold
+}
+
+// ClaimPriceVariance represents the price variance computed for a stored claim.
+type ClaimPriceVariance struct {
+	ClaimID string
+	PriceVariance
+}
+
+// PriceOutlier represents a claim flagged for the variance of its unit price.
+type PriceOutlier struct {
+	ClaimID            string  `json:"claim_id"`                  // ID of the claim
+	NDC                string  `json:"ndc"`                       // National Drug Code of the medication
+	Quantity           float64 `json:"quantity"`                  // Quantity of the medication
+	Price              float64 `json:"price"`                     // Price submitted by the pharmacy
+	UnitPrice          float64 `json:"unit_price"`                // Price / Quantity
+	ReferenceUnitPrice float64 `json:"reference_unit_price"`      // Reference unit price in effect on the claim date
+	VariancePercent    float64 `json:"variance_percent"`          // Unit price above the reference, in percent
+	Status             string  `json:"status"`                    // Lifecycle status of the claim
+	Timestamp          string  `json:"timestamp"`                 // Date and time of claim submission
+	DateOfService      string  `json:"date_of_service,omitempty"` // Date the prescription was filled (YYYY-MM-DD)
+}
+
+// PharmacyPriceOutliers represents the flagged claims of a pharmacy.
+type PharmacyPriceOutliers struct {
+	NPI        string         `json:"npi"`         // National Provider Identifier of the pharmacy
+	Chain      string         `json:"chain"`       // Pharmacy chain
+	ClaimCount int            `json:"claim_count"` // Number of flagged claims
+	Claims     []PriceOutlier `json:"claims"`      // Flagged claims, by timestamp
+}
+
+// PriceVarianceReport represents the claims flagged for their unit price variance over a period.
+type PriceVarianceReport struct {
+	From       string                  `json:"from"`        // First day of the period (YYYY-MM-DD)
+	To         string                  `json:"to"`          // Last day of the period (YYYY-MM-DD)
+	Date       string                  `json:"date"`        // Date placing the claims in the period: received or service
+	ClaimCount int                     `json:"claim_count"` // Number of flagged claims of every pharmacy
+	Pharmacies []PharmacyPriceOutliers `json:"pharmacies"`  // Flagged claims by pharmacy, sorted by NPI
+}
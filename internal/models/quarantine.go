@@ -0,0 +1,20 @@
+package models
+
+// QuarantinedClaim represents a claim or revert record rejected during bulk loading.
+type QuarantinedClaim struct {
+	SourceFile  string `json:"source_file" db:"source_file"`   // Name of the file the record was read from
+	RecordIndex int    `json:"record_index" db:"record_index"` // Position of the record inside the file
+	ClaimID     string `json:"claim_id" db:"claim_id"`         // ID of the claim, if it could be decoded
+	Reason      string `json:"reason" db:"reason"`             // Business rule the record violated
+	Payload     string `json:"payload" db:"payload"`           // Original record as read from the file
+	Timestamp   string `json:"timestamp" db:"timestamp"`       // Date and time the record was quarantined
+}
+
+// LoadSummary summarizes the outcome of loading a single claims or reverts file.
+type LoadSummary struct {
+	SourceFile string         `json:"source_file"` // Name of the loaded file
+	Total      int            `json:"total"`       // Number of records found in the file
+	Loaded     int            `json:"loaded"`      // Number of records saved to the database
+	Rejected   int            `json:"rejected"`    // Number of records sent to quarantine
+	Reasons    map[string]int `json:"reasons"`     // Rejected record count per reason
+}
@@ -0,0 +1,20 @@
+package models
+
+// ReversedClaim pairs a revert record with the claim it reversed.
+type ReversedClaim struct {
+	Revert Revert `json:"revert"` // Reversal record
+	Claim  Claim  `json:"claim"`  // Claim that was reversed
+}
+
+// RemittanceExport describes a generated X12 835 remittance advice.
+type RemittanceExport struct {
+	NPI           string  `json:"npi"`            // Pharmacy receiving the remittance
+	From          string  `json:"from"`           // First day of the period (YYYY-MM-DD)
+	To            string  `json:"to"`             // Last day of the period (YYYY-MM-DD)
+	ControlNumber int64   `json:"control_number"` // Interchange control number of the 835
+	ClaimCount    int     `json:"claim_count"`    // Number of paid claims in the period
+	ReversalCount int     `json:"reversal_count"` // Number of reversals netted in the period
+	TotalPaid     float64 `json:"total_paid"`     // Net amount paid
+	FileName      string  `json:"file_name"`      // Name of the exported file
+	Content       string  `json:"-"`              // X12 835 interchange
+}
@@ -1,8 +1,49 @@
 package models
 
-// Revert represents a reversal of a claim.
+// ReversalTolerance absorbs the floating point residue when reversed quantities and amounts
+// are compared with the outstanding ones.
+const ReversalTolerance = 1e-6
+
+// NCPDP-style reason codes of a reversal.
+const (
+	ReversalReasonBilledInError    = "01" // Claim billed in error
+	ReversalReasonDuplicate        = "02" // Duplicate claim
+	ReversalReasonWrongDrug        = "03" // Wrong drug or NDC
+	ReversalReasonWrongQuantity    = "04" // Wrong quantity or days supply
+	ReversalReasonWrongProvider    = "05" // Wrong pharmacy or prescriber
+	ReversalReasonPricingError     = "06" // Pricing or billing correction
+	ReversalReasonReturnToStock    = "07" // Prescription not picked up and returned to stock
+	ReversalReasonPharmacyReversal = "08" // Reversed by the pharmacy with an NCPDP B2 transaction
+	ReversalReasonOther            = "99" // Other, explained in the reason text
+)
+
+// ReversalReasonCodes describes the reason codes accepted for a reversal.
+var ReversalReasonCodes = map[string]string{
+	ReversalReasonBilledInError:    "Claim billed in error",
+	ReversalReasonDuplicate:        "Duplicate claim",
+	ReversalReasonWrongDrug:        "Wrong drug or NDC",
+	ReversalReasonWrongQuantity:    "Wrong quantity or days supply",
+	ReversalReasonWrongProvider:    "Wrong pharmacy or prescriber",
+	ReversalReasonPricingError:     "Pricing or billing correction",
+	ReversalReasonReturnToStock:    "Prescription not picked up, returned to stock",
+	ReversalReasonPharmacyReversal: "Reversed by the pharmacy (NCPDP B2)",
+	ReversalReasonOther:            "Other, explained in the reason text",
+}
+
+// Revert represents a full or partial reversal of a claim.
 type Revert struct {
-	ID        string `json:"id" db:"id"`               // Unique ID of the reversal (UUID)
-	ClaimID   string `json:"claim_id" db:"claim_id"`   // ID of the claim that was reverted
-	Timestamp string `json:"timestamp" db:"timestamp"` // Date and time of the reversal
+	ID        string  `json:"id" db:"id"`                   // Unique ID of the reversal (UUID)
+	ClaimID   string  `json:"claim_id" db:"claim_id"`       // ID of the claim that was reverted
+	Timestamp string  `json:"timestamp" db:"timestamp"`     // Date and time of the reversal
+	Reason    string  `json:"reason,omitempty" db:"reason"` // Audit reason text
+	Quantity  float64 `json:"quantity" db:"quantity"`       // Quantity reversed; reverts loaded without quantity and amount reverse the whole claim
+	Amount    float64 `json:"amount" db:"amount"`           // Amount reversed
+
+	ReasonCode string `json:"reason_code,omitempty" db:"reason_code"` // NCPDP-style reason code, see ReversalReasonCodes
+
+	PatientPay        float64 `json:"patient_pay,omitempty" db:"patient_pay"`               // Patient pay of the claim rolled back from the member accumulators
+	DeductibleApplied float64 `json:"deductible_applied,omitempty" db:"deductible_applied"` // Deductible of the claim rolled back from the member accumulators
+
+	VoidedAt   string `json:"voided_at,omitempty" db:"voided_at"`     // Date and time the revert was voided by a reinstatement
+	VoidReason string `json:"void_reason,omitempty" db:"void_reason"` // Audit reason of the reinstatement
 }
@@ -0,0 +1,75 @@
+package models
+
+// Drug represents an NDC of the drug catalog. NDCs with the same active ingredient, strength and
+// dosage form are equivalent.
+type Drug struct {
+	NDC        string `json:"ndc"`            // National Drug Code
+	Name       string `json:"name,omitempty"` // Proprietary or product name, e.g. "LIPITOR"
+	Ingredient string `json:"ingredient"`     // Active ingredient, e.g. "ATORVASTATIN CALCIUM"
+	Strength   string `json:"strength"`       // Strength, e.g. "20 MG"
+	Form       string `json:"form"`           // Dosage form, e.g. "TABLET"
+	Generic    bool   `json:"generic"`        // False for a brand NDC
+}
+
+// NDCClaimTotals represents the paid claims of an NDC billed by a pharmacy.
+type NDCClaimTotals struct {
+	NPI        string
+	Chain      string
+	NDC        string
+	ClaimCount int
+	Quantity   float64 // Total quantity not reversed
+	Amount     float64 // Total amount not reversed
+}
+
+// SubstitutionOpportunity represents the claims of a pharmacy for a brand NDC with an equivalent
+// generic NDC cheaper on average.
+type SubstitutionOpportunity struct {
+	BrandNDC         string  `json:"brand_ndc"`              // Brand NDC billed by the pharmacy
+	BrandName        string  `json:"brand_name,omitempty"`   // Name of the brand NDC
+	GenericNDC       string  `json:"generic_ndc"`            // Cheapest equivalent generic NDC
+	GenericName      string  `json:"generic_name,omitempty"` // Name of the generic NDC
+	Ingredient       string  `json:"ingredient"`             // Active ingredient of both NDCs
+	Strength         string  `json:"strength"`               // Strength of both NDCs
+	Form             string  `json:"form"`                   // Dosage form of both NDCs
+	ClaimCount       int     `json:"claim_count"`            // Number of paid claims for the brand NDC
+	Quantity         float64 `json:"quantity"`               // Total quantity of the brand NDC
+	BrandAmount      float64 `json:"brand_amount"`           // Total price of the brand NDC
+	BrandUnitPrice   float64 `json:"brand_unit_price"`       // Average unit price of the brand NDC billed by the pharmacy
+	GenericUnitPrice float64 `json:"generic_unit_price"`     // Average unit price of the generic NDC billed by every pharmacy
+	PotentialSavings float64 `json:"potential_savings"`      // BrandAmount - Quantity * GenericUnitPrice
+}
+
+// PharmacySubstitutionSavings represents the generic substitution opportunities of a pharmacy.
+type PharmacySubstitutionSavings struct {
+	NPI              string                    `json:"npi"`               // National Provider Identifier of the pharmacy
+	Chain            string                    `json:"chain"`             // Pharmacy chain
+	ClaimCount       int                       `json:"claim_count"`       // Number of brand claims with savings
+	BrandAmount      float64                   `json:"brand_amount"`      // Total price of those claims
+	PotentialSavings float64                   `json:"potential_savings"` // Savings of every opportunity
+	Opportunities    []SubstitutionOpportunity `json:"opportunities"`     // Opportunities by brand NDC
+}
+
+// ChainSubstitutionSavings represents the generic substitution savings of a pharmacy chain.
+type ChainSubstitutionSavings struct {
+	Chain            string  `json:"chain"`             // Pharmacy chain
+	ClaimCount       int     `json:"claim_count"`       // Number of brand claims with savings
+	BrandAmount      float64 `json:"brand_amount"`      // Total price of those claims
+	PotentialSavings float64 `json:"potential_savings"` // Savings of the pharmacies of the chain
+}
+
+// SubstitutionReport represents the potential savings of substituting brand NDCs with cheaper
+// equivalent generic NDCs over a period.
+type SubstitutionReport struct {
+	From       string                        `json:"from"`       // First day of the period (YYYY-MM-DD)
+	To         string                        `json:"to"`         // Last day of the period (YYYY-MM-DD)
+	Date       string                        `json:"date"`       // Date placing the claims in the period: received or service
+	Pharmacies []PharmacySubstitutionSavings `json:"pharmacies"` // Savings by pharmacy, sorted by NPI
+	Chains     []ChainSubstitutionSavings    `json:"chains"`     // Savings by chain, sorted by chain
+	Total      ChainSubstitutionSavings      `json:"total"`      // Savings of every chain, with an empty chain
+}
+
+// ReportExport describes a report exported as a file.
+type ReportExport struct {
+	FileName string // Name of the exported file
+	Content  []byte // Content of the file
+}
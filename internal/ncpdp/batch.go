@@ -0,0 +1,213 @@
+package ncpdp
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record identifiers of the NCPDP Batch Standard.
+const (
+	batchHeaderID  = "00"
+	batchDetailID  = "G1"
+	batchTrailerID = "99"
+)
+
+// Batch transmission types (880-K6).
+const (
+	BatchTransaction = "T"
+	BatchResponse    = "R"
+)
+
+const (
+	batchHeaderLength     = 73
+	batchTrailerMinLength = 19
+	batchReferenceLength  = 10
+)
+
+// BatchHeader is the transmission header record of a batch file.
+type BatchHeader struct {
+	TransmissionType string // 880-K6: T transaction, R response
+	SenderID         string // 880-K1
+	BatchNumber      string // 806-5C
+	CreationDate     string // 880-K2 (CCYYMMDD)
+	CreationTime     string // 880-K3 (HHMM)
+	FileType         string // 702: P production, T test
+	Version          string // 102-A2
+	ReceiverID       string // 880-K7
+}
+
+// BatchDetail is a detail record wrapping a single D.0 transmission.
+type BatchDetail struct {
+	ReferenceNumber string // 880-K5
+	Transmission    []byte
+}
+
+// BatchTrailer is the transmission trailer record of a batch file.
+type BatchTrailer struct {
+	BatchNumber string // 806-5C
+	RecordCount int    // 751: header, detail and trailer records
+	Message     string // 504-F4
+}
+
+// Batch is a parsed batch file.
+type Batch struct {
+	Header  BatchHeader
+	Details []BatchDetail
+	Trailer BatchTrailer
+}
+
+// ParseBatch parses a batch file and validates its header and trailer.
+// Records are framed by STX and ETX; anything between records (e.g. line breaks) is ignored.
+func ParseBatch(data []byte) (*Batch, error) {
+	records, err := splitBatchRecords(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%w: batch must contain at least a header and a trailer", ErrMalformedTransaction)
+	}
+
+	batch := &Batch{}
+
+	header := string(records[0])
+	if !strings.HasPrefix(header, batchHeaderID) || len(header) < batchHeaderLength {
+		return nil, fmt.Errorf("%w: invalid batch header record", ErrMalformedTransaction)
+	}
+	batch.Header = BatchHeader{
+		TransmissionType: header[2:3],
+		SenderID:         strings.TrimSpace(header[3:27]),
+		BatchNumber:      strings.TrimSpace(header[27:34]),
+		CreationDate:     header[34:42],
+		CreationTime:     header[42:46],
+		FileType:         header[46:47],
+		Version:          header[47:49],
+		ReceiverID:       strings.TrimSpace(header[49:73]),
+	}
+
+	trailer := string(records[len(records)-1])
+	if !strings.HasPrefix(trailer, batchTrailerID) || len(trailer) < batchTrailerMinLength {
+		return nil, fmt.Errorf("%w: invalid batch trailer record", ErrMalformedTransaction)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(trailer[9:19]))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid batch trailer record count '%s'", ErrMalformedTransaction, trailer[9:19])
+	}
+	batch.Trailer = BatchTrailer{
+		BatchNumber: strings.TrimSpace(trailer[2:9]),
+		RecordCount: count,
+		Message:     strings.TrimSpace(trailer[19:]),
+	}
+
+	for i, record := range records[1 : len(records)-1] {
+		if !bytes.HasPrefix(record, []byte(batchDetailID)) || len(record) < 2+batchReferenceLength {
+			return nil, fmt.Errorf("%w: invalid batch detail record %d", ErrMalformedTransaction, i+1)
+		}
+		batch.Details = append(batch.Details, BatchDetail{
+			ReferenceNumber: strings.TrimSpace(string(record[2 : 2+batchReferenceLength])),
+			Transmission:    record[2+batchReferenceLength:],
+		})
+	}
+
+	if err := batch.Validate(); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// Validate checks that the trailer matches the header and the number of records.
+func (b *Batch) Validate() error {
+	if b.Header.TransmissionType != BatchTransaction && b.Header.TransmissionType != BatchResponse {
+		return fmt.Errorf("%w: unknown batch transmission type '%s'", ErrMalformedTransaction, b.Header.TransmissionType)
+	}
+	if b.Header.BatchNumber == "" {
+		return fmt.Errorf("%w: batch number is required", ErrMalformedTransaction)
+	}
+	if b.Trailer.BatchNumber != b.Header.BatchNumber {
+		return fmt.Errorf("%w: trailer batch number '%s' does not match header batch number '%s'", ErrMalformedTransaction, b.Trailer.BatchNumber, b.Header.BatchNumber)
+	}
+	if expected := len(b.Details) + 2; b.Trailer.RecordCount != expected {
+		return fmt.Errorf("%w: trailer record count %d does not match %d records", ErrMalformedTransaction, b.Trailer.RecordCount, expected)
+	}
+	return nil
+}
+
+// Encode serializes the batch, setting the trailer record count from the detail records.
+func (b *Batch) Encode() []byte {
+	var buf bytes.Buffer
+
+	writeBatchRecord(&buf, batchHeaderID+
+		pad(b.Header.TransmissionType, 1)+
+		pad(b.Header.SenderID, 24)+
+		pad(b.Header.BatchNumber, 7)+
+		pad(b.Header.CreationDate, 8)+
+		pad(b.Header.CreationTime, 4)+
+		pad(b.Header.FileType, 1)+
+		pad(b.Header.Version, 2)+
+		pad(b.Header.ReceiverID, 24))
+
+	for _, detail := range b.Details {
+		writeBatchRecord(&buf, batchDetailID+pad(detail.ReferenceNumber, batchReferenceLength)+string(detail.Transmission))
+	}
+
+	b.Trailer.RecordCount = len(b.Details) + 2
+	writeBatchRecord(&buf, batchTrailerID+
+		pad(b.Trailer.BatchNumber, 7)+
+		fmt.Sprintf("%010d", b.Trailer.RecordCount)+
+		pad(b.Trailer.Message, 35))
+
+	return buf.Bytes()
+}
+
+func writeBatchRecord(buf *bytes.Buffer, record string) {
+	buf.WriteByte(startOfText)
+	buf.WriteString(record)
+	buf.WriteByte(endOfText)
+	buf.WriteByte('\n')
+}
+
+func splitBatchRecords(data []byte) ([][]byte, error) {
+	var records [][]byte
+	for {
+		start := bytes.IndexByte(data, startOfText)
+		if start < 0 {
+			break
+		}
+		end := bytes.IndexByte(data[start:], endOfText)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: batch record without ETX", ErrMalformedTransaction)
+		}
+		records = append(records, data[start+1:start+end])
+		data = data[start+end+1:]
+	}
+	return records, nil
+}
+
+// ProcessBatch replays every detail transmission of a batch through the processor and
+// returns the response batch, with one detail record per transaction carrying its reference number.
+func (p *Processor) ProcessBatch(batch *Batch) *Batch {
+	now := time.Now()
+	response := &Batch{
+		Header: BatchHeader{
+			TransmissionType: BatchResponse,
+			SenderID:         batch.Header.ReceiverID,
+			BatchNumber:      batch.Header.BatchNumber,
+			CreationDate:     now.Format("20060102"),
+			CreationTime:     now.Format("1504"),
+			FileType:         batch.Header.FileType,
+			Version:          batch.Header.Version,
+			ReceiverID:       batch.Header.SenderID,
+		},
+		Trailer: BatchTrailer{BatchNumber: batch.Header.BatchNumber},
+	}
+
+	for _, detail := range batch.Details {
+		response.Details = append(response.Details, BatchDetail{
+			ReferenceNumber: detail.ReferenceNumber,
+			Transmission:    p.Process(detail.Transmission),
+		})
+	}
+	return response
+}
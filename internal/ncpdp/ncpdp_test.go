@@ -0,0 +1,450 @@
+package ncpdp_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/ncpdp"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// fakeClaimService records the requests it receives and answers from fixed data.
+type fakeClaimService struct {
+	service.ClaimService
+	submitted []models.ClaimSubmissionRequest
+	reversed  []models.ClaimReversalRequest
+}
+
+func (f *fakeClaimService) SubmitClaim(req models.ClaimSubmissionRequest) (*models.Claim, error) {
+	f.submitted = append(f.submitted, req)
+	if req.NPI != "1234567890" {
+		return nil, fmt.Errorf("%w '%s'", service.ErrUnknownNPI, req.NPI)
+	}
+	if req.NDC == "99999999999" {
+		return &models.Claim{ID: "claim-2", NDC: req.NDC, NPI: req.NPI, Status: models.ClaimStatusRejected, Rejects: []models.ClaimReject{
+			{Code: "70", Rule: "blocked", Message: "NDC 99999999999 is not covered"},
+			{Code: "76", Rule: "max-quantity", Message: "quantity exceeds the maximum"},
+		}}, nil
+	}
+	claim := &models.Claim{ID: "claim-1", NDC: req.NDC, NPI: req.NPI, Quantity: req.Quantity, Price: req.Price, AllowedAmount: 70.5}
+	if req.MemberID == "M1001" {
+		claim.ClaimBenefit = models.ClaimBenefit{Plan: "GOLD", PlanYear: 2024, PatientPay: 20.5, DeductibleApplied: 10}
+	}
+	return claim, nil
+}
+
+func (f *fakeClaimService) GetPrescriptionClaim(npi, rxNumber string, fillNumber int, dateOfService string) (*models.Claim, error) {
+	if npi == "1234567890" && rxNumber == "000000123456" && fillNumber == 1 && dateOfService == "2024-02-01" {
+		return &models.Claim{ID: "claim-1", NPI: npi}, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeClaimService) ReverseClaim(req models.ClaimReversalRequest) (*models.Revert, error) {
+	f.reversed = append(f.reversed, req)
+	if req.ClaimID != "claim-1" {
+		return nil, errors.New("claim with ID '" + req.ClaimID + "' not found for reversal")
+	}
+	return &models.Revert{ID: "revert-1", ClaimID: req.ClaimID}, nil
+}
+
+func billingRequest(npi string) *ncpdp.Request {
+	claim := ncpdp.Segment{ID: ncpdp.SegmentClaim}
+	claim.Add(ncpdp.FieldRxReferenceQualifier, "1")
+	claim.Add(ncpdp.FieldRxReferenceNumber, "000000123456")
+	claim.Add(ncpdp.FieldProductIDQualifier, ncpdp.ProductIDQualifierNDC)
+	claim.Add(ncpdp.FieldProductID, "00002323401")
+	claim.Add(ncpdp.FieldQuantityDispensed, ncpdp.FormatQuantity(5.5))
+	claim.Add(ncpdp.FieldFillNumber, "1")
+	claim.Add(ncpdp.FieldDaysSupply, "30")
+	claim.Add(ncpdp.FieldDAWCode, "0")
+
+	pricing := ncpdp.Segment{ID: ncpdp.SegmentPricing}
+	pricing.Add(ncpdp.FieldIngredientCostSubmitted, ncpdp.FormatAmount(70))
+	pricing.Add(ncpdp.FieldDispensingFeeSubmitted, ncpdp.FormatAmount(5.25))
+
+	return &ncpdp.Request{
+		Header: ncpdp.RequestHeader{
+			BIN:                        "610144",
+			Version:                    ncpdp.VersionD0,
+			TransactionCode:            ncpdp.TransactionB1,
+			PCN:                        "PCN1",
+			TransactionCount:           1,
+			ServiceProviderIDQualifier: ncpdp.ServiceProviderIDQualifierNPI,
+			ServiceProviderID:          npi,
+			DateOfService:              "20240201",
+			SoftwareVendorID:           "VENDOR01",
+		},
+		Transactions: [][]ncpdp.Segment{{claim, pricing}},
+	}
+}
+
+func TestAmountOverpunch(t *testing.T) {
+	cases := map[string]float64{"105{": 10.50, "7525E": 752.55, "100}": -10.00, "2J": -0.21, "{": 0}
+
+	for encoded, amount := range cases {
+		decoded, err := ncpdp.ParseAmount(encoded)
+		assert.Nil(t, err, "Expected no error decoding %s", encoded)
+		assert.InDelta(t, amount, decoded, 0.001, "Decoded amount for %s", encoded)
+		assert.Equal(t, encoded, ncpdp.FormatAmount(amount), "Encoded amount for %v", amount)
+	}
+
+	_, err := ncpdp.ParseAmount("12X")
+	assert.NotNil(t, err, "Expected an error for an invalid overpunch character")
+}
+
+func TestRequestRoundTrip(t *testing.T) {
+	req := billingRequest("1234567890")
+
+	parsed, err := ncpdp.ParseRequest(req.Encode())
+
+	assert.Nil(t, err, "Expected an encoded request to parse")
+	assert.Equal(t, req, parsed, "Parsed request should match the encoded one")
+}
+
+func TestProcessBillingPaid(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "610144")
+
+	resp, err := ncpdp.ParseResponse(processor.Process(billingRequest("1234567890").Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	assert.Equal(t, ncpdp.HeaderAccepted, resp.Header.Status)
+	assert.Len(t, claimService.submitted, 1, "Expected the claim to be submitted")
+	assert.Equal(t, models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 5.5, Price: 75.25,
+		Prescription: models.Prescription{RxNumber: "000000123456", FillNumber: 1, DaysSupply: 30, DateOfService: "2024-02-01", DAWCode: "0"},
+	}, claimService.submitted[0])
+
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	authorization, _ := status.Get(ncpdp.FieldAuthorizationNumber)
+	assert.Equal(t, ncpdp.StatusPaid, responseStatus)
+	assert.Equal(t, "claim-1", authorization)
+
+	pricing, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponsePricing)
+	paid, _ := pricing.Get(ncpdp.FieldTotalAmountPaid)
+	assert.Equal(t, ncpdp.FormatAmount(70.5), paid, "Expected the allowed amount to be paid")
+}
+
+func TestProcessBillingPatientPay(t *testing.T) {
+	processor := ncpdp.NewProcessor(&fakeClaimService{}, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	insurance := ncpdp.Segment{ID: ncpdp.SegmentInsurance}
+	insurance.Add(ncpdp.FieldCardholderID, "M1001")
+	req.Segments = append(req.Segments, insurance)
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	pricing, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponsePricing)
+	patientPay, _ := pricing.Get(ncpdp.FieldPatientPayAmount)
+	paid, _ := pricing.Get(ncpdp.FieldTotalAmountPaid)
+	deductible, _ := pricing.Get(ncpdp.FieldAmountAppliedToDeductible)
+	assert.Equal(t, ncpdp.FormatAmount(20.5), patientPay)
+	assert.Equal(t, ncpdp.FormatAmount(50), paid, "Expected the plan to pay the allowed amount less the patient pay")
+	assert.Equal(t, ncpdp.FormatAmount(10), deductible)
+}
+
+func TestProcessBillingRejectedUnknownPharmacy(t *testing.T) {
+	processor := ncpdp.NewProcessor(&fakeClaimService{}, logger.NewLogger(), "")
+
+	resp, err := ncpdp.ParseResponse(processor.Process(billingRequest("8888888888").Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	assert.Equal(t, ncpdp.StatusRejected, responseStatus)
+	assert.Equal(t, []string{ncpdp.RejectNonMatchedPharmacy}, status.GetAll(ncpdp.FieldRejectCode))
+}
+
+func TestProcessBillingRejectedQuantity(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	req.Transactions[0][0].Fields[4].Value = "0"
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	assert.Equal(t, []string{ncpdp.RejectMissingQuantity}, status.GetAll(ncpdp.FieldRejectCode))
+	assert.Empty(t, claimService.submitted, "Rejected claims should not reach the claim service")
+}
+
+func TestProcessBillingMemberAndPrescriber(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	insurance := ncpdp.Segment{ID: ncpdp.SegmentInsurance}
+	insurance.Add(ncpdp.FieldCardholderID, "M1001")
+	req.Segments = append(req.Segments, insurance)
+	prescriber := ncpdp.Segment{ID: ncpdp.SegmentPrescriber}
+	prescriber.Add(ncpdp.FieldPrescriberIDQualifier, ncpdp.ServiceProviderIDQualifierNPI)
+	prescriber.Add(ncpdp.FieldPrescriberID, "1999999984")
+	req.Transactions[0] = append(req.Transactions[0], prescriber)
+
+	_, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	assert.Len(t, claimService.submitted, 1, "Expected the claim to be submitted")
+	assert.Equal(t, "M1001", claimService.submitted[0].MemberID)
+	assert.Equal(t, "1999999984", claimService.submitted[0].PrescriberNPI)
+}
+
+func TestProcessBillingOverrideCode(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	req.Transactions[0][0].Add(ncpdp.FieldSubmissionClarification, "3")
+
+	_, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	assert.Len(t, claimService.submitted, 1, "Expected the claim to be submitted")
+	assert.Equal(t, models.OverrideVacationSupply, claimService.submitted[0].OverrideCode)
+}
+
+func TestProcessBillingRejectedDaysSupply(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	req.Transactions[0][0].Fields[6].Value = "3O"
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	assert.Equal(t, []string{ncpdp.RejectMissingDaysSupply}, status.GetAll(ncpdp.FieldRejectCode))
+	assert.Empty(t, claimService.submitted, "Rejected claims should not reach the claim service")
+}
+
+func TestProcessBillingRejectedFutureDateOfService(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	req.Header.DateOfService = time.Now().AddDate(0, 0, 2).Format("20060102")
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	assert.Equal(t, []string{ncpdp.RejectMissingDateOfService}, status.GetAll(ncpdp.FieldRejectCode))
+	assert.Empty(t, claimService.submitted, "Rejected claims should not reach the claim service")
+}
+
+func TestProcessBillingRejectedAtAdjudication(t *testing.T) {
+	processor := ncpdp.NewProcessor(&fakeClaimService{}, logger.NewLogger(), "")
+	req := billingRequest("1234567890")
+	req.Transactions[0][0].Fields[3].Value = "99999999999"
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	message, _ := status.Get(ncpdp.FieldAdditionalMessage)
+	assert.Equal(t, ncpdp.StatusRejected, responseStatus)
+	assert.Equal(t, []string{"70", "76"}, status.GetAll(ncpdp.FieldRejectCode))
+	assert.Contains(t, message, "is not covered")
+}
+
+func reversalRequest(npi string) *ncpdp.Request {
+	claim := ncpdp.Segment{ID: ncpdp.SegmentClaim}
+	claim.Add(ncpdp.FieldRxReferenceQualifier, "1")
+	claim.Add(ncpdp.FieldRxReferenceNumber, "000000123456")
+	claim.Add(ncpdp.FieldFillNumber, "1")
+	req := billingRequest(npi)
+	req.Header.TransactionCode = ncpdp.TransactionB2
+	req.Transactions = [][]ncpdp.Segment{{claim}}
+	return req
+}
+
+func TestProcessReversal(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+
+	resp, err := ncpdp.ParseResponse(processor.Process(reversalRequest("1234567890").Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	assert.Equal(t, ncpdp.StatusAccepted, responseStatus)
+	assert.Equal(t, []models.ClaimReversalRequest{{ClaimID: "claim-1", ReasonCode: models.ReversalReasonPharmacyReversal, Actor: models.ActorNCPDP}}, claimService.reversed)
+}
+
+func TestProcessReversalOtherPharmacyNotFound(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+
+	resp, err := ncpdp.ParseResponse(processor.Process(reversalRequest("8888888888").Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	message, _ := status.Get(ncpdp.FieldAdditionalMessage)
+	assert.Equal(t, ncpdp.StatusRejected, responseStatus)
+	assert.Equal(t, []string{ncpdp.RejectReversalNotProcessed}, status.GetAll(ncpdp.FieldRejectCode))
+	assert.Contains(t, message, "not found")
+	assert.Empty(t, claimService.reversed, "Expected no claim of another pharmacy to be reversed")
+}
+
+func TestProcessReversalClaimIDNotAccepted(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+	req := reversalRequest("1234567890")
+	req.Transactions[0][0].Fields[1].Value = "claim-1"
+
+	resp, err := ncpdp.ParseResponse(processor.Process(req.Encode()))
+
+	assert.Nil(t, err, "Expected a parsable response")
+	status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+	responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+	assert.Equal(t, ncpdp.StatusRejected, responseStatus)
+	assert.Empty(t, claimService.reversed, "Expected the reference number to be matched as an Rx number, not a claim ID")
+}
+
+func TestProcessMalformedTransmission(t *testing.T) {
+	processor := ncpdp.NewProcessor(&fakeClaimService{}, logger.NewLogger(), "")
+
+	resp, err := ncpdp.ParseResponse(processor.Process([]byte("garbage")))
+
+	assert.Nil(t, err, "Expected a parsable response even for malformed input")
+	assert.Equal(t, ncpdp.HeaderRejected, resp.Header.Status)
+}
+
+func testBatch() *ncpdp.Batch {
+	return &ncpdp.Batch{
+		Header: ncpdp.BatchHeader{
+			TransmissionType: ncpdp.BatchTransaction,
+			SenderID:         "CHAIN01",
+			BatchNumber:      "0000042",
+			CreationDate:     "20240201",
+			CreationTime:     "2330",
+			FileType:         "P",
+			Version:          "15",
+			ReceiverID:       "PROCESSOR",
+		},
+		Details: []ncpdp.BatchDetail{
+			{ReferenceNumber: "1", Transmission: billingRequest("1234567890").Encode()},
+			{ReferenceNumber: "2", Transmission: billingRequest("8888888888").Encode()},
+		},
+		Trailer: ncpdp.BatchTrailer{BatchNumber: "0000042"},
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	claimService := &fakeClaimService{}
+	processor := ncpdp.NewProcessor(claimService, logger.NewLogger(), "")
+
+	batch, err := ncpdp.ParseBatch(testBatch().Encode())
+	assert.Nil(t, err, "Expected an encoded batch to parse")
+	assert.Len(t, batch.Details, 2)
+
+	response, err := ncpdp.ParseBatch(processor.ProcessBatch(batch).Encode())
+
+	assert.Nil(t, err, "Expected the response batch to parse")
+	assert.Equal(t, ncpdp.BatchResponse, response.Header.TransmissionType)
+	assert.Equal(t, "PROCESSOR", response.Header.SenderID)
+	assert.Equal(t, "CHAIN01", response.Header.ReceiverID)
+	assert.Len(t, response.Details, 2)
+
+	expected := map[string]string{"1": ncpdp.StatusPaid, "2": ncpdp.StatusRejected}
+	for _, detail := range response.Details {
+		resp, err := ncpdp.ParseResponse(detail.Transmission)
+		assert.Nil(t, err, "Expected detail %s to carry a D.0 response", detail.ReferenceNumber)
+		status, _ := ncpdp.FindSegment(resp.Transactions[0], ncpdp.SegmentResponseStatus)
+		responseStatus, _ := status.Get(ncpdp.FieldTransactionResponseStatus)
+		assert.Equal(t, expected[detail.ReferenceNumber], responseStatus, "Status of detail %s", detail.ReferenceNumber)
+	}
+}
+
+func TestParseBatchTrailerCountMismatch(t *testing.T) {
+	data := testBatch().Encode()
+	data = []byte(strings.Replace(string(data), "0000000004", "0000000009", 1))
+
+	_, err := ncpdp.ParseBatch(data)
+
+	assert.NotNil(t, err, "Expected an error when the trailer count does not match")
+	assert.Contains(t, err.Error(), "trailer record count 9")
+}
+
+// startTCPServer serves the fake claim service on a free loopback port and returns its address.
+func startTCPServer(t *testing.T, allowed string) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error finding a free port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, network, _ := net.ParseCIDR(allowed)
+	server := ncpdp.NewTCPServer(addr, ncpdp.NewProcessor(&fakeClaimService{}, logger.NewLogger(), ""), logger.NewLogger(), []*net.IPNet{network})
+	go server.ListenAndServe()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	})
+
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, time.Second, 10*time.Millisecond, "Expected the TCP listener to start")
+	return addr
+}
+
+// frameFrom reads the next STX/ETX framed transmission of conn, or fails when it is closed first.
+func frameFrom(conn net.Conn) ([]byte, error) {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return bufio.NewReader(conn).ReadBytes('\x03')
+}
+
+func TestTCPServerProcessesFramedTransmission(t *testing.T) {
+	conn, err := net.Dial("tcp", startTCPServer(t, "127.0.0.0/8"))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	conn.Write(append(append([]byte{'\x02'}, billingRequest("1234567890").Encode()...), '\x03'))
+	frame, err := frameFrom(conn)
+
+	assert.Nil(t, err, "Expected a framed response")
+	resp, err := ncpdp.ParseResponse(frame[1 : len(frame)-1])
+	assert.Nil(t, err, "Expected a parsable response")
+	assert.Equal(t, ncpdp.HeaderAccepted, resp.Header.Status)
+}
+
+func TestTCPServerRefusesAddressNotAllowed(t *testing.T) {
+	conn, err := net.Dial("tcp", startTCPServer(t, "10.0.0.0/8"))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	conn.Write(append(append([]byte{'\x02'}, billingRequest("1234567890").Encode()...), '\x03'))
+	_, err = frameFrom(conn)
+
+	assert.NotNil(t, err, "Expected the connection to be closed without a response")
+}
+
+func TestTCPServerClosesOversizedFrame(t *testing.T) {
+	conn, err := net.Dial("tcp", startTCPServer(t, "127.0.0.0/8"))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	conn.Write(append([]byte{'\x02'}, []byte(strings.Repeat("A", 2*ncpdp.MaxTransmissionSize))...))
+	_, err = frameFrom(conn)
+
+	assert.NotNil(t, err, "Expected the connection to be closed when no ETX comes within the maximum size")
+}
@@ -0,0 +1,70 @@
+package ncpdp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Signed overpunch characters for the last digit of s9(n)v99 amount fields.
+const (
+	positiveOverpunch = "{ABCDEFGHI"
+	negativeOverpunch = "}JKLMNOPQR"
+)
+
+// ParseAmount decodes a signed overpunch dollar amount with two implied decimals (e.g. "105{" is 10.50).
+func ParseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, fmt.Errorf("empty amount")
+	}
+
+	last := value[len(value)-1]
+	digits := value[:len(value)-1]
+	sign := 1.0
+
+	if i := strings.IndexByte(positiveOverpunch, last); i >= 0 {
+		digits += strconv.Itoa(i)
+	} else if i := strings.IndexByte(negativeOverpunch, last); i >= 0 {
+		digits += strconv.Itoa(i)
+		sign = -1
+	} else if last >= '0' && last <= '9' {
+		digits += string(last)
+	} else {
+		return 0, fmt.Errorf("invalid amount '%s'", value)
+	}
+
+	cents, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount '%s'", value)
+	}
+	return sign * float64(cents) / 100, nil
+}
+
+// FormatAmount encodes a dollar amount as signed overpunch with two implied decimals.
+func FormatAmount(amount float64) string {
+	cents := int64(math.Round(math.Abs(amount) * 100))
+	digits := strconv.FormatInt(cents, 10)
+	last := digits[len(digits)-1] - '0'
+
+	overpunch := positiveOverpunch
+	if amount < 0 && cents != 0 {
+		overpunch = negativeOverpunch
+	}
+	return digits[:len(digits)-1] + string(overpunch[last])
+}
+
+// ParseQuantity decodes a quantity with three implied decimals (e.g. "30000" is 30.000).
+func ParseQuantity(value string) (float64, error) {
+	thousandths, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid quantity '%s'", value)
+	}
+	return float64(thousandths) / 1000, nil
+}
+
+// FormatQuantity encodes a quantity with three implied decimals.
+func FormatQuantity(quantity float64) string {
+	return strconv.FormatInt(int64(math.Round(quantity*1000)), 10)
+}
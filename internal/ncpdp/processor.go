@@ -0,0 +1,459 @@
+package ncpdp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/service"
+)
+
+// Reject codes returned in field 511-FB.
+const (
+	RejectMissingBIN              = "01" // M/I BIN Number
+	RejectMissingPharmacyNumber   = "05" // M/I Pharmacy Number
+	RejectMissingDateOfService    = "15" // M/I Date of Service
+	RejectMissingFillNumber       = "17" // M/I Fill Number
+	RejectMissingDaysSupply       = "19" // M/I Days Supply
+	RejectMissingProductID        = "21" // M/I Product/Service ID
+	RejectMissingPrescriberID     = "25" // M/I Prescriber ID
+	RejectNonMatchedPharmacy      = "50" // Non-Matched Pharmacy Number
+	RejectClaimNotProcessed       = "85" // Claim Not Processed
+	RejectReversalNotProcessed    = "87" // Reversal Not Processed
+	RejectHostProcessingError     = "99" // Host Processing Error
+	RejectVersionNotSupported     = "1R" // Version/Release Not Supported
+	RejectTransactionNotSupported = "1S" // Transaction Code/Type Not Supported
+	RejectMissingTransCount       = "A9" // M/I Transaction Count
+	RejectMissingQuantity         = "E7" // M/I Quantity Dispensed
+	RejectMissingGrossAmountDue   = "DU" // M/I Gross Amount Due
+)
+
+// Transaction response statuses returned in field 112-AN.
+const (
+	StatusPaid     = "P"
+	StatusRejected = "R"
+	StatusAccepted = "A"
+	StatusCaptured = "C" // Pended for review at adjudication
+)
+
+// Header response statuses returned in field 501-F1.
+const (
+	HeaderAccepted = "A"
+	HeaderRejected = "R"
+)
+
+// rejection describes why a transaction was not processed.
+type rejection struct {
+	codes   []string
+	message string
+}
+
+func reject(code, format string, v ...interface{}) *rejection {
+	return &rejection{codes: []string{code}, message: fmt.Sprintf(format, v...)}
+}
+
+// Processor maps D.0 billing and reversal transactions onto the claim service.
+type Processor struct {
+	claimService service.ClaimService
+	logger       logger.Logger
+	bin          string
+}
+
+// NewProcessor creates a Processor. When bin is not empty, requests for other BINs are rejected.
+func NewProcessor(claimService service.ClaimService, log logger.Logger, bin string) *Processor {
+	return &Processor{
+		claimService: claimService,
+		logger:       log,
+		bin:          bin,
+	}
+}
+
+// Process parses a raw request transmission and returns the encoded response.
+// A response is always produced, malformed transmissions are answered with a rejected header.
+func (p *Processor) Process(data []byte) []byte {
+	req, err := ParseRequest(data)
+	if err != nil {
+		p.logger.Error("Error parsing NCPDP transaction: %v", err)
+		transactionCode := ""
+		if len(data) >= 10 {
+			transactionCode = string(data[8:10])
+		}
+		return rejectedHeader(RequestHeader{Version: VersionD0, TransactionCode: transactionCode, TransactionCount: 1},
+			reject(RejectClaimNotProcessed, "%v", err)).Encode()
+	}
+	return p.Handle(req).Encode()
+}
+
+// Handle processes a parsed request transmission.
+func (p *Processor) Handle(req *Request) *Response {
+	if r := p.checkHeader(req); r != nil {
+		p.logger.Warning("NCPDP %s transmission rejected: %s", req.Header.TransactionCode, r.message)
+		return rejectedHeader(req.Header, r)
+	}
+
+	resp := &Response{Header: responseHeader(req.Header, HeaderAccepted)}
+	for _, group := range req.Transactions {
+		switch req.Header.TransactionCode {
+		case TransactionB1:
+			resp.Transactions = append(resp.Transactions, p.handleBilling(req, group))
+		case TransactionB2:
+			resp.Transactions = append(resp.Transactions, p.handleReversal(req, group))
+		}
+	}
+	return resp
+}
+
+// checkHeader validates the fields of the transaction header shared by every transaction.
+func (p *Processor) checkHeader(req *Request) *rejection {
+	header := req.Header
+	if header.Version != VersionD0 {
+		return reject(RejectVersionNotSupported, "version '%s' is not supported", header.Version)
+	}
+	if header.TransactionCode != TransactionB1 && header.TransactionCode != TransactionB2 {
+		return reject(RejectTransactionNotSupported, "transaction code '%s' is not supported", header.TransactionCode)
+	}
+	if p.bin != "" && header.BIN != p.bin {
+		return reject(RejectMissingBIN, "BIN '%s' is not handled by this processor", header.BIN)
+	}
+	if header.TransactionCount < 1 || header.TransactionCount != len(req.Transactions) {
+		return reject(RejectMissingTransCount, "transaction count %d does not match %d transaction groups", header.TransactionCount, len(req.Transactions))
+	}
+	if header.ServiceProviderID == "" {
+		return reject(RejectMissingPharmacyNumber, "service provider ID is required")
+	}
+	if header.ServiceProviderIDQualifier != "" && header.ServiceProviderIDQualifier != ServiceProviderIDQualifierNPI {
+		return reject(RejectMissingPharmacyNumber, "service provider ID qualifier '%s' is not supported, expected NPI", header.ServiceProviderIDQualifier)
+	}
+	if pharmacy, ok := FindSegment(req.Segments, SegmentPharmacy); ok {
+		qualifier, _ := pharmacy.Get(FieldServiceProviderIDQualifier)
+		providerID, _ := pharmacy.Get(FieldServiceProviderID)
+		if qualifier == ServiceProviderIDQualifierNPI && providerID != "" && strings.TrimSpace(providerID) != header.ServiceProviderID {
+			return reject(RejectNonMatchedPharmacy, "pharmacy segment provider ID '%s' does not match header service provider ID", providerID)
+		}
+	}
+	return nil
+}
+
+// handleBilling submits the claim of a B1 transaction group.
+func (p *Processor) handleBilling(req *Request, group []Segment) []Segment {
+	claimSegment, _ := FindSegment(group, SegmentClaim)
+	claimReq, r := billingRequest(req, group)
+	if r != nil {
+		return rejectedTransaction(claimSegment, r)
+	}
+
+	claim, err := p.claimService.SubmitClaim(*claimReq)
+	if err != nil {
+		p.logger.Error("Error submitting NCPDP B1 claim for NPI %s: %v", claimReq.NPI, err)
+		switch {
+		case errors.Is(err, service.ErrUnknownNPI):
+			return rejectedTransaction(claimSegment, reject(RejectNonMatchedPharmacy, "%v", err))
+		case errors.Is(err, service.ErrInvalidClaimData), errors.Is(err, service.ErrInvalidPrescription):
+			return rejectedTransaction(claimSegment, reject(RejectClaimNotProcessed, "%v", err))
+		default:
+			return rejectedTransaction(claimSegment, reject(RejectHostProcessingError, "%v", err))
+		}
+	}
+
+	switch claim.Status {
+	case models.ClaimStatusRejected:
+		p.logger.Info("NCPDP B1 claim %s rejected at adjudication for NPI %s", claim.ID, claim.NPI)
+		return rejectedTransaction(claimSegment, adjudicationRejection(claim.Rejects))
+	case models.ClaimStatusPending:
+		status := Segment{ID: SegmentResponseStatus}
+		status.Add(FieldTransactionResponseStatus, StatusCaptured)
+		status.Add(FieldAuthorizationNumber, claim.ID)
+		status.Add(FieldAdditionalMessage, adjudicationRejection(claim.Rejects).message)
+		p.logger.Info("NCPDP B1 claim %s captured for review for NPI %s", claim.ID, claim.NPI)
+		return []Segment{status, responseClaim(claimSegment)}
+	}
+
+	status := Segment{ID: SegmentResponseStatus}
+	status.Add(FieldTransactionResponseStatus, StatusPaid)
+	status.Add(FieldAuthorizationNumber, claim.ID)
+
+	// The plan pays the allowed amount less the patient pay of members with a benefit plan.
+	pricing := Segment{ID: SegmentResponsePricing}
+	if claim.Plan != "" {
+		pricing.Add(FieldPatientPayAmount, FormatAmount(claim.PatientPay))
+	}
+	pricing.Add(FieldTotalAmountPaid, FormatAmount(claim.AllowedAmount-claim.PatientPay))
+	if claim.DeductibleApplied > 0 {
+		pricing.Add(FieldAmountAppliedToDeductible, FormatAmount(claim.DeductibleApplied))
+	}
+
+	p.logger.Info("NCPDP B1 claim %s paid for NPI %s", claim.ID, claim.NPI)
+	return []Segment{status, responseClaim(claimSegment), pricing}
+}
+
+// handleReversal reverses the claim referenced by a B2 transaction group: the claim of the
+// header's service provider for the prescription/service reference number (D2) and fill number
+// (D3) of the claim segment, filled on the header's date of service. Claims of other pharmacies
+// are never found. B2 transactions carry no reason, so the reversal is recorded with the
+// pharmacy reversal reason code.
+func (p *Processor) handleReversal(req *Request, group []Segment) []Segment {
+	claimSegment, ok := FindSegment(group, SegmentClaim)
+	if !ok {
+		return rejectedTransaction(claimSegment, reject(RejectReversalNotProcessed, "claim segment is required"))
+	}
+	rxNumber, _ := claimSegment.Get(FieldRxReferenceNumber)
+	rxNumber = strings.TrimSpace(rxNumber)
+	if rxNumber == "" {
+		return rejectedTransaction(claimSegment, reject(RejectReversalNotProcessed, "prescription/service reference number is required"))
+	}
+	fillNumber, err := optionalNumber(claimSegment, FieldFillNumber)
+	if err != nil {
+		return rejectedTransaction(claimSegment, reject(RejectMissingFillNumber, "fill number must be a number"))
+	}
+	date, err := time.Parse("20060102", req.Header.DateOfService)
+	if err != nil {
+		return rejectedTransaction(claimSegment, reject(RejectMissingDateOfService, "date of service '%s' must be formatted as CCYYMMDD", req.Header.DateOfService))
+	}
+	npi := req.Header.ServiceProviderID
+
+	claim, err := p.claimService.GetPrescriptionClaim(npi, rxNumber, fillNumber, date.Format("2006-01-02"))
+	if err != nil {
+		p.logger.Error("Error fetching NCPDP B2 claim of Rx %s for NPI %s: %v", rxNumber, npi, err)
+		return rejectedTransaction(claimSegment, reject(RejectHostProcessingError, "%v", err))
+	}
+	if claim == nil {
+		p.logger.Warning("NCPDP B2 reversal of Rx %s fill %d on %s not found for NPI %s", rxNumber, fillNumber, req.Header.DateOfService, npi)
+		return rejectedTransaction(claimSegment, reject(RejectReversalNotProcessed, "claim of Rx %s fill %d on %s not found for this pharmacy", rxNumber, fillNumber, req.Header.DateOfService))
+	}
+
+	revert, err := p.claimService.ReverseClaim(models.ClaimReversalRequest{
+		ClaimID:    claim.ID,
+		ReasonCode: models.ReversalReasonPharmacyReversal,
+		Actor:      models.ActorNCPDP,
+	})
+	if err != nil {
+		p.logger.Error("Error reversing NCPDP B2 claim %s: %v", claim.ID, err)
+		if strings.HasPrefix(err.Error(), "internal error") {
+			return rejectedTransaction(claimSegment, reject(RejectHostProcessingError, "%v", err))
+		}
+		return rejectedTransaction(claimSegment, reject(RejectReversalNotProcessed, "%v", err))
+	}
+
+	status := Segment{ID: SegmentResponseStatus}
+	status.Add(FieldTransactionResponseStatus, StatusAccepted)
+	status.Add(FieldAuthorizationNumber, revert.ID)
+
+	p.logger.Info("NCPDP B2 reversal accepted for claim %s", claim.ID)
+	return []Segment{status, responseClaim(claimSegment)}
+}
+
+// billingRequest extracts the claim submission from a B1 transaction group.
+func billingRequest(req *Request, group []Segment) (*models.ClaimSubmissionRequest, *rejection) {
+	claimSegment, ok := FindSegment(group, SegmentClaim)
+	if !ok {
+		return nil, reject(RejectClaimNotProcessed, "claim segment is required")
+	}
+
+	qualifier, _ := claimSegment.Get(FieldProductIDQualifier)
+	if qualifier != "" && qualifier != ProductIDQualifierNDC {
+		return nil, reject(RejectMissingProductID, "product/service ID qualifier '%s' is not supported, expected NDC", qualifier)
+	}
+	ndc, _ := claimSegment.Get(FieldProductID)
+	ndc = strings.TrimSpace(ndc)
+	if ndc == "" {
+		return nil, reject(RejectMissingProductID, "product/service ID is required")
+	}
+
+	rawQuantity, _ := claimSegment.Get(FieldQuantityDispensed)
+	quantity, err := ParseQuantity(rawQuantity)
+	if err != nil || quantity <= 0 {
+		return nil, reject(RejectMissingQuantity, "quantity dispensed must be positive")
+	}
+
+	price, r := grossAmountDue(group)
+	if r != nil {
+		return nil, r
+	}
+
+	prescription, r := billingPrescription(req, claimSegment, group)
+	if r != nil {
+		return nil, r
+	}
+
+	return &models.ClaimSubmissionRequest{
+		NDC:      ndc,
+		NPI:      req.Header.ServiceProviderID,
+		Quantity: quantity,
+		Price:    price,
+
+		Prescription: prescription,
+	}, nil
+}
+
+// billingPrescription reads the member and prescription details of a B1 transaction: the date of
+// service of the header, the cardholder ID of the insurance segment, the prescriber of the
+// prescriber segment and the prescription fields of the claim segment. Absent fields stay empty.
+func billingPrescription(req *Request, claimSegment Segment, group []Segment) (models.Prescription, *rejection) {
+	var prescription models.Prescription
+	if req.Header.DateOfService != "" {
+		date, err := time.Parse("20060102", req.Header.DateOfService)
+		if err != nil {
+			return prescription, reject(RejectMissingDateOfService, "date of service '%s' must be formatted as CCYYMMDD", req.Header.DateOfService)
+		}
+		if req.Header.DateOfService > time.Now().Format("20060102") {
+			return prescription, reject(RejectMissingDateOfService, "date of service '%s' is in the future", req.Header.DateOfService)
+		}
+		prescription.DateOfService = date.Format("2006-01-02")
+	}
+
+	if insurance, ok := FindSegment(req.Segments, SegmentInsurance); ok {
+		cardholderID, _ := insurance.Get(FieldCardholderID)
+		prescription.MemberID = strings.TrimSpace(cardholderID)
+	}
+
+	prescriber, ok := FindSegment(group, SegmentPrescriber)
+	if !ok {
+		prescriber, ok = FindSegment(req.Segments, SegmentPrescriber)
+	}
+	if ok {
+		qualifier, _ := prescriber.Get(FieldPrescriberIDQualifier)
+		if qualifier != "" && qualifier != ServiceProviderIDQualifierNPI {
+			return prescription, reject(RejectMissingPrescriberID, "prescriber ID qualifier '%s' is not supported, expected NPI", qualifier)
+		}
+		prescriberID, _ := prescriber.Get(FieldPrescriberID)
+		prescription.PrescriberNPI = strings.TrimSpace(prescriberID)
+	}
+
+	rxNumber, _ := claimSegment.Get(FieldRxReferenceNumber)
+	prescription.RxNumber = strings.TrimSpace(rxNumber)
+	dawCode, _ := claimSegment.Get(FieldDAWCode)
+	prescription.DAWCode = strings.TrimSpace(dawCode)
+	// Submission clarification codes are two digits; single digits are accepted as sent by some systems.
+	if clarification, _ := claimSegment.Get(FieldSubmissionClarification); strings.TrimSpace(clarification) != "" {
+		prescription.OverrideCode = fmt.Sprintf("%02s", strings.TrimSpace(clarification))
+	}
+
+	var err error
+	if prescription.FillNumber, err = optionalNumber(claimSegment, FieldFillNumber); err != nil {
+		return prescription, reject(RejectMissingFillNumber, "fill number must be a number")
+	}
+	if prescription.DaysSupply, err = optionalNumber(claimSegment, FieldDaysSupply); err != nil {
+		return prescription, reject(RejectMissingDaysSupply, "days supply must be a number")
+	}
+	return prescription, nil
+}
+
+// optionalNumber reads a numeric field of a segment, 0 when it is absent or blank.
+func optionalNumber(segment Segment, fieldID string) (int, error) {
+	raw, _ := segment.Get(fieldID)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(raw)
+}
+
+// grossAmountDue reads the billed amount from the pricing segment, falling back to
+// ingredient cost plus dispensing fee when gross amount due is absent.
+func grossAmountDue(group []Segment) (float64, *rejection) {
+	pricing, ok := FindSegment(group, SegmentPricing)
+	if !ok {
+		return 0, reject(RejectMissingGrossAmountDue, "pricing segment is required")
+	}
+
+	if raw, ok := pricing.Get(FieldGrossAmountDue); ok {
+		amount, err := ParseAmount(raw)
+		if err != nil || amount <= 0 {
+			return 0, reject(RejectMissingGrossAmountDue, "gross amount due must be positive")
+		}
+		return amount, nil
+	}
+
+	var total float64
+	for _, fieldID := range []string{FieldIngredientCostSubmitted, FieldDispensingFeeSubmitted} {
+		raw, ok := pricing.Get(fieldID)
+		if !ok {
+			continue
+		}
+		amount, err := ParseAmount(raw)
+		if err != nil {
+			return 0, reject(RejectMissingGrossAmountDue, "invalid amount in field %s", fieldID)
+		}
+		total += amount
+	}
+	if total <= 0 {
+		return 0, reject(RejectMissingGrossAmountDue, "gross amount due must be positive")
+	}
+	return total, nil
+}
+
+func responseHeader(header RequestHeader, status string) ResponseHeader {
+	version := header.Version
+	if version == "" {
+		version = VersionD0
+	}
+	count := header.TransactionCount
+	if count < 1 {
+		count = 1
+	}
+	return ResponseHeader{
+		Version:                    version,
+		TransactionCode:            header.TransactionCode,
+		TransactionCount:           count,
+		Status:                     status,
+		ServiceProviderIDQualifier: header.ServiceProviderIDQualifier,
+		ServiceProviderID:          header.ServiceProviderID,
+		DateOfService:              header.DateOfService,
+	}
+}
+
+// rejectedHeader builds the response for a transmission whose header could not be accepted.
+func rejectedHeader(header RequestHeader, r *rejection) *Response {
+	message := Segment{ID: SegmentResponseMessage}
+	message.Add(FieldMessage, r.message)
+
+	return &Response{
+		Header:       responseHeader(header, HeaderRejected),
+		Segments:     []Segment{message},
+		Transactions: [][]Segment{{rejectionStatus(r)}},
+	}
+}
+
+// adjudicationRejection describes the rejects given to a claim by the adjudication rules.
+func adjudicationRejection(rejects []models.ClaimReject) *rejection {
+	r := &rejection{}
+	messages := make([]string, len(rejects))
+	for i, reject := range rejects {
+		r.codes = append(r.codes, reject.Code)
+		messages[i] = reject.Message
+	}
+	r.message = strings.Join(messages, "; ")
+	return r
+}
+
+func rejectedTransaction(claimSegment Segment, r *rejection) []Segment {
+	return []Segment{rejectionStatus(r), responseClaim(claimSegment)}
+}
+
+func rejectionStatus(r *rejection) Segment {
+	status := Segment{ID: SegmentResponseStatus}
+	status.Add(FieldTransactionResponseStatus, StatusRejected)
+	status.Add(FieldRejectCount, fmt.Sprintf("%d", len(r.codes)))
+	for _, code := range r.codes {
+		status.Add(FieldRejectCode, code)
+	}
+	status.Add(FieldAdditionalMessage, r.message)
+	return status
+}
+
+// responseClaim echoes the prescription reference of the request claim segment.
+func responseClaim(claimSegment Segment) Segment {
+	segment := Segment{ID: SegmentResponseClaim}
+	qualifier, ok := claimSegment.Get(FieldRxReferenceQualifier)
+	if !ok {
+		qualifier = "1"
+	}
+	segment.Add(FieldRxReferenceQualifier, qualifier)
+	reference, _ := claimSegment.Get(FieldRxReferenceNumber)
+	segment.Add(FieldRxReferenceNumber, reference)
+	return segment
+}
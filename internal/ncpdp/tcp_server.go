@@ -0,0 +1,213 @@
+package ncpdp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+)
+
+// Framing bytes wrapping each transmission on the TCP listener.
+const (
+	startOfText = '\x02'
+	endOfText   = '\x03'
+)
+
+const tcpIdleTimeout = 2 * time.Minute
+
+// MaxTransmissionSize bounds the size of a D.0 transmission accepted over HTTP and TCP.
+const MaxTransmissionSize = 64 * 1024
+
+// ErrServerClosed is returned by ListenAndServe after Shutdown is called.
+var ErrServerClosed = errors.New("ncpdp: server closed")
+
+// errFrameTooLarge is returned by readFrame when no ETX is found within MaxTransmissionSize bytes.
+var errFrameTooLarge = errors.New("ncpdp: frame exceeds the maximum transmission size")
+
+// TCPServer accepts D.0 transmissions framed as STX <transmission> ETX over raw TCP.
+// Each connection may send several transmissions; every one is answered with a framed response.
+// Only connections from the allowed networks are served; the raw protocol carries no credentials.
+type TCPServer struct {
+	addr      string
+	processor *Processor
+	logger    logger.Logger
+	allowed   []*net.IPNet
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewTCPServer creates a TCP server listening on addr (e.g. ":9100") that serves connections
+// from the allowed networks only.
+func NewTCPServer(addr string, processor *Processor, log logger.Logger, allowed []*net.IPNet) *TCPServer {
+	return &TCPServer{
+		addr:      addr,
+		processor: processor,
+		logger:    log,
+		allowed:   allowed,
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the configured address and serves connections until Shutdown.
+func (s *TCPServer) ListenAndServe() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return ErrServerClosed
+	}
+	s.listener = listener
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		if !s.isAllowed(conn.RemoteAddr()) {
+			s.logger.Warning("Refusing NCPDP connection from %s: address not allowed.", conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// Shutdown stops accepting connections, closes open ones and waits for in-flight
+// transmissions to finish or ctx to expire.
+func (s *TCPServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *TCPServer) serveConn(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	reader := bufio.NewReader(conn)
+	for {
+		s.mu.Lock()
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return
+		}
+
+		conn.SetReadDeadline(time.Now().Add(tcpIdleTimeout))
+		frame, err := readFrame(reader)
+		if err == errFrameTooLarge {
+			s.logger.Warning("Closing NCPDP connection from %s: %v.", conn.RemoteAddr(), err)
+			return
+		}
+		if err != nil {
+			return
+		}
+
+		start := -1
+		for i, b := range frame {
+			if b == startOfText {
+				start = i
+			}
+		}
+		if start < 0 {
+			s.logger.Warning("Discarding NCPDP frame from %s without STX.", conn.RemoteAddr())
+			continue
+		}
+
+		response := s.processor.Process(frame[start+1 : len(frame)-1])
+
+		framed := make([]byte, 0, len(response)+2)
+		framed = append(framed, startOfText)
+		framed = append(framed, response...)
+		framed = append(framed, endOfText)
+		if _, err := conn.Write(framed); err != nil {
+			s.logger.Error("Error writing NCPDP response to %s: %v", conn.RemoteAddr(), err)
+			return
+		}
+	}
+}
+
+// isAllowed reports whether addr belongs to one of the allowed networks.
+func (s *TCPServer) isAllowed(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	for _, network := range s.allowed {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// readFrame reads up to and including the next ETX. A frame longer than MaxTransmissionSize plus
+// its STX and ETX is rejected with errFrameTooLarge, so a client never sending ETX cannot grow
+// the buffer without limit.
+func readFrame(reader *bufio.Reader) ([]byte, error) {
+	var frame []byte
+	for {
+		chunk, err := reader.ReadSlice(endOfText)
+		if len(frame)+len(chunk) > MaxTransmissionSize+2 {
+			return nil, errFrameTooLarge
+		}
+		frame = append(frame, chunk...)
+		if err == nil {
+			return frame, nil
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package ncpdp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Separators defined by the NCPDP Telecommunication Standard.
+const (
+	FieldSeparator   = '\x1C'
+	GroupSeparator   = '\x1D'
+	SegmentSeparator = '\x1E'
+)
+
+// Version and transaction codes supported by this package.
+const (
+	VersionD0       = "D0"
+	TransactionB1   = "B1" // Billing
+	TransactionB2   = "B2" // Reversal
+	segmentIDPrefix = "AM"
+)
+
+// Segment identifiers (field 111-AM).
+const (
+	SegmentPatient         = "01"
+	SegmentPharmacy        = "02"
+	SegmentPrescriber      = "03"
+	SegmentInsurance       = "04"
+	SegmentClaim           = "07"
+	SegmentPricing         = "11"
+	SegmentResponseMessage = "20"
+	SegmentResponseStatus  = "21"
+	SegmentResponseClaim   = "22"
+	SegmentResponsePricing = "23"
+)
+
+// Field identifiers used by the billing and reversal transactions.
+const (
+	FieldServiceProviderIDQualifier = "EY" // 465-EY
+	FieldServiceProviderID          = "E9" // 444-E9
+	FieldRxReferenceQualifier       = "EM" // 455-EM
+	FieldRxReferenceNumber          = "D2" // 402-D2
+	FieldFillNumber                 = "D3" // 403-D3
+	FieldDaysSupply                 = "D5" // 405-D5
+	FieldDAWCode                    = "D8" // 408-D8
+	FieldSubmissionClarification    = "DK" // 420-DK
+	FieldCardholderID               = "C2" // 302-C2
+	FieldPrescriberIDQualifier      = "EZ" // 466-EZ
+	FieldPrescriberID               = "DB" // 411-DB
+	FieldProductIDQualifier         = "E1" // 436-E1
+	FieldProductID                  = "D7" // 407-D7
+	FieldQuantityDispensed          = "E7" // 442-E7
+	FieldIngredientCostSubmitted    = "D9" // 409-D9
+	FieldDispensingFeeSubmitted     = "DC" // 412-DC
+	FieldGrossAmountDue             = "DU" // 430-DU
+	FieldTransactionResponseStatus  = "AN" // 112-AN
+	FieldAuthorizationNumber        = "F3" // 503-F3
+	FieldRejectCount                = "FA" // 510-FA
+	FieldRejectCode                 = "FB" // 511-FB
+	FieldAdditionalMessage          = "FQ" // 526-FQ
+	FieldMessage                    = "F4" // 504-F4
+	FieldTotalAmountPaid            = "F9" // 509-F9
+	FieldPatientPayAmount           = "F5" // 505-F5
+	FieldAmountAppliedToDeductible  = "FH" // 517-FH
+)
+
+// ProductIDQualifierNDC identifies an NDC in field 436-E1.
+const ProductIDQualifierNDC = "03"
+
+// ServiceProviderIDQualifierNPI identifies an NPI in the service provider and prescriber ID qualifier fields.
+const ServiceProviderIDQualifierNPI = "01"
+
+const (
+	requestHeaderLength  = 56
+	responseHeaderLength = 31
+)
+
+// ErrMalformedTransaction is returned when a transmission cannot be parsed.
+var ErrMalformedTransaction = errors.New("malformed NCPDP transaction")
+
+// Field is a single field of a segment: a two character field identifier followed by its value.
+type Field struct {
+	ID    string
+	Value string
+}
+
+// Segment is an NCPDP segment identified by its 111-AM segment identification.
+type Segment struct {
+	ID     string
+	Fields []Field
+}
+
+// Get returns the value of the first field with the given identifier.
+func (s Segment) Get(fieldID string) (string, bool) {
+	for _, field := range s.Fields {
+		if field.ID == fieldID {
+			return field.Value, true
+		}
+	}
+	return "", false
+}
+
+// GetAll returns the values of every field with the given identifier (e.g. repeating reject codes).
+func (s Segment) GetAll(fieldID string) []string {
+	var values []string
+	for _, field := range s.Fields {
+		if field.ID == fieldID {
+			values = append(values, field.Value)
+		}
+	}
+	return values
+}
+
+// Add appends a field to the segment.
+func (s *Segment) Add(fieldID, value string) {
+	s.Fields = append(s.Fields, Field{ID: fieldID, Value: value})
+}
+
+// FindSegment returns the first segment with the given identifier.
+func FindSegment(segments []Segment, id string) (Segment, bool) {
+	for _, segment := range segments {
+		if segment.ID == id {
+			return segment, true
+		}
+	}
+	return Segment{}, false
+}
+
+// RequestHeader is the fixed-width transaction header of a request transmission.
+type RequestHeader struct {
+	BIN                        string // 101-A1
+	Version                    string // 102-A2
+	TransactionCode            string // 103-A3
+	PCN                        string // 104-A4
+	TransactionCount           int    // 109-A9
+	ServiceProviderIDQualifier string // 202-B2
+	ServiceProviderID          string // 201-B1
+	DateOfService              string // 401-D1 (CCYYMMDD)
+	SoftwareVendorID           string // 110-AK
+}
+
+// Request is a billing or reversal request transmission.
+// Segments holds the transmission-level segments (patient, insurance, pharmacy) and
+// Transactions holds the segments of each transaction group (claim, pricing).
+type Request struct {
+	Header       RequestHeader
+	Segments     []Segment
+	Transactions [][]Segment
+}
+
+// ResponseHeader is the fixed-width transaction header of a response transmission.
+type ResponseHeader struct {
+	Version                    string // 102-A2
+	TransactionCode            string // 103-A3
+	TransactionCount           int    // 109-A9
+	Status                     string // 501-F1: A accepted, R rejected
+	ServiceProviderIDQualifier string // 202-B2
+	ServiceProviderID          string // 201-B1
+	DateOfService              string // 401-D1 (CCYYMMDD)
+}
+
+// Response is a response transmission.
+type Response struct {
+	Header       ResponseHeader
+	Segments     []Segment
+	Transactions [][]Segment
+}
+
+// ParseRequest parses a D.0 request transmission.
+func ParseRequest(data []byte) (*Request, error) {
+	raw := string(data)
+	if len(raw) < requestHeaderLength {
+		return nil, fmt.Errorf("%w: header shorter than %d bytes", ErrMalformedTransaction, requestHeaderLength)
+	}
+
+	header := raw[:requestHeaderLength]
+	count, err := strconv.Atoi(header[20:21])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid transaction count '%s'", ErrMalformedTransaction, header[20:21])
+	}
+
+	segments, transactions, err := parseBody(raw[requestHeaderLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Request{
+		Header: RequestHeader{
+			BIN:                        strings.TrimSpace(header[0:6]),
+			Version:                    header[6:8],
+			TransactionCode:            header[8:10],
+			PCN:                        strings.TrimSpace(header[10:20]),
+			TransactionCount:           count,
+			ServiceProviderIDQualifier: strings.TrimSpace(header[21:23]),
+			ServiceProviderID:          strings.TrimSpace(header[23:38]),
+			DateOfService:              strings.TrimSpace(header[38:46]),
+			SoftwareVendorID:           strings.TrimSpace(header[46:56]),
+		},
+		Segments:     segments,
+		Transactions: transactions,
+	}, nil
+}
+
+// Encode serializes the request transmission.
+func (r *Request) Encode() []byte {
+	var sb strings.Builder
+	sb.WriteString(pad(r.Header.BIN, 6))
+	sb.WriteString(pad(r.Header.Version, 2))
+	sb.WriteString(pad(r.Header.TransactionCode, 2))
+	sb.WriteString(pad(r.Header.PCN, 10))
+	sb.WriteString(strconv.Itoa(r.Header.TransactionCount % 10))
+	sb.WriteString(pad(r.Header.ServiceProviderIDQualifier, 2))
+	sb.WriteString(pad(r.Header.ServiceProviderID, 15))
+	sb.WriteString(pad(r.Header.DateOfService, 8))
+	sb.WriteString(pad(r.Header.SoftwareVendorID, 10))
+	encodeBody(&sb, r.Segments, r.Transactions)
+	return []byte(sb.String())
+}
+
+// ParseResponse parses a D.0 response transmission.
+func ParseResponse(data []byte) (*Response, error) {
+	raw := string(data)
+	if len(raw) < responseHeaderLength {
+		return nil, fmt.Errorf("%w: response header shorter than %d bytes", ErrMalformedTransaction, responseHeaderLength)
+	}
+
+	header := raw[:responseHeaderLength]
+	count, err := strconv.Atoi(header[4:5])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid transaction count '%s'", ErrMalformedTransaction, header[4:5])
+	}
+
+	segments, transactions, err := parseBody(raw[responseHeaderLength:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		Header: ResponseHeader{
+			Version:                    header[0:2],
+			TransactionCode:            header[2:4],
+			TransactionCount:           count,
+			Status:                     header[5:6],
+			ServiceProviderIDQualifier: strings.TrimSpace(header[6:8]),
+			ServiceProviderID:          strings.TrimSpace(header[8:23]),
+			DateOfService:              strings.TrimSpace(header[23:31]),
+		},
+		Segments:     segments,
+		Transactions: transactions,
+	}, nil
+}
+
+// Encode serializes the response transmission.
+func (r *Response) Encode() []byte {
+	var sb strings.Builder
+	sb.WriteString(pad(r.Header.Version, 2))
+	sb.WriteString(pad(r.Header.TransactionCode, 2))
+	sb.WriteString(strconv.Itoa(r.Header.TransactionCount % 10))
+	sb.WriteString(pad(r.Header.Status, 1))
+	sb.WriteString(pad(r.Header.ServiceProviderIDQualifier, 2))
+	sb.WriteString(pad(r.Header.ServiceProviderID, 15))
+	sb.WriteString(pad(r.Header.DateOfService, 8))
+	encodeBody(&sb, r.Segments, r.Transactions)
+	return []byte(sb.String())
+}
+
+// parseBody splits the data following the header into transmission-level segments and
+// transaction groups. Groups are delimited by the group separator.
+func parseBody(body string) ([]Segment, [][]Segment, error) {
+	groups := strings.Split(body, string(GroupSeparator))
+
+	segments, err := parseSegments(groups[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transactions [][]Segment
+	for _, group := range groups[1:] {
+		groupSegments, err := parseSegments(group)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(groupSegments) > 0 {
+			transactions = append(transactions, groupSegments)
+		}
+	}
+	return segments, transactions, nil
+}
+
+func parseSegments(group string) ([]Segment, error) {
+	var segments []Segment
+	for _, rawSegment := range strings.Split(group, string(SegmentSeparator)) {
+		if strings.TrimSpace(rawSegment) == "" {
+			continue
+		}
+
+		// Each segment starts with a field separator before its 111-AM segment identification.
+		parts := strings.Split(strings.TrimPrefix(rawSegment, string(FieldSeparator)), string(FieldSeparator))
+		segmentField := parts[0]
+		if len(segmentField) != 4 || !strings.HasPrefix(segmentField, segmentIDPrefix) {
+			return nil, fmt.Errorf("%w: invalid segment identification '%s'", ErrMalformedTransaction, segmentField)
+		}
+
+		segment := Segment{ID: segmentField[2:]}
+		for _, rawField := range parts[1:] {
+			if rawField == "" {
+				continue
+			}
+			if len(rawField) < 2 {
+				return nil, fmt.Errorf("%w: invalid field '%s' in segment %s", ErrMalformedTransaction, rawField, segment.ID)
+			}
+			segment.Add(rawField[:2], rawField[2:])
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func encodeBody(sb *strings.Builder, segments []Segment, transactions [][]Segment) {
+	encodeSegments(sb, segments)
+	for _, group := range transactions {
+		sb.WriteRune(GroupSeparator)
+		encodeSegments(sb, group)
+	}
+}
+
+func encodeSegments(sb *strings.Builder, segments []Segment) {
+	for _, segment := range segments {
+		sb.WriteRune(SegmentSeparator)
+		sb.WriteRune(FieldSeparator)
+		sb.WriteString(segmentIDPrefix)
+		sb.WriteString(segment.ID)
+		for _, field := range segment.Fields {
+			sb.WriteRune(FieldSeparator)
+			sb.WriteString(field.ID)
+			sb.WriteString(field.Value)
+		}
+	}
+}
+
+// pad left-aligns value in a space-filled field of the given width, truncating if needed.
+func pad(value string, width int) string {
+	if len(value) >= width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
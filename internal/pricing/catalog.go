@@ -0,0 +1,164 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// catalogColumns maps the accepted header names of the drug catalog columns, compared in lower
+// case with spaces as underscores, to their column.
+var catalogColumns = map[string]string{
+	"ndc":               "ndc",
+	"name":              "name",
+	"proprietary_name":  "name",
+	"ingredient":        "ingredient",
+	"active_ingredient": "ingredient",
+	"strength":          "strength",
+	"form":              "form",
+	"dosage_form":       "form",
+	"type":              "type",
+}
+
+// Drug types of the catalog type column.
+const (
+	drugTypeBrand   = "brand"
+	drugTypeGeneric = "generic"
+)
+
+// NDCCatalog holds the drugs of a catalog file by NDC, and the NDCs of every group of
+// equivalent drugs.
+type NDCCatalog struct {
+	byNDC        map[string]models.Drug
+	byEquivalent map[string][]string // NDCs sorted, by equivalenceKey
+}
+
+// LoadNDCCatalog reads a CSV drug catalog. The header row must name the ndc, active_ingredient
+// (or ingredient), strength, dosage_form (or form) and type (brand or generic) columns; a name
+// (or proprietary_name) column is optional and other columns are ignored. An invalid row or an
+// NDC listed twice is an error.
+func LoadNDCCatalog(path string) (*NDCCatalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening NDC catalog %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of NDC catalog %s: %w", path, err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if column, ok := catalogColumns[key]; ok {
+			index[column] = i
+		}
+	}
+	for _, column := range []string{"ndc", "ingredient", "strength", "form", "type"} {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("NDC catalog %s has no %s column", path, column)
+		}
+	}
+
+	catalog := &NDCCatalog{byNDC: make(map[string]models.Drug), byEquivalent: make(map[string][]string)}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading line %d of NDC catalog %s: %w", line, path, err)
+		}
+		drug, err := parseDrug(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d of NDC catalog %s: %w", line, path, err)
+		}
+		if _, ok := catalog.byNDC[drug.NDC]; ok {
+			return nil, fmt.Errorf("invalid line %d of NDC catalog %s: NDC %s is listed twice", line, path, drug.NDC)
+		}
+		catalog.byNDC[drug.NDC] = drug
+		key := equivalenceKey(drug)
+		catalog.byEquivalent[key] = append(catalog.byEquivalent[key], drug.NDC)
+	}
+
+	for _, ndcs := range catalog.byEquivalent {
+		sort.Strings(ndcs)
+	}
+	return catalog, nil
+}
+
+// parseDrug reads the drug of a CSV record.
+func parseDrug(record []string, index map[string]int) (models.Drug, error) {
+	field := func(column string) string {
+		if i, ok := index[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	drug := models.Drug{
+		NDC:        field("ndc"),
+		Name:       field("name"),
+		Ingredient: field("ingredient"),
+		Strength:   field("strength"),
+		Form:       field("form"),
+	}
+	if drug.NDC == "" {
+		return drug, errors.New("missing ndc")
+	}
+	if drug.Ingredient == "" || drug.Strength == "" || drug.Form == "" {
+		return drug, fmt.Errorf("NDC %s needs an active ingredient, a strength and a dosage form", drug.NDC)
+	}
+	switch strings.ToLower(field("type")) {
+	case drugTypeBrand:
+	case drugTypeGeneric:
+		drug.Generic = true
+	default:
+		return drug, fmt.Errorf("type '%s' of NDC %s must be %s or %s", field("type"), drug.NDC, drugTypeBrand, drugTypeGeneric)
+	}
+	return drug, nil
+}
+
+// equivalenceKey identifies the drugs with the same active ingredient, strength and dosage form,
+// ignoring case and repeated spaces.
+func equivalenceKey(drug models.Drug) string {
+	normalize := func(value string) string {
+		return strings.Join(strings.Fields(strings.ToUpper(value)), " ")
+	}
+	return normalize(drug.Ingredient) + "|" + normalize(drug.Strength) + "|" + normalize(drug.Form)
+}
+
+// Drug returns the drug of the NDC, false when the catalog does not list it.
+func (c *NDCCatalog) Drug(ndc string) (models.Drug, bool) {
+	drug, ok := c.byNDC[ndc]
+	return drug, ok
+}
+
+// Generics returns the generic drugs equivalent to the NDC, sorted by NDC.
+func (c *NDCCatalog) Generics(ndc string) []models.Drug {
+	drug, ok := c.byNDC[ndc]
+	if !ok {
+		return nil
+	}
+	var generics []models.Drug
+	for _, equivalent := range c.byEquivalent[equivalenceKey(drug)] {
+		if other := c.byNDC[equivalent]; other.Generic && equivalent != ndc {
+			generics = append(generics, other)
+		}
+	}
+	return generics
+}
+
+// Len returns the number of drugs in the catalog.
+func (c *NDCCatalog) Len() int {
+	return len(c.byNDC)
+}
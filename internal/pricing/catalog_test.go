@@ -0,0 +1,62 @@
+package pricing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/pricing"
+)
+
+const testCatalogCSV = `NDC,Proprietary Name,Active Ingredient,Strength,Dosage Form,Type,Labeler
+00071015523,LIPITOR,ATORVASTATIN CALCIUM,20 MG,TABLET,brand,PFIZER
+60505257909,,atorvastatin calcium,20  mg,Tablet,Generic,APOTEX
+00093505698,,ATORVASTATIN CALCIUM,20 MG,TABLET,generic,TEVA
+00378201577,,ATORVASTATIN CALCIUM,40 MG,TABLET,generic,MYLAN
+`
+
+func writeCatalog(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "catalog.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing NDC catalog: %v", err)
+	}
+	return path
+}
+
+func TestLoadNDCCatalog(t *testing.T) {
+	catalog, err := pricing.LoadNDCCatalog(writeCatalog(t, testCatalogCSV))
+	assert.Nil(t, err, "Expected no error loading the NDC catalog")
+	assert.Equal(t, 4, catalog.Len())
+
+	brand, ok := catalog.Drug("00071015523")
+	assert.True(t, ok)
+	assert.Equal(t, models.Drug{NDC: "00071015523", Name: "LIPITOR", Ingredient: "ATORVASTATIN CALCIUM", Strength: "20 MG", Form: "TABLET"}, brand)
+
+	generics := catalog.Generics("00071015523")
+	assert.Len(t, generics, 2, "Expected the generics of the same strength, ignoring case and spaces")
+	assert.Equal(t, "00093505698", generics[0].NDC)
+	assert.Equal(t, "60505257909", generics[1].NDC)
+	generics = catalog.Generics("60505257909")
+	assert.Len(t, generics, 1, "Expected a generic not to be its own equivalent")
+	assert.Equal(t, "00093505698", generics[0].NDC)
+	assert.Empty(t, catalog.Generics("99999999999"), "Expected no generics for an unknown NDC")
+}
+
+func TestLoadNDCCatalogInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing column":   "ndc,ingredient,strength,type\n111,A,1 MG,brand\n",
+		"invalid type":     "ndc,ingredient,strength,form,type\n111,A,1 MG,TABLET,branded\n",
+		"missing strength": "ndc,ingredient,strength,form,type\n111,A,,TABLET,brand\n",
+		"missing ndc":      "ndc,ingredient,strength,form,type\n,A,1 MG,TABLET,brand\n",
+		"duplicate ndc":    "ndc,ingredient,strength,form,type\n111,A,1 MG,TABLET,brand\n111,A,1 MG,TABLET,generic\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := pricing.LoadNDCCatalog(writeCatalog(t, content))
+			assert.NotNil(t, err, "Expected an error for %s", name)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package pricing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Contract holds the reimbursement terms of a chain. The contract amount of a claim is the
+// reference unit cost of its NDC times the quantity, less the discount, plus the dispensing fee.
+type Contract struct {
+	DiscountPercent float64 `json:"discount_percent" yaml:"discount_percent"` // Discount off the reference unit cost, e.g. 15 for 15%
+	DispensingFee   float64 `json:"dispensing_fee" yaml:"dispensing_fee"`     // Fee added to every claim
+	LesserOf        bool    `json:"lesser_of" yaml:"lesser_of"`               // Allow the submitted price when lower than the contract amount
+}
+
+// Contracts is the content of a contracts file.
+type Contracts struct {
+	ReferenceUnitCosts map[string]float64  `json:"reference_unit_costs" yaml:"reference_unit_costs"` // Reference cost per unit, by NDC
+	Chains             map[string]Contract `json:"chains" yaml:"chains"`                             // Contracts by pharmacy chain
+	Default            *Contract           `json:"default,omitempty" yaml:"default,omitempty"`       // Contract of chains without their own
+}
+
+// LoadContracts reads and validates a contracts file. Files ending in .json are decoded as
+// JSON, anything else as YAML.
+func LoadContracts(path string) (*Contracts, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading contracts file %s: %w", path, err)
+	}
+
+	var contracts Contracts
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&contracts)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&contracts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding contracts file %s: %w", path, err)
+	}
+
+	if err := contracts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid contracts file %s: %w", path, err)
+	}
+	return &contracts, nil
+}
+
+// Validate checks that reference unit costs are positive and that contract terms are in range.
+func (c *Contracts) Validate() error {
+	for ndc, cost := range c.ReferenceUnitCosts {
+		if cost <= 0 {
+			return fmt.Errorf("reference unit cost of NDC %s must be positive", ndc)
+		}
+	}
+	for chain, contract := range c.Chains {
+		if err := contract.validate(); err != nil {
+			return fmt.Errorf("contract of chain '%s': %w", chain, err)
+		}
+	}
+	if c.Default != nil {
+		if err := c.Default.validate(); err != nil {
+			return fmt.Errorf("default contract: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c Contract) validate() error {
+	if c.DiscountPercent < 0 || c.DiscountPercent >= 100 {
+		return fmt.Errorf("discount_percent must be in [0, 100), got %g", c.DiscountPercent)
+	}
+	if c.DispensingFee < 0 {
+		return fmt.Errorf("dispensing_fee must not be negative, got %g", c.DispensingFee)
+	}
+	return nil
+}
+
+// contract returns the contract of the chain, or the default one.
+func (c *Contracts) contract(chain string) (Contract, bool) {
+	if contract, ok := c.Chains[chain]; ok {
+		return contract, true
+	}
+	if c.Default != nil {
+		return *c.Default, true
+	}
+	return Contract{}, false
+}
+
+// Price computes the allowed amount of a claim submitted by a pharmacy of the chain. Claims
+// without a contract or a reference unit cost for their NDC are allowed the submitted price.
+func (c *Contracts) Price(claim models.ClaimSubmissionRequest, chain string) models.ClaimPricing {
+	submitted := models.ClaimPricing{AllowedAmount: claim.Price, Basis: models.PricingBasisSubmitted}
+
+	contract, ok := c.contract(chain)
+	if !ok {
+		return submitted
+	}
+	unitCost, ok := c.ReferenceUnitCosts[claim.NDC]
+	if !ok {
+		return submitted
+	}
+
+	amount := roundAmount(unitCost*claim.Quantity*(1-contract.DiscountPercent/100) + contract.DispensingFee)
+	if contract.LesserOf && claim.Price < amount {
+		return models.ClaimPricing{AllowedAmount: claim.Price, Basis: models.PricingBasisLesserOf}
+	}
+	return models.ClaimPricing{AllowedAmount: amount, Basis: models.PricingBasisContract}
+}
+
+// roundAmount rounds an amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
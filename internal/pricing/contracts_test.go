@@ -0,0 +1,80 @@
+package pricing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/pricing"
+)
+
+func testContracts() *pricing.Contracts {
+	return &pricing.Contracts{
+		ReferenceUnitCosts: map[string]float64{"00002323401": 10},
+		Chains: map[string]pricing.Contract{
+			"health": {DiscountPercent: 20, DispensingFee: 1.5, LesserOf: true},
+			"saint":  {DiscountPercent: 10},
+		},
+	}
+}
+
+func TestPrice(t *testing.T) {
+	contracts := testContracts()
+
+	tests := []struct {
+		name    string
+		claim   models.ClaimSubmissionRequest
+		chain   string
+		allowed float64
+		basis   string
+	}{
+		{"contract amount", models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 3, Price: 40}, "health", 25.5, models.PricingBasisContract},
+		{"lesser of submitted", models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 3, Price: 20}, "health", 20, models.PricingBasisLesserOf},
+		{"contract above submitted", models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 3, Price: 20}, "saint", 27, models.PricingBasisContract},
+		{"no reference unit cost", models.ClaimSubmissionRequest{NDC: "00054027225", Quantity: 3, Price: 20}, "health", 20, models.PricingBasisSubmitted},
+		{"no contract", models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 3, Price: 40}, "doctor", 40, models.PricingBasisSubmitted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricing := contracts.Price(tt.claim, tt.chain)
+			assert.Equal(t, tt.allowed, pricing.AllowedAmount)
+			assert.Equal(t, tt.basis, pricing.Basis)
+		})
+	}
+}
+
+func TestPriceDefaultContract(t *testing.T) {
+	contracts := testContracts()
+	contracts.Default = &pricing.Contract{DiscountPercent: 50}
+
+	result := contracts.Price(models.ClaimSubmissionRequest{NDC: "00002323401", Quantity: 1, Price: 40}, "doctor")
+	assert.Equal(t, models.ClaimPricing{AllowedAmount: 5, Basis: models.PricingBasisContract}, result)
+}
+
+func TestLoadContracts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "contracts.yaml")
+	os.WriteFile(path, []byte(`
+reference_unit_costs:
+  "00002323401": 10
+chains:
+  health: {discount_percent: 20, dispensing_fee: 1.5, lesser_of: true}
+`), 0644)
+
+	contracts, err := pricing.LoadContracts(path)
+	assert.Nil(t, err, "Expected no error loading the contracts")
+	assert.Equal(t, testContracts().Chains["health"], contracts.Chains["health"])
+
+	jsonPath := filepath.Join(dir, "contracts.json")
+	os.WriteFile(jsonPath, []byte(`{"chains": {"health": {"discount_percent": 120}}}`), 0644)
+	_, err = pricing.LoadContracts(jsonPath)
+	assert.NotNil(t, err, "Expected an error for a discount above 100%")
+
+	os.WriteFile(path, []byte("reference_unit_costs:\n  \"1\": 0\n"), 0644)
+	_, err = pricing.LoadContracts(path)
+	assert.NotNil(t, err, "Expected an error for a zero reference unit cost")
+}
@@ -0,0 +1,182 @@
+package pricing
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/reloader"
+)
+
+// referenceDateLayouts are the accepted effective date formats: ISO and the MM/DD/YYYY used by
+// the NADAC files.
+var referenceDateLayouts = []string{"2006-01-02", "01/02/2006"}
+
+// referenceColumns maps the accepted header names of the reference price columns, compared in
+// lower case with spaces as underscores, to their column.
+var referenceColumns = map[string]string{
+	"ndc":            "ndc",
+	"nadac_per_unit": "unit_price",
+	"unit_price":     "unit_price",
+	"effective_date": "effective_date",
+}
+
+// ReferencePrice is the reference unit price of an NDC from its effective date.
+type ReferencePrice struct {
+	NDC           string
+	UnitPrice     float64
+	EffectiveDate time.Time
+}
+
+// ReferencePrices holds the reference prices of a file by NDC.
+type ReferencePrices struct {
+	byNDC map[string][]ReferencePrice // Sorted by effective date
+	count int
+}
+
+// LoadReferencePrices reads a NADAC-style CSV file of reference unit prices. The header row must
+// name the ndc, nadac_per_unit (or unit_price) and effective_date columns; other columns are
+// ignored. An invalid row is an error.
+func LoadReferencePrices(path string) (*ReferencePrices, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening reference price file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of reference price file %s: %w", path, err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		key := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+		if column, ok := referenceColumns[key]; ok {
+			index[column] = i
+		}
+	}
+	for _, column := range []string{"ndc", "unit_price", "effective_date"} {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("reference price file %s has no %s column", path, column)
+		}
+	}
+
+	prices := &ReferencePrices{byNDC: make(map[string][]ReferencePrice)}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading line %d of reference price file %s: %w", line, path, err)
+		}
+		price, err := parseReferencePrice(record, index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line %d of reference price file %s: %w", line, path, err)
+		}
+		prices.byNDC[price.NDC] = append(prices.byNDC[price.NDC], price)
+		prices.count++
+	}
+
+	for _, history := range prices.byNDC {
+		sort.SliceStable(history, func(i, j int) bool {
+			return history[i].EffectiveDate.Before(history[j].EffectiveDate)
+		})
+	}
+	return prices, nil
+}
+
+// parseReferencePrice reads the reference price of a CSV record.
+func parseReferencePrice(record []string, index map[string]int) (ReferencePrice, error) {
+	field := func(column string) string {
+		if i := index[column]; i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	price := ReferencePrice{NDC: field("ndc")}
+	if price.NDC == "" {
+		return price, errors.New("missing ndc")
+	}
+	unitPrice, err := strconv.ParseFloat(field("unit_price"), 64)
+	if err != nil || unitPrice <= 0 {
+		return price, fmt.Errorf("unit price '%s' of NDC %s must be a positive number", field("unit_price"), price.NDC)
+	}
+	price.UnitPrice = unitPrice
+	for _, layout := range referenceDateLayouts {
+		if date, err := time.Parse(layout, field("effective_date")); err == nil {
+			price.EffectiveDate = date
+			return price, nil
+		}
+	}
+	return price, fmt.Errorf("effective date '%s' of NDC %s must be YYYY-MM-DD or MM/DD/YYYY", field("effective_date"), price.NDC)
+}
+
+// UnitPrice returns the reference unit price of the NDC in effect on the date: the price with
+// the latest effective date not after it.
+func (r *ReferencePrices) UnitPrice(ndc string, date time.Time) (float64, bool) {
+	history := r.byNDC[ndc]
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].EffectiveDate.After(day)
+	})
+	if i == 0 {
+		return 0, false
+	}
+	return history[i-1].UnitPrice, true
+}
+
+// Len returns the number of reference prices.
+func (r *ReferencePrices) Len() int {
+	return r.count
+}
+
+// ReferenceBook serves the reference prices of a file, reloaded whenever the file changes.
+// It is safe for concurrent use.
+type ReferenceBook struct {
+	file   *reloader.File[*ReferencePrices]
+	logger logger.Logger
+}
+
+// NewReferenceBook loads the reference price file at path. An invalid file is an error.
+func NewReferenceBook(path string, log logger.Logger) (*ReferenceBook, error) {
+	b := &ReferenceBook{file: reloader.New(path, "reference price", LoadReferencePrices, nil, log), logger: log}
+	if _, err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reload reads the reference price file again when it changed and reports whether new prices
+// were loaded, see reloader.File.Reload.
+func (b *ReferenceBook) Reload() (bool, error) {
+	return b.file.Reload()
+}
+
+// Run checks the reference price file for changes every interval until ctx is cancelled.
+func (b *ReferenceBook) Run(ctx context.Context, interval time.Duration) {
+	b.file.Run(ctx, interval, func() {
+		b.logger.Info("Reference prices reloaded from %s: %d prices.", b.file.Path(), b.Len())
+	})
+}
+
+// Len returns the number of reference prices in use.
+func (b *ReferenceBook) Len() int {
+	return b.file.Value().Len()
+}
+
+// UnitPrice returns the reference unit price of the NDC in effect on the date.
+func (b *ReferenceBook) UnitPrice(ndc string, date time.Time) (float64, bool) {
+	return b.file.Value().UnitPrice(ndc, date)
+}
@@ -0,0 +1,103 @@
+package pricing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/pricing"
+)
+
+const testReferenceCSV = `NDC Description,NDC,NADAC_Per_Unit,Effective_Date,Pricing_Unit
+INSULIN LISPRO,00002323401,12.00,02/07/2024,ML
+INSULIN LISPRO,00002323401,10.00,01/03/2024,ML
+PREDNISONE,00054027225,0.04,2024-01-03,EA
+`
+
+func writeReferencePrices(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "reference_prices.csv")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing reference price file: %v", err)
+	}
+	return path
+}
+
+func day(value string) time.Time {
+	date, _ := time.Parse("2006-01-02", value)
+	return date
+}
+
+func TestLoadReferencePrices(t *testing.T) {
+	prices, err := pricing.LoadReferencePrices(writeReferencePrices(t, testReferenceCSV))
+	assert.Nil(t, err, "Expected no error loading the reference prices")
+	assert.Equal(t, 3, prices.Len())
+
+	tests := []struct {
+		name  string
+		ndc   string
+		date  string
+		price float64
+		found bool
+	}{
+		{"before the first effective date", "00002323401", "2024-01-02", 0, false},
+		{"on the first effective date", "00002323401", "2024-01-03", 10, true},
+		{"between effective dates", "00002323401", "2024-02-06", 10, true},
+		{"after the latest effective date", "00002323401", "2024-06-01", 12, true},
+		{"ISO effective date", "00054027225", "2024-01-03", 0.04, true},
+		{"unknown NDC", "99999999999", "2024-06-01", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, found := prices.UnitPrice(tt.ndc, day(tt.date))
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.price, price)
+		})
+	}
+}
+
+func TestLoadReferencePricesInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing column":     "ndc,effective_date\n00002323401,2024-01-03\n",
+		"invalid unit price": "ndc,unit_price,effective_date\n00002323401,abc,2024-01-03\n",
+		"zero unit price":    "ndc,unit_price,effective_date\n00002323401,0,2024-01-03\n",
+		"invalid date":       "ndc,unit_price,effective_date\n00002323401,1.5,2024/01/03\n",
+		"missing ndc":        "ndc,unit_price,effective_date\n,1.5,2024-01-03\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := pricing.LoadReferencePrices(writeReferencePrices(t, content))
+			assert.NotNil(t, err, "Expected an error for %s", name)
+		})
+	}
+}
+
+func TestReferenceBookReload(t *testing.T) {
+	path := writeReferencePrices(t, "ndc,unit_price,effective_date\n111,1.00,2024-01-01\n")
+	book, err := pricing.NewReferenceBook(path, logger.NewLogger())
+	assert.Nil(t, err, "Expected no error creating the reference book")
+
+	price, _ := book.UnitPrice("111", day("2024-06-01"))
+	assert.Equal(t, 1.0, price)
+
+	// Invalid prices are refused and the previous ones kept
+	later := time.Now().Add(time.Minute)
+	os.WriteFile(path, []byte("ndc,unit_price\n111,2.00\n"), 0644)
+	os.Chtimes(path, later, later)
+	_, err = book.Reload()
+	assert.NotNil(t, err, "Expected an error reloading invalid prices")
+	price, _ = book.UnitPrice("111", day("2024-06-01"))
+	assert.Equal(t, 1.0, price)
+
+	later = later.Add(time.Minute)
+	os.WriteFile(path, []byte("ndc,unit_price,effective_date\n111,1.00,2024-01-01\n111,2.00,2024-05-01\n"), 0644)
+	os.Chtimes(path, later, later)
+	reloaded, err := book.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded, "Expected the changed file to be reloaded")
+	price, _ = book.UnitPrice("111", day("2024-06-01"))
+	assert.Equal(t, 2.0, price)
+}
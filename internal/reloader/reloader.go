@@ -0,0 +1,114 @@
+// Package reloader keeps the content of a configuration file in memory up to date with the file.
+package reloader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+)
+
+// File serves the value loaded from a file, loaded again whenever the size or modification time
+// of the file changes. It is safe for concurrent use.
+type File[T any] struct {
+	path   string
+	name   string
+	load   func(path string) (T, error)
+	onLoad func(previous, current T)
+	logger logger.Logger
+
+	mu      sync.RWMutex
+	value   T
+	loaded  bool
+	size    int64
+	modTime time.Time
+}
+
+// New returns a File of the given name, e.g. "adjudication rules", reading path with load. The file
+// is only read by Reload. onLoad, when not nil, is called by every reload with the value replaced,
+// the zero value on the first load, and the new one.
+func New[T any](path, name string, load func(path string) (T, error), onLoad func(previous, current T), log logger.Logger) *File[T] {
+	return &File[T]{path: path, name: name, load: load, onLoad: onLoad, logger: log}
+}
+
+// Path returns the path of the file.
+func (f *File[T]) Path() string {
+	return f.path
+}
+
+// Value returns the value loaded last, the zero value before the first load.
+func (f *File[T]) Value() T {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.value
+}
+
+// Reload reads the file again when its size or modification time changed and reports whether a
+// new value was loaded. When the file is invalid, the previous value is kept and the error is only
+// returned once for that version of the file.
+func (f *File[T]) Reload() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, fmt.Errorf("error reading %s file %s: %w", f.name, f.path, err)
+	}
+
+	f.mu.RLock()
+	previous := f.value
+	unchanged := f.loaded && info.Size() == f.size && info.ModTime().Equal(f.modTime)
+	f.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	value, err := f.load(f.path)
+	if err != nil {
+		// Remember the invalid file so it is only reported again once it changes.
+		f.mu.Lock()
+		if f.loaded {
+			f.size = info.Size()
+			f.modTime = info.ModTime()
+		}
+		f.mu.Unlock()
+		return false, err
+	}
+
+	f.mu.Lock()
+	f.value = value
+	f.loaded = true
+	f.size = info.Size()
+	f.modTime = info.ModTime()
+	f.mu.Unlock()
+	if f.onLoad != nil {
+		f.onLoad(previous, value)
+	}
+	return true, nil
+}
+
+// Run checks the file for changes every interval until ctx is cancelled. reloaded, when not nil,
+// is called after every reload, e.g. to log what changed.
+func (f *File[T]) Run(ctx context.Context, interval time.Duration, reloaded func()) {
+	f.logger.Info("Watching %s file %s every %s", f.name, f.path, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			f.logger.Info("Stopping %s watcher.", f.name)
+			return
+		case <-ticker.C:
+			ok, err := f.Reload()
+			if err != nil {
+				f.logger.Error("Error reloading %s, keeping the previous version: %v", f.name, err)
+				continue
+			}
+			if ok && reloaded != nil {
+				reloaded()
+			}
+		}
+	}
+}
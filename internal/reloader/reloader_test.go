@@ -0,0 +1,89 @@
+package reloader_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/reloader"
+)
+
+// loadWords reads a file of words separated by spaces; an empty file is invalid.
+func loadWords(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	words := strings.Fields(string(data))
+	if len(words) == 0 {
+		return nil, errors.New("no words")
+	}
+	return words, nil
+}
+
+// rewrite replaces the content of the file at path and moves its modification time to at.
+func rewrite(t *testing.T, path, content string, at time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error writing test file: %v", err)
+	}
+	if err := os.Chtimes(path, at, at); err != nil {
+		t.Fatalf("error dating test file: %v", err)
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	later := time.Now().Add(time.Minute)
+	rewrite(t, path, "a b", later)
+
+	var loads [][2][]string
+	file := reloader.New(path, "words", loadWords, func(previous, current []string) {
+		loads = append(loads, [2][]string{previous, current})
+	}, logger.NewLogger())
+	assert.Nil(t, file.Value(), "Expected nothing loaded before the first reload")
+
+	reloaded, err := file.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded, "Expected the first reload to load the file")
+	assert.Equal(t, []string{"a", "b"}, file.Value())
+
+	reloaded, err = file.Reload()
+	assert.Nil(t, err)
+	assert.False(t, reloaded, "Expected no reload of an unchanged file")
+
+	// An invalid file is refused once and the previous value kept
+	later = later.Add(time.Minute)
+	rewrite(t, path, " ", later)
+	_, err = file.Reload()
+	assert.NotNil(t, err, "Expected an error reloading an invalid file")
+	_, err = file.Reload()
+	assert.Nil(t, err, "Expected an invalid file to be reported only once")
+	assert.Equal(t, []string{"a", "b"}, file.Value())
+
+	later = later.Add(time.Minute)
+	rewrite(t, path, "c", later)
+	reloaded, err = file.Reload()
+	assert.Nil(t, err)
+	assert.True(t, reloaded, "Expected the changed file to be reloaded")
+	assert.Equal(t, []string{"c"}, file.Value())
+
+	if assert.Len(t, loads, 2, "Expected onLoad called once per load") {
+		assert.Nil(t, loads[0][0], "Expected no previous value on the first load")
+		assert.Equal(t, []string{"a", "b"}, loads[1][0])
+		assert.Equal(t, []string{"c"}, loads[1][1])
+	}
+}
+
+func TestFileReloadMissingFile(t *testing.T) {
+	file := reloader.New(filepath.Join(t.TempDir(), "missing.txt"), "words", loadWords, nil, logger.NewLogger())
+
+	_, err := file.Reload()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
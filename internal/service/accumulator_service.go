@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidPlanYear is returned when the plan year of an accumulator request is not a year.
+var ErrInvalidPlanYear = errors.New("invalid plan year: must be a year such as 2024")
+
+// AccumulatorService defines the interface of the member accumulator lookups.
+type AccumulatorService interface {
+	GetAccumulators(memberID, planYear string) (*models.MemberAccumulators, error)
+}
+
+type accumulatorService struct {
+	logger   logger.Logger
+	dbRepo   database.DBRepository
+	roster   EligibilityChecker
+	benefits BenefitPlans
+}
+
+// NewAccumulatorService creates and returns a new instance of the AccumulatorService interface.
+// Without a roster or plan benefits, accumulators are returned without the limits of the plan.
+func NewAccumulatorService(log logger.Logger, dbRepo database.DBRepository, roster EligibilityChecker, benefits BenefitPlans) AccumulatorService {
+	return &accumulatorService{
+		logger:   log,
+		dbRepo:   dbRepo,
+		roster:   roster,
+		benefits: benefits,
+	}
+}
+
+// GetAccumulators returns the deductible met and out-of-pocket spent by a member in the plan year,
+// the current year by default, with what remains of the limits of the member's plan. The plan is
+// the one covering the member at the end of the plan year, or today for the current year.
+func (s *accumulatorService) GetAccumulators(memberID, planYear string) (*models.MemberAccumulators, error) {
+	now := time.Now()
+	year := now.Year()
+	if planYear != "" {
+		var err error
+		if year, err = strconv.Atoi(planYear); err != nil || year < 1900 || year > 9999 {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidPlanYear, planYear)
+		}
+	}
+
+	accumulator, err := s.dbRepo.GetAccumulator(memberID, year)
+	if err != nil {
+		s.logger.Error("Error fetching accumulator of member %s for plan year %d: %v", memberID, year, err)
+		return nil, errors.New("internal error fetching accumulators")
+	}
+
+	result := &models.MemberAccumulators{Accumulator: *accumulator}
+	if s.roster == nil || s.benefits == nil {
+		return result, nil
+	}
+	date := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	if year == now.Year() {
+		date = now
+	}
+	eligibility := s.roster.Check(memberID, date)
+	if eligibility.Coverage == nil {
+		return result, nil
+	}
+	plan, ok := s.benefits.Plan(eligibility.Coverage.Plan)
+	if !ok {
+		return result, nil
+	}
+
+	result.Plan = &plan
+	result.DeductibleRemaining = math.Max(0, roundAmount(plan.Deductible-accumulator.DeductibleMet))
+	if plan.OutOfPocketMax > 0 {
+		remaining := math.Max(0, roundAmount(plan.OutOfPocketMax-accumulator.OutOfPocketSpent))
+		result.OutOfPocketRemaining = &remaining
+	}
+	return result, nil
+}
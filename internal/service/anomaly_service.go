@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/metrics"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidAnomalyFilter is returned when an anomaly filter has an unknown type, severity or status.
+var ErrInvalidAnomalyFilter = errors.New("invalid anomaly filter: unknown type, severity or status")
+
+// minPricePharmacies is the number of pharmacies billing an NDC needed for a meaningful median.
+const minPricePharmacies = 3
+
+// AnomalyConfig holds the periods and limits of the claim analyzer.
+type AnomalyConfig struct {
+	Window              time.Duration // Recent period whose submissions are compared to the baseline, e.g. 24h
+	Baseline            time.Duration // Period before the window the baseline rate is computed over; also the period of the reversal rates and unit prices
+	SpikeFactor         float64       // Submissions in the window above the baseline times SpikeFactor are a spike
+	ReversalRatePercent float64       // Share of reversed claims above which a pharmacy is flagged; 0 disables the check
+	PriceFactor         float64       // Unit prices above the cross-pharmacy median times PriceFactor are flagged
+	MinClaims           int           // Claims a pharmacy needs in the window (spikes) or period (reversal rates) to be flagged
+}
+
+// AnomalyService defines the interface of the claim analyzer.
+type AnomalyService interface {
+	Analyze(now time.Time) ([]models.Anomaly, error)
+	ListAnomalies(filter models.AnomalyFilter) ([]models.Anomaly, error)
+	Run(ctx context.Context, interval time.Duration)
+}
+
+type anomalyService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+	cfg    AnomalyConfig
+}
+
+// NewAnomalyService creates and returns a new instance of the AnomalyService interface.
+func NewAnomalyService(log logger.Logger, dbRepo database.DBRepository, cfg AnomalyConfig) AnomalyService {
+	return &anomalyService{
+		logger: log,
+		dbRepo: dbRepo,
+		cfg:    cfg,
+	}
+}
+
+// Analyze looks for anomalies in the claims submitted up to now, records them and updates the
+// anomaly gauges. It returns the anomalies found.
+func (s *anomalyService) Analyze(now time.Time) ([]models.Anomaly, error) {
+	var anomalies []models.Anomaly
+	for _, detect := range []func(time.Time) ([]models.Anomaly, error){
+		s.submissionSpikes,
+		s.reversalRates,
+		s.unitPrices,
+	} {
+		found, err := detect(now)
+		if err != nil {
+			s.logger.Error("Error analyzing claims: %v", err)
+			return nil, errors.New("internal error analyzing claims")
+		}
+		anomalies = append(anomalies, found...)
+	}
+
+	if err := s.dbRepo.RecordAnomalies(anomalies, now.Format("2006-01-02T15:04:05")); err != nil {
+		s.logger.Error("Error recording %d anomalies: %v", len(anomalies), err)
+		return nil, errors.New("internal error recording anomalies")
+	}
+	updateAnomalyGauges(anomalies)
+	return anomalies, nil
+}
+
+// submissionSpikes flags the pharmacies whose submissions in the window are far above their
+// average over the same length of time during the baseline period.
+func (s *anomalyService) submissionSpikes(now time.Time) ([]models.Anomaly, error) {
+	windowStart := now.Add(-s.cfg.Window)
+	current, err := s.dbRepo.CountClaimsByNPI(claimTimestamp(windowStart), claimTimestamp(now))
+	if err != nil {
+		return nil, fmt.Errorf("error counting claims of the window: %w", err)
+	}
+	baseline, err := s.dbRepo.CountClaimsByNPI(claimTimestamp(windowStart.Add(-s.cfg.Baseline)), claimTimestamp(windowStart))
+	if err != nil {
+		return nil, fmt.Errorf("error counting claims of the baseline: %w", err)
+	}
+
+	windows := float64(s.cfg.Baseline) / float64(s.cfg.Window)
+	var anomalies []models.Anomaly
+	for npi, count := range current {
+		if count < s.cfg.MinClaims {
+			continue
+		}
+		expected := float64(baseline[npi]) / windows
+		// A pharmacy without a baseline is expected at least one claim per window.
+		limit := math.Max(expected, 1) * s.cfg.SpikeFactor
+		if float64(count) <= limit {
+			continue
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			Type:     models.AnomalySubmissionSpike,
+			NPI:      npi,
+			Severity: anomalySeverity(float64(count) / limit),
+			Value:    float64(count),
+			Expected: roundAmount(expected),
+			Message: fmt.Sprintf("%d claims in the last %s, %.2f expected from the previous %s",
+				count, formatAge(s.cfg.Window), expected, formatAge(s.cfg.Baseline)),
+		})
+	}
+	sortAnomalies(anomalies)
+	return anomalies, nil
+}
+
+// reversalRates flags the pharmacies reversing a share of their paid claims above the threshold.
+func (s *anomalyService) reversalRates(now time.Time) ([]models.Anomaly, error) {
+	if s.cfg.ReversalRatePercent <= 0 {
+		return nil, nil
+	}
+	counts, err := s.dbRepo.GetReversalCounts(claimTimestamp(now.Add(-s.cfg.Baseline)), claimTimestamp(now))
+	if err != nil {
+		return nil, fmt.Errorf("error counting reversals: %w", err)
+	}
+
+	var anomalies []models.Anomaly
+	for _, count := range counts {
+		if count.ClaimCount < s.cfg.MinClaims || count.ClaimCount == 0 {
+			continue
+		}
+		rate := float64(count.ReversedCount) / float64(count.ClaimCount) * 100
+		if rate <= s.cfg.ReversalRatePercent {
+			continue
+		}
+		anomalies = append(anomalies, models.Anomaly{
+			Type:     models.AnomalyReversalRate,
+			NPI:      count.NPI,
+			Severity: anomalySeverity(rate / s.cfg.ReversalRatePercent),
+			Value:    roundAmount(rate),
+			Expected: s.cfg.ReversalRatePercent,
+			Message: fmt.Sprintf("%d of %d claims of the last %s reversed (%.2f%%), above %g%%",
+				count.ReversedCount, count.ClaimCount, formatAge(s.cfg.Baseline), rate, s.cfg.ReversalRatePercent),
+		})
+	}
+	return anomalies, nil
+}
+
+// unitPrices flags the pharmacies billing an NDC at an average unit price far above the median
+// of the pharmacies billing it.
+func (s *anomalyService) unitPrices(now time.Time) ([]models.Anomaly, error) {
+	prices, err := s.dbRepo.GetPharmacyUnitPrices(claimTimestamp(now.Add(-s.cfg.Baseline)), claimTimestamp(now))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching unit prices: %w", err)
+	}
+
+	byNDC := make(map[string][]models.PharmacyUnitPrice)
+	var ndcs []string
+	for _, price := range prices {
+		if _, ok := byNDC[price.NDC]; !ok {
+			ndcs = append(ndcs, price.NDC)
+		}
+		byNDC[price.NDC] = append(byNDC[price.NDC], price)
+	}
+
+	var anomalies []models.Anomaly
+	for _, ndc := range ndcs {
+		pharmacies := byNDC[ndc]
+		if len(pharmacies) < minPricePharmacies {
+			continue
+		}
+		unitPrices := make([]float64, len(pharmacies))
+		for i, pharmacy := range pharmacies {
+			unitPrices[i] = pharmacy.UnitPrice
+		}
+		median := medianOf(unitPrices)
+		limit := median * s.cfg.PriceFactor
+		for _, pharmacy := range pharmacies {
+			if median <= 0 || pharmacy.UnitPrice <= limit {
+				continue
+			}
+			anomalies = append(anomalies, models.Anomaly{
+				Type:     models.AnomalyUnitPrice,
+				NPI:      pharmacy.NPI,
+				NDC:      ndc,
+				Severity: anomalySeverity(pharmacy.UnitPrice / limit),
+				Value:    roundUnitPrice(pharmacy.UnitPrice),
+				Expected: roundUnitPrice(median),
+				Message: fmt.Sprintf("average unit price %.4f of NDC %s over %d claims is %.1f times the median %.4f of %d pharmacies",
+					pharmacy.UnitPrice, ndc, pharmacy.ClaimCount, pharmacy.UnitPrice/median, median, len(pharmacies)),
+			})
+		}
+	}
+	return anomalies, nil
+}
+
+// ListAnomalies fetches the anomalies matching the filter, the latest detected first.
+func (s *anomalyService) ListAnomalies(filter models.AnomalyFilter) ([]models.Anomaly, error) {
+	switch filter.Type {
+	case "", models.AnomalySubmissionSpike, models.AnomalyReversalRate, models.AnomalyUnitPrice:
+	default:
+		return nil, fmt.Errorf("%w: type '%s'", ErrInvalidAnomalyFilter, filter.Type)
+	}
+	switch filter.Severity {
+	case "", models.SeverityLow, models.SeverityMedium, models.SeverityHigh:
+	default:
+		return nil, fmt.Errorf("%w: severity '%s'", ErrInvalidAnomalyFilter, filter.Severity)
+	}
+
+	anomalies, err := s.dbRepo.ListAnomalies(filter)
+	if err != nil {
+		s.logger.Error("DB error listing anomalies: %v", err)
+		return nil, fmt.Errorf("error listing anomalies: %w", err)
+	}
+	if anomalies == nil {
+		anomalies = []models.Anomaly{}
+	}
+	return anomalies, nil
+}
+
+// Run analyzes the claims right away and then every interval until ctx is cancelled.
+func (s *anomalyService) Run(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Analyzing claims for anomalies every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		anomalies, err := s.Analyze(time.Now())
+		if err != nil {
+			s.logger.Error("Claim anomaly analysis failed: %v", err)
+		} else if len(anomalies) > 0 {
+			s.logger.Warning("Claim anomaly analysis found %d open anomalies.", len(anomalies))
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping claim anomaly analyzer.")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// updateAnomalyGauges exposes the open anomalies as the Prometheus gauges.
+func updateAnomalyGauges(anomalies []models.Anomaly) {
+	metrics.AnomaliesOpen.Reset()
+	metrics.PharmacyAnomalyScore.Reset()
+	for _, anomaly := range anomalies {
+		metrics.AnomaliesOpen.WithLabelValues(anomaly.Type, anomaly.Severity).Inc()
+		if anomaly.Expected > 0 {
+			metrics.PharmacyAnomalyScore.WithLabelValues(anomaly.Type, anomaly.NPI, anomaly.NDC).Set(anomaly.Value / anomaly.Expected)
+		}
+	}
+}
+
+// anomalySeverity grades how far a value is past its limit, ratio being value / limit.
+func anomalySeverity(ratio float64) string {
+	switch {
+	case ratio >= 2:
+		return models.SeverityHigh
+	case ratio >= 1.5:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
+
+// medianOf returns the median of the values; the slice is sorted in place.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	middle := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[middle-1] + values[middle]) / 2
+	}
+	return values[middle]
+}
+
+// sortAnomalies orders anomalies by NPI, for a stable output of map iterations.
+func sortAnomalies(anomalies []models.Anomaly) {
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].NPI < anomalies[j].NPI
+	})
+}
+
+// claimTimestamp formats a time like the claim timestamps, for period bounds.
+func claimTimestamp(t time.Time) string {
+	return t.Format("2006-01-02T15:04:05")
+}
+
+// roundUnitPrice rounds a unit price to 4 decimals.
+func roundUnitPrice(price float64) float64 {
+	return math.Round(price*10000) / 10000
+}
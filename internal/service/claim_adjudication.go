@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrRebillNotPaid is returned when the corrected claim of a rebill would not be paid at adjudication.
+var ErrRebillNotPaid = errors.New("corrected claim not paid at adjudication")
+
+// Adjudicator decides whether a claim submitted by a pharmacy of a chain is paid, pended or rejected.
+type Adjudicator interface {
+	Adjudicate(claim models.ClaimSubmissionRequest, chain string) models.Adjudication
+}
+
+// adjudicate applies the configured adjudicator to a claim; without one every claim is paid.
+// A claim for a member not covered on its date of service, or submitted after the timely filing
+// limit of the chain, is rejected whatever the rules decide.
+func (s *claimService) adjudicate(req models.ClaimSubmissionRequest, chain string) models.Adjudication {
+	adjudication := models.Adjudication{Status: models.ClaimStatusPaid}
+	if s.cfg.Adjudicator != nil {
+		adjudication = s.cfg.Adjudicator.Adjudicate(req, chain)
+	}
+	if reject, ok := s.timelyFilingReject(req, chain); ok {
+		adjudication.Status = models.ClaimStatusRejected
+		adjudication.Rejects = append([]models.ClaimReject{reject}, adjudication.Rejects...)
+	}
+	if reject, ok := s.eligibilityReject(req); ok {
+		adjudication.Status = models.ClaimStatusRejected
+		adjudication.Rejects = append([]models.ClaimReject{reject}, adjudication.Rejects...)
+	}
+	return adjudication
+}
+
+// AdjudicateClaim returns the adjudication a claim would get if it were submitted now, without
+// saving it. The claim is validated and checked for refills too soon as on submission.
+func (s *claimService) AdjudicateClaim(req models.ClaimSubmissionRequest) (*models.Adjudication, error) {
+	if err := ValidateClaimFields(req.NDC, req.NPI, req.Quantity, req.Price); err != nil {
+		return nil, err
+	}
+	if err := ValidatePrescription(req.Prescription); err != nil {
+		return nil, err
+	}
+
+	pharmacy, err := s.dbRepo.GetPharmacyByNPI(req.NPI)
+	if err != nil {
+		s.logger.Error("Error fetching pharmacy with NPI %s: %v", req.NPI, err)
+		return nil, errors.New("internal error adjudicating claim")
+	}
+	if pharmacy == nil {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownNPI, req.NPI)
+	}
+
+	adjudication := s.adjudicate(req, pharmacy.Chain)
+	if err := s.checkRefill(&adjudication, req, time.Now(), nil, nil); err != nil {
+		s.logger.Error("Error fetching fill history of member %s: %v", req.MemberID, err)
+		return nil, errors.New("internal error adjudicating claim")
+	}
+	return &adjudication, nil
+}
+
+// rejectSummary lists the reject codes and messages of an adjudication, e.g. for error messages.
+func rejectSummary(rejects []models.ClaimReject) string {
+	parts := make([]string, len(rejects))
+	for i, reject := range rejects {
+		parts[i] = fmt.Sprintf("%s (%s)", reject.Code, reject.Message)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidBatchMode is returned when a batch mode is neither all_or_nothing nor best_effort.
+var ErrInvalidBatchMode = errors.New("invalid batch mode: must be all_or_nothing or best_effort")
+
+// SubmitClaims validates and saves a batch of claims in a single transaction.
+// In all_or_nothing mode nothing is saved when any item is invalid; in best_effort mode
+// the valid items are saved and the invalid ones rejected. The returned response holds
+// one result per request, in order; an error is only returned when the batch could not
+// be processed at all.
+func (s *claimService) SubmitClaims(reqs []models.ClaimSubmissionRequest, mode string) (*models.ClaimBatchResponse, error) {
+	if mode != models.BatchModeAllOrNothing && mode != models.BatchModeBestEffort {
+		return nil, ErrInvalidBatchMode
+	}
+
+	response := &models.ClaimBatchResponse{
+		Mode:    mode,
+		Results: make([]models.ClaimBatchItemResult, len(reqs)),
+	}
+
+	pharmacies := make(map[string]*models.Pharmacy)
+	now := time.Now()
+	var claims []models.Claim
+	var indexes []int
+
+	s.benefitMu.Lock()
+	defer s.benefitMu.Unlock()
+	for i, req := range reqs {
+		response.Results[i].Index = i
+
+		if err := ValidateClaimFields(req.NDC, req.NPI, req.Quantity, req.Price); err != nil {
+			s.rejectBatchItem(response, i, err)
+			continue
+		}
+		if err := ValidatePrescription(req.Prescription); err != nil {
+			s.rejectBatchItem(response, i, err)
+			continue
+		}
+
+		pharmacy, cached := pharmacies[req.NPI]
+		if !cached {
+			var err error
+			pharmacy, err = s.dbRepo.GetPharmacyByNPI(req.NPI)
+			if err != nil {
+				s.logger.Error("Error fetching pharmacy with NPI %s: %v", req.NPI, err)
+				return nil, errors.New("internal error processing claim batch")
+			}
+			pharmacies[req.NPI] = pharmacy
+		}
+		if pharmacy == nil {
+			s.rejectBatchItem(response, i, fmt.Errorf("%w '%s'", ErrUnknownNPI, req.NPI))
+			continue
+		}
+
+		claim, err := s.buildClaim(req, pharmacy.Chain, now, claims)
+		if err != nil {
+			return nil, errors.New("internal error processing claim batch")
+		}
+		claims = append(claims, claim)
+		indexes = append(indexes, i)
+	}
+
+	if mode == models.BatchModeAllOrNothing && response.Rejected > 0 {
+		for _, i := range indexes {
+			response.Results[i].Status = models.BatchItemSkipped
+		}
+		s.logger.Info("Claim batch of %d items not committed: %d items rejected", len(reqs), response.Rejected)
+		return response, nil
+	}
+
+	if len(claims) == 0 {
+		return response, nil
+	}
+
+	saved := claims
+	if err := s.dbRepo.SaveClaims(claims); err != nil {
+		if mode == models.BatchModeAllOrNothing {
+			s.logger.Error("Error saving claim batch of %d claims: %v", len(claims), err)
+			return nil, errors.New("internal error saving claim batch")
+		}
+		// Best effort: isolate the failing claims by saving them one at a time.
+		s.logger.Warning("Error saving claim batch, retrying claims individually: %v", err)
+		saved = nil
+		for j, claim := range claims {
+			if err := s.dbRepo.SaveClaim(claim); err != nil {
+				s.logger.Error("Error saving claim %s of batch: %v", claim.ID, err)
+				s.rejectBatchItem(response, indexes[j], errors.New("internal error saving claim"))
+				continue
+			}
+			saved = append(saved, claim)
+		}
+	}
+
+	position := make(map[string]int, len(claims))
+	for j, claim := range claims {
+		position[claim.ID] = indexes[j]
+	}
+	for _, claim := range saved {
+		i := position[claim.ID]
+		response.Results[i].Status = models.BatchItemAccepted
+		response.Results[i].ClaimID = claim.ID
+		response.Results[i].ClaimStatus = claim.Status
+		response.Results[i].Rejects = claim.Rejects
+		response.Accepted++
+		if dropped := s.events.publish(claim); dropped > 0 {
+			s.logger.Warning("Claim %s not delivered to %d slow claim watchers", claim.ID, dropped)
+		}
+	}
+	response.Committed = response.Accepted > 0
+
+	s.logger.Info("Claim batch processed in %s mode: %d accepted, %d rejected", mode, response.Accepted, response.Rejected)
+	return response, nil
+}
+
+func (s *claimService) rejectBatchItem(response *models.ClaimBatchResponse, i int, err error) {
+	response.Results[i].Status = models.BatchItemRejected
+	response.Results[i].Error = err.Error()
+	response.Rejected++
+}
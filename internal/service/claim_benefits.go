@@ -0,0 +1,56 @@
+package service
+
+import (
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// BenefitPlans computes the cost sharing of claims under the benefit plans of their members.
+type BenefitPlans interface {
+	Plan(id string) (models.BenefitPlan, bool)
+	PatientPay(plan string, allowed float64, accumulator models.Accumulator) (models.ClaimBenefit, bool)
+}
+
+// benefit computes the cost sharing of a claim with the allowed amount under the plan covering its
+// member on the date of service, against the member's accumulator of the plan year. The paid claims
+// of the same batch not saved yet (pending) count toward the accumulator, while the outstanding cost
+// sharing of the claim a rebill replaces does not. Claims without a member, without coverage or
+// whose plan has no benefit definition have no cost sharing, as do all claims when no plan benefit
+// file or no eligibility roster is configured.
+//
+// The caller must hold benefitMu until the claim is saved, so that claims of a member are not
+// computed against the same accumulator concurrently.
+func (s *claimService) benefit(req models.ClaimSubmissionRequest, allowed float64, received time.Time, pending []models.Claim, replaced *models.Claim) (models.ClaimBenefit, error) {
+	if s.cfg.Benefits == nil || s.cfg.Eligibility == nil || req.MemberID == "" {
+		return models.ClaimBenefit{}, nil
+	}
+	date := serviceDate(req.Prescription, received)
+	eligibility := s.cfg.Eligibility.Check(req.MemberID, date)
+	if !eligibility.Eligible || eligibility.Coverage == nil {
+		return models.ClaimBenefit{}, nil
+	}
+
+	saved, err := s.dbRepo.GetAccumulator(req.MemberID, date.Year())
+	if err != nil {
+		return models.ClaimBenefit{}, err
+	}
+	accumulator := *saved
+	for _, claim := range pending {
+		if claim.Status == models.ClaimStatusPaid && claim.MemberID == req.MemberID && claim.PlanYear == accumulator.PlanYear {
+			accumulator.DeductibleMet += claim.DeductibleApplied
+			accumulator.OutOfPocketSpent += claim.PatientPay
+		}
+	}
+	if replaced != nil && replaced.MemberID == req.MemberID && replaced.PlanYear == accumulator.PlanYear {
+		accumulator.DeductibleMet -= replaced.OutstandingDeductible
+		accumulator.OutOfPocketSpent -= replaced.OutstandingPatientPay
+	}
+
+	benefit, ok := s.cfg.Benefits.PatientPay(eligibility.Coverage.Plan, allowed, accumulator)
+	if !ok {
+		s.logger.Warning("Plan %s of member %s has no benefit definition, no patient pay computed", eligibility.Coverage.Plan, req.MemberID)
+		return models.ClaimBenefit{}, nil
+	}
+	return benefit, nil
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// claimEventBuffer is the number of claims buffered per subscriber before new claims are dropped.
+const claimEventBuffer = 64
+
+// claimEvents fans submitted claims out to the subscribers of WatchClaims.
+// Publishing never blocks: a subscriber that falls more than claimEventBuffer claims
+// behind misses the claims that do not fit in its buffer.
+type claimEvents struct {
+	mu          sync.Mutex
+	subscribers map[chan models.Claim]struct{}
+}
+
+func newClaimEvents() *claimEvents {
+	return &claimEvents{subscribers: make(map[chan models.Claim]struct{})}
+}
+
+// subscribe registers a subscriber. The returned function unregisters it and closes the channel.
+func (e *claimEvents) subscribe() (<-chan models.Claim, func()) {
+	ch := make(chan models.Claim, claimEventBuffer)
+
+	e.mu.Lock()
+	e.subscribers[ch] = struct{}{}
+	e.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			e.mu.Lock()
+			delete(e.subscribers, ch)
+			e.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// publish delivers a claim to every subscriber and returns how many subscribers missed it.
+func (e *claimEvents) publish(claim models.Claim) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	dropped := 0
+	for ch := range e.subscribers {
+		select {
+		case ch <- claim:
+		default:
+			dropped++
+		}
+	}
+	return dropped
+}
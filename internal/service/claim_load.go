@@ -0,0 +1,105 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrClaimExists is returned for a loaded claim whose ID is already recorded.
+var ErrClaimExists = errors.New("claim already recorded")
+
+// LoadClaims adjudicates, prices and cost shares claims bulk loaded from files exactly like
+// SubmitClaim, keeping the ID of each record and its timestamp when set. The status, pricing and
+// cost sharing of the records are ignored. Only the paid claims are saved, in one transaction.
+// The returned slice holds one outcome per claim, in order: nil when it is saved, ErrClaimExists
+// when its ID is already recorded, or the reason it is refused. An error is only returned when the
+// claims could not be processed or saved, in which case none is saved; when only the save failed,
+// the outcomes are returned with it so the refused claims can still be quarantined.
+func (s *claimService) LoadClaims(claims []models.Claim) ([]error, error) {
+	outcomes := make([]error, len(claims))
+	pharmacies := make(map[string]*models.Pharmacy)
+	seen := make(map[string]bool, len(claims))
+	now := time.Now()
+	var paid []models.Claim
+
+	s.benefitMu.Lock()
+	defer s.benefitMu.Unlock()
+	for i, record := range claims {
+		if record.ID == "" {
+			outcomes[i] = errors.New("missing claim ID")
+			continue
+		}
+		if seen[record.ID] {
+			outcomes[i] = fmt.Errorf("duplicate claim ID '%s' in the same load", record.ID)
+			continue
+		}
+		seen[record.ID] = true
+
+		req := models.ClaimSubmissionRequest{NDC: record.NDC, NPI: record.NPI, Quantity: record.Quantity, Price: record.Price, Prescription: record.Prescription}
+		if err := ValidateClaimFields(req.NDC, req.NPI, req.Quantity, req.Price); err != nil {
+			outcomes[i] = err
+			continue
+		}
+		if err := ValidatePrescription(req.Prescription); err != nil {
+			outcomes[i] = err
+			continue
+		}
+		received := now
+		if record.Timestamp != "" {
+			var err error
+			if received, err = time.ParseInLocation("2006-01-02T15:04:05", record.Timestamp, time.Local); err != nil {
+				outcomes[i] = fmt.Errorf("invalid timestamp '%s': must be YYYY-MM-DDTHH:MM:SS", record.Timestamp)
+				continue
+			}
+		}
+
+		pharmacy, cached := pharmacies[req.NPI]
+		if !cached {
+			var err error
+			pharmacy, err = s.dbRepo.GetPharmacyByNPI(req.NPI)
+			if err != nil {
+				s.logger.Error("Error fetching pharmacy with NPI %s: %v", req.NPI, err)
+				return nil, errors.New("internal error loading claims")
+			}
+			pharmacies[req.NPI] = pharmacy
+		}
+		if pharmacy == nil {
+			outcomes[i] = fmt.Errorf("%w '%s'", ErrUnknownNPI, req.NPI)
+			continue
+		}
+
+		existing, err := s.dbRepo.GetClaimByID(record.ID)
+		if err != nil {
+			s.logger.Error("Error fetching claim %s: %v", record.ID, err)
+			return nil, errors.New("internal error loading claims")
+		}
+		if existing != nil {
+			outcomes[i] = ErrClaimExists
+			continue
+		}
+
+		claim, err := s.buildClaim(req, pharmacy.Chain, received, paid)
+		if err != nil {
+			return nil, errors.New("internal error loading claims")
+		}
+		if claim.Status != models.ClaimStatusPaid {
+			outcomes[i] = fmt.Errorf("claim adjudicated %s: %s", claim.Status, rejectSummary(claim.Rejects))
+			continue
+		}
+		claim.ID = record.ID
+		paid = append(paid, claim)
+	}
+
+	if len(paid) == 0 {
+		return outcomes, nil
+	}
+	if err := s.dbRepo.SaveClaims(paid); err != nil {
+		s.logger.Error("Error saving %d loaded claims: %v", len(paid), err)
+		return outcomes, errors.New("internal error saving loaded claims")
+	}
+	s.logger.Info("%d loaded claims adjudicated and saved, %d refused or already recorded", len(paid), len(claims)-len(paid))
+	return outcomes, nil
+}
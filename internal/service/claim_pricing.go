@@ -0,0 +1,18 @@
+package service
+
+import (
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// Pricer computes the allowed amount of a claim submitted by a pharmacy of a chain.
+type Pricer interface {
+	Price(claim models.ClaimSubmissionRequest, chain string) models.ClaimPricing
+}
+
+// price applies the configured pricer to a claim; without one the submitted price is allowed.
+func (s *claimService) price(req models.ClaimSubmissionRequest, chain string) models.ClaimPricing {
+	if s.cfg.Pricer == nil {
+		return models.ClaimPricing{AllowedAmount: req.Price, Basis: models.PricingBasisSubmitted}
+	}
+	return s.cfg.Pricer.Price(req, chain)
+}
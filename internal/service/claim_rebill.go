@@ -0,0 +1,149 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// RebillClaim reverses what is outstanding of a paid claim and replaces it with a corrected
+// claim referencing it, atomically. The original claim moves to rebilled; its reversal is subject
+// to the reversal policy like any other and records the reason code. The corrected claim
+// must be paid at adjudication, otherwise nothing changes, and is priced like a new claim. Its
+// patient pay is computed as if the original claim had never been paid.
+func (s *claimService) RebillClaim(req models.ClaimRebillRequest) (*models.Claim, error) {
+	if err := s.cfg.ReversalPolicy.CheckReasonCode(req.ReasonCode, req.Reason); err != nil {
+		return nil, err
+	}
+
+	original, err := s.dbRepo.GetClaimByID(req.ClaimID)
+	if err != nil {
+		s.logger.Error("Error fetching claim %s for rebill: %v", req.ClaimID, err)
+		return nil, errors.New("internal error rebilling claim")
+	}
+	if original == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrClaimNotFound, req.ClaimID)
+	}
+	if err := checkTransition(original, models.ClaimStatusRebilled); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.checkReversalAge(*original, now, map[string]string{}); err != nil {
+		if errors.Is(err, ErrReversalPolicyViolation) {
+			return nil, err
+		}
+		s.logger.Error("Error checking the reversal policy for claim %s: %v", original.ID, err)
+		return nil, errors.New("internal error rebilling claim")
+	}
+
+	replacement := models.Claim{
+		ID:              uuid.New().String(),
+		NDC:             original.NDC,
+		NPI:             original.NPI,
+		Quantity:        original.Quantity,
+		Price:           original.Price,
+		Timestamp:       now.Format("2006-01-02T15:04:05"),
+		Status:          models.ClaimStatusPaid,
+		ReplacesClaimID: original.ID,
+		Prescription:    original.Prescription,
+	}
+	if req.NDC != "" {
+		replacement.NDC = req.NDC
+	}
+	if req.Quantity != 0 {
+		replacement.Quantity = req.Quantity
+	}
+	if req.Price != 0 {
+		replacement.Price = req.Price
+	}
+	if err := ValidateClaimFields(replacement.NDC, replacement.NPI, replacement.Quantity, replacement.Price); err != nil {
+		return nil, err
+	}
+	replacement.OutstandingQuantity = replacement.Quantity
+	replacement.OutstandingAmount = replacement.Price
+
+	var chain string
+	if s.cfg.Adjudicator != nil || s.cfg.Pricer != nil || s.cfg.TimelyFiling.hasChainRules() {
+		pharmacy, err := s.dbRepo.GetPharmacyByNPI(replacement.NPI)
+		if err != nil {
+			s.logger.Error("Error fetching pharmacy with NPI %s for rebill: %v", replacement.NPI, err)
+			return nil, errors.New("internal error rebilling claim")
+		}
+		if pharmacy != nil {
+			chain = pharmacy.Chain
+		}
+	}
+	submission := models.ClaimSubmissionRequest{
+		NDC:      replacement.NDC,
+		Quantity: replacement.Quantity,
+		NPI:      replacement.NPI,
+		Price:    replacement.Price,
+
+		Prescription: replacement.Prescription,
+	}
+	adjudication := s.adjudicate(submission, chain)
+	if err := s.checkRefill(&adjudication, submission, now, nil, original); err != nil {
+		s.logger.Error("Error fetching fill history of member %s for rebill: %v", submission.MemberID, err)
+		return nil, errors.New("internal error rebilling claim")
+	}
+	if adjudication.Status != models.ClaimStatusPaid {
+		return nil, fmt.Errorf("%w: adjudicated %s with %s", ErrRebillNotPaid, adjudication.Status, rejectSummary(adjudication.Rejects))
+	}
+	pricing := s.price(submission, chain)
+	replacement.AllowedAmount = pricing.AllowedAmount
+	replacement.PricingBasis = pricing.Basis
+	variance := s.cfg.PriceVariance.Check(replacement.NDC, replacement.Quantity, replacement.Price, serviceDate(replacement.Prescription, now))
+	replacement.ReferenceUnitPrice = variance.ReferenceUnitPrice
+	replacement.PriceVariance = variance.VariancePercent
+	replacement.PriceFlagged = variance.Flagged
+
+	s.benefitMu.Lock()
+	defer s.benefitMu.Unlock()
+	benefit, err := s.benefit(submission, replacement.AllowedAmount, now, nil, original)
+	if err != nil {
+		s.logger.Error("Error fetching accumulator of member %s for rebill: %v", replacement.MemberID, err)
+		return nil, errors.New("internal error rebilling claim")
+	}
+	replacement.ClaimBenefit = benefit
+	replacement.OutstandingPatientPay = benefit.PatientPay
+	replacement.OutstandingDeductible = benefit.DeductibleApplied
+
+	revert := models.Revert{
+		ID:         uuid.New().String(),
+		ClaimID:    original.ID,
+		Timestamp:  replacement.Timestamp,
+		ReasonCode: req.ReasonCode,
+		Reason:     req.Reason,
+		Quantity:   original.OutstandingQuantity,
+		Amount:     original.OutstandingAmount,
+	}
+	if err := s.dbRepo.RebillClaim(revert, replacement, requestActor(req.Actor)); err != nil {
+		s.logger.Error("Error rebilling claim %s: %v", original.ID, err)
+		return nil, errors.New("internal error rebilling claim")
+	}
+
+	s.logger.Info("Claim %s rebilled as claim %s. Revert ID: %s", original.ID, replacement.ID, revert.ID)
+	if dropped := s.events.publish(replacement); dropped > 0 {
+		s.logger.Warning("Claim %s not delivered to %d slow claim watchers", replacement.ID, dropped)
+	}
+	return &replacement, nil
+}
+
+// GetClaimVersions fetches the chain of versions the claim belongs to, from the original claim
+// to its latest replacement.
+func (s *claimService) GetClaimVersions(id string) ([]models.Claim, error) {
+	versions, err := s.dbRepo.GetClaimVersions(id)
+	if err != nil {
+		s.logger.Error("DB error fetching versions of claim %s: %v", id, err)
+		return nil, fmt.Errorf("error fetching claim versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrClaimNotFound, id)
+	}
+	return versions, nil
+}
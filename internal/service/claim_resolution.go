@@ -0,0 +1,77 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidResolution is returned when a resolution request has no reason or asks for a status
+// other than paid or rejected.
+var ErrInvalidResolution = errors.New("invalid resolution: a reason and a status of paid or rejected are required")
+
+// ResolutionService defines the interface for resolving the claims pended at adjudication.
+type ResolutionService interface {
+	ResolveClaim(req models.ClaimResolveRequest) (*models.Claim, error)
+}
+
+type resolutionService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+}
+
+// NewResolutionService creates and returns a new instance of the ResolutionService interface.
+func NewResolutionService(log logger.Logger, dbRepo database.DBRepository) ResolutionService {
+	return &resolutionService{
+		logger: log,
+		dbRepo: dbRepo,
+	}
+}
+
+// ResolveClaim moves a pending claim to paid or rejected after its manual review. The decision is
+// recorded in the status history with the reason; a paid claim adds its cost sharing to the
+// member accumulators.
+func (s *resolutionService) ResolveClaim(req models.ClaimResolveRequest) (*models.Claim, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || (req.Status != models.ClaimStatusPaid && req.Status != models.ClaimStatusRejected) {
+		return nil, ErrInvalidResolution
+	}
+
+	claim, err := s.dbRepo.GetClaimByID(req.ClaimID)
+	if err != nil {
+		s.logger.Error("Error fetching claim %s for resolution: %v", req.ClaimID, err)
+		return nil, errors.New("internal error resolving claim")
+	}
+	if claim == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrClaimNotFound, req.ClaimID)
+	}
+	if claim.Status != models.ClaimStatusPending {
+		return nil, fmt.Errorf("%w: claim with ID '%s' is %s, only pending claims can be resolved", ErrInvalidStatusTransition, claim.ID, claim.Status)
+	}
+
+	change := models.ClaimStatusChange{
+		ClaimID:    claim.ID,
+		FromStatus: claim.Status,
+		ToStatus:   req.Status,
+		Actor:      requestActor(req.Actor),
+		Reason:     reason,
+		Timestamp:  time.Now().Format("2006-01-02T15:04:05"),
+	}
+	if err := s.dbRepo.UpdateClaimStatus(change); err != nil {
+		s.logger.Error("Error resolving claim %s: %v", claim.ID, err)
+		return nil, errors.New("internal error resolving claim")
+	}
+
+	resolved, err := s.dbRepo.GetClaimByID(claim.ID)
+	if err != nil || resolved == nil {
+		s.logger.Error("Error fetching resolved claim %s: %v", claim.ID, err)
+		return nil, errors.New("internal error resolving claim")
+	}
+	s.logger.Info("Pending claim %s resolved as %s. Reason: %s", claim.ID, resolved.Status, reason)
+	return resolved, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidClaimData is returned when a claim is missing required data or has non-positive amounts.
+var ErrInvalidClaimData = errors.New("invalid claim data: NDC, NPI, Quantity, and Price are required and must be positive")
+
+// ErrInvalidPrescription is returned when the member or prescription details of a claim are malformed.
+var ErrInvalidPrescription = errors.New("invalid prescription data")
+
+// ErrUnknownNPI is returned when a claim references an NPI that is not a known pharmacy.
+var ErrUnknownNPI = errors.New("invalid NPI")
+
+// ValidateClaimFields applies the field-level business rules shared by claim submission and bulk loading.
+func ValidateClaimFields(ndc, npi string, quantity, price float64) error {
+	if ndc == "" || npi == "" || quantity <= 0 || price <= 0 {
+		return ErrInvalidClaimData
+	}
+	return nil
+}
+
+// ValidatePrescription checks the format of the member and prescription details shared by claim
+// submission and bulk loading, and that the date of service is not after today. Empty fields are
+// not validated.
+func ValidatePrescription(p models.Prescription) error {
+	if len(p.MemberID) > 20 {
+		return fmt.Errorf("%w: member ID '%s' is longer than 20 characters", ErrInvalidPrescription, p.MemberID)
+	}
+	if p.PrescriberNPI != "" && !isDigits(p.PrescriberNPI, 10) {
+		return fmt.Errorf("%w: prescriber NPI '%s' must be 10 digits", ErrInvalidPrescription, p.PrescriberNPI)
+	}
+	if len(p.RxNumber) > 12 || !isAlphanumeric(p.RxNumber) {
+		return fmt.Errorf("%w: Rx number '%s' must be at most 12 letters or digits", ErrInvalidPrescription, p.RxNumber)
+	}
+	if p.FillNumber < 0 || p.FillNumber > 99 {
+		return fmt.Errorf("%w: fill number %d must be between 0 and 99", ErrInvalidPrescription, p.FillNumber)
+	}
+	if p.DaysSupply < 0 || p.DaysSupply > 999 {
+		return fmt.Errorf("%w: days supply %d must be between 0 and 999", ErrInvalidPrescription, p.DaysSupply)
+	}
+	if p.DateOfService != "" {
+		date, err := time.Parse(periodDateLayout, p.DateOfService)
+		if err != nil {
+			return fmt.Errorf("%w: date of service '%s' must be formatted as YYYY-MM-DD", ErrInvalidPrescription, p.DateOfService)
+		}
+		if date.After(dateOnly(time.Now())) {
+			return fmt.Errorf("%w: date of service '%s' is in the future", ErrInvalidPrescription, p.DateOfService)
+		}
+	}
+	if p.DAWCode != "" && !isDigits(p.DAWCode, 1) {
+		return fmt.Errorf("%w: DAW code '%s' must be a single digit", ErrInvalidPrescription, p.DAWCode)
+	}
+	if _, ok := models.RefillOverrideCodes[p.OverrideCode]; p.OverrideCode != "" && !ok {
+		return fmt.Errorf("%w: override code '%s' is not one of %s", ErrInvalidPrescription, p.OverrideCode, overrideCodeList())
+	}
+	return nil
+}
+
+// isDigits reports whether s is made of exactly n ASCII digits.
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphanumeric reports whether s is made of ASCII letters and digits only.
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePharmacyNPI checks that the NPI belongs to a known pharmacy.
+// Repository failures are returned wrapped so callers can tell them apart from unknown NPIs.
+func ValidatePharmacyNPI(dbRepo database.DBRepository, npi string) error {
+	pharmacy, err := dbRepo.GetPharmacyByNPI(npi)
+	if err != nil {
+		return fmt.Errorf("error fetching pharmacy with NPI %s: %w", npi, err)
+	}
+	if pharmacy == nil {
+		return fmt.Errorf("%w '%s'", ErrUnknownNPI, npi)
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,32 +17,63 @@ import (
 // This interface specifies the methods that any claim service implementation must have.
 type ClaimService interface {
 	SubmitClaim(req models.ClaimSubmissionRequest) (*models.Claim, error)
+	SubmitClaims(reqs []models.ClaimSubmissionRequest, mode string) (*models.ClaimBatchResponse, error)
+	LoadClaims(claims []models.Claim) ([]error, error)
+	AdjudicateClaim(req models.ClaimSubmissionRequest) (*models.Adjudication, error)
 	ReverseClaim(req models.ClaimReversalRequest) (*models.Revert, error)
+	ReverseClaims(req models.BatchReversalRequest) (*models.BatchReversalResponse, error)
 	GetClaimByID(id string) (*models.Claim, error)
+	GetPrescriptionClaim(npi, rxNumber string, fillNumber int, dateOfService string) (*models.Claim, error)
+	GetClaimHistory(id string) ([]models.ClaimStatusChange, error)
+	RebillClaim(req models.ClaimRebillRequest) (*models.Claim, error)
+	GetClaimVersions(id string) ([]models.Claim, error)
+	SearchClaims(filter models.ClaimFilter) ([]models.Claim, int, error)
+	WatchClaims() (<-chan models.Claim, func())
 	// Add other methods that your ClaimService might have in the future here
 }
 
+// ClaimConfig holds the policies applied by the claim service.
+type ClaimConfig struct {
+	ReversalPolicy ReversalPolicy      // Rules checked before a claim is reversed
+	Adjudicator    Adjudicator         // Decides the status of submitted claims; nil pays every claim
+	Pricer         Pricer              // Computes the allowed amount of submitted claims; nil allows the submitted price
+	PriceVariance  PriceVarianceConfig // Flags submitted claims priced far above the reference price
+	Eligibility    EligibilityChecker  // Rejects claims for members not covered on the date of service; nil disables the check
+	RefillPolicy   RefillPolicy        // Rejects refills submitted before the previous fill is consumed enough
+	TimelyFiling   TimelyFilingPolicy  // Rejects claims submitted too long after their date of service
+	Benefits       BenefitPlans        // Computes the patient pay of claims under the plan of their member; nil disables cost sharing
+}
+
 // claimService is the concrete implementation of the ClaimService interface.
 // The lowercase 'c' is a convention to differentiate it from the interface of the same name.
 type claimService struct {
 	logger logger.Logger
 	dbRepo database.DBRepository
+	cfg    ClaimConfig
+	events *claimEvents
+
+	benefitMu sync.Mutex // Serializes the cost sharing of claims against the member accumulators
 }
 
 // NewClaimService creates and returns a new instance of the ClaimService interface.
 // It returns a POINTER to the concrete 'claimService' struct, which satisfies the interface.
-func NewClaimService(log logger.Logger, dbRepo database.DBRepository) ClaimService {
+func NewClaimService(log logger.Logger, dbRepo database.DBRepository, cfg ClaimConfig) ClaimService {
 	return &claimService{ // Returns a pointer to the concrete implementation
 		logger: log,
 		dbRepo: dbRepo,
+		cfg:    cfg,
+		events: newClaimEvents(),
 	}
 }
 
 // SubmitClaim processes the submission of a new claim.
 // The '*claimService' receiver means this method operates on a pointer to the struct.
 func (s *claimService) SubmitClaim(req models.ClaimSubmissionRequest) (*models.Claim, error) {
-	if req.NDC == "" || req.NPI == "" || req.Quantity <= 0 || req.Price <= 0 {
-		return nil, errors.New("invalid claim data: NDC, NPI, Quantity, and Price are required and must be positive")
+	if err := ValidateClaimFields(req.NDC, req.NPI, req.Quantity, req.Price); err != nil {
+		return nil, err
+	}
+	if err := ValidatePrescription(req.Prescription); err != nil {
+		return nil, err
 	}
 
 	pharmacy, err := s.dbRepo.GetPharmacyByNPI(req.NPI)
@@ -50,17 +82,14 @@ func (s *claimService) SubmitClaim(req models.ClaimSubmissionRequest) (*models.C
 		return nil, fmt.Errorf("internal error processing claim")
 	}
 	if pharmacy == nil {
-		return nil, fmt.Errorf("invalid NPI '%s'", req.NPI)
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownNPI, req.NPI)
 	}
 
-	newClaim := models.Claim{
-		ID:        uuid.New().String(),
-		NDC:       req.NDC,
-		NPI:       req.NPI,
-		Quantity:  req.Quantity,
-		Price:     req.Price,
-		Timestamp: time.Now().Format("2006-01-02T15:04:05"), // String format for the timestamp
-		Reverted:  false,
+	s.benefitMu.Lock()
+	defer s.benefitMu.Unlock()
+	newClaim, err := s.buildClaim(req, pharmacy.Chain, time.Now(), nil)
+	if err != nil {
+		return nil, errors.New("internal error processing claim")
 	}
 
 	if err := s.dbRepo.SaveClaim(newClaim); err != nil {
@@ -68,16 +97,77 @@ func (s *claimService) SubmitClaim(req models.ClaimSubmissionRequest) (*models.C
 		return nil, errors.New("internal error saving claim")
 	}
 
-	s.logger.Info("Claim %s submitted successfully for NPI %s", newClaim.ID, newClaim.NPI)
+	s.logger.Info("Claim %s submitted successfully for NPI %s, adjudicated %s", newClaim.ID, newClaim.NPI, newClaim.Status)
+	if newClaim.PriceFlagged {
+		s.logger.Warning("Claim %s flagged: unit price %.2f%% above the reference price of NDC %s", newClaim.ID, newClaim.PriceVariance, newClaim.NDC)
+	}
+	if dropped := s.events.publish(newClaim); dropped > 0 {
+		s.logger.Warning("Claim %s not delivered to %d slow claim watchers", newClaim.ID, dropped)
+	}
 	return &newClaim, nil
 }
 
-// ReverseClaim processes the reversal of an existing claim.
+// buildClaim builds the claim of a valid request from a pharmacy of the chain received at received:
+// adjudicated, checked for refills too soon among the saved claims and the claims of the same
+// batch not saved yet (pending), priced, checked for price variance and cost shared. The error is
+// only returned when the fill history or the accumulator of the member cannot be read. The caller
+// must hold benefitMu until the claim is saved.
+func (s *claimService) buildClaim(req models.ClaimSubmissionRequest, chain string, received time.Time, pending []models.Claim) (models.Claim, error) {
+	adjudication := s.adjudicate(req, chain)
+	if err := s.checkRefill(&adjudication, req, received, pending, nil); err != nil {
+		s.logger.Error("Error fetching fill history of member %s: %v", req.MemberID, err)
+		return models.Claim{}, err
+	}
+	pricing := s.price(req, chain)
+	variance := s.cfg.PriceVariance.Check(req.NDC, req.Quantity, req.Price, serviceDate(req.Prescription, received))
+
+	var benefit models.ClaimBenefit
+	if adjudication.Status != models.ClaimStatusRejected {
+		var err error
+		if benefit, err = s.benefit(req, pricing.AllowedAmount, received, pending, nil); err != nil {
+			s.logger.Error("Error fetching accumulator of member %s: %v", req.MemberID, err)
+			return models.Claim{}, err
+		}
+	}
+	return models.Claim{
+		ID:        uuid.New().String(),
+		NDC:       req.NDC,
+		NPI:       req.NPI,
+		Quantity:  req.Quantity,
+		Price:     req.Price,
+		Timestamp: received.Format("2006-01-02T15:04:05"), // String format for the timestamp
+		Status:    adjudication.Status,
+		Rejects:   adjudication.Rejects,
+
+		AllowedAmount: pricing.AllowedAmount,
+		PricingBasis:  pricing.Basis,
+
+		ReferenceUnitPrice: variance.ReferenceUnitPrice,
+		PriceVariance:      variance.VariancePercent,
+		PriceFlagged:       variance.Flagged,
+
+		Prescription: req.Prescription,
+		ClaimBenefit: benefit,
+
+		OutstandingQuantity:   req.Quantity,
+		OutstandingAmount:     req.Price,
+		OutstandingPatientPay: benefit.PatientPay,
+		OutstandingDeductible: benefit.DeductibleApplied,
+	}, nil
+}
+
+// ReverseClaim processes the full or partial reversal of an existing claim.
 // The '*claimService' receiver means this method operates on a pointer to the struct.
 func (s *claimService) ReverseClaim(req models.ClaimReversalRequest) (*models.Revert, error) {
 	if req.ClaimID == "" {
 		return nil, errors.New("invalid reversal claim ID")
 	}
+	if req.Quantity < 0 || req.Amount < 0 {
+		return nil, ErrInvalidReversalAmount
+	}
+	if err := s.cfg.ReversalPolicy.CheckReasonCode(req.ReasonCode, req.Reason); err != nil {
+		return nil, err
+	}
 
 	claim, err := s.dbRepo.GetClaimByID(req.ClaimID) // This already exists and works in dbRepo
 	if err != nil {
@@ -87,28 +177,44 @@ func (s *claimService) ReverseClaim(req models.ClaimReversalRequest) (*models.Re
 	if claim == nil {
 		return nil, fmt.Errorf("claim with ID '%s' not found for reversal", req.ClaimID)
 	}
-	if claim.Reverted {
+	if claim.Status == models.ClaimStatusReversed {
 		return nil, fmt.Errorf("claim with ID '%s' is already reverted", req.ClaimID)
 	}
+	if err := checkTransition(claim, models.ClaimStatusReversed); err != nil {
+		return nil, err
+	}
 
-	// Update the claim status as reverted
-	if err := s.dbRepo.UpdateClaimRevertedStatus(claim.ID, true); err != nil {
-		s.logger.Error("Error updating claim reversal status for claim %s: %v", claim.ID, err)
+	now := time.Now()
+	if err := s.checkReversalAge(*claim, now, map[string]string{}); err != nil {
+		if errors.Is(err, ErrReversalPolicyViolation) {
+			return nil, err
+		}
+		s.logger.Error("Error checking the reversal policy for claim %s: %v", claim.ID, err)
 		return nil, errors.New("internal error reverting claim")
 	}
 
+	quantity, amount, err := ReversalAmounts(claim, req.Quantity, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
 	newRevert := models.Revert{
-		ID:        uuid.New().String(),
-		ClaimID:   claim.ID,
-		Timestamp: time.Now().Format("2006-01-02T15:04:05"), // String format for the timestamp
+		ID:         uuid.New().String(),
+		ClaimID:    claim.ID,
+		Timestamp:  now.Format("2006-01-02T15:04:05"), // String format for the timestamp
+		Reason:     req.Reason,
+		Quantity:   quantity,
+		Amount:     amount,
+		ReasonCode: req.ReasonCode,
 	}
 
-	if err := s.dbRepo.SaveRevert(newRevert); err != nil {
+	// Save the revert; the claim moves to reversed once nothing of it is outstanding
+	if err := s.dbRepo.ReverseClaims([]models.Revert{newRevert}, requestActor(req.Actor)); err != nil {
 		s.logger.Error("Error saving reversal record for claim %s: %v", newRevert.ClaimID, err)
-		return nil, errors.New("internal error saving claim reversal")
+		return nil, errors.New("internal error reverting claim")
 	}
 
-	s.logger.Info("Claim %s reverted successfully. Revert ID: %s", claim.ID, newRevert.ID)
+	s.logger.Info("Claim %s reverted successfully (quantity %g, amount %.2f, reason code %s). Revert ID: %s", claim.ID, quantity, amount, newRevert.ReasonCode, newRevert.ID)
 	return &newRevert, nil
 }
 
@@ -123,3 +229,32 @@ func (s *claimService) GetClaimByID(id string) (*models.Claim, error) {
 	}
 	return claim, nil
 }
+
+// GetPrescriptionClaim fetches the latest claim of a pharmacy for the fill of a prescription
+// filled on the date of service (YYYY-MM-DD), nil when there is none. Claims of other pharmacies
+// never match.
+func (s *claimService) GetPrescriptionClaim(npi, rxNumber string, fillNumber int, dateOfService string) (*models.Claim, error) {
+	claim, err := s.dbRepo.GetClaimByPrescription(npi, rxNumber, fillNumber, dateOfService)
+	if err != nil {
+		s.logger.Error("DB error fetching claim of Rx %s fill %d for NPI %s: %v", rxNumber, fillNumber, npi, err)
+		return nil, fmt.Errorf("error fetching claim: %w", err)
+	}
+	return claim, nil
+}
+
+// SearchClaims fetches the claims matching the filter and the total number of matches.
+func (s *claimService) SearchClaims(filter models.ClaimFilter) ([]models.Claim, int, error) {
+	claims, total, err := s.dbRepo.SearchClaims(filter)
+	if err != nil {
+		s.logger.Error("DB error searching claims: %v", err)
+		return nil, 0, fmt.Errorf("error searching claims: %w", err)
+	}
+	return claims, total, nil
+}
+
+// WatchClaims subscribes to the claims submitted through the service from now on.
+// Claims bulk loaded from files are not published. The returned function ends the
+// subscription and closes the channel; it must be called once the caller stops reading.
+func (s *claimService) WatchClaims() (<-chan models.Claim, func()) {
+	return s.events.subscribe()
+}
@@ -2,11 +2,15 @@ package service_test
 
 import (
 	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/diogocarasco/go-pharmacy-service/internal/benefits"
 	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
 	"github.com/diogocarasco/go-pharmacy-service/internal/models"
 	"github.com/diogocarasco/go-pharmacy-service/internal/service"
@@ -29,6 +33,14 @@ func (m *MockDBRepository) GetPharmacyByNPI(npi string) (*models.Pharmacy, error
 	return args.Get(0).(*models.Pharmacy), args.Error(1)
 }
 
+func (m *MockDBRepository) ListPharmacies(chain string) ([]models.Pharmacy, error) {
+	args := m.Called(chain)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Pharmacy), args.Error(1)
+}
+
 func (m *MockDBRepository) SaveClaim(claim models.Claim) error {
 	args := m.Called(claim)
 	return args.Error(0)
@@ -47,18 +59,171 @@ func (m *MockDBRepository) GetClaimByID(id string) (*models.Claim, error) {
 	return args.Get(0).(*models.Claim), args.Error(1)
 }
 
-func (m *MockDBRepository) UpdateClaimRevertedStatus(id string, reverted bool) error {
-	args := m.Called(id, reverted)
+func (m *MockDBRepository) GetClaimByPrescription(npi, rxNumber string, fillNumber int, date string) (*models.Claim, error) {
+	args := m.Called(npi, rxNumber, fillNumber, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Claim), args.Error(1)
+}
+
+func (m *MockDBRepository) UpdateClaimStatus(change models.ClaimStatusChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetClaimStatusHistory(claimID string) ([]models.ClaimStatusChange, error) {
+	args := m.Called(claimID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ClaimStatusChange), args.Error(1)
+}
+
+func (m *MockDBRepository) SaveQuarantinedClaims(records []models.QuarantinedClaim) error {
+	args := m.Called(records)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetClaimsByNPI(npi, from, to string) ([]models.Claim, error) {
+	args := m.Called(npi, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Claim), args.Error(1)
+}
+
+func (m *MockDBRepository) GetLatestFill(memberID, ndc, date, excludeID string) (*models.Claim, error) {
+	args := m.Called(memberID, ndc, date, excludeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Claim), args.Error(1)
+}
+
+func (m *MockDBRepository) GetAccumulator(memberID string, planYear int) (*models.Accumulator, error) {
+	args := m.Called(memberID, planYear)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Accumulator), args.Error(1)
+}
+
+func (m *MockDBRepository) GetReversedClaimsByNPI(npi, from, to string) ([]models.ReversedClaim, error) {
+	args := m.Called(npi, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ReversedClaim), args.Error(1)
+}
+
+func (m *MockDBRepository) NextControlNumber(name string) (int64, error) {
+	args := m.Called(name)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDBRepository) SearchClaims(filter models.ClaimFilter) ([]models.Claim, int, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]models.Claim), args.Int(1), args.Error(2)
+}
+
+func (m *MockDBRepository) UpdateClaimPriceVariances(variances []models.ClaimPriceVariance) error {
+	args := m.Called(variances)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) GetPriceOutliers(npi, from, to, date string) ([]models.PharmacyPriceOutliers, error) {
+	args := m.Called(npi, from, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PharmacyPriceOutliers), args.Error(1)
+}
+
+func (m *MockDBRepository) CountClaimsByNPI(from, to string) (map[string]int, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockDBRepository) GetReversalCounts(from, to string) ([]models.ReversalCount, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ReversalCount), args.Error(1)
+}
+
+func (m *MockDBRepository) GetPharmacyUnitPrices(from, to string) ([]models.PharmacyUnitPrice, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PharmacyUnitPrice), args.Error(1)
+}
+
+func (m *MockDBRepository) RecordAnomalies(anomalies []models.Anomaly, detectedAt string) error {
+	args := m.Called(anomalies, detectedAt)
+	return args.Error(0)
+}
+
+func (m *MockDBRepository) ListAnomalies(filter models.AnomalyFilter) ([]models.Anomaly, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Anomaly), args.Error(1)
+}
+
+func (m *MockDBRepository) GetNDCClaimTotals(from, to, date string) ([]models.NDCClaimTotals, error) {
+	args := m.Called(from, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NDCClaimTotals), args.Error(1)
+}
+
+func (m *MockDBRepository) GetChainSavings(from, to, date string) ([]models.ChainSavings, error) {
+	args := m.Called(from, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ChainSavings), args.Error(1)
+}
+
+func (m *MockDBRepository) ReverseClaims(reverts []models.Revert, actor string) error {
+	args := m.Called(reverts, actor)
 	return args.Error(0)
 }
 
-func (m *MockDBRepository) SaveRevert(revert models.Revert) error {
-	args := m.Called(revert)
+func (m *MockDBRepository) RebillClaim(revert models.Revert, replacement models.Claim, actor string) error {
+	args := m.Called(revert, replacement, actor)
 	return args.Error(0)
 }
 
-func (m *MockDBRepository) SaveReverts(reverts []models.Revert) error {
-	args := m.Called(reverts)
+func (m *MockDBRepository) GetClaimVersions(id string) ([]models.Claim, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Claim), args.Error(1)
+}
+
+func (m *MockDBRepository) GetRevertsByClaimID(claimID string) ([]models.Revert, error) {
+	args := m.Called(claimID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Revert), args.Error(1)
+}
+
+func (m *MockDBRepository) ReinstateClaim(revert models.Revert, change *models.ClaimStatusChange) error {
+	args := m.Called(revert, change)
 	return args.Error(0)
 }
 
@@ -75,7 +240,7 @@ func TestSubmitClaimSuccess(t *testing.T) {
 	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
 	req := models.ClaimSubmissionRequest{
 		NDC:      "00002323401",
@@ -93,6 +258,7 @@ func TestSubmitClaimSuccess(t *testing.T) {
 	assert.Equal(t, req.Quantity, claim.Quantity, "Claim Quantity should match request Quantity")
 	assert.Equal(t, req.Price, claim.Price, "Claim Price should match request Price")
 	assert.False(t, claim.Reverted, "Claim should not be reverted initially")
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status, "Claim should be paid initially")
 	mockRepo.AssertExpectations(t)
 }
 
@@ -101,7 +267,7 @@ func TestSubmitClaimInvalidData(t *testing.T) {
 	mockLogger := logger.NewLogger()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
 	req := models.ClaimSubmissionRequest{
 		NDC:      "",
@@ -120,6 +286,61 @@ func TestSubmitClaimInvalidData(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestSubmitClaimWithPrescription(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	prescription := models.Prescription{
+		MemberID:      "M1001",
+		PrescriberNPI: "1999999984",
+		RxNumber:      "RX123456",
+		FillNumber:    2,
+		DaysSupply:    30,
+		DateOfService: "2024-02-01",
+		DAWCode:       "1",
+	}
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.MatchedBy(func(claim models.Claim) bool {
+		return claim.Prescription == prescription
+	})).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50, Prescription: prescription,
+	})
+
+	assert.Nil(t, err, "Expected no error submitting a claim with prescription details")
+	assert.Equal(t, prescription, claim.Prescription, "Claim should carry the prescription details")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitClaimInvalidPrescription(t *testing.T) {
+	tests := map[string]models.Prescription{
+		"prescriber NPI":  {PrescriberNPI: "12345"},
+		"Rx number":       {RxNumber: "RX-1"},
+		"long Rx number":  {RxNumber: "1234567890123"},
+		"fill number":     {FillNumber: 100},
+		"days supply":     {DaysSupply: -1},
+		"date of service": {DateOfService: "02/01/2024"},
+		"future date":     {DateOfService: time.Now().AddDate(0, 0, 1).Format("2006-01-02")},
+		"DAW code":        {DAWCode: "10"},
+		"long member ID":  {MemberID: "M12345678901234567890"},
+	}
+
+	for name, prescription := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockDBRepository)
+			claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+
+			claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+				NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50, Prescription: prescription,
+			})
+
+			assert.Nil(t, claim, "Expected no claim for an invalid %s", name)
+			assert.ErrorIs(t, err, service.ErrInvalidPrescription)
+			mockRepo.AssertNotCalled(t, "SaveClaim", mock.Anything)
+		})
+	}
+}
+
 func TestSubmitClaimNPINotSupported(t *testing.T) {
 	mockRepo := new(MockDBRepository)
 	mockLogger := logger.NewLogger()
@@ -127,7 +348,7 @@ func TestSubmitClaimNPINotSupported(t *testing.T) {
 	mockRepo.On("GetPharmacyByNPI", "9999999999").Return(nil, nil).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
 	req := models.ClaimSubmissionRequest{
 		NDC:      "00002323401",
@@ -157,17 +378,21 @@ func TestReverseClaimSuccess(t *testing.T) {
 		Quantity:  10,
 		Price:     100.0,
 		Timestamp: "2006-01-02T15:04:05",
-		Reverted:  false,
+		Status:    models.ClaimStatusPaid,
+
+		OutstandingQuantity: 10,
+		OutstandingAmount:   100.0,
 	}
 
 	mockRepo.On("GetClaimByID", claimID).Return(mockClaim, nil).Once()
-	mockRepo.On("UpdateClaimRevertedStatus", claimID, true).Return(nil).Once()
-	mockRepo.On("SaveRevert", mock.AnythingOfType("models.Revert")).Return(nil).Once()
+	mockRepo.On("ReverseClaims", mock.MatchedBy(func(reverts []models.Revert) bool {
+		return len(reverts) == 1 && reverts[0].ClaimID == claimID && reverts[0].Quantity == 10 && reverts[0].Amount == 100.0
+	}), models.ActorAPI).Return(nil).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
-	req := models.ClaimReversalRequest{ClaimID: claimID}
+	req := models.ClaimReversalRequest{ClaimID: claimID, ReasonCode: models.ReversalReasonBilledInError, Actor: models.ActorAPI}
 	revert, err := claimService.ReverseClaim(req)
 
 	assert.Nil(t, err, "Expected no error for successful claim reversal")
@@ -185,16 +410,15 @@ func TestReverseClaimNotFound(t *testing.T) {
 	mockRepo.On("GetClaimByID", claimID).Return(nil, nil).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
-	req := models.ClaimReversalRequest{ClaimID: claimID}
+	req := models.ClaimReversalRequest{ClaimID: claimID, ReasonCode: models.ReversalReasonBilledInError}
 	revert, err := claimService.ReverseClaim(req)
 
 	assert.Nil(t, revert, "Expected no revert object to be returned when claim is not found")
 	assert.NotNil(t, err, "Expected an error when claim is not found")
 	assert.Contains(t, err.Error(), "claim with ID 'non-existent-id' not found for reversal", "Error message should indicate claim not found")
-	mockRepo.AssertNotCalled(t, "UpdateClaimRevertedStatus", mock.Anything, mock.Anything)
-	mockRepo.AssertNotCalled(t, "SaveRevert", mock.Anything)
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -210,22 +434,22 @@ func TestReverseClaimAlreadyReverted(t *testing.T) {
 		Quantity:  10,
 		Price:     100.0,
 		Timestamp: "2006-01-02T15:04:05",
+		Status:    models.ClaimStatusReversed,
 		Reverted:  true,
 	}
 
 	mockRepo.On("GetClaimByID", claimID).Return(mockClaim, nil).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
-	req := models.ClaimReversalRequest{ClaimID: claimID}
+	req := models.ClaimReversalRequest{ClaimID: claimID, ReasonCode: models.ReversalReasonBilledInError}
 	revert, err := claimService.ReverseClaim(req)
 
 	assert.Nil(t, revert, "Expected no revert object to be returned when claim is already reverted")
 	assert.NotNil(t, err, "Expected an error when claim is already reverted")
 	assert.Contains(t, err.Error(), "claim with ID 'already-reverted-id' is already reverted", "Error message should indicate claim is already reverted")
-	mockRepo.AssertNotCalled(t, "UpdateClaimRevertedStatus", mock.Anything, mock.Anything)
-	mockRepo.AssertNotCalled(t, "SaveRevert", mock.Anything)
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
 	mockRepo.AssertExpectations(t)
 }
 
@@ -241,22 +465,1499 @@ func TestReverseClaimUpdateDBError(t *testing.T) {
 		Quantity:  10,
 		Price:     100.0,
 		Timestamp: "2006-01-02T15:04:05",
-		Reverted:  false,
+		Status:    models.ClaimStatusPaid,
+
+		OutstandingQuantity: 10,
+		OutstandingAmount:   100.0,
 	}
 	dbError := errors.New("simulated DB error during update")
 
 	mockRepo.On("GetClaimByID", claimID).Return(mockClaim, nil).Once()
-	mockRepo.On("UpdateClaimRevertedStatus", claimID, true).Return(dbError).Once()
+	mockRepo.On("ReverseClaims", mock.AnythingOfType("[]models.Revert"), models.ActorSystem).Return(dbError).Once()
 	mockRepo.On("Close").Return(nil).Maybe()
 
-	claimService := service.NewClaimService(mockLogger, mockRepo)
+	claimService := service.NewClaimService(mockLogger, mockRepo, service.ClaimConfig{})
 
-	req := models.ClaimReversalRequest{ClaimID: claimID}
+	req := models.ClaimReversalRequest{ClaimID: claimID, ReasonCode: models.ReversalReasonBilledInError}
 	revert, err := claimService.ReverseClaim(req)
 
 	assert.Nil(t, revert, "Expected no revert object to be returned on DB update error")
 	assert.NotNil(t, err, "Expected an error on DB update error")
 	assert.Contains(t, err.Error(), "internal error reverting claim", "Error message should indicate internal error")
-	mockRepo.AssertNotCalled(t, "SaveRevert", mock.Anything)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestReverseClaimInvalidTransition(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+
+	claimID := "rejected-claim-id"
+	mockRepo.On("GetClaimByID", claimID).Return(&models.Claim{ID: claimID, Status: models.ClaimStatusRejected}, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	revert, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: claimID, ReasonCode: models.ReversalReasonBilledInError})
+
+	assert.Nil(t, revert)
+	assert.ErrorIs(t, err, service.ErrInvalidStatusTransition)
+	assert.Contains(t, err.Error(), "claim with ID 'rejected-claim-id' cannot move from rejected to reversed")
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
+}
+
+func partiallyReversibleClaim() *models.Claim {
+	return &models.Claim{
+		ID:       "partial-claim-id",
+		NDC:      "00002323401",
+		NPI:      "1234567890",
+		Quantity: 30,
+		Price:    90,
+		Status:   models.ClaimStatusPaid,
+
+		OutstandingQuantity: 20,
+		OutstandingAmount:   60,
+	}
+}
+
+func TestReverseClaimPartialQuantityIsProrated(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Once()
+	mockRepo.On("ReverseClaims", mock.MatchedBy(func(reverts []models.Revert) bool {
+		return len(reverts) == 1 && reverts[0].Quantity == 5 && reverts[0].Amount == 15
+	}), models.ActorSystem).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	revert, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 5})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, revert.Quantity)
+	assert.Equal(t, 15.0, revert.Amount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseClaimPartialAmountIsProrated(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Once()
+	mockRepo.On("ReverseClaims", mock.MatchedBy(func(reverts []models.Revert) bool {
+		return len(reverts) == 1 && reverts[0].Quantity == 20 && reverts[0].Amount == 60
+	}), models.ActorSystem).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	_, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Amount: 60})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseClaimExceedingOutstanding(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Times(2)
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	_, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 25})
+	assert.ErrorIs(t, err, service.ErrReversalExceedsClaim)
+
+	_, err = claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 10, Amount: 61})
+	assert.ErrorIs(t, err, service.ErrReversalExceedsClaim)
+
+	_, err = claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: -1})
+	assert.ErrorIs(t, err, service.ErrInvalidReversalAmount)
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
+}
+
+func TestReverseClaimPartialQuantityAndAmountMustMatch(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Times(3)
+	mockRepo.On("ReverseClaims", mock.AnythingOfType("[]models.Revert"), models.ActorSystem).Return(nil).Twice()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	_, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 5, Amount: 40})
+	assert.ErrorIs(t, err, service.ErrReversalAmountMismatch, "Expected 5 units at 3.00 not to be reversed for 40.00")
+
+	_, err = claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 5, Amount: 15})
+	assert.NoError(t, err)
+
+	_, err = claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonBilledInError, Quantity: 20, Amount: 60})
+	assert.NoError(t, err, "Expected what is outstanding to be reversible")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRebillClaim(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Once()
+	mockRepo.On("RebillClaim",
+		mock.MatchedBy(func(revert models.Revert) bool {
+			return revert.ClaimID == "partial-claim-id" && revert.Quantity == 20 && revert.Amount == 60 &&
+				revert.ReasonCode == models.ReversalReasonPricingError && revert.Reason == "pricing error"
+		}),
+		mock.MatchedBy(func(claim models.Claim) bool {
+			return claim.ReplacesClaimID == "partial-claim-id" && claim.Quantity == 30 && claim.Price == 75 && claim.Status == models.ClaimStatusPaid
+		}),
+		models.ActorAPI,
+	).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	replacement, err := claimService.RebillClaim(models.ClaimRebillRequest{
+		ClaimID:    "partial-claim-id",
+		Price:      75,
+		ReasonCode: models.ReversalReasonPricingError,
+		Reason:     "pricing error",
+		Actor:      models.ActorAPI,
+	})
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, "partial-claim-id", replacement.ID)
+	assert.Equal(t, "partial-claim-id", replacement.ReplacesClaimID)
+	assert.Equal(t, 75.0, replacement.OutstandingAmount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRebillClaimRejected(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "missing").Return(nil, nil).Once()
+	mockRepo.On("GetClaimByID", "reversed-claim-id").Return(&models.Claim{ID: "reversed-claim-id", Status: models.ClaimStatusReversed}, nil).Once()
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+
+	_, err := claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "partial-claim-id"})
+	assert.ErrorIs(t, err, service.ErrReversalPolicyViolation, "Expected a reason code to be required")
+
+	_, err = claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "missing", ReasonCode: models.ReversalReasonPricingError})
+	assert.ErrorIs(t, err, service.ErrClaimNotFound)
+
+	_, err = claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "reversed-claim-id", ReasonCode: models.ReversalReasonPricingError})
+	assert.ErrorIs(t, err, service.ErrInvalidStatusTransition)
+
+	_, err = claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "partial-claim-id", ReasonCode: models.ReversalReasonPricingError, Quantity: -3})
+	assert.ErrorIs(t, err, service.ErrInvalidClaimData)
+	mockRepo.AssertNotCalled(t, "RebillClaim", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRebillClaimTooOldToReverse(t *testing.T) {
+	original := partiallyReversibleClaim()
+	original.Timestamp = time.Now().Add(-48 * time.Hour).Format("2006-01-02T15:04:05")
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(original, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		ReversalPolicy: service.ReversalPolicy{MaxAge: 24 * time.Hour},
+	})
+	_, err := claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "partial-claim-id", Price: 75, ReasonCode: models.ReversalReasonPricingError})
+
+	assert.ErrorIs(t, err, service.ErrReversalPolicyViolation)
+	mockRepo.AssertNotCalled(t, "RebillClaim", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRebillClaimRefillTooSoon(t *testing.T) {
+	original := partiallyReversibleClaim()
+	original.Prescription = models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: "2024-03-10"}
+	previous := &models.Claim{
+		ID: "c1", NDC: "00002323402", Timestamp: "2024-03-01T10:00:00", Status: models.ClaimStatusPaid,
+		Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: "2024-03-01"},
+	}
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(original, nil).Twice()
+	mockRepo.On("GetLatestFill", "M1001", "00002323402", "2024-03-10", "partial-claim-id").Return(previous, nil).Once()
+	mockRepo.On("GetLatestFill", "M1001", original.NDC, "2024-03-10", "partial-claim-id").Return(nil, nil).Once()
+	mockRepo.On("RebillClaim", mock.AnythingOfType("models.Revert"), mock.AnythingOfType("models.Claim"), models.ActorAPI).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{RefillPolicy: service.RefillPolicy{ConsumedPercent: 75}})
+	_, err := claimService.RebillClaim(models.ClaimRebillRequest{
+		ClaimID: "partial-claim-id", NDC: "00002323402", ReasonCode: models.ReversalReasonPricingError, Actor: models.ActorAPI,
+	})
+	assert.ErrorIs(t, err, service.ErrRebillNotPaid, "Expected a rebill to an NDC filled recently to be refused")
+
+	_, err = claimService.RebillClaim(models.ClaimRebillRequest{
+		ClaimID: "partial-claim-id", Price: 75, ReasonCode: models.ReversalReasonPricingError, Actor: models.ActorAPI,
+	})
+	assert.NoError(t, err, "Expected the rebilled claim not to count as a previous fill")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetClaimVersionsUnknownClaim(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimVersions", "missing").Return(nil, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	_, err := claimService.GetClaimVersions("missing")
+
+	assert.ErrorIs(t, err, service.ErrClaimNotFound)
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, service.CanTransition(models.ClaimStatusPending, models.ClaimStatusPaid))
+	assert.True(t, service.CanTransition(models.ClaimStatusPending, models.ClaimStatusRejected))
+	assert.True(t, service.CanTransition(models.ClaimStatusPaid, models.ClaimStatusReversed))
+	assert.True(t, service.CanTransition(models.ClaimStatusPaid, models.ClaimStatusRebilled))
+	assert.False(t, service.CanTransition(models.ClaimStatusPending, models.ClaimStatusReversed))
+	assert.False(t, service.CanTransition(models.ClaimStatusRejected, models.ClaimStatusPaid))
+	assert.True(t, service.CanTransition(models.ClaimStatusReversed, models.ClaimStatusPaid))
+	assert.False(t, service.CanTransition(models.ClaimStatusReversed, models.ClaimStatusRebilled))
+	assert.False(t, service.CanTransition(models.ClaimStatusRebilled, models.ClaimStatusReversed))
+}
+
+func TestGetClaimHistory(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	history := []models.ClaimStatusChange{
+		{ID: 1, ClaimID: "claim-1", ToStatus: models.ClaimStatusPaid, Actor: models.ActorSystem},
+		{ID: 2, ClaimID: "claim-1", FromStatus: models.ClaimStatusPaid, ToStatus: models.ClaimStatusReversed, Actor: models.ActorAPI},
+	}
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusReversed}, nil).Once()
+	mockRepo.On("GetClaimStatusHistory", "claim-1").Return(history, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	got, err := claimService.GetClaimHistory("claim-1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, history, got)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetClaimHistoryUnknownClaim(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "missing").Return(nil, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	_, err := claimService.GetClaimHistory("missing")
+
+	assert.ErrorIs(t, err, service.ErrClaimNotFound)
+	mockRepo.AssertNotCalled(t, "GetClaimStatusHistory", mock.Anything)
+}
+
+func TestValidatePharmacyNPIUnknown(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+
+	mockRepo.On("GetPharmacyByNPI", "8888888888").Return(nil, nil).Once()
+
+	err := service.ValidatePharmacyNPI(mockRepo, "8888888888")
+
+	assert.NotNil(t, err, "Expected an error for unknown NPI")
+	assert.True(t, errors.Is(err, service.ErrUnknownNPI), "Error should wrap ErrUnknownNPI")
+	assert.Contains(t, err.Error(), "invalid NPI '8888888888'", "Error message should indicate invalid NPI")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestValidatePharmacyNPIRepositoryError(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(nil, errors.New("simulated DB error")).Once()
+
+	err := service.ValidatePharmacyNPI(mockRepo, "1234567890")
+
+	assert.NotNil(t, err, "Expected an error when the repository fails")
+	assert.False(t, errors.Is(err, service.ErrUnknownNPI), "Repository errors should not be reported as unknown NPI")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestWatchClaimsReceivesSubmittedClaims(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	claims, unsubscribe := claimService.WatchClaims()
+
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50})
+	assert.NoError(t, err)
+
+	received := <-claims
+	assert.Equal(t, claim.ID, received.ID, "Watcher should receive the submitted claim")
+
+	unsubscribe()
+	_, open := <-claims
+	assert.False(t, open, "Channel should be closed after unsubscribing")
+}
+
+func batchRequests() []models.ClaimSubmissionRequest {
+	return []models.ClaimSubmissionRequest{
+		{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50},
+		{NDC: "00002323401", NPI: "9999999999", Quantity: 10, Price: 50},
+		{NDC: "", NPI: "1234567890", Quantity: 10, Price: 50},
+		{NDC: "00054027225", NPI: "1234567890", Quantity: 5, Price: 20},
+	}
+}
+
+func TestSubmitClaimsBestEffort(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetPharmacyByNPI", "9999999999").Return(nil, nil).Once()
+	mockRepo.On("SaveClaims", mock.MatchedBy(func(claims []models.Claim) bool { return len(claims) == 2 })).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.SubmitClaims(batchRequests(), models.BatchModeBestEffort)
+
+	assert.NoError(t, err)
+	assert.True(t, response.Committed)
+	assert.Equal(t, 2, response.Accepted)
+	assert.Equal(t, 2, response.Rejected)
+	assert.Equal(t, models.BatchItemAccepted, response.Results[0].Status)
+	assert.NotEmpty(t, response.Results[0].ClaimID)
+	assert.Equal(t, models.BatchItemRejected, response.Results[1].Status)
+	assert.Contains(t, response.Results[1].Error, "9999999999")
+	assert.Equal(t, models.BatchItemRejected, response.Results[2].Status)
+	assert.Equal(t, models.BatchItemAccepted, response.Results[3].Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitClaimsAllOrNothingRejected(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetPharmacyByNPI", "9999999999").Return(nil, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.SubmitClaims(batchRequests(), models.BatchModeAllOrNothing)
+
+	assert.NoError(t, err)
+	assert.False(t, response.Committed)
+	assert.Equal(t, 0, response.Accepted)
+	assert.Equal(t, models.BatchItemSkipped, response.Results[0].Status)
+	assert.Empty(t, response.Results[0].ClaimID)
+	assert.Equal(t, models.BatchItemRejected, response.Results[1].Status)
+	mockRepo.AssertNotCalled(t, "SaveClaims", mock.Anything)
+}
+
+func TestSubmitClaimsAllOrNothingSaveError(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaims", mock.AnythingOfType("[]models.Claim")).Return(errors.New("disk full")).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	reqs := []models.ClaimSubmissionRequest{batchRequests()[0], batchRequests()[3]}
+	response, err := claimService.SubmitClaims(reqs, models.BatchModeAllOrNothing)
+
+	assert.Error(t, err)
+	assert.Nil(t, response)
+}
+
+func TestSubmitClaimsBestEffortIsolatesSaveErrors(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaims", mock.AnythingOfType("[]models.Claim")).Return(errors.New("constraint failed")).Once()
+	mockRepo.On("SaveClaim", mock.MatchedBy(func(c models.Claim) bool { return c.NDC == "00002323401" })).Return(errors.New("constraint failed")).Once()
+	mockRepo.On("SaveClaim", mock.MatchedBy(func(c models.Claim) bool { return c.NDC == "00054027225" })).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	reqs := []models.ClaimSubmissionRequest{batchRequests()[0], batchRequests()[3]}
+	response, err := claimService.SubmitClaims(reqs, models.BatchModeBestEffort)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.BatchItemRejected, response.Results[0].Status)
+	assert.Equal(t, models.BatchItemAccepted, response.Results[1].Status)
+	assert.Equal(t, 1, response.Accepted)
+}
+
+func TestSubmitClaimsInvalidMode(t *testing.T) {
+	claimService := service.NewClaimService(logger.NewLogger(), new(MockDBRepository), service.ClaimConfig{})
+	_, err := claimService.SubmitClaims(batchRequests(), "sometimes")
+	assert.ErrorIs(t, err, service.ErrInvalidBatchMode)
+}
+
+func TestReverseClaimsByIDs(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPaid}, nil).Once()
+	mockRepo.On("GetClaimByID", "claim-2").Return(&models.Claim{ID: "claim-2", Status: models.ClaimStatusReversed, Reverted: true}, nil).Once()
+	mockRepo.On("GetClaimByID", "claim-3").Return(nil, nil).Once()
+	mockRepo.On("GetClaimByID", "claim-4").Return(&models.Claim{ID: "claim-4", Status: models.ClaimStatusRejected}, nil).Once()
+	mockRepo.On("ReverseClaims", mock.MatchedBy(func(reverts []models.Revert) bool {
+		return len(reverts) == 1 && reverts[0].ClaimID == "claim-1" && reverts[0].Reason == "misconfigured terminal" &&
+			reverts[0].ReasonCode == models.ReversalReasonBilledInError
+	}), models.ActorAPI).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.ReverseClaims(models.BatchReversalRequest{
+		ClaimIDs:   []string{"claim-1", "claim-2", "claim-3", "claim-1", "claim-4"},
+		Reason:     "misconfigured terminal",
+		ReasonCode: models.ReversalReasonBilledInError,
+		Actor:      models.ActorAPI,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, response.Matched)
+	assert.Equal(t, 1, response.Reversed)
+	outcomes := map[string]string{}
+	for _, result := range response.Results {
+		outcomes[result.ClaimID] = result.Outcome
+	}
+	assert.Equal(t, map[string]string{
+		"claim-1": models.ReversalOutcomeReversed,
+		"claim-2": models.ReversalOutcomeAlreadyReversed,
+		"claim-3": models.ReversalOutcomeNotFound,
+		"claim-4": models.ReversalOutcomeNotReversible,
+	}, outcomes)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReverseClaimsByFilterDryRun(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	filter := models.ClaimFilter{NPI: "1234567890", From: "2024-02-01T08:00:00", To: "2024-02-01T12:00:00"}
+	mockRepo.On("SearchClaims", filter).Return([]models.Claim{{ID: "claim-1", Status: models.ClaimStatusPaid}, {ID: "claim-2", Status: models.ClaimStatusPaid}}, 2, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.ReverseClaims(models.BatchReversalRequest{
+		Filter:     &models.ReversalFilter{NPI: filter.NPI, From: filter.From, To: filter.To},
+		Reason:     "misconfigured terminal",
+		ReasonCode: models.ReversalReasonBilledInError,
+		DryRun:     true,
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, response.DryRun)
+	assert.Equal(t, 2, response.Reversed)
+	assert.Equal(t, models.ReversalOutcomeWouldReverse, response.Results[0].Outcome)
+	assert.Empty(t, response.Results[0].RevertID)
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
+}
+
+func TestReverseClaimsByFilterTooLarge(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	filter := models.ClaimFilter{NPI: "1234567890", From: "2024-02-01T08:00:00", To: "2024-02-01T12:00:00"}
+	mockRepo.On("SearchClaims", filter).Return([]models.Claim{{ID: "claim-1", Status: models.ClaimStatusPaid}, {ID: "claim-2", Status: models.ClaimStatusPaid}}, 2, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.ReverseClaims(models.BatchReversalRequest{
+		Filter:     &models.ReversalFilter{NPI: filter.NPI, From: filter.From, To: filter.To},
+		Reason:     "misconfigured terminal",
+		ReasonCode: models.ReversalReasonBilledInError,
+		MaxClaims:  1,
+	})
+
+	assert.ErrorIs(t, err, service.ErrReversalBatchTooLarge)
+	assert.Nil(t, response)
+	mockRepo.AssertNotCalled(t, "ReverseClaims", mock.Anything, mock.Anything)
+}
+
+func TestReverseClaimsRepositoryErrorReversesNothing(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPaid}, nil).Once()
+	mockRepo.On("ReverseClaims", mock.AnythingOfType("[]models.Revert"), models.ActorSystem).Return(errors.New("claim claim-1 not found or already reverted")).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	response, err := claimService.ReverseClaims(models.BatchReversalRequest{ClaimIDs: []string{"claim-1"}, Reason: "duplicate", ReasonCode: models.ReversalReasonDuplicate})
+
+	assert.Error(t, err)
+	assert.Nil(t, response)
+}
+
+func TestReverseClaimsInvalidRequest(t *testing.T) {
+	claimService := service.NewClaimService(logger.NewLogger(), new(MockDBRepository), service.ClaimConfig{})
+
+	for _, req := range []models.BatchReversalRequest{
+		{ClaimIDs: []string{"claim-1"}},
+		{ClaimIDs: []string{"claim-1"}, Reason: "  "},
+		{Reason: "duplicate"},
+		{ClaimIDs: []string{"claim-1"}, Filter: &models.ReversalFilter{NPI: "1234567890", From: "2024-02-01", To: "2024-02-02"}, Reason: "duplicate"},
+		{Filter: &models.ReversalFilter{NPI: "1234567890"}, Reason: "duplicate"},
+	} {
+		_, err := claimService.ReverseClaims(req)
+		assert.ErrorIs(t, err, service.ErrInvalidReversalBatch)
+	}
+}
+
+func TestReverseClaimReasonCodePolicy(t *testing.T) {
+	claimService := service.NewClaimService(logger.NewLogger(), new(MockDBRepository), service.ClaimConfig{})
+
+	for _, req := range []models.ClaimReversalRequest{
+		{ClaimID: "claim-1"},
+		{ClaimID: "claim-1", ReasonCode: "42"},
+		{ClaimID: "claim-1", ReasonCode: models.ReversalReasonOther, Reason: " "},
+	} {
+		_, err := claimService.ReverseClaim(req)
+		assert.ErrorIs(t, err, service.ErrReversalPolicyViolation)
+	}
+
+	_, err := claimService.ReverseClaims(models.BatchReversalRequest{ClaimIDs: []string{"claim-1"}, Reason: "duplicate"})
+	assert.ErrorIs(t, err, service.ErrReversalPolicyViolation)
+}
+
+func TestReverseClaimMaxAgePolicy(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	submitted := time.Now().Add(-45 * 24 * time.Hour).Format("2006-01-02T15:04:05")
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", NPI: "1234567890", Timestamp: submitted, Status: models.ClaimStatusPaid,
+		Quantity: 10, Price: 50, OutstandingQuantity: 10, OutstandingAmount: 50}, nil)
+	mockRepo.On("GetClaimByID", "claim-2").Return(&models.Claim{ID: "claim-2", NPI: "2222222222", Timestamp: submitted, Status: models.ClaimStatusPaid,
+		Quantity: 10, Price: 50, OutstandingQuantity: 10, OutstandingAmount: 50}, nil)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{NPI: "1234567890", Chain: "health"}, nil)
+	mockRepo.On("GetPharmacyByNPI", "2222222222").Return(&models.Pharmacy{NPI: "2222222222", Chain: "saint"}, nil)
+	mockRepo.On("ReverseClaims", mock.AnythingOfType("[]models.Revert"), models.ActorSystem).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		ReversalPolicy: service.ReversalPolicy{
+			MaxAge:      30 * 24 * time.Hour,
+			ChainMaxAge: map[string]time.Duration{"saint": 60 * 24 * time.Hour},
+		},
+	})
+
+	_, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "claim-1", ReasonCode: models.ReversalReasonBilledInError})
+	assert.ErrorIs(t, err, service.ErrReversalPolicyViolation)
+	assert.Contains(t, err.Error(), "submitted 45 days ago")
+	assert.Contains(t, err.Error(), "allowed up to 30 days")
+
+	revert, err := claimService.ReverseClaim(models.ClaimReversalRequest{ClaimID: "claim-2", ReasonCode: models.ReversalReasonReturnToStock})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReversalReasonReturnToStock, revert.ReasonCode)
+
+	response, err := claimService.ReverseClaims(models.BatchReversalRequest{
+		ClaimIDs:   []string{"claim-1"},
+		Reason:     "duplicate",
+		ReasonCode: models.ReversalReasonDuplicate,
+		DryRun:     true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReversalOutcomePolicyViolation, response.Results[0].Outcome)
+	assert.Contains(t, response.Results[0].Error, "allowed up to 30 days")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReinstateClaim(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	revertedAt := time.Now().Add(-time.Hour).Format("2006-01-02T15:04:05")
+	reverts := []models.Revert{
+		{ID: "revert-1", ClaimID: "claim-1", Timestamp: revertedAt, Quantity: 10, Amount: 25},
+	}
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusReversed}, nil).Once()
+	mockRepo.On("GetRevertsByClaimID", "claim-1").Return(reverts, nil).Once()
+	mockRepo.On("ReinstateClaim", mock.MatchedBy(func(r models.Revert) bool {
+		return r.ID == "revert-1" && r.VoidedAt != "" && r.VoidReason == "reversed the wrong claim"
+	}), mock.MatchedBy(func(c *models.ClaimStatusChange) bool {
+		return c != nil && c.FromStatus == models.ClaimStatusReversed && c.ToStatus == models.ClaimStatusPaid && c.Actor == models.ActorAPI
+	})).Return(nil).Once()
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPaid, OutstandingQuantity: 10, OutstandingAmount: 25}, nil).Once()
+
+	reinstatementService := service.NewReinstatementService(logger.NewLogger(), mockRepo, 72*time.Hour)
+	claim, err := reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-1", Reason: "reversed the wrong claim", Actor: models.ActorAPI})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReinstateClaimPartialRevertKeepsStatus(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	now := time.Now()
+	reverts := []models.Revert{
+		{ID: "revert-1", ClaimID: "claim-1", Timestamp: now.Add(-2 * time.Hour).Format("2006-01-02T15:04:05"), Quantity: 2, Amount: 5},
+		{ID: "revert-2", ClaimID: "claim-1", Timestamp: now.Add(-time.Hour).Format("2006-01-02T15:04:05"), Quantity: 3, Amount: 7.5, VoidedAt: "2024-01-01T00:00:00"},
+	}
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPaid}, nil)
+	mockRepo.On("GetRevertsByClaimID", "claim-1").Return(reverts, nil).Once()
+	mockRepo.On("ReinstateClaim", mock.MatchedBy(func(r models.Revert) bool { return r.ID == "revert-1" }), (*models.ClaimStatusChange)(nil)).Return(nil).Once()
+
+	reinstatementService := service.NewReinstatementService(logger.NewLogger(), mockRepo, 72*time.Hour)
+	_, err := reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-1", Reason: "wrong quantity"})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReinstateClaimWindowExpired(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	reverts := []models.Revert{{ID: "revert-1", ClaimID: "claim-1", Timestamp: time.Now().Add(-73 * time.Hour).Format("2006-01-02T15:04:05")}}
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusReversed}, nil).Once()
+	mockRepo.On("GetRevertsByClaimID", "claim-1").Return(reverts, nil).Once()
+
+	reinstatementService := service.NewReinstatementService(logger.NewLogger(), mockRepo, 72*time.Hour)
+	_, err := reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-1", Reason: "reversed the wrong claim"})
+
+	assert.ErrorIs(t, err, service.ErrReinstatementWindowExpired)
+	mockRepo.AssertNotCalled(t, "ReinstateClaim", mock.Anything, mock.Anything)
+}
+
+func TestReinstateClaimInvalidRequest(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "claim-2").Return(&models.Claim{ID: "claim-2", Status: models.ClaimStatusRebilled}, nil).Once()
+	mockRepo.On("GetClaimByID", "claim-3").Return(&models.Claim{ID: "claim-3", Status: models.ClaimStatusPaid}, nil).Once()
+	mockRepo.On("GetRevertsByClaimID", "claim-3").Return([]models.Revert{}, nil).Once()
+	reinstatementService := service.NewReinstatementService(logger.NewLogger(), mockRepo, 72*time.Hour)
+
+	_, err := reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-1", Reason: " "})
+	assert.ErrorIs(t, err, service.ErrInvalidReinstatement)
+
+	_, err = reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-2", Reason: "wrong claim"})
+	assert.ErrorIs(t, err, service.ErrInvalidStatusTransition)
+
+	_, err = reinstatementService.ReinstateClaim(models.ClaimReinstateRequest{ClaimID: "claim-3", Reason: "wrong claim"})
+	assert.ErrorIs(t, err, service.ErrRevertNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveClaim(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPending}, nil).Once()
+	mockRepo.On("UpdateClaimStatus", mock.MatchedBy(func(c models.ClaimStatusChange) bool {
+		return c.ClaimID == "claim-1" && c.FromStatus == models.ClaimStatusPending && c.ToStatus == models.ClaimStatusPaid &&
+			c.Actor == models.ActorAPI && c.Reason == "prior authorization approved"
+	})).Return(nil).Once()
+	mockRepo.On("GetClaimByID", "claim-1").Return(&models.Claim{ID: "claim-1", Status: models.ClaimStatusPaid}, nil).Once()
+
+	resolutionService := service.NewResolutionService(logger.NewLogger(), mockRepo)
+	claim, err := resolutionService.ResolveClaim(models.ClaimResolveRequest{ClaimID: "claim-1", Status: models.ClaimStatusPaid, Reason: " prior authorization approved ", Actor: models.ActorAPI})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, claim.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveClaimInvalidRequest(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "claim-2").Return(&models.Claim{ID: "claim-2", Status: models.ClaimStatusReversed}, nil).Once()
+	mockRepo.On("GetClaimByID", "claim-3").Return(nil, nil).Once()
+	resolutionService := service.NewResolutionService(logger.NewLogger(), mockRepo)
+
+	_, err := resolutionService.ResolveClaim(models.ClaimResolveRequest{ClaimID: "claim-1", Status: models.ClaimStatusPaid, Reason: " "})
+	assert.ErrorIs(t, err, service.ErrInvalidResolution)
+
+	_, err = resolutionService.ResolveClaim(models.ClaimResolveRequest{ClaimID: "claim-1", Status: models.ClaimStatusReversed, Reason: "reviewed"})
+	assert.ErrorIs(t, err, service.ErrInvalidResolution)
+
+	_, err = resolutionService.ResolveClaim(models.ClaimResolveRequest{ClaimID: "claim-2", Status: models.ClaimStatusPaid, Reason: "reviewed"})
+	assert.ErrorIs(t, err, service.ErrInvalidStatusTransition, "Expected only pending claims to be resolved")
+
+	_, err = resolutionService.ResolveClaim(models.ClaimResolveRequest{ClaimID: "claim-3", Status: models.ClaimStatusRejected, Reason: "reviewed"})
+	assert.ErrorIs(t, err, service.ErrClaimNotFound)
+	mockRepo.AssertNotCalled(t, "UpdateClaimStatus", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// stubAdjudicator rejects the claims of the chain and records the chain of the last claim.
+type stubAdjudicator struct {
+	rejectChain string
+	chain       string
+}
+
+func (a *stubAdjudicator) Adjudicate(claim models.ClaimSubmissionRequest, chain string) models.Adjudication {
+	a.chain = chain
+	if chain == a.rejectChain {
+		return models.Adjudication{
+			Status:  models.ClaimStatusRejected,
+			Rejects: []models.ClaimReject{{Code: "70", Rule: "blocked", Message: "NDC " + claim.NDC + " is not covered"}},
+		}
+	}
+	return models.Adjudication{Status: models.ClaimStatusPaid}
+}
+
+func TestSubmitClaimAdjudicated(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.MatchedBy(func(claim models.Claim) bool {
+		return claim.Status == models.ClaimStatusRejected && len(claim.Rejects) == 1 && claim.Rejects[0].Code == "70"
+	})).Return(nil).Once()
+
+	adjudicator := &stubAdjudicator{rejectChain: "health"}
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{Adjudicator: adjudicator})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "health", adjudicator.chain, "Expected the chain of the pharmacy to be adjudicated")
+	assert.Equal(t, models.ClaimStatusRejected, claim.Status)
+	assert.Equal(t, "70", claim.Rejects[0].Code)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAdjudicateClaimDryRun(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetPharmacyByNPI", "9999999999").Return(nil, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{Adjudicator: &stubAdjudicator{rejectChain: "health"}})
+
+	adjudication, err := claimService.AdjudicateClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusRejected, adjudication.Status)
+
+	_, err = claimService.AdjudicateClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "9999999999", Quantity: 10, Price: 50})
+	assert.ErrorIs(t, err, service.ErrUnknownNPI)
+
+	_, err = claimService.AdjudicateClaim(models.ClaimSubmissionRequest{NPI: "1234567890", Quantity: 10, Price: 50})
+	assert.ErrorIs(t, err, service.ErrInvalidClaimData)
+	mockRepo.AssertNotCalled(t, "SaveClaim", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRebillClaimNotPaidAtAdjudication(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "partial-claim-id").Return(partiallyReversibleClaim(), nil).Once()
+	mockRepo.On("GetPharmacyByNPI", mock.Anything).Return(&models.Pharmacy{Chain: "saint"}, nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{Adjudicator: &stubAdjudicator{rejectChain: "saint"}})
+	_, err := claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "partial-claim-id", Price: 75, ReasonCode: models.ReversalReasonPricingError})
+
+	assert.ErrorIs(t, err, service.ErrRebillNotPaid)
+	mockRepo.AssertNotCalled(t, "RebillClaim", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+// halfPricer allows half of the submitted price to the pharmacies of the chain.
+type halfPricer struct {
+	chain string
+}
+
+func (p halfPricer) Price(claim models.ClaimSubmissionRequest, chain string) models.ClaimPricing {
+	if chain != p.chain {
+		return models.ClaimPricing{AllowedAmount: claim.Price, Basis: models.PricingBasisSubmitted}
+	}
+	return models.ClaimPricing{AllowedAmount: claim.Price / 2, Basis: models.PricingBasisContract}
+}
+
+func TestSubmitClaimPriced(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.MatchedBy(func(claim models.Claim) bool {
+		return claim.Price == 50 && claim.AllowedAmount == 25 && claim.PricingBasis == models.PricingBasisContract
+	})).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{Pricer: halfPricer{chain: "health"}})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, claim.AllowedAmount)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitClaimWithoutPricerAllowsSubmittedPrice(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, claim.AllowedAmount)
+	assert.Equal(t, models.PricingBasisSubmitted, claim.PricingBasis)
+}
+
+func TestGetSavingsReport(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetChainSavings", "2024-02-01", "2024-03-01", models.ReportDateReceived).Return([]models.ChainSavings{
+		{Chain: "health", ClaimCount: 2, SubmittedAmount: 200, AllowedAmount: 150},
+		{Chain: "saint", ClaimCount: 1, SubmittedAmount: 100, AllowedAmount: 100},
+	}, nil).Once()
+
+	reportService := service.NewReportService(logger.NewLogger(), mockRepo)
+	report, err := reportService.GetSavingsReport("2024-02-01", "2024-02-29", "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportDateReceived, report.Date, "Expected claims placed by the date received by default")
+	assert.Equal(t, 50.0, report.Chains[0].Savings)
+	assert.Equal(t, 25.0, report.Chains[0].SavingsPercent)
+	assert.Equal(t, 0.0, report.Chains[1].Savings)
+	assert.Equal(t, models.ChainSavings{ClaimCount: 3, SubmittedAmount: 300, AllowedAmount: 250, Savings: 50, SavingsPercent: 16.67}, report.Total)
+
+	_, err = reportService.GetSavingsReport("2024-02-29", "2024-02-01", "")
+	assert.ErrorIs(t, err, service.ErrInvalidPeriod)
+	_, err = reportService.GetSavingsReport("2024-02-01", "2024-02-29", "filled")
+	assert.ErrorIs(t, err, service.ErrInvalidReportDate)
+	mockRepo.AssertExpectations(t)
+}
+
+// fixedReferences returns the same reference unit price for every date.
+type fixedReferences map[string]float64
+
+func (r fixedReferences) UnitPrice(ndc string, date time.Time) (float64, bool) {
+	price, ok := r[ndc]
+	return price, ok
+}
+
+// datedReferences returns the reference unit price before the date, and after it the price after.
+type datedReferences struct {
+	date          time.Time
+	before, after float64
+}
+
+func (r datedReferences) UnitPrice(ndc string, date time.Time) (float64, bool) {
+	if date.Before(r.date) {
+		return r.before, true
+	}
+	return r.after, true
+}
+
+func TestSubmitClaimPriceVarianceFlagged(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		PriceVariance: service.PriceVarianceConfig{References: fixedReferences{"00002323401": 4}, ThresholdPercent: 25},
+	})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 60})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4.0, claim.ReferenceUnitPrice)
+	assert.Equal(t, 50.0, claim.PriceVariance)
+	assert.True(t, claim.PriceFlagged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubmitClaimPriceVarianceAtDateOfService(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+	references := datedReferences{date: time.Now().AddDate(0, 0, -3), before: 6, after: 4}
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		PriceVariance: service.PriceVarianceConfig{References: references, ThresholdPercent: 25},
+	})
+	dateOfService := time.Now().AddDate(0, 0, -5).Format("2006-01-02")
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 60,
+		Prescription: models.Prescription{DateOfService: dateOfService}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, claim.ReferenceUnitPrice, "Expected the reference price in effect on the date of service")
+	assert.False(t, claim.PriceFlagged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPriceVarianceCheck(t *testing.T) {
+	cfg := service.PriceVarianceConfig{References: fixedReferences{"00002323401": 4}, ThresholdPercent: 25}
+	now := time.Now()
+
+	assert.Equal(t, models.PriceVariance{ReferenceUnitPrice: 4, VariancePercent: 25}, cfg.Check("00002323401", 10, 50, now), "Expected a variance at the threshold not to be flagged")
+	assert.Equal(t, models.PriceVariance{ReferenceUnitPrice: 4, VariancePercent: -50}, cfg.Check("00002323401", 10, 20, now))
+	assert.Equal(t, models.PriceVariance{}, cfg.Check("99999999999", 10, 500, now), "Expected no variance without a reference price")
+	assert.Equal(t, models.PriceVariance{}, service.PriceVarianceConfig{}.Check("00002323401", 10, 500, now), "Expected no variance without reference prices")
+}
+
+func TestPriceVarianceJobCheckClaims(t *testing.T) {
+	now := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("SearchClaims", models.ClaimFilter{From: "2024-03-03"}).Return([]models.Claim{
+		// Loaded from a file, never checked
+		{ID: "loaded", NDC: "00002323401", Quantity: 10, Price: 60, Timestamp: "2024-03-04T10:00:00"},
+		// Checked at submission, unchanged
+		{ID: "unchanged", NDC: "00002323401", Quantity: 10, Price: 40, Timestamp: "2024-03-05T10:00:00", ReferenceUnitPrice: 4},
+		// No reference price
+		{ID: "unknown", NDC: "99999999999", Quantity: 10, Price: 40, Timestamp: "2024-03-05T10:00:00"},
+	}, 3, nil).Once()
+	mockRepo.On("UpdateClaimPriceVariances", []models.ClaimPriceVariance{
+		{ClaimID: "loaded", PriceVariance: models.PriceVariance{ReferenceUnitPrice: 4, VariancePercent: 50, Flagged: true}},
+	}).Return(nil).Once()
+
+	job := service.NewPriceVarianceJob(logger.NewLogger(), mockRepo,
+		service.PriceVarianceConfig{References: fixedReferences{"00002323401": 4}, ThresholdPercent: 25}, 7*24*time.Hour)
+	updated, flagged, err := job.CheckClaims(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, 1, flagged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPriceVarianceJobCheckClaimsAtFillDate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 2, 0, 0, 0, time.UTC)
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("SearchClaims", models.ClaimFilter{From: "2024-03-03"}).Return([]models.Claim{
+		// Filled before the reference price dropped, submitted after
+		{ID: "late", NDC: "00002323401", Quantity: 10, Price: 60, Timestamp: "2024-03-06T10:00:00",
+			Prescription: models.Prescription{DateOfService: "2024-02-28"}},
+	}, 1, nil).Once()
+	mockRepo.On("UpdateClaimPriceVariances", []models.ClaimPriceVariance{
+		{ClaimID: "late", PriceVariance: models.PriceVariance{ReferenceUnitPrice: 6}},
+	}).Return(nil).Once()
+
+	references := datedReferences{date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), before: 6, after: 4}
+	job := service.NewPriceVarianceJob(logger.NewLogger(), mockRepo,
+		service.PriceVarianceConfig{References: references, ThresholdPercent: 25}, 7*24*time.Hour)
+	updated, flagged, err := job.CheckClaims(now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, 0, flagged, "Expected the reference price in effect on the date of service")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPriceVarianceReport(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPriceOutliers", "1234567890", "2024-02-01", "2024-03-01", models.ReportDateService).Return([]models.PharmacyPriceOutliers{
+		{NPI: "1234567890", Chain: "health", Claims: []models.PriceOutlier{
+			{ClaimID: "c1", NDC: "00002323401", Quantity: 3, Price: 20, ReferenceUnitPrice: 4, VariancePercent: 66.67},
+		}},
+	}, nil).Once()
+
+	reportService := service.NewReportService(logger.NewLogger(), mockRepo)
+	report, err := reportService.GetPriceVarianceReport("2024-02-01", "2024-02-29", "1234567890", models.ReportDateService)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ReportDateService, report.Date)
+	assert.Equal(t, 1, report.ClaimCount)
+	assert.Equal(t, 1, report.Pharmacies[0].ClaimCount)
+	assert.Equal(t, 6.6667, report.Pharmacies[0].Claims[0].UnitPrice)
+
+	_, err = reportService.GetPriceVarianceReport("2024-02", "2024-02-29", "", "")
+	assert.ErrorIs(t, err, service.ErrInvalidPeriod)
+	mockRepo.AssertExpectations(t)
+}
+
+// testCatalog is a drug catalog where the drugs of the same ingredient are equivalent.
+type testCatalog map[string]models.Drug
+
+func (c testCatalog) Drug(ndc string) (models.Drug, bool) {
+	drug, ok := c[ndc]
+	return drug, ok
+}
+
+func (c testCatalog) Generics(ndc string) []models.Drug {
+	var generics []models.Drug
+	for _, drug := range c {
+		if drug.Generic && drug.NDC != ndc && drug.Ingredient == c[ndc].Ingredient {
+			generics = append(generics, drug)
+		}
+	}
+	return generics
+}
+
+func TestGetSubstitutionReport(t *testing.T) {
+	catalog := testCatalog{
+		"00071015523": {NDC: "00071015523", Name: "LIPITOR", Ingredient: "ATORVASTATIN CALCIUM", Strength: "20 MG", Form: "TABLET"},
+		"60505257909": {NDC: "60505257909", Ingredient: "ATORVASTATIN CALCIUM", Strength: "20 MG", Form: "TABLET", Generic: true},
+		"00093505698": {NDC: "00093505698", Ingredient: "ATORVASTATIN CALCIUM", Strength: "20 MG", Form: "TABLET", Generic: true},
+		"00078035834": {NDC: "00078035834", Name: "DIOVAN", Ingredient: "VALSARTAN", Strength: "160 MG", Form: "TABLET"},
+		"00093105601": {NDC: "00093105601", Ingredient: "VALSARTAN", Strength: "160 MG", Form: "TABLET", Generic: true},
+	}
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetNDCClaimTotals", "2024-02-01", "2024-03-01", models.ReportDateService).Return([]models.NDCClaimTotals{
+		{NPI: "1111111111", Chain: "health", NDC: "00071015523", ClaimCount: 4, Quantity: 100, Amount: 500},
+		{NPI: "1111111111", Chain: "health", NDC: "60505257909", ClaimCount: 2, Quantity: 100, Amount: 100},
+		// The generic of the brand is pricier on average
+		{NPI: "1111111111", Chain: "health", NDC: "00078035834", ClaimCount: 1, Quantity: 10, Amount: 10},
+		{NPI: "1111111111", Chain: "health", NDC: "00093105601", ClaimCount: 1, Quantity: 10, Amount: 20},
+		// Brand billed below the average of the cheapest generic: nothing to save
+		{NPI: "2222222222", Chain: "saint", NDC: "00071015523", ClaimCount: 1, Quantity: 10, Amount: 5},
+		{NPI: "2222222222", Chain: "saint", NDC: "00093505698", ClaimCount: 3, Quantity: 100, Amount: 200},
+		{NPI: "3333333333", Chain: "saint", NDC: "00071015523", ClaimCount: 2, Quantity: 20, Amount: 45},
+	}, nil).Twice()
+
+	substitutionService := service.NewSubstitutionService(logger.NewLogger(), mockRepo, catalog)
+	report, err := substitutionService.GetSubstitutionReport("2024-02-01", "2024-02-29", models.ReportDateService)
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Pharmacies, 2)
+	assert.Equal(t, []models.SubstitutionOpportunity{{
+		BrandNDC: "00071015523", BrandName: "LIPITOR", GenericNDC: "60505257909",
+		Ingredient: "ATORVASTATIN CALCIUM", Strength: "20 MG", Form: "TABLET",
+		ClaimCount: 4, Quantity: 100, BrandAmount: 500, BrandUnitPrice: 5, GenericUnitPrice: 1, PotentialSavings: 400,
+	}}, report.Pharmacies[0].Opportunities, "Expected the cheapest generic to be offered")
+	assert.Equal(t, "3333333333", report.Pharmacies[1].NPI)
+	assert.Equal(t, 25.0, report.Pharmacies[1].PotentialSavings)
+	assert.Equal(t, []models.ChainSubstitutionSavings{
+		{Chain: "health", ClaimCount: 4, BrandAmount: 500, PotentialSavings: 400},
+		{Chain: "saint", ClaimCount: 2, BrandAmount: 45, PotentialSavings: 25},
+	}, report.Chains)
+	assert.Equal(t, models.ChainSubstitutionSavings{ClaimCount: 6, BrandAmount: 545, PotentialSavings: 425}, report.Total)
+
+	export, err := substitutionService.ExportSubstitutionReport("2024-02-01", "2024-02-29", models.ReportDateService)
+	assert.NoError(t, err)
+	assert.Equal(t, "generic_substitution_20240201_20240229_service.csv", export.FileName)
+	lines := strings.Split(strings.TrimSpace(string(export.Content)), "\n")
+	assert.Len(t, lines, 3, "Expected a header and a row per opportunity")
+	assert.Equal(t, "3333333333,saint,00071015523,LIPITOR,60505257909,,ATORVASTATIN CALCIUM,20 MG,TABLET,2,20,45,2.25,1,25", lines[2])
+
+	_, err = substitutionService.GetSubstitutionReport("2024-02-01", "2024-02-29", "filled")
+	assert.ErrorIs(t, err, service.ErrInvalidReportDate)
+	mockRepo.AssertExpectations(t)
+}
+
+func testAnomalyConfig() service.AnomalyConfig {
+	return service.AnomalyConfig{
+		Window:              24 * time.Hour,
+		Baseline:            4 * 24 * time.Hour,
+		SpikeFactor:         3,
+		ReversalRatePercent: 20,
+		PriceFactor:         2,
+		MinClaims:           10,
+	}
+}
+
+func TestAnalyzeAnomalies(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	mockRepo := new(MockDBRepository)
+	// Window [03-09 12:00, 03-10 12:00), baseline of 4 days before it
+	mockRepo.On("CountClaimsByNPI", "2024-03-09T12:00:00", "2024-03-10T12:00:00").Return(map[string]int{
+		"spiking": 40, // 10 per day in the baseline: 4x
+		"steady":  12, // 10 per day in the baseline
+		"new":     8,  // below the minimum number of claims
+	}, nil).Once()
+	mockRepo.On("CountClaimsByNPI", "2024-03-05T12:00:00", "2024-03-09T12:00:00").Return(map[string]int{
+		"spiking": 40,
+		"steady":  40,
+	}, nil).Once()
+	mockRepo.On("GetReversalCounts", "2024-03-06T12:00:00", "2024-03-10T12:00:00").Return([]models.ReversalCount{
+		{NPI: "reversing", ClaimCount: 20, ReversedCount: 7}, // 35%
+		{NPI: "steady", ClaimCount: 50, ReversedCount: 5},
+		{NPI: "few", ClaimCount: 2, ReversedCount: 2},
+	}, nil).Once()
+	mockRepo.On("GetPharmacyUnitPrices", "2024-03-06T12:00:00", "2024-03-10T12:00:00").Return([]models.PharmacyUnitPrice{
+		{NDC: "00002323401", NPI: "a", ClaimCount: 5, UnitPrice: 10},
+		{NDC: "00002323401", NPI: "b", ClaimCount: 5, UnitPrice: 11},
+		{NDC: "00002323401", NPI: "c", ClaimCount: 5, UnitPrice: 12},
+		{NDC: "00002323401", NPI: "pricey", ClaimCount: 3, UnitPrice: 33}, // median 11.5
+		{NDC: "00054027225", NPI: "a", ClaimCount: 5, UnitPrice: 1},
+		{NDC: "00054027225", NPI: "pricey", ClaimCount: 5, UnitPrice: 9}, // too few pharmacies
+	}, nil).Once()
+	mockRepo.On("RecordAnomalies", mock.AnythingOfType("[]models.Anomaly"), "2024-03-10T12:00:00").Return(nil).Once()
+
+	anomalyService := service.NewAnomalyService(logger.NewLogger(), mockRepo, testAnomalyConfig())
+	anomalies, err := anomalyService.Analyze(now)
+
+	assert.NoError(t, err)
+	assert.Len(t, anomalies, 3)
+	assert.Equal(t, models.Anomaly{Type: models.AnomalySubmissionSpike, NPI: "spiking", Severity: models.SeverityLow, Value: 40, Expected: 10}, withoutMessage(anomalies[0]))
+	assert.Equal(t, models.Anomaly{Type: models.AnomalyReversalRate, NPI: "reversing", Severity: models.SeverityMedium, Value: 35, Expected: 20}, withoutMessage(anomalies[1]))
+	assert.Equal(t, models.Anomaly{Type: models.AnomalyUnitPrice, NPI: "pricey", NDC: "00002323401", Severity: models.SeverityLow, Value: 33, Expected: 11.5}, withoutMessage(anomalies[2]))
+	mockRepo.AssertExpectations(t)
+}
+
+func withoutMessage(anomaly models.Anomaly) models.Anomaly {
+	anomaly.Message = ""
+	return anomaly
+}
+
+func TestListAnomaliesInvalidFilter(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	anomalyService := service.NewAnomalyService(logger.NewLogger(), mockRepo, testAnomalyConfig())
+
+	_, err := anomalyService.ListAnomalies(models.AnomalyFilter{Type: "fraud"})
+	assert.ErrorIs(t, err, service.ErrInvalidAnomalyFilter)
+	_, err = anomalyService.ListAnomalies(models.AnomalyFilter{Severity: "critical"})
+	assert.ErrorIs(t, err, service.ErrInvalidAnomalyFilter)
+	mockRepo.AssertNotCalled(t, "ListAnomalies", mock.Anything)
+}
+
+// coveredMembers covers each member from its start date, open-ended.
+type coveredMembers map[string]string
+
+func (r coveredMembers) Check(memberID string, date time.Time) models.Eligibility {
+	eligibility := models.Eligibility{MemberID: memberID, DateOfService: date.Format("2006-01-02")}
+	start, ok := r[memberID]
+	switch {
+	case !ok:
+		eligibility.RejectCode = models.RejectNonMatchedCardholderID
+		eligibility.Message = "member " + memberID + " is not on the eligibility roster"
+	case eligibility.DateOfService < start:
+		eligibility.RejectCode = models.RejectPatientNotCovered
+		eligibility.Message = "member " + memberID + " is not covered on " + eligibility.DateOfService
+	default:
+		eligibility.Eligible = true
+		eligibility.Coverage = &models.Coverage{MemberID: memberID, Plan: "GOLD", Start: start}
+	}
+	return eligibility
+}
+
+func (r coveredMembers) Status() models.RosterStatus {
+	return models.RosterStatus{MemberCount: len(r)}
+}
+
+func TestSubmitClaimEligibility(t *testing.T) {
+	tests := []struct {
+		name       string
+		memberID   string
+		date       string
+		status     string
+		rejectCode string
+	}{
+		{"covered member", "M1001", "2024-02-01", models.ClaimStatusPaid, ""},
+		{"not covered on the date of service", "M1001", "2023-12-31", models.ClaimStatusRejected, models.RejectPatientNotCovered},
+		{"member not on the roster", "M9999", "2024-02-01", models.ClaimStatusRejected, models.RejectNonMatchedCardholderID},
+		{"claim without member", "", "2024-02-01", models.ClaimStatusRejected, models.RejectNonMatchedCardholderID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDBRepository)
+			mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+			mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+			claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{Eligibility: coveredMembers{"M1001": "2024-01-01"}})
+			claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+				NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+				Prescription: models.Prescription{MemberID: tt.memberID, DateOfService: tt.date},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, claim.Status)
+			if tt.rejectCode != "" {
+				assert.Len(t, claim.Rejects, 1)
+				assert.Equal(t, tt.rejectCode, claim.Rejects[0].Code)
+				assert.Equal(t, "eligibility", claim.Rejects[0].Rule)
+			}
+		})
+	}
+}
+
+func TestCheckEligibility(t *testing.T) {
+	eligibilityService := service.NewEligibilityService(logger.NewLogger(), coveredMembers{"M1001": "2024-01-01"})
+
+	eligibility, err := eligibilityService.CheckEligibility(models.EligibilityRequest{MemberID: "M1001", DateOfService: "2024-02-01"})
+	assert.NoError(t, err)
+	assert.True(t, eligibility.Eligible)
+	assert.Equal(t, "GOLD", eligibility.Coverage.Plan)
+
+	eligibility, err = eligibilityService.CheckEligibility(models.EligibilityRequest{MemberID: "M1001"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().Format("2006-01-02"), eligibility.DateOfService, "The date of service should default to today")
+
+	_, err = eligibilityService.CheckEligibility(models.EligibilityRequest{DateOfService: "2024-02-01"})
+	assert.ErrorIs(t, err, service.ErrInvalidEligibilityRequest)
+	_, err = eligibilityService.CheckEligibility(models.EligibilityRequest{MemberID: "M1001", DateOfService: "02/01/2024"})
+	assert.ErrorIs(t, err, service.ErrInvalidEligibilityRequest)
+}
+
+func TestRefillPolicyCheck(t *testing.T) {
+	policy := service.RefillPolicy{ConsumedPercent: 75}
+	previous := models.Claim{
+		ID: "c1", NDC: "00002323401", Timestamp: "2024-03-05T10:00:00",
+		Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: "2024-03-01"},
+	}
+
+	reject, tooSoon := policy.Check(previous, time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC))
+	assert.True(t, tooSoon, "19 of 30 days is less than 75% consumed")
+	assert.Equal(t, models.RejectRefillTooSoon, reject.Code)
+	assert.Equal(t, "refill_too_soon", reject.Rule)
+	assert.Contains(t, reject.Message, "claim c1")
+	assert.Contains(t, reject.Message, "refill allowed from 2024-03-24")
+
+	_, tooSoon = policy.Check(previous, time.Date(2024, 3, 24, 0, 0, 0, 0, time.UTC))
+	assert.False(t, tooSoon, "23 of 30 days is over 75% consumed")
+
+	// Without a date of service the fill date is the date of the claim timestamp
+	previous.DateOfService = ""
+	_, tooSoon = policy.Check(previous, time.Date(2024, 3, 24, 0, 0, 0, 0, time.UTC))
+	assert.True(t, tooSoon)
+
+	_, tooSoon = service.RefillPolicy{}.Check(previous, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
+	assert.False(t, tooSoon, "A zero percentage disables the check")
+}
+
+func TestSubmitClaimRefillTooSoon(t *testing.T) {
+	previous := &models.Claim{
+		ID: "c1", NDC: "00002323401", Timestamp: "2024-03-01T10:00:00", Status: models.ClaimStatusPaid,
+		Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: "2024-03-01"},
+	}
+	tests := []struct {
+		name         string
+		date         string
+		overrideCode string
+		previous     *models.Claim
+		status       string
+	}{
+		{"refill too soon", "2024-03-10", "", previous, models.ClaimStatusRejected},
+		{"refill on time", "2024-03-25", "", previous, models.ClaimStatusPaid},
+		{"overridden refill", "2024-03-10", models.OverrideVacationSupply, nil, models.ClaimStatusPaid},
+		{"first fill", "2024-03-10", "", nil, models.ClaimStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDBRepository)
+			mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+			if tt.overrideCode == "" {
+				mockRepo.On("GetLatestFill", "M1001", "00002323401", tt.date, "").Return(tt.previous, nil).Once()
+			}
+			mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+			claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{RefillPolicy: service.RefillPolicy{ConsumedPercent: 75}})
+			claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+				NDC: "00002323401", NPI: "1234567890", Quantity: 30, Price: 50,
+				Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: tt.date, OverrideCode: tt.overrideCode},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, claim.Status)
+			if tt.status == models.ClaimStatusRejected {
+				assert.Len(t, claim.Rejects, 1)
+				assert.Equal(t, models.RejectRefillTooSoon, claim.Rejects[0].Code)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmitClaimRefillHistoryError(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetLatestFill", "M1001", "00002323401", "2024-03-10", "").Return(nil, errors.New("db error")).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{RefillPolicy: service.RefillPolicy{ConsumedPercent: 75}})
+	_, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 30, Price: 50,
+		Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: "2024-03-10"},
+	})
+
+	assert.EqualError(t, err, "internal error processing claim")
+	mockRepo.AssertNotCalled(t, "SaveClaim", mock.Anything)
+}
+
+func TestSubmitClaimInvalidOverrideCode(t *testing.T) {
+	claimService := service.NewClaimService(logger.NewLogger(), new(MockDBRepository), service.ClaimConfig{})
+	_, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 30, Price: 50,
+		Prescription: models.Prescription{MemberID: "M1001", OverrideCode: "42"},
+	})
+
+	assert.ErrorIs(t, err, service.ErrInvalidPrescription)
+}
+
+func TestSubmitClaimsRefillTooSoonWithinBatch(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetLatestFill", "M1001", "00002323401", mock.AnythingOfType("string"), "").Return(nil, nil).Twice()
+	mockRepo.On("SaveClaims", mock.AnythingOfType("[]models.Claim")).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{RefillPolicy: service.RefillPolicy{ConsumedPercent: 75}})
+	fill := func(date string) models.ClaimSubmissionRequest {
+		return models.ClaimSubmissionRequest{
+			NDC: "00002323401", NPI: "1234567890", Quantity: 30, Price: 50,
+			Prescription: models.Prescription{MemberID: "M1001", DaysSupply: 30, DateOfService: date},
+		}
+	}
+	response, err := claimService.SubmitClaims([]models.ClaimSubmissionRequest{fill("2024-03-01"), fill("2024-03-05")}, models.BatchModeBestEffort)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusPaid, response.Results[0].ClaimStatus)
+	assert.Equal(t, models.ClaimStatusRejected, response.Results[1].ClaimStatus, "The second fill follows the first one of the batch too soon")
+	assert.Equal(t, models.RejectRefillTooSoon, response.Results[1].Rejects[0].Code)
+}
+
+func TestTimelyFilingPolicyCheck(t *testing.T) {
+	policy := service.TimelyFilingPolicy{Limit: 90 * 24 * time.Hour, ChainLimit: map[string]time.Duration{"saint": 30 * 24 * time.Hour}}
+	served := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	_, late := policy.Check(served, "health", time.Date(2024, 4, 9, 18, 0, 0, 0, time.Local))
+	assert.False(t, late, "90 days after the date of service is within the limit")
+
+	reject, late := policy.Check(served, "health", time.Date(2024, 4, 10, 8, 0, 0, 0, time.Local))
+	assert.True(t, late, "91 days after the date of service is over the limit")
+	assert.Equal(t, models.RejectClaimTooOld, reject.Code)
+	assert.Equal(t, "timely_filing", reject.Rule)
+	assert.Contains(t, reject.Message, "within 90 days, by 2024-04-09")
+
+	reject, late = policy.Check(served, "saint", time.Date(2024, 2, 10, 8, 0, 0, 0, time.Local))
+	assert.True(t, late, "The limit of the chain overrides the default one")
+	assert.Contains(t, reject.Message, "claims of chain 'saint'")
+
+	_, late = service.TimelyFilingPolicy{}.Check(served, "health", time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local))
+	assert.False(t, late, "A zero limit allows any age")
+}
+
+func TestSubmitClaimTimelyFiling(t *testing.T) {
+	tests := []struct {
+		name   string
+		date   string
+		status string
+	}{
+		{"filed on time", time.Now().AddDate(0, 0, -30).Format("2006-01-02"), models.ClaimStatusPaid},
+		{"filed late", time.Now().AddDate(0, 0, -31).Format("2006-01-02"), models.ClaimStatusRejected},
+		{"dated by its submission", "", models.ClaimStatusPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDBRepository)
+			mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+			mockRepo.On("SaveClaim", mock.MatchedBy(func(claim models.Claim) bool {
+				return claim.Status == tt.status && claim.DateOfService == tt.date
+			})).Return(nil).Once()
+
+			claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+				TimelyFiling: service.TimelyFilingPolicy{Limit: 365 * 24 * time.Hour, ChainLimit: map[string]time.Duration{"health": 30 * 24 * time.Hour}},
+			})
+			claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+				NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+				Prescription: models.Prescription{DateOfService: tt.date},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, claim.Status)
+			if tt.status == models.ClaimStatusRejected {
+				assert.Equal(t, models.RejectClaimTooOld, claim.Rejects[0].Code)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func goldPlan() *benefits.Plans {
+	return &benefits.Plans{Plans: map[string]benefits.Plan{
+		"GOLD": {Deductible: 100, OutOfPocketMax: 150, CoinsurancePercent: 20},
+	}}
+}
+
+func TestSubmitClaimPatientPay(t *testing.T) {
+	tests := []struct {
+		name       string
+		met        float64
+		spent      float64
+		patientPay float64
+		deductible float64
+	}{
+		{"start of the plan year", 0, 0, 50, 50},
+		{"deductible partly met", 80, 80, 26, 20},
+		{"coinsurance capped at the out-of-pocket maximum", 100, 145, 5, 0},
+		{"out-of-pocket maximum reached", 100, 150, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockDBRepository)
+			mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+			mockRepo.On("GetAccumulator", "M1001", 2024).Return(&models.Accumulator{MemberID: "M1001", PlanYear: 2024, DeductibleMet: tt.met, OutOfPocketSpent: tt.spent}, nil).Once()
+			mockRepo.On("SaveClaim", mock.MatchedBy(func(claim models.Claim) bool {
+				return claim.PatientPay == tt.patientPay && claim.DeductibleApplied == tt.deductible
+			})).Return(nil).Once()
+
+			claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+				Eligibility: coveredMembers{"M1001": "2024-01-01"},
+				Benefits:    goldPlan(),
+			})
+			claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+				NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+				Prescription: models.Prescription{MemberID: "M1001", DateOfService: "2024-02-01"},
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, models.ClaimBenefit{Plan: "GOLD", PlanYear: 2024, PatientPay: tt.patientPay, DeductibleApplied: tt.deductible}, claim.ClaimBenefit)
+			assert.Equal(t, tt.patientPay, claim.OutstandingPatientPay)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSubmitClaimNoPatientPayWhenRejected(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("SaveClaim", mock.AnythingOfType("models.Claim")).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		Eligibility: coveredMembers{"M1001": "2024-01-01"},
+		Benefits:    goldPlan(),
+	})
+	claim, err := claimService.SubmitClaim(models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+		Prescription: models.Prescription{MemberID: "M1001", DateOfService: "2023-12-31"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ClaimStatusRejected, claim.Status)
+	assert.Equal(t, models.ClaimBenefit{}, claim.ClaimBenefit)
+	mockRepo.AssertNotCalled(t, "GetAccumulator", mock.Anything, mock.Anything)
+}
+
+func TestSubmitClaimsPatientPayWithinBatch(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetAccumulator", "M1001", 2024).Return(&models.Accumulator{MemberID: "M1001", PlanYear: 2024}, nil)
+	var saved []models.Claim
+	mockRepo.On("SaveClaims", mock.AnythingOfType("[]models.Claim")).Run(func(args mock.Arguments) {
+		saved = args.Get(0).([]models.Claim)
+	}).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		Eligibility: coveredMembers{"M1001": "2024-01-01"},
+		Benefits:    goldPlan(),
+	})
+	fill := models.ClaimSubmissionRequest{
+		NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+		Prescription: models.Prescription{MemberID: "M1001", DateOfService: "2024-02-01"},
+	}
+	_, err := claimService.SubmitClaims([]models.ClaimSubmissionRequest{fill, fill, fill}, models.BatchModeBestEffort)
+
+	assert.NoError(t, err)
+	assert.Len(t, saved, 3)
+	assert.Equal(t, []float64{50, 50, 10}, []float64{saved[0].PatientPay, saved[1].PatientPay, saved[2].PatientPay},
+		"Claims of the batch should count toward the deductible of the later ones")
+	assert.Equal(t, 0.0, saved[2].DeductibleApplied)
+}
+
+func TestRebillClaimPatientPay(t *testing.T) {
+	original := &models.Claim{
+		ID: "original-claim-id", NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 50,
+		Timestamp: "2024-02-01T10:00:00", Status: models.ClaimStatusPaid,
+		Prescription:        models.Prescription{MemberID: "M1001", DateOfService: "2024-02-01"},
+		ClaimBenefit:        models.ClaimBenefit{Plan: "GOLD", PlanYear: 2024, PatientPay: 50, DeductibleApplied: 50},
+		OutstandingQuantity: 10, OutstandingAmount: 50, OutstandingPatientPay: 50, OutstandingDeductible: 50,
+	}
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetClaimByID", "original-claim-id").Return(original, nil).Once()
+	mockRepo.On("GetAccumulator", "M1001", 2024).Return(&models.Accumulator{MemberID: "M1001", PlanYear: 2024, DeductibleMet: 50, OutOfPocketSpent: 50}, nil).Once()
+	mockRepo.On("RebillClaim", mock.AnythingOfType("models.Revert"), mock.AnythingOfType("models.Claim"), models.ActorAPI).Return(nil).Once()
+
+	claimService := service.NewClaimService(logger.NewLogger(), mockRepo, service.ClaimConfig{
+		Eligibility: coveredMembers{"M1001": "2024-01-01"},
+		Benefits:    goldPlan(),
+	})
+	replacement, err := claimService.RebillClaim(models.ClaimRebillRequest{ClaimID: "original-claim-id", Price: 75, ReasonCode: models.ReversalReasonPricingError, Actor: models.ActorAPI})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 75.0, replacement.PatientPay, "The original claim should not count toward the deductible of its replacement")
+	assert.Equal(t, 75.0, replacement.DeductibleApplied)
+}
+
+func TestGetAccumulators(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	mockRepo.On("GetAccumulator", "M1001", 2024).Return(&models.Accumulator{MemberID: "M1001", PlanYear: 2024, DeductibleMet: 80, OutOfPocketSpent: 90}, nil).Once()
+
+	accumulatorService := service.NewAccumulatorService(logger.NewLogger(), mockRepo, coveredMembers{"M1001": "2024-01-01"}, goldPlan())
+	accumulators, err := accumulatorService.GetAccumulators("M1001", "2024")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "GOLD", accumulators.Plan.Plan)
+	assert.Equal(t, 20.0, accumulators.DeductibleRemaining)
+	assert.Equal(t, 60.0, *accumulators.OutOfPocketRemaining)
+
+	_, err = accumulatorService.GetAccumulators("M1001", "24th")
+	assert.ErrorIs(t, err, service.ErrInvalidPlanYear)
+}
+
+func TestGenerateRemittancePatientPay(t *testing.T) {
+	mockRepo := new(MockDBRepository)
+	claim := models.Claim{ID: "claim-1", NDC: "00002323401", NPI: "1234567890", Quantity: 10, Price: 100, Timestamp: "2024-02-01T10:00:00",
+		Status: models.ClaimStatusPaid, AllowedAmount: 80, PricingBasis: models.PricingBasisContract,
+		ClaimBenefit: models.ClaimBenefit{Plan: "GOLD", PlanYear: 2024, PatientPay: 30, DeductibleApplied: 20}}
+	mockRepo.On("GetPharmacyByNPI", "1234567890").Return(&models.Pharmacy{Chain: "health", NPI: "1234567890"}, nil).Once()
+	mockRepo.On("GetClaimsByNPI", "1234567890", "2024-02-01", "2024-03-01").Return([]models.Claim{claim}, nil).Once()
+	mockRepo.On("GetReversedClaimsByNPI", "1234567890", "2024-02-01", "2024-03-01").Return([]models.ReversedClaim{
+		{Revert: models.Revert{ID: "revert-1", ClaimID: "claim-1", Timestamp: "2024-02-10T10:00:00", Quantity: 5, Amount: 50, ReasonCode: "07",
+			PatientPay: 15, DeductibleApplied: 10}, Claim: claim},
+	}, nil).Once()
+	mockRepo.On("NextControlNumber", "isa").Return(int64(1), nil).Once()
+	mockRepo.On("NextControlNumber", "gs").Return(int64(1), nil).Once()
+
+	remittanceService := service.NewRemittanceService(logger.NewLogger(), mockRepo, service.RemittanceConfig{Benefits: goldPlan()})
+	export, err := remittanceService.GenerateRemittance("1234567890", "2024-02-01", "2024-02-29")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, export.TotalPaid, "Expected the allowed amount less the patient pay, net of the partial reversal")
+	assert.Contains(t, export.Content, "CLP*claim-1*1*100.00*50.00*30.00*ZZ*claim-1~")
+	assert.Contains(t, export.Content, "SVC*N4:00002323401*100.00*50.00**10~CAS*CO*45*20.00~CAS*PR*1*20.00**2*10.00~")
+	assert.Contains(t, export.Content, "CLP*claim-1*22*-50.00*-25.00*-15.00*ZZ*claim-1~")
+	assert.Contains(t, export.Content, "CAS*CO*45*-10.00~CAS*PR*1*-10.00**2*-5.00~LQ*RX*07~")
+	assertRemittanceBalanced(t, export.Content)
+	mockRepo.AssertExpectations(t)
+}
+
+// assertRemittanceBalanced checks that the charge less the payment of every claim of an 835
+// (CLP03 - CLP04) equals the sum of its adjustments (CAS).
+func assertRemittanceBalanced(t *testing.T, content string) {
+	t.Helper()
+	var claimID string
+	var balance float64
+	check := func() {
+		if claimID != "" {
+			assert.InDelta(t, 0, balance, 0.001, "Expected the adjustments of claim payment %s to balance", claimID)
+		}
+	}
+	for _, segment := range strings.Split(content, "~") {
+		elements := strings.Split(segment, "*")
+		switch elements[0] {
+		case "CLP":
+			check()
+			charge, _ := strconv.ParseFloat(elements[3], 64)
+			paid, _ := strconv.ParseFloat(elements[4], 64)
+			claimID, balance = elements[1], charge-paid
+		case "CAS":
+			// Reason/amount/quantity triplets follow the group code.
+			for i := 3; i < len(elements); i += 3 {
+				adjustment, _ := strconv.ParseFloat(elements[i], 64)
+				balance -= adjustment
+			}
+		}
+	}
+	check()
+}
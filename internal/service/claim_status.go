@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidStatusTransition is returned when a claim cannot move from its current status to the requested one.
+var ErrInvalidStatusTransition = errors.New("invalid claim status transition")
+
+// ErrClaimNotFound is returned when no claim has the requested ID.
+var ErrClaimNotFound = errors.New("claim not found")
+
+// claimTransitions lists, for each claim status, the statuses a claim can move to.
+// Statuses missing from the table are final. A reversed claim moves back to paid when
+// its reversal is voided by a reinstatement.
+var claimTransitions = map[string][]string{
+	models.ClaimStatusPending:  {models.ClaimStatusPaid, models.ClaimStatusRejected},
+	models.ClaimStatusPaid:     {models.ClaimStatusReversed, models.ClaimStatusRebilled},
+	models.ClaimStatusReversed: {models.ClaimStatusPaid},
+}
+
+// CanTransition reports whether a claim in status from can move to status to.
+func CanTransition(from, to string) bool {
+	for _, allowed := range claimTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTransition returns an error wrapping ErrInvalidStatusTransition when the claim cannot move to status to.
+func checkTransition(claim *models.Claim, to string) error {
+	if !CanTransition(claim.Status, to) {
+		return fmt.Errorf("%w: claim with ID '%s' cannot move from %s to %s", ErrInvalidStatusTransition, claim.ID, claim.Status, to)
+	}
+	return nil
+}
+
+// requestActor returns the actor of a request, defaulting to the system when the channel is unknown.
+func requestActor(actor string) string {
+	if actor == "" {
+		return models.ActorSystem
+	}
+	return actor
+}
+
+// GetClaimHistory fetches the status changes of a claim, oldest first.
+func (s *claimService) GetClaimHistory(id string) ([]models.ClaimStatusChange, error) {
+	claim, err := s.dbRepo.GetClaimByID(id)
+	if err != nil {
+		s.logger.Error("DB error fetching claim %s: %v", id, err)
+		return nil, fmt.Errorf("error fetching claim: %w", err)
+	}
+	if claim == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrClaimNotFound, id)
+	}
+
+	history, err := s.dbRepo.GetClaimStatusHistory(id)
+	if err != nil {
+		s.logger.Error("DB error fetching status history of claim %s: %v", id, err)
+		return nil, fmt.Errorf("error fetching claim history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidEligibilityRequest is returned when an eligibility check has no member ID or a malformed date of service.
+var ErrInvalidEligibilityRequest = errors.New("invalid eligibility request: member ID is required and the date of service must be YYYY-MM-DD")
+
+// eligibilityRule is the rule name reported with the reject of a claim for a member not covered.
+const eligibilityRule = "eligibility"
+
+// EligibilityChecker looks up whether a member is covered on a date of service.
+type EligibilityChecker interface {
+	Check(memberID string, date time.Time) models.Eligibility
+}
+
+// EligibilityRoster is an EligibilityChecker reporting the roster it checks against.
+type EligibilityRoster interface {
+	EligibilityChecker
+	Status() models.RosterStatus
+}
+
+// EligibilityService defines the interface of the member eligibility checks.
+type EligibilityService interface {
+	CheckEligibility(req models.EligibilityRequest) (*models.Eligibility, error)
+	GetRosterStatus() models.RosterStatus
+}
+
+type eligibilityService struct {
+	logger logger.Logger
+	roster EligibilityRoster
+}
+
+// NewEligibilityService creates and returns a new instance of the EligibilityService interface.
+func NewEligibilityService(log logger.Logger, roster EligibilityRoster) EligibilityService {
+	return &eligibilityService{
+		logger: log,
+		roster: roster,
+	}
+}
+
+// CheckEligibility checks whether a member is covered on the date of service, today by default,
+// like an NCPDP E1 eligibility verification. A member not covered is not an error: the outcome
+// carries the reject code and reason.
+func (s *eligibilityService) CheckEligibility(req models.EligibilityRequest) (*models.Eligibility, error) {
+	if req.MemberID == "" {
+		return nil, ErrInvalidEligibilityRequest
+	}
+	date := time.Now()
+	if req.DateOfService != "" {
+		var err error
+		if date, err = time.Parse(periodDateLayout, req.DateOfService); err != nil {
+			return nil, fmt.Errorf("%w: '%s'", ErrInvalidEligibilityRequest, req.DateOfService)
+		}
+	}
+
+	eligibility := s.roster.Check(req.MemberID, date)
+	if !eligibility.Eligible {
+		s.logger.Info("Member %s not eligible on %s: %s", req.MemberID, eligibility.DateOfService, eligibility.Message)
+	}
+	return &eligibility, nil
+}
+
+// GetRosterStatus returns the size of the roster in use and the changes of its latest reload.
+func (s *eligibilityService) GetRosterStatus() models.RosterStatus {
+	return s.roster.Status()
+}
+
+// eligibilityReject returns the reject of a claim for a member not covered on its date of service.
+// With a roster configured, a claim without a member ID cannot match a cardholder and is rejected;
+// without one, claims are not checked.
+func (s *claimService) eligibilityReject(req models.ClaimSubmissionRequest) (models.ClaimReject, bool) {
+	if s.cfg.Eligibility == nil {
+		return models.ClaimReject{}, false
+	}
+	if req.MemberID == "" {
+		return models.ClaimReject{Code: models.RejectNonMatchedCardholderID, Rule: eligibilityRule,
+			Message: "a member ID is required to check eligibility"}, true
+	}
+	eligibility := s.cfg.Eligibility.Check(req.MemberID, serviceDate(req.Prescription, time.Now()))
+	if eligibility.Eligible {
+		return models.ClaimReject{}, false
+	}
+	return models.ClaimReject{Code: eligibility.RejectCode, Rule: eligibilityRule, Message: eligibility.Message}, true
+}
+
+// serviceDate returns the date of service of a prescription, or received when it has none.
+// The date of service is expected to be validated.
+func serviceDate(p models.Prescription, received time.Time) time.Time {
+	if date, err := time.Parse(periodDateLayout, p.DateOfService); err == nil {
+		return date
+	}
+	return received
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidReversalAmount is returned when a reversal has a negative quantity or amount.
+var ErrInvalidReversalAmount = errors.New("invalid reversal quantity or amount: must not be negative")
+
+// ErrReversalExceedsClaim is returned when a reversal would exceed the outstanding quantity or amount of a claim.
+var ErrReversalExceedsClaim = errors.New("reversal exceeds the outstanding claim")
+
+// ErrReversalAmountMismatch is returned when a reversal sets a quantity and an amount that do not
+// match the unit price of the claim.
+var ErrReversalAmountMismatch = errors.New("reversal quantity and amount do not match the unit price of the claim")
+
+// ReversalAmounts resolves the quantity and amount of a reversal of the claim. Without quantity
+// and amount everything outstanding is reversed; when only one of them is set, the other is
+// prorated at the unit price of the claim; when both are set, the amount must be the quantity at
+// that unit price, unless they are what is outstanding. Cumulative reversals never exceed the claim.
+func ReversalAmounts(claim *models.Claim, quantity, amount float64) (float64, float64, error) {
+	var mismatch error
+	switch {
+	case quantity == 0 && amount == 0:
+		quantity, amount = claim.OutstandingQuantity, claim.OutstandingAmount
+	case amount == 0:
+		amount = roundAmount(claim.Price * quantity / claim.Quantity)
+		if math.Abs(quantity-claim.OutstandingQuantity) <= models.ReversalTolerance {
+			amount = claim.OutstandingAmount // Leave no rounding residue behind
+		}
+	case quantity == 0:
+		quantity = roundQuantity(claim.Quantity * amount / claim.Price)
+		if math.Abs(amount-claim.OutstandingAmount) <= models.ReversalTolerance {
+			quantity = claim.OutstandingQuantity
+		}
+	default:
+		remainder := math.Abs(quantity-claim.OutstandingQuantity) <= models.ReversalTolerance &&
+			math.Abs(amount-claim.OutstandingAmount) <= models.ReversalTolerance
+		prorated := roundAmount(claim.Price * quantity / claim.Quantity)
+		if !remainder && math.Abs(amount-prorated) > models.ReversalTolerance {
+			mismatch = fmt.Errorf("%w: claim with ID '%s' is billed %.2f for %g units, so %g units are %.2f, %.2f requested",
+				ErrReversalAmountMismatch, claim.ID, claim.Price, claim.Quantity, quantity, prorated, amount)
+		}
+	}
+
+	if quantity > claim.OutstandingQuantity+models.ReversalTolerance || amount > claim.OutstandingAmount+models.ReversalTolerance {
+		return 0, 0, fmt.Errorf("%w: claim with ID '%s' has %g units and %.2f outstanding, %g units and %.2f requested",
+			ErrReversalExceedsClaim, claim.ID, claim.OutstandingQuantity, claim.OutstandingAmount, quantity, amount)
+	}
+	if mismatch != nil {
+		return 0, 0, mismatch
+	}
+	return quantity, amount, nil
+}
+
+// roundAmount rounds an amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// roundQuantity rounds a quantity to the three decimals of NCPDP quantities.
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
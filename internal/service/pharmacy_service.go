@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// PharmacyService defines the interface for pharmacy queries.
+type PharmacyService interface {
+	GetPharmacy(npi string) (*models.Pharmacy, error)
+	ListPharmacies(chain string) ([]models.Pharmacy, error)
+}
+
+type pharmacyService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+}
+
+// NewPharmacyService creates and returns a new instance of the PharmacyService interface.
+func NewPharmacyService(log logger.Logger, dbRepo database.DBRepository) PharmacyService {
+	return &pharmacyService{
+		logger: log,
+		dbRepo: dbRepo,
+	}
+}
+
+// GetPharmacy fetches a pharmacy by its NPI, returning ErrUnknownNPI when it is not registered.
+func (s *pharmacyService) GetPharmacy(npi string) (*models.Pharmacy, error) {
+	pharmacy, err := s.dbRepo.GetPharmacyByNPI(npi)
+	if err != nil {
+		s.logger.Error("Error fetching pharmacy with NPI %s: %v", npi, err)
+		return nil, errors.New("internal error fetching pharmacy")
+	}
+	if pharmacy == nil {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownNPI, npi)
+	}
+	return pharmacy, nil
+}
+
+// ListPharmacies lists the registered pharmacies, restricted to a chain when chain is not empty.
+func (s *pharmacyService) ListPharmacies(chain string) ([]models.Pharmacy, error) {
+	pharmacies, err := s.dbRepo.ListPharmacies(chain)
+	if err != nil {
+		s.logger.Error("Error listing pharmacies: %v", err)
+		return nil, errors.New("internal error listing pharmacies")
+	}
+	return pharmacies, nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ReferencePricer looks up the reference unit price of an NDC in effect on a date.
+type ReferencePricer interface {
+	UnitPrice(ndc string, date time.Time) (float64, bool)
+}
+
+// PriceVarianceConfig holds the reference prices claims are compared to and the variance over
+// which they are flagged.
+type PriceVarianceConfig struct {
+	References       ReferencePricer // Reference unit prices; nil disables the check
+	ThresholdPercent float64         // Unit price variance above the reference, in percent, over which claims are flagged
+}
+
+// Check compares the unit price of a claim with the reference unit price in effect on its date,
+// the date of service when it has one. Claims without a reference price are not flagged.
+func (c PriceVarianceConfig) Check(ndc string, quantity, price float64, date time.Time) models.PriceVariance {
+	if c.References == nil || quantity <= 0 {
+		return models.PriceVariance{}
+	}
+	reference, ok := c.References.UnitPrice(ndc, date)
+	if !ok || reference <= 0 {
+		return models.PriceVariance{}
+	}
+	variance := roundAmount((price/quantity - reference) / reference * 100)
+	return models.PriceVariance{
+		ReferenceUnitPrice: reference,
+		VariancePercent:    variance,
+		Flagged:            variance > c.ThresholdPercent,
+	}
+}
+
+// claimDate returns the date of a claim timestamp, e.g. "2024-02-01T10:00:00".
+func claimDate(timestamp string) (time.Time, error) {
+	return time.Parse(periodDateLayout, timestamp[:min(len(timestamp), len(periodDateLayout))])
+}
+
+// PriceVarianceJob checks the price variance of the recent claims again, e.g. the claims loaded
+// from files or submitted before the reference prices of their date were published.
+type PriceVarianceJob struct {
+	logger   logger.Logger
+	dbRepo   database.DBRepository
+	cfg      PriceVarianceConfig
+	lookback time.Duration
+}
+
+// NewPriceVarianceJob creates a job checking the claims submitted within lookback.
+func NewPriceVarianceJob(log logger.Logger, dbRepo database.DBRepository, cfg PriceVarianceConfig, lookback time.Duration) *PriceVarianceJob {
+	return &PriceVarianceJob{
+		logger:   log,
+		dbRepo:   dbRepo,
+		cfg:      cfg,
+		lookback: lookback,
+	}
+}
+
+// CheckClaims compares the claims submitted since now minus the lookback with the reference
+// prices in effect on their fill date and stores the variances that changed. It returns the number of claims updated
+// and the number of them flagged.
+func (j *PriceVarianceJob) CheckClaims(now time.Time) (int, int, error) {
+	since := now.Add(-j.lookback).Format(periodDateLayout)
+	claims, _, err := j.dbRepo.SearchClaims(models.ClaimFilter{From: since})
+	if err != nil {
+		j.logger.Error("Error fetching claims submitted since %s for price variance: %v", since, err)
+		return 0, 0, errors.New("internal error checking price variance")
+	}
+
+	var changed []models.ClaimPriceVariance
+	flagged := 0
+	for _, claim := range claims {
+		if _, err := claimDate(claim.Timestamp); err != nil && claim.DateOfService == "" {
+			j.logger.Warning("Claim %s has an invalid timestamp '%s', price variance not checked", claim.ID, claim.Timestamp)
+			continue
+		}
+		variance := j.cfg.Check(claim.NDC, claim.Quantity, claim.Price, fillDate(claim))
+		stored := models.PriceVariance{ReferenceUnitPrice: claim.ReferenceUnitPrice, VariancePercent: claim.PriceVariance, Flagged: claim.PriceFlagged}
+		if variance == stored {
+			continue
+		}
+		changed = append(changed, models.ClaimPriceVariance{ClaimID: claim.ID, PriceVariance: variance})
+		if variance.Flagged {
+			flagged++
+		}
+	}
+	if len(changed) == 0 {
+		return 0, 0, nil
+	}
+
+	if err := j.dbRepo.UpdateClaimPriceVariances(changed); err != nil {
+		j.logger.Error("Error saving the price variance of %d claims: %v", len(changed), err)
+		return 0, 0, errors.New("internal error checking price variance")
+	}
+	return len(changed), flagged, nil
+}
+
+// Run checks the claims every day at the time of day at (e.g. 2h for 02:00, local time) until
+// ctx is cancelled.
+func (j *PriceVarianceJob) Run(ctx context.Context, at time.Duration) {
+	for {
+		next := nextDailyRun(time.Now(), at)
+		j.logger.Info("Next price variance check at %s", next.Format("2006-01-02T15:04:05"))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			j.logger.Info("Stopping price variance job.")
+			return
+		case <-timer.C:
+			updated, flagged, err := j.CheckClaims(time.Now())
+			if err != nil {
+				j.logger.Error("Price variance check failed: %v", err)
+				continue
+			}
+			j.logger.Info("Price variance check completed: %d claims updated, %d of them flagged.", updated, flagged)
+		}
+	}
+}
+
+// nextDailyRun returns the first time after now at the time of day at.
+func nextDailyRun(now time.Time, at time.Duration) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(at)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(at)
+	}
+	return next
+}
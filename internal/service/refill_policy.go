@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// refillTooSoonRule is the rule name reported with the reject of a refill submitted too soon.
+const refillTooSoonRule = "refill_too_soon"
+
+// RefillPolicy holds the refill-too-soon check of submitted claims: a fill is rejected while the
+// previous fill of the same NDC for the same member has not consumed ConsumedPercent of its days
+// supply. Claims with an override code from models.RefillOverrideCodes are not checked.
+type RefillPolicy struct {
+	ConsumedPercent float64 // Share of the days supply of the previous fill to consume before a refill; zero disables the check
+}
+
+// Check returns the reject of a fill on date when the previous fill has not consumed enough of its
+// days supply by then.
+func (p RefillPolicy) Check(previous models.Claim, date time.Time) (models.ClaimReject, bool) {
+	if p.ConsumedPercent <= 0 || previous.DaysSupply <= 0 {
+		return models.ClaimReject{}, false
+	}
+
+	filled := fillDate(previous)
+	elapsed := int(dateOnly(date).Sub(filled).Hours() / 24)
+	consumed := float64(elapsed) * 100 / float64(previous.DaysSupply)
+	if consumed >= p.ConsumedPercent {
+		return models.ClaimReject{}, false
+	}
+
+	allowed := filled.AddDate(0, 0, int(math.Ceil(float64(previous.DaysSupply)*p.ConsumedPercent/100)))
+	return models.ClaimReject{
+		Code: models.RejectRefillTooSoon,
+		Rule: refillTooSoonRule,
+		Message: fmt.Sprintf("refill too soon: NDC %s was filled for member %s on %s (claim %s, %d days supply), %.0f%% consumed of the %.0f%% required; refill allowed from %s",
+			previous.NDC, previous.MemberID, filled.Format(periodDateLayout), previous.ID, previous.DaysSupply,
+			consumed, p.ConsumedPercent, allowed.Format(periodDateLayout)),
+	}, true
+}
+
+// checkRefill rejects the adjudication of a claim submitted before the latest fill of the same NDC
+// for the member, either saved or among the pending claims of the same batch, is consumed enough.
+// The claim a rebill replaces, when not nil, is not a previous fill of its replacement.
+// Claims without a member ID, with an override code or when the check is disabled are not checked.
+// The error is only returned when the fill history cannot be read.
+func (s *claimService) checkRefill(adjudication *models.Adjudication, req models.ClaimSubmissionRequest, received time.Time, pending []models.Claim, replaced *models.Claim) error {
+	if s.cfg.RefillPolicy.ConsumedPercent <= 0 || req.MemberID == "" {
+		return nil
+	}
+	if req.OverrideCode != "" {
+		s.logger.Info("Refill-too-soon check of NDC %s for member %s bypassed with override code %s (%s)",
+			req.NDC, req.MemberID, req.OverrideCode, models.RefillOverrideCodes[req.OverrideCode])
+		return nil
+	}
+
+	date := dateOnly(serviceDate(req.Prescription, received))
+	var excludeID string
+	if replaced != nil {
+		excludeID = replaced.ID
+	}
+	previous, err := s.dbRepo.GetLatestFill(req.MemberID, req.NDC, date.Format(periodDateLayout), excludeID)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		claim := &pending[i]
+		if claim.MemberID != req.MemberID || claim.NDC != req.NDC || claim.DaysSupply <= 0 ||
+			(claim.Status != models.ClaimStatusPaid && claim.Status != models.ClaimStatusPending) {
+			continue
+		}
+		if filled := fillDate(*claim); !filled.After(date) && (previous == nil || !filled.Before(fillDate(*previous))) {
+			previous = claim
+		}
+	}
+	if previous == nil {
+		return nil
+	}
+
+	if reject, ok := s.cfg.RefillPolicy.Check(*previous, date); ok {
+		adjudication.Status = models.ClaimStatusRejected
+		adjudication.Rejects = append(adjudication.Rejects, reject)
+	}
+	return nil
+}
+
+// fillDate returns the date a claim was filled: its date of service, or the date of its timestamp.
+func fillDate(claim models.Claim) time.Time {
+	received, err := time.Parse("2006-01-02T15:04:05", claim.Timestamp)
+	if err != nil {
+		received = time.Now()
+	}
+	return dateOnly(serviceDate(claim.Prescription, received))
+}
+
+// dateOnly returns the day of t at midnight UTC, so days can be counted between dates.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// overrideCodeList returns the accepted override codes, sorted, as a comma-separated list.
+func overrideCodeList() string {
+	codes := make([]string, 0, len(models.RefillOverrideCodes))
+	for code := range models.RefillOverrideCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return strings.Join(codes, ", ")
+}
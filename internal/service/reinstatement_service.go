@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidReinstatement is returned when a reinstatement request has no reason.
+var ErrInvalidReinstatement = errors.New("invalid reinstatement: a reason is required")
+
+// ErrRevertNotFound is returned when a claim has no active revert to void.
+var ErrRevertNotFound = errors.New("revert not found")
+
+// ErrReinstatementWindowExpired is returned when a revert is older than the reinstatement window.
+var ErrReinstatementWindowExpired = errors.New("reinstatement window expired")
+
+// ReinstatementService defines the interface for undoing claim reversals.
+type ReinstatementService interface {
+	ReinstateClaim(req models.ClaimReinstateRequest) (*models.Claim, error)
+}
+
+type reinstatementService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+	window time.Duration
+}
+
+// NewReinstatementService creates and returns a new instance of the ReinstatementService interface.
+// Reverts can be voided up to window after they were recorded.
+func NewReinstatementService(log logger.Logger, dbRepo database.DBRepository, window time.Duration) ReinstatementService {
+	return &reinstatementService{
+		logger: log,
+		dbRepo: dbRepo,
+		window: window,
+	}
+}
+
+// ReinstateClaim voids a revert of a claim, the requested one or else the latest active one,
+// and moves a reversed claim back to paid. The revert record is kept, marked as voided with the
+// reason. Voiding a partial revert of a paid claim only restores its outstanding quantity and amount.
+func (s *reinstatementService) ReinstateClaim(req models.ClaimReinstateRequest) (*models.Claim, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, ErrInvalidReinstatement
+	}
+
+	claim, err := s.dbRepo.GetClaimByID(req.ClaimID)
+	if err != nil {
+		s.logger.Error("Error fetching claim %s for reinstatement: %v", req.ClaimID, err)
+		return nil, errors.New("internal error reinstating claim")
+	}
+	if claim == nil {
+		return nil, fmt.Errorf("%w: '%s'", ErrClaimNotFound, req.ClaimID)
+	}
+	if claim.Status != models.ClaimStatusPaid {
+		if err := checkTransition(claim, models.ClaimStatusPaid); err != nil {
+			return nil, err
+		}
+	}
+
+	reverts, err := s.dbRepo.GetRevertsByClaimID(claim.ID)
+	if err != nil {
+		s.logger.Error("Error fetching reverts of claim %s for reinstatement: %v", claim.ID, err)
+		return nil, errors.New("internal error reinstating claim")
+	}
+	revert := activeRevert(reverts, req.RevertID)
+	if revert == nil {
+		if req.RevertID != "" {
+			return nil, fmt.Errorf("%w: no active revert '%s' for claim '%s'", ErrRevertNotFound, req.RevertID, claim.ID)
+		}
+		return nil, fmt.Errorf("%w: no active revert for claim '%s'", ErrRevertNotFound, claim.ID)
+	}
+
+	now := time.Now()
+	reverted, err := time.ParseInLocation("2006-01-02T15:04:05", revert.Timestamp, time.Local)
+	if err != nil || now.Sub(reverted) > s.window {
+		return nil, fmt.Errorf("%w: revert '%s' of claim '%s' was recorded at %s, reinstatement is allowed for %s",
+			ErrReinstatementWindowExpired, revert.ID, claim.ID, revert.Timestamp, s.window)
+	}
+
+	revert.VoidedAt = now.Format("2006-01-02T15:04:05")
+	revert.VoidReason = reason
+	var change *models.ClaimStatusChange
+	if claim.Status != models.ClaimStatusPaid {
+		change = &models.ClaimStatusChange{
+			ClaimID:    claim.ID,
+			FromStatus: claim.Status,
+			ToStatus:   models.ClaimStatusPaid,
+			Actor:      requestActor(req.Actor),
+			Reason:     reason,
+			Timestamp:  revert.VoidedAt,
+		}
+	}
+	if err := s.dbRepo.ReinstateClaim(*revert, change); err != nil {
+		s.logger.Error("Error reinstating claim %s: %v", claim.ID, err)
+		return nil, errors.New("internal error reinstating claim")
+	}
+
+	reinstated, err := s.dbRepo.GetClaimByID(claim.ID)
+	if err != nil || reinstated == nil {
+		s.logger.Error("Error fetching reinstated claim %s: %v", claim.ID, err)
+		return nil, errors.New("internal error reinstating claim")
+	}
+	s.logger.Info("Revert %s of claim %s voided, claim reinstated as %s. Reason: %s", revert.ID, claim.ID, reinstated.Status, reason)
+	return reinstated, nil
+}
+
+// activeRevert returns the revert with the given ID, or the latest one when id is empty,
+// among the reverts that were not voided.
+func activeRevert(reverts []models.Revert, id string) *models.Revert {
+	for i := len(reverts) - 1; i >= 0; i-- {
+		if reverts[i].VoidedAt != "" {
+			continue
+		}
+		if id == "" || reverts[i].ID == id {
+			return &reverts[i]
+		}
+	}
+	return nil
+}
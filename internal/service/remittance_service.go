@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+	"github.com/diogocarasco/go-pharmacy-service/internal/x12"
+)
+
+// Names of the persisted X12 control number sequences.
+const (
+	interchangeControlSequence = "isa"
+	groupControlSequence       = "gs"
+)
+
+const periodDateLayout = "2006-01-02"
+
+// ErrInvalidPeriod is returned when a reporting period is missing or malformed.
+var ErrInvalidPeriod = errors.New("invalid period: from and to must be dates (YYYY-MM-DD) with from <= to")
+
+// RemittanceService defines the interface for remittance advice generation.
+type RemittanceService interface {
+	GenerateRemittance(npi, from, to string) (*models.RemittanceExport, error)
+}
+
+// RemittanceConfig holds the identifiers written into generated remittances.
+type RemittanceConfig struct {
+	SenderID   string // ISA06/GS02 sender identifier
+	PayerName  string // N1*PR payer name
+	PayerID    string // TRN03 payer identifier
+	ExportDir  string // Directory the 835 files are written to; empty disables the file export
+	Production bool   // ISA15 usage indicator
+
+	Benefits BenefitPlans // Plan benefit definitions telling copays from coinsurance; nil reports cost sharing as coinsurance
+}
+
+type remittanceService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+	cfg    RemittanceConfig
+}
+
+// NewRemittanceService creates and returns a new instance of the RemittanceService interface.
+func NewRemittanceService(log logger.Logger, dbRepo database.DBRepository, cfg RemittanceConfig) RemittanceService {
+	return &remittanceService{
+		logger: log,
+		dbRepo: dbRepo,
+		cfg:    cfg,
+	}
+}
+
+// GenerateRemittance builds the X12 835 for the claims of a pharmacy in the period [from, to].
+// Claims submitted in the period are reported as paid, reversals recorded in the period as
+// negative claim payments of the reversed quantity and amount, so partial reversals net partially, with
+// the reason code of the reversal. The patient pay is not paid by the plan: it is reported as patient
+// responsibility, and a reversal nets the patient pay it rolled back. Every generated 835 consumes new
+// interchange and group control numbers.
+func (s *remittanceService) GenerateRemittance(npi, from, to string) (*models.RemittanceExport, error) {
+	fromDate, err := time.Parse(periodDateLayout, from)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+	toDate, err := time.Parse(periodDateLayout, to)
+	if err != nil || toDate.Before(fromDate) {
+		return nil, ErrInvalidPeriod
+	}
+	upperBound := toDate.AddDate(0, 0, 1).Format(periodDateLayout)
+
+	pharmacy, err := s.dbRepo.GetPharmacyByNPI(npi)
+	if err != nil {
+		s.logger.Error("Error fetching pharmacy with NPI %s: %v", npi, err)
+		return nil, errors.New("internal error generating remittance")
+	}
+	if pharmacy == nil {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownNPI, npi)
+	}
+
+	claims, err := s.dbRepo.GetClaimsByNPI(npi, from, upperBound)
+	if err != nil {
+		s.logger.Error("Error fetching claims for remittance of NPI %s: %v", npi, err)
+		return nil, errors.New("internal error generating remittance")
+	}
+	reversals, err := s.dbRepo.GetReversedClaimsByNPI(npi, from, upperBound)
+	if err != nil {
+		s.logger.Error("Error fetching reversals for remittance of NPI %s: %v", npi, err)
+		return nil, errors.New("internal error generating remittance")
+	}
+
+	interchange, err := s.dbRepo.NextControlNumber(interchangeControlSequence)
+	if err != nil {
+		s.logger.Error("Error allocating interchange control number: %v", err)
+		return nil, errors.New("internal error generating remittance")
+	}
+	group, err := s.dbRepo.NextControlNumber(groupControlSequence)
+	if err != nil {
+		s.logger.Error("Error allocating group control number: %v", err)
+		return nil, errors.New("internal error generating remittance")
+	}
+
+	remittance := &x12.Remittance835{
+		Envelope: x12.Envelope{
+			SenderID:                 s.cfg.SenderID,
+			ReceiverID:               npi,
+			InterchangeControlNumber: interchange,
+			GroupControlNumber:       group,
+			Production:               s.cfg.Production,
+			CreatedAt:                time.Now(),
+		},
+		PayerName: s.cfg.PayerName,
+		PayerID:   s.cfg.PayerID,
+		PayeeName: pharmacy.Chain,
+		PayeeNPI:  npi,
+	}
+	for _, claim := range claims {
+		payment := claimPayment(claim, claim.Timestamp, claim.Quantity, claim.Price, false)
+		s.addPatientPay(&payment, claim, claim.PatientPay, claim.DeductibleApplied)
+		remittance.Claims = append(remittance.Claims, payment)
+	}
+	for _, reversal := range reversals {
+		payment := claimPayment(reversal.Claim, reversal.Revert.Timestamp, reversal.Revert.Quantity, reversal.Revert.Amount, true)
+		s.addPatientPay(&payment, reversal.Claim, reversal.Revert.PatientPay, reversal.Revert.DeductibleApplied)
+		payment.ReasonCode = reversal.Revert.ReasonCode
+		remittance.Claims = append(remittance.Claims, payment)
+	}
+
+	export := &models.RemittanceExport{
+		NPI:           npi,
+		From:          from,
+		To:            to,
+		ControlNumber: interchange,
+		ClaimCount:    len(claims),
+		ReversalCount: len(reversals),
+		TotalPaid:     remittance.TotalPaid(),
+		FileName:      fmt.Sprintf("835_%s_%s_%s_%09d.x12", npi, fromDate.Format("20060102"), toDate.Format("20060102"), interchange),
+		Content:       remittance.Encode(),
+	}
+
+	if s.cfg.ExportDir != "" {
+		if err := os.MkdirAll(s.cfg.ExportDir, 0755); err != nil {
+			s.logger.Error("Error creating remittance export directory %s: %v", s.cfg.ExportDir, err)
+			return nil, errors.New("internal error exporting remittance")
+		}
+		if err := os.WriteFile(filepath.Join(s.cfg.ExportDir, export.FileName), []byte(export.Content), 0644); err != nil {
+			s.logger.Error("Error writing remittance file %s: %v", export.FileName, err)
+			return nil, errors.New("internal error exporting remittance")
+		}
+	}
+
+	s.logger.Info("Remittance %s generated for NPI %s: %d claims, %d reversals, net %.2f",
+		export.FileName, npi, export.ClaimCount, export.ReversalCount, export.TotalPaid)
+	return export, nil
+}
+
+// claimPayment maps a claim to an 835 claim payment of the given quantity and submitted amount.
+// The payment is the share of the allowed amount of the claim matching that amount, and the rest
+// of the amount is a contractual adjustment (CO-45), so the charge less the payment always equals
+// the adjustments. The date is the submission date for payments and the reversal date for
+// reversals.
+func claimPayment(claim models.Claim, timestamp string, quantity, amount float64, reversal bool) x12.ClaimPayment {
+	serviceDate, err := time.Parse("2006-01-02T15:04:05", timestamp)
+	if err != nil {
+		serviceDate, _ = time.Parse(periodDateLayout, timestamp[:min(len(timestamp), len(periodDateLayout))])
+	}
+	paid := allowedShare(claim, amount)
+	return x12.ClaimPayment{
+		ClaimID:  claim.ID,
+		NDC:      claim.NDC,
+		Quantity: quantity,
+		Charge:   amount,
+		Paid:     paid,
+		Adjustments: []x12.Adjustment{
+			{Group: x12.GroupContractualObligation, Reason: x12.AdjustmentReasonContractual, Amount: roundAmount(amount - paid)},
+		},
+		Reversal:    reversal,
+		ServiceDate: serviceDate,
+	}
+}
+
+// addPatientPay takes the patient pay, deductible included, of a claim payment out of what the plan
+// pays and reports it as patient responsibility: the deductible, and the rest as copay or coinsurance
+// by the plan of the claim.
+func (s *remittanceService) addPatientPay(payment *x12.ClaimPayment, claim models.Claim, patientPay, deductible float64) {
+	if patientPay <= 0 {
+		return
+	}
+	payment.Paid = roundAmount(math.Max(payment.Paid-patientPay, 0))
+	payment.PatientPay = patientPay
+
+	costShareReason := x12.AdjustmentReasonCoinsurance
+	if s.cfg.Benefits != nil {
+		if plan, ok := s.cfg.Benefits.Plan(claim.Plan); ok && plan.Copay > 0 {
+			costShareReason = x12.AdjustmentReasonCopay
+		}
+	}
+	payment.Adjustments = append(payment.Adjustments,
+		x12.Adjustment{Group: x12.GroupPatientResponsibility, Reason: x12.AdjustmentReasonDeductible, Amount: deductible},
+		x12.Adjustment{Group: x12.GroupPatientResponsibility, Reason: costShareReason, Amount: roundAmount(patientPay - deductible)},
+	)
+}
+
+// allowedShare returns the part of the allowed amount of a claim matching a part of its
+// submitted price, e.g. the payment netted by a partial reversal.
+func allowedShare(claim models.Claim, amount float64) float64 {
+	if claim.Price <= 0 || amount >= claim.Price {
+		return claim.AllowedAmount
+	}
+	return roundAmount(claim.AllowedAmount * amount / claim.Price)
+}
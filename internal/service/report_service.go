@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ReportService defines the interface for the claim reports.
+type ReportService interface {
+	GetSavingsReport(from, to, date string) (*models.SavingsReport, error)
+	GetPriceVarianceReport(from, to, npi, date string) (*models.PriceVarianceReport, error)
+}
+
+// ErrInvalidReportDate is returned when a report is requested by a date other than received or service.
+var ErrInvalidReportDate = errors.New("invalid report date: must be received or service")
+
+type reportService struct {
+	logger logger.Logger
+	dbRepo database.DBRepository
+}
+
+// NewReportService creates and returns a new instance of the ReportService interface.
+func NewReportService(log logger.Logger, dbRepo database.DBRepository) ReportService {
+	return &reportService{
+		logger: log,
+		dbRepo: dbRepo,
+	}
+}
+
+// parsePeriod validates the days of a period [from, to] and returns the exclusive upper bound
+// of its claim timestamps, the day after to.
+func parsePeriod(from, to string) (string, error) {
+	fromDate, err := time.Parse(periodDateLayout, from)
+	if err != nil {
+		return "", ErrInvalidPeriod
+	}
+	toDate, err := time.Parse(periodDateLayout, to)
+	if err != nil || toDate.Before(fromDate) {
+		return "", ErrInvalidPeriod
+	}
+	return toDate.AddDate(0, 0, 1).Format(periodDateLayout), nil
+}
+
+// parseReportDate validates the date by which a report places claims in its period, received
+// when empty.
+func parseReportDate(date string) (string, error) {
+	switch date {
+	case "":
+		return models.ReportDateReceived, nil
+	case models.ReportDateReceived, models.ReportDateService:
+		return date, nil
+	}
+	return "", ErrInvalidReportDate
+}
+
+// GetSavingsReport compares the submitted and allowed amounts of the paid claims of the period
+// [from, to], by pharmacy chain. Claims are placed in the period by the date they were received
+// or by their date of service.
+func (s *reportService) GetSavingsReport(from, to, date string) (*models.SavingsReport, error) {
+	upperBound, err := parsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if date, err = parseReportDate(date); err != nil {
+		return nil, err
+	}
+
+	chains, err := s.dbRepo.GetChainSavings(from, upperBound, date)
+	if err != nil {
+		s.logger.Error("Error fetching savings by chain from %s to %s by %s date: %v", from, to, date, err)
+		return nil, errors.New("internal error generating savings report")
+	}
+
+	report := &models.SavingsReport{From: from, To: to, Date: date, Chains: make([]models.ChainSavings, 0, len(chains))}
+	for _, chain := range chains {
+		report.Total.ClaimCount += chain.ClaimCount
+		report.Total.SubmittedAmount += chain.SubmittedAmount
+		report.Total.AllowedAmount += chain.AllowedAmount
+		report.Chains = append(report.Chains, chainSavings(chain))
+	}
+	report.Total = chainSavings(report.Total)
+	return report, nil
+}
+
+// chainSavings rounds the amounts of a chain and derives its savings.
+func chainSavings(chain models.ChainSavings) models.ChainSavings {
+	chain.SubmittedAmount = roundAmount(chain.SubmittedAmount)
+	chain.AllowedAmount = roundAmount(chain.AllowedAmount)
+	chain.Savings = roundAmount(chain.SubmittedAmount - chain.AllowedAmount)
+	if chain.SubmittedAmount > 0 {
+		chain.SavingsPercent = roundAmount(chain.Savings / chain.SubmittedAmount * 100)
+	}
+	return chain
+}
+
+// GetPriceVarianceReport lists the claims of the period [from, to] that were flagged for their
+// unit price variance, by pharmacy. Claims are placed in the period by the date they were received
+// or by their date of service. An empty npi reports every pharmacy.
+func (s *reportService) GetPriceVarianceReport(from, to, npi, date string) (*models.PriceVarianceReport, error) {
+	upperBound, err := parsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if date, err = parseReportDate(date); err != nil {
+		return nil, err
+	}
+
+	pharmacies, err := s.dbRepo.GetPriceOutliers(npi, from, upperBound, date)
+	if err != nil {
+		s.logger.Error("Error fetching price outliers from %s to %s by %s date: %v", from, to, date, err)
+		return nil, errors.New("internal error generating price variance report")
+	}
+
+	report := &models.PriceVarianceReport{From: from, To: to, Date: date, Pharmacies: make([]models.PharmacyPriceOutliers, 0, len(pharmacies))}
+	for _, pharmacy := range pharmacies {
+		for i, claim := range pharmacy.Claims {
+			if claim.Quantity > 0 {
+				pharmacy.Claims[i].UnitPrice = math.Round(claim.Price/claim.Quantity*10000) / 10000
+			}
+		}
+		pharmacy.ClaimCount = len(pharmacy.Claims)
+		report.ClaimCount += pharmacy.ClaimCount
+		report.Pharmacies = append(report.Pharmacies, pharmacy)
+	}
+	return report, nil
+}
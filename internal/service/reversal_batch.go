@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrInvalidReversalBatch is returned when a batch reversal has no reason or does not select
+// claims by exactly one of IDs or filter.
+var ErrInvalidReversalBatch = errors.New("invalid reversal batch: a reason and either claim_ids or a filter with npi, from and to are required")
+
+// ErrReversalBatchTooLarge is returned when the filter of a batch reversal matches more claims
+// than the request allows.
+var ErrReversalBatchTooLarge = errors.New("reversal batch too large")
+
+// ReverseClaims reverses what is outstanding of the claims selected by ID or by filter. Claims already reversed, not
+// found, in a status that cannot be reversed or refused by the reversal policy are reported and left untouched; the other
+// claims are reversed atomically, each with its own revert record carrying the audit reason and reason code. A dry run only
+// reports the outcomes. A filter matching more than MaxClaims claims is refused, dry run or not, and reverses nothing.
+func (s *claimService) ReverseClaims(req models.BatchReversalRequest) (*models.BatchReversalResponse, error) {
+	if strings.TrimSpace(req.Reason) == "" || (len(req.ClaimIDs) > 0) == (req.Filter != nil) {
+		return nil, ErrInvalidReversalBatch
+	}
+	if req.Filter != nil && (req.Filter.NPI == "" || req.Filter.From == "" || req.Filter.To == "") {
+		return nil, ErrInvalidReversalBatch
+	}
+	if err := s.cfg.ReversalPolicy.CheckReasonCode(req.ReasonCode, req.Reason); err != nil {
+		return nil, err
+	}
+
+	response := &models.BatchReversalResponse{DryRun: req.DryRun, Reason: req.Reason, ReasonCode: req.ReasonCode}
+
+	var claims []models.Claim
+	if req.Filter != nil {
+		matches, _, err := s.dbRepo.SearchClaims(models.ClaimFilter{NPI: req.Filter.NPI, From: req.Filter.From, To: req.Filter.To})
+		if err != nil {
+			s.logger.Error("Error searching claims for batch reversal: %v", err)
+			return nil, errors.New("internal error processing reversal batch")
+		}
+		if req.MaxClaims > 0 && len(matches) > req.MaxClaims {
+			return nil, fmt.Errorf("%w: the filter matches %d claims, more than the maximum of %d; narrow the time range", ErrReversalBatchTooLarge, len(matches), req.MaxClaims)
+		}
+		claims = matches
+	} else {
+		seen := make(map[string]bool, len(req.ClaimIDs))
+		for _, id := range req.ClaimIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			claim, err := s.dbRepo.GetClaimByID(id)
+			if err != nil {
+				s.logger.Error("Error fetching claim %s for batch reversal: %v", id, err)
+				return nil, errors.New("internal error processing reversal batch")
+			}
+			if claim == nil {
+				response.Results = append(response.Results, models.BatchReversalResult{ClaimID: id, Outcome: models.ReversalOutcomeNotFound})
+				continue
+			}
+			claims = append(claims, *claim)
+		}
+	}
+
+	now := time.Now()
+	timestamp := now.Format("2006-01-02T15:04:05")
+	chains := map[string]string{}
+	var reverts []models.Revert
+	for _, claim := range claims {
+		response.Matched++
+		result := models.BatchReversalResult{ClaimID: claim.ID}
+
+		var policyErr error
+		if claim.Status == models.ClaimStatusPaid {
+			policyErr = s.checkReversalAge(claim, now, chains)
+			if policyErr != nil && !errors.Is(policyErr, ErrReversalPolicyViolation) {
+				s.logger.Error("Error checking the reversal policy for claim %s: %v", claim.ID, policyErr)
+				return nil, errors.New("internal error processing reversal batch")
+			}
+		}
+
+		switch {
+		case claim.Status == models.ClaimStatusReversed:
+			result.Outcome = models.ReversalOutcomeAlreadyReversed
+		case !CanTransition(claim.Status, models.ClaimStatusReversed):
+			result.Outcome = models.ReversalOutcomeNotReversible
+		case policyErr != nil:
+			result.Outcome = models.ReversalOutcomePolicyViolation
+			result.Error = policyErr.Error()
+		case req.DryRun:
+			result.Outcome = models.ReversalOutcomeWouldReverse
+			response.Reversed++
+		default:
+			revert := models.Revert{
+				ID:         uuid.New().String(),
+				ClaimID:    claim.ID,
+				Timestamp:  timestamp,
+				Reason:     req.Reason,
+				Quantity:   claim.OutstandingQuantity,
+				Amount:     claim.OutstandingAmount,
+				ReasonCode: req.ReasonCode,
+			}
+			reverts = append(reverts, revert)
+			result.Outcome = models.ReversalOutcomeReversed
+			result.RevertID = revert.ID
+			response.Reversed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if len(reverts) > 0 {
+		if err := s.dbRepo.ReverseClaims(reverts, requestActor(req.Actor)); err != nil {
+			s.logger.Error("Error reversing batch of %d claims: %v", len(reverts), err)
+			return nil, errors.New("internal error reversing claims, no claim was reversed")
+		}
+	}
+
+	s.logger.Info("Batch reversal (dry run: %t, reason: %s): %d claims matched, %d reversed", req.DryRun, req.Reason, response.Matched, response.Reversed)
+	return response, nil
+}
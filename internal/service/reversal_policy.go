@@ -0,0 +1,114 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// ErrReversalPolicyViolation is returned when a reversal breaks a rule of the reversal policy.
+var ErrReversalPolicyViolation = errors.New("reversal policy violation")
+
+// ReversalPolicy holds the rules a reversal must comply with. A reason code from
+// models.ReversalReasonCodes is always required.
+type ReversalPolicy struct {
+	MaxAge      time.Duration            // Maximum time between the claim timestamp and its reversal; zero allows any age
+	ChainMaxAge map[string]time.Duration // Overrides of MaxAge for the pharmacies of a chain
+}
+
+// CheckReasonCode returns an error wrapping ErrReversalPolicyViolation when the reason code is
+// missing or unknown, or when reason code 99 comes without a reason text.
+func (p ReversalPolicy) CheckReasonCode(code, reason string) error {
+	if code == "" {
+		return fmt.Errorf("%w: a reason code is required, one of %s", ErrReversalPolicyViolation, reasonCodeList())
+	}
+	if _, ok := models.ReversalReasonCodes[code]; !ok {
+		return fmt.Errorf("%w: reason code '%s' is not one of %s", ErrReversalPolicyViolation, code, reasonCodeList())
+	}
+	if code == models.ReversalReasonOther && strings.TrimSpace(reason) == "" {
+		return fmt.Errorf("%w: reason code %s requires a reason text", ErrReversalPolicyViolation, code)
+	}
+	return nil
+}
+
+// CheckAge returns an error wrapping ErrReversalPolicyViolation when the claim, of a pharmacy of
+// the chain, is older at now than the maximum age allowed for its reversal.
+func (p ReversalPolicy) CheckAge(claim models.Claim, chain string, now time.Time) error {
+	maxAge, scope := p.MaxAge, "reversals"
+	if chainMaxAge, ok := p.ChainMaxAge[chain]; ok {
+		maxAge, scope = chainMaxAge, fmt.Sprintf("reversals for chain '%s'", chain)
+	}
+	if maxAge <= 0 {
+		return nil
+	}
+
+	submitted, err := time.ParseInLocation("2006-01-02T15:04:05", claim.Timestamp, time.Local)
+	if err != nil {
+		return fmt.Errorf("%w: the age of claim '%s' cannot be checked, its timestamp '%s' is invalid",
+			ErrReversalPolicyViolation, claim.ID, claim.Timestamp)
+	}
+	if age := now.Sub(submitted); age > maxAge {
+		return fmt.Errorf("%w: claim '%s' was submitted %s ago (%s); %s are allowed up to %s after the claim",
+			ErrReversalPolicyViolation, claim.ID, formatAge(age), claim.Timestamp, scope, formatAge(maxAge))
+	}
+	return nil
+}
+
+// hasChainRules reports whether the policy depends on the chain of the pharmacy.
+func (p ReversalPolicy) hasChainRules() bool {
+	return len(p.ChainMaxAge) > 0
+}
+
+// reasonCodeList returns the accepted reason codes, sorted, as a comma-separated list.
+func reasonCodeList() string {
+	codes := make([]string, 0, len(models.ReversalReasonCodes))
+	for code := range models.ReversalReasonCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return strings.Join(codes, ", ")
+}
+
+// formatAge formats a duration in whole days, or in hours and minutes when shorter than a day.
+func formatAge(d time.Duration) string {
+	if d < 24*time.Hour {
+		return d.Truncate(time.Minute).String()
+	}
+	days := int(d / (24 * time.Hour))
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// checkReversalAge applies the age rule of the reversal policy to a claim reversed at now.
+func (s *claimService) checkReversalAge(claim models.Claim, now time.Time, chains map[string]string) error {
+	return CheckReversalAge(s.dbRepo, s.cfg.ReversalPolicy, claim, now, chains)
+}
+
+// CheckReversalAge applies the age rule of the policy to a claim reversed at now. The chain of the
+// pharmacy is only looked up when the policy has chain overrides, once per NPI through chains.
+// Repository errors are returned as they are, policy violations wrap ErrReversalPolicyViolation.
+func CheckReversalAge(dbRepo database.DBRepository, policy ReversalPolicy, claim models.Claim, now time.Time, chains map[string]string) error {
+	var chain string
+	if policy.hasChainRules() {
+		cached, ok := chains[claim.NPI]
+		if !ok {
+			pharmacy, err := dbRepo.GetPharmacyByNPI(claim.NPI)
+			if err != nil {
+				return fmt.Errorf("error fetching pharmacy %s for the reversal policy: %w", claim.NPI, err)
+			}
+			if pharmacy != nil {
+				cached = pharmacy.Chain
+			}
+			chains[claim.NPI] = cached
+		}
+		chain = cached
+	}
+	return policy.CheckAge(claim, chain, now)
+}
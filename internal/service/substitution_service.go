@@ -0,0 +1,202 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/database"
+	"github.com/diogocarasco/go-pharmacy-service/internal/logger"
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// DrugCatalog describes NDCs and their equivalent generics.
+type DrugCatalog interface {
+	Drug(ndc string) (models.Drug, bool)
+	Generics(ndc string) []models.Drug
+}
+
+// SubstitutionService defines the interface of the generic substitution savings report.
+type SubstitutionService interface {
+	GetSubstitutionReport(from, to, date string) (*models.SubstitutionReport, error)
+	ExportSubstitutionReport(from, to, date string) (*models.ReportExport, error)
+}
+
+type substitutionService struct {
+	logger  logger.Logger
+	dbRepo  database.DBRepository
+	catalog DrugCatalog
+}
+
+// NewSubstitutionService creates and returns a new instance of the SubstitutionService interface.
+func NewSubstitutionService(log logger.Logger, dbRepo database.DBRepository, catalog DrugCatalog) SubstitutionService {
+	return &substitutionService{
+		logger:  log,
+		dbRepo:  dbRepo,
+		catalog: catalog,
+	}
+}
+
+// substitute is the cheapest generic equivalent of a brand NDC and its average unit price.
+type substitute struct {
+	brand     models.Drug
+	generic   models.Drug
+	unitPrice float64
+}
+
+// GetSubstitutionReport finds the paid claims of the period [from, to] for brand NDCs with an
+// equivalent generic NDC whose average unit price over the claims of every pharmacy in the period
+// is lower than the brand's. The potential savings of a pharmacy are what it billed for the brand
+// less its quantity at the generic's average unit price; claims of a pharmacy that billed the
+// brand below that price save nothing and are left out. Claims are placed in the period by the
+// date they were received or by their date of service.
+func (s *substitutionService) GetSubstitutionReport(from, to, date string) (*models.SubstitutionReport, error) {
+	upperBound, err := parsePeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if date, err = parseReportDate(date); err != nil {
+		return nil, err
+	}
+
+	totals, err := s.dbRepo.GetNDCClaimTotals(from, upperBound, date)
+	if err != nil {
+		s.logger.Error("Error fetching claim totals by NDC from %s to %s by %s date: %v", from, to, date, err)
+		return nil, errors.New("internal error generating generic substitution report")
+	}
+
+	substitutes := s.substitutes(totals)
+	report := &models.SubstitutionReport{
+		From:       from,
+		To:         to,
+		Date:       date,
+		Pharmacies: make([]models.PharmacySubstitutionSavings, 0),
+		Chains:     make([]models.ChainSubstitutionSavings, 0),
+	}
+	chains := make(map[string]*models.ChainSubstitutionSavings)
+	for _, total := range totals {
+		sub, ok := substitutes[total.NDC]
+		if !ok {
+			continue
+		}
+		savings := roundAmount(total.Amount - total.Quantity*sub.unitPrice)
+		if savings <= 0 {
+			continue
+		}
+
+		if len(report.Pharmacies) == 0 || report.Pharmacies[len(report.Pharmacies)-1].NPI != total.NPI {
+			report.Pharmacies = append(report.Pharmacies, models.PharmacySubstitutionSavings{NPI: total.NPI, Chain: total.Chain})
+		}
+		pharmacy := &report.Pharmacies[len(report.Pharmacies)-1]
+		pharmacy.Opportunities = append(pharmacy.Opportunities, models.SubstitutionOpportunity{
+			BrandNDC:         sub.brand.NDC,
+			BrandName:        sub.brand.Name,
+			GenericNDC:       sub.generic.NDC,
+			GenericName:      sub.generic.Name,
+			Ingredient:       sub.brand.Ingredient,
+			Strength:         sub.brand.Strength,
+			Form:             sub.brand.Form,
+			ClaimCount:       total.ClaimCount,
+			Quantity:         total.Quantity,
+			BrandAmount:      roundAmount(total.Amount),
+			BrandUnitPrice:   roundUnitPrice(total.Amount / total.Quantity),
+			GenericUnitPrice: roundUnitPrice(sub.unitPrice),
+			PotentialSavings: savings,
+		})
+		pharmacy.ClaimCount += total.ClaimCount
+		pharmacy.BrandAmount = roundAmount(pharmacy.BrandAmount + total.Amount)
+		pharmacy.PotentialSavings = roundAmount(pharmacy.PotentialSavings + savings)
+
+		chain, ok := chains[total.Chain]
+		if !ok {
+			chain = &models.ChainSubstitutionSavings{Chain: total.Chain}
+			chains[total.Chain] = chain
+		}
+		chain.ClaimCount += total.ClaimCount
+		chain.BrandAmount = roundAmount(chain.BrandAmount + total.Amount)
+		chain.PotentialSavings = roundAmount(chain.PotentialSavings + savings)
+	}
+
+	for _, chain := range chains {
+		report.Chains = append(report.Chains, *chain)
+		report.Total.ClaimCount += chain.ClaimCount
+		report.Total.BrandAmount = roundAmount(report.Total.BrandAmount + chain.BrandAmount)
+		report.Total.PotentialSavings = roundAmount(report.Total.PotentialSavings + chain.PotentialSavings)
+	}
+	sort.Slice(report.Chains, func(i, j int) bool { return report.Chains[i].Chain < report.Chains[j].Chain })
+	return report, nil
+}
+
+// substitutes returns the cheapest generic equivalent, by average unit price over the claim
+// totals of every pharmacy, of each brand NDC of the totals whose own average unit price is higher.
+func (s *substitutionService) substitutes(totals []models.NDCClaimTotals) map[string]substitute {
+	quantities := make(map[string]float64)
+	amounts := make(map[string]float64)
+	for _, total := range totals {
+		quantities[total.NDC] += total.Quantity
+		amounts[total.NDC] += total.Amount
+	}
+
+	substitutes := make(map[string]substitute)
+	for ndc, quantity := range quantities {
+		brand, ok := s.catalog.Drug(ndc)
+		if !ok || brand.Generic {
+			continue
+		}
+		brandUnitPrice := amounts[ndc] / quantity
+		for _, generic := range s.catalog.Generics(ndc) {
+			genericQuantity, billed := quantities[generic.NDC]
+			if !billed {
+				continue
+			}
+			unitPrice := amounts[generic.NDC] / genericQuantity
+			if cheapest, found := substitutes[ndc]; unitPrice < brandUnitPrice && (!found || unitPrice < cheapest.unitPrice) {
+				substitutes[ndc] = substitute{brand: brand, generic: generic, unitPrice: unitPrice}
+			}
+		}
+	}
+	return substitutes
+}
+
+// substitutionCSVHeader is the header row of the exported generic substitution report.
+var substitutionCSVHeader = []string{
+	"npi", "chain", "brand_ndc", "brand_name", "generic_ndc", "generic_name", "ingredient", "strength", "form",
+	"claim_count", "quantity", "brand_amount", "brand_unit_price", "generic_unit_price", "potential_savings",
+}
+
+// ExportSubstitutionReport returns the generic substitution report of the period as a CSV file,
+// one row per pharmacy and brand NDC.
+func (s *substitutionService) ExportSubstitutionReport(from, to, date string) (*models.ReportExport, error) {
+	report, err := s.GetSubstitutionReport(from, to, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(substitutionCSVHeader)
+	amount := func(value float64) string { return strconv.FormatFloat(value, 'f', -1, 64) }
+	for _, pharmacy := range report.Pharmacies {
+		for _, o := range pharmacy.Opportunities {
+			writer.Write([]string{
+				pharmacy.NPI, pharmacy.Chain, o.BrandNDC, o.BrandName, o.GenericNDC, o.GenericName, o.Ingredient, o.Strength, o.Form,
+				strconv.Itoa(o.ClaimCount), amount(o.Quantity), amount(o.BrandAmount), amount(o.BrandUnitPrice),
+				amount(o.GenericUnitPrice), amount(o.PotentialSavings),
+			})
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		s.logger.Error("Error writing generic substitution report from %s to %s: %v", from, to, err)
+		return nil, errors.New("internal error exporting generic substitution report")
+	}
+
+	return &models.ReportExport{
+		FileName: fmt.Sprintf("generic_substitution_%s_%s_%s.csv", strings.ReplaceAll(from, "-", ""), strings.ReplaceAll(to, "-", ""), report.Date),
+		Content:  buf.Bytes(),
+	}, nil
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/models"
+)
+
+// timelyFilingRule is the rule name reported with the reject of a claim submitted too late.
+const timelyFilingRule = "timely_filing"
+
+// TimelyFilingPolicy holds the time allowed between the date of service of a claim and its
+// submission. Claims without a date of service are dated by their submission and always on time.
+type TimelyFilingPolicy struct {
+	Limit      time.Duration            // Maximum time from the date of service to the submission; zero allows any age
+	ChainLimit map[string]time.Duration // Overrides of Limit for the pharmacies of a chain
+}
+
+// Check returns the reject of a claim served on date and received at received from a pharmacy of
+// the chain, when the time between both days is over the filing limit.
+func (p TimelyFilingPolicy) Check(date time.Time, chain string, received time.Time) (models.ClaimReject, bool) {
+	limit, scope := p.Limit, "claims"
+	if chainLimit, ok := p.ChainLimit[chain]; ok {
+		limit, scope = chainLimit, fmt.Sprintf("claims of chain '%s'", chain)
+	}
+	if limit <= 0 {
+		return models.ClaimReject{}, false
+	}
+
+	served := dateOnly(date)
+	if age := dateOnly(received).Sub(served); age > limit {
+		return models.ClaimReject{
+			Code: models.RejectClaimTooOld,
+			Rule: timelyFilingRule,
+			Message: fmt.Sprintf("claim too old: date of service %s is %s before submission; %s must be filed within %s, by %s",
+				served.Format(periodDateLayout), formatAge(age), scope, formatAge(limit), served.Add(limit).Format(periodDateLayout)),
+		}, true
+	}
+	return models.ClaimReject{}, false
+}
+
+// hasChainRules reports whether the policy depends on the chain of the pharmacy.
+func (p TimelyFilingPolicy) hasChainRules() bool {
+	return len(p.ChainLimit) > 0
+}
+
+// timelyFilingReject returns the reject of a claim submitted now by a pharmacy of the chain after
+// the timely filing limit of its date of service.
+func (s *claimService) timelyFilingReject(req models.ClaimSubmissionRequest, chain string) (models.ClaimReject, bool) {
+	now := time.Now()
+	return s.cfg.TimelyFiling.Check(serviceDate(req.Prescription, now), chain, now)
+}
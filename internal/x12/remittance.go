@@ -0,0 +1,226 @@
+package x12
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Delimiters used in the generated interchanges.
+const (
+	ElementSeparator    = "*"
+	SubElementSeparator = ":"
+	RepetitionSeparator = "^"
+	SegmentTerminator   = "~"
+)
+
+const (
+	isaVersion             = "00501"
+	implementationGuide835 = "005010X221A1"
+	transactionSet835      = "835"
+)
+
+// Claim status codes (CLP02).
+const (
+	ClaimStatusProcessedAsPrimary = "1"
+	ClaimStatusReversal           = "22"
+)
+
+// Envelope holds the interchange (ISA/IEA) and functional group (GS/GE) identifiers.
+type Envelope struct {
+	SenderID                 string
+	ReceiverID               string
+	InterchangeControlNumber int64
+	GroupControlNumber       int64
+	Production               bool
+	CreatedAt                time.Time
+}
+
+// Code list qualifier (LQ01) of the NCPDP reject/payment codes.
+const codeListNCPDP = "RX"
+
+// Claim adjustment group (CAS01) and reason (CAS02) codes.
+const (
+	GroupContractualObligation  = "CO"
+	GroupPatientResponsibility  = "PR"
+	AdjustmentReasonDeductible  = "1"
+	AdjustmentReasonCoinsurance = "2"
+	AdjustmentReasonCopay       = "3"
+	AdjustmentReasonContractual = "45" // Charge exceeds the fee schedule or contracted amount
+)
+
+// Adjustment is an amount of the charge of a claim not paid, and why (CAS).
+type Adjustment struct {
+	Group  string
+	Reason string
+	Amount float64
+}
+
+// ClaimPayment is a single claim payment (CLP loop) of a remittance.
+type ClaimPayment struct {
+	ClaimID     string
+	NDC         string
+	Quantity    float64
+	Charge      float64
+	Paid        float64
+	PatientPay  float64      // Patient responsibility (CLP05)
+	Adjustments []Adjustment // Service adjustments, e.g. the patient pay by deductible and cost sharing
+	Reversal    bool
+	ReasonCode  string // NCPDP-style reason code of a reversal, reported in an LQ segment
+	ServiceDate time.Time
+}
+
+// Remittance835 is a health care claim payment/advice (835) for a single payee.
+type Remittance835 struct {
+	Envelope  Envelope
+	PayerName string
+	PayerID   string
+	PayeeName string
+	PayeeNPI  string
+	Claims    []ClaimPayment
+}
+
+// TotalPaid returns the net amount paid, with reversals subtracted.
+func (r *Remittance835) TotalPaid() float64 {
+	var total float64
+	for _, claim := range r.Claims {
+		if claim.Reversal {
+			total -= claim.Paid
+		} else {
+			total += claim.Paid
+		}
+	}
+	return math.Round(total*100) / 100
+}
+
+// Encode serializes the remittance as an X12 interchange with one 835 transaction set.
+// Reversals are reported as claim status 22 with negated amounts so they net against payments,
+// followed by their reason code when known. Adjustments follow the service line, one CAS per group.
+func (r *Remittance835) Encode() string {
+	env := r.Envelope
+	created := env.CreatedAt
+	usage := "T"
+	if env.Production {
+		usage = "P"
+	}
+	interchange := fmt.Sprintf("%09d", env.InterchangeControlNumber%1000000000)
+	group := fmt.Sprintf("%d", env.GroupControlNumber)
+
+	var sb strings.Builder
+	writeSegment(&sb, "ISA", "00", fixed("", 10), "00", fixed("", 10),
+		"ZZ", fixed(env.SenderID, 15), "ZZ", fixed(env.ReceiverID, 15),
+		created.Format("060102"), created.Format("1504"), RepetitionSeparator, isaVersion,
+		interchange, "0", usage, SubElementSeparator)
+	writeSegment(&sb, "GS", "HP", env.SenderID, env.ReceiverID, created.Format("20060102"), created.Format("1504"),
+		group, "X", implementationGuide835)
+
+	body := r.transactionSetBody()
+	controlNumber := "0001"
+	writeSegment(&sb, "ST", transactionSet835, controlNumber, implementationGuide835)
+	for _, segment := range body {
+		sb.WriteString(segment)
+	}
+	writeSegment(&sb, "SE", fmt.Sprintf("%d", len(body)+2), controlNumber)
+
+	writeSegment(&sb, "GE", "1", group)
+	writeSegment(&sb, "IEA", "1", interchange)
+	return sb.String()
+}
+
+// transactionSetBody returns the segments between ST and SE.
+func (r *Remittance835) transactionSetBody() []string {
+	created := r.Envelope.CreatedAt
+	total := r.TotalPaid()
+
+	var segments []string
+	add := func(id string, elements ...string) {
+		var sb strings.Builder
+		writeSegment(&sb, id, elements...)
+		segments = append(segments, sb.String())
+	}
+
+	if total > 0 {
+		add("BPR", "I", amount(total), "C", "CHK", "", "", "", "", "", "", "", "", "", "", "", created.Format("20060102"))
+	} else {
+		add("BPR", "H", amount(0), "C", "NON", "", "", "", "", "", "", "", "", "", "", "", created.Format("20060102"))
+	}
+	add("TRN", "1", fmt.Sprintf("%d", r.Envelope.InterchangeControlNumber), "1"+r.PayerID)
+	add("DTM", "405", created.Format("20060102"))
+	add("N1", "PR", r.PayerName)
+	add("N1", "PE", r.PayeeName, "XX", r.PayeeNPI)
+
+	add("LX", "1")
+	for _, claim := range r.Claims {
+		status := ClaimStatusProcessedAsPrimary
+		charge, paid, patientPay, sign := claim.Charge, claim.Paid, claim.PatientPay, 1.0
+		if claim.Reversal {
+			status = ClaimStatusReversal
+			sign = -1
+			charge, paid, patientPay = -charge, -paid, -patientPay
+		}
+		patientResponsibility := ""
+		if patientPay != 0 {
+			patientResponsibility = amount(patientPay)
+		}
+		add("CLP", claim.ClaimID, status, amount(charge), amount(paid), patientResponsibility, "ZZ", claim.ClaimID)
+		add("DTM", "050", claim.ServiceDate.Format("20060102"))
+		add("SVC", "N4"+SubElementSeparator+claim.NDC, amount(charge), amount(paid), "", quantity(claim.Quantity))
+		for _, cas := range adjustmentSegments(claim.Adjustments, sign) {
+			add("CAS", cas...)
+		}
+		if claim.Reversal && claim.ReasonCode != "" {
+			add("LQ", codeListNCPDP, claim.ReasonCode)
+		}
+	}
+	return segments
+}
+
+// adjustmentSegments returns the elements of the CAS segments of the adjustments, one segment per
+// group in the order the groups first appear, with the amounts multiplied by sign. Adjustments of
+// zero are left out.
+func adjustmentSegments(adjustments []Adjustment, sign float64) [][]string {
+	var segments [][]string
+	groups := make(map[string]int)
+	for _, adjustment := range adjustments {
+		if adjustment.Amount == 0 {
+			continue
+		}
+		i, ok := groups[adjustment.Group]
+		if !ok {
+			i = len(segments)
+			groups[adjustment.Group] = i
+			segments = append(segments, []string{adjustment.Group})
+		} else {
+			// CAS04 (quantity) is left empty between reason/amount pairs
+			segments[i] = append(segments[i], "")
+		}
+		segments[i] = append(segments[i], adjustment.Reason, amount(sign*adjustment.Amount))
+	}
+	return segments
+}
+
+func writeSegment(sb *strings.Builder, id string, elements ...string) {
+	sb.WriteString(id)
+	for _, element := range elements {
+		sb.WriteString(ElementSeparator)
+		sb.WriteString(element)
+	}
+	sb.WriteString(SegmentTerminator)
+}
+
+// fixed left-aligns value in a space-filled element of the given width, as required by ISA.
+func fixed(value string, width int) string {
+	if len(value) >= width {
+		return value[:width]
+	}
+	return value + strings.Repeat(" ", width-len(value))
+}
+
+func amount(value float64) string {
+	return fmt.Sprintf("%.2f", math.Round(value*100)/100)
+}
+
+func quantity(value float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+}
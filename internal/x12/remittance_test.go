@@ -0,0 +1,82 @@
+package x12_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diogocarasco/go-pharmacy-service/internal/x12"
+)
+
+func testRemittance() *x12.Remittance835 {
+	serviceDate := time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)
+	return &x12.Remittance835{
+		Envelope: x12.Envelope{
+			SenderID:                 "PHARMACYCLAIMS",
+			ReceiverID:               "1234567890",
+			InterchangeControlNumber: 42,
+			GroupControlNumber:       7,
+			CreatedAt:                time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC),
+		},
+		PayerName: "PHARMACY CLAIM SERVICE",
+		PayerID:   "999999999",
+		PayeeName: "health",
+		PayeeNPI:  "1234567890",
+		Claims: []x12.ClaimPayment{
+			{ClaimID: "claim-1", NDC: "00002323401", Quantity: 30, Charge: 100, Paid: 100, ServiceDate: serviceDate},
+			{ClaimID: "claim-2", NDC: "00054027225", Quantity: 2.5, Charge: 20.5, Paid: 20.5, ServiceDate: serviceDate},
+			{ClaimID: "claim-2", NDC: "00054027225", Quantity: 2.5, Charge: 20.5, Paid: 20.5, Reversal: true, ReasonCode: "07", ServiceDate: serviceDate},
+		},
+	}
+}
+
+func TestRemittanceEnvelope(t *testing.T) {
+	segments := strings.Split(strings.TrimSuffix(testRemittance().Encode(), x12.SegmentTerminator), x12.SegmentTerminator)
+
+	isa := segments[0]
+	assert.Len(t, isa, 105, "ISA must be fixed width (106 characters with the terminator)")
+	assert.True(t, strings.HasSuffix(isa, "*000000042*0*T*:"), "ISA should carry the interchange control number")
+	assert.Equal(t, "GS*HP*PHARMACYCLAIMS*1234567890*20240301*0830*7*X*005010X221A1", segments[1])
+	assert.Equal(t, "ST*835*0001*005010X221A1", segments[2])
+
+	var stIndex, seIndex int
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, "ST*"):
+			stIndex = i
+		case strings.HasPrefix(segment, "SE*"):
+			seIndex = i
+			assert.Equal(t, "SE*"+strconv.Itoa(seIndex-stIndex+1)+"*0001", segment, "SE01 must count ST through SE")
+		}
+	}
+	assert.Equal(t, "GE*1*7", segments[len(segments)-2])
+	assert.Equal(t, "IEA*1*000000042", segments[len(segments)-1])
+}
+
+func TestRemittanceNetsReversals(t *testing.T) {
+	remittance := testRemittance()
+	content := remittance.Encode()
+
+	assert.Equal(t, 100.0, remittance.TotalPaid(), "Reversal should net against its payment")
+	assert.Contains(t, content, "BPR*I*100.00*C*CHK")
+	assert.Contains(t, content, "CLP*claim-2*1*20.50*20.50**ZZ*claim-2")
+	assert.Contains(t, content, "CLP*claim-2*22*-20.50*-20.50**ZZ*claim-2")
+	assert.Contains(t, content, "SVC*N4:00054027225*-20.50*-20.50**2.5~LQ*RX*07~")
+}
+
+func TestRemittancePatientResponsibility(t *testing.T) {
+	remittance := testRemittance()
+	remittance.Claims[0].Paid, remittance.Claims[0].PatientPay = 90, 10
+	remittance.Claims[0].Adjustments = []x12.Adjustment{
+		{Group: x12.GroupPatientResponsibility, Reason: x12.AdjustmentReasonDeductible, Amount: 0},
+		{Group: x12.GroupPatientResponsibility, Reason: x12.AdjustmentReasonCopay, Amount: 10},
+	}
+	content := remittance.Encode()
+
+	assert.Equal(t, 90.0, remittance.TotalPaid(), "Patient pay should not be paid by the plan")
+	assert.Contains(t, content, "CLP*claim-1*1*100.00*90.00*10.00*ZZ*claim-1~")
+	assert.Contains(t, content, "SVC*N4:00002323401*100.00*90.00**30~CAS*PR*3*10.00~CLP*claim-2", "Adjustments of zero should be left out")
+}